@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"orchestrator/pkg/architect"
+	"orchestrator/pkg/dispatch"
+)
+
+// ClarificationProvider interface for architects that ask a human about a spec before generating stories.
+type ClarificationProvider interface {
+	PendingClarification() *architect.SpecClarification
+	AnswerClarification(id string, answers map[string]string) error
+}
+
+var (
+	errInputClosed       = errors.New("no terminal input")
+	errClarificationGone = errors.New("clarification no longer pending")
+)
+
+// startClarificationPrompt lets the operator answer the architect's spec questions on the terminal.
+func startClarificationPrompt(ctx context.Context, architectAgent dispatch.Agent) {
+	provider, ok := architectAgent.(ClarificationProvider)
+	if !ok {
+		return
+	}
+	go promptForClarifications(ctx, provider, os.Stdin, os.Stdout, time.Second)
+}
+
+// promptForClarifications polls for spec questions and reads the answers from in. The questions
+// can also be answered in the web UI; whichever answer arrives first wins.
+func promptForClarifications(ctx context.Context, provider ClarificationProvider, in io.Reader, out io.Writer, interval time.Duration) {
+	var lines <-chan string
+	var lastID string
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		clarification := provider.PendingClarification()
+		if clarification == nil || clarification.ID == lastID {
+			continue
+		}
+		lastID = clarification.ID
+
+		// Start reading input only once there is something to answer
+		if lines == nil {
+			lines = readLines(ctx, in)
+		}
+
+		answers, err := askClarification(ctx, provider, clarification, lines, out, interval)
+		switch {
+		case errors.Is(err, errInputClosed):
+			fmt.Fprintln(out, "No terminal input available - answer the spec questions in the web UI, or wait for the timeout.")
+			return
+		case errors.Is(err, errClarificationGone):
+			fmt.Fprintln(out, "The spec questions were answered elsewhere or timed out.")
+			continue
+		case err != nil:
+			return
+		}
+
+		if err := provider.AnswerClarification(clarification.ID, answers); err != nil {
+			fmt.Fprintf(out, "Could not send answers: %v\n", err)
+			continue
+		}
+		fmt.Fprintf(out, "✅ Sent %d answers to the architect.\n\n", len(answers))
+	}
+}
+
+// askClarification prints each question and reads its answer. An empty answer accepts the
+// question's assumption, and "proceed" accepts the assumptions of all remaining questions.
+func askClarification(ctx context.Context, provider ClarificationProvider, clarification *architect.SpecClarification, lines <-chan string, out io.Writer, interval time.Duration) (map[string]string, error) {
+	fmt.Fprintf(out, "\n❓ The architect has %d questions about the spec before generating stories.\n", len(clarification.Questions))
+	fmt.Fprintf(out, "Press Enter to accept a question's assumption, or type 'proceed' to accept all remaining assumptions.\n")
+	fmt.Fprintf(out, "Unanswered questions proceed with their assumptions at %s.\n", clarification.Deadline.Local().Format(time.Kitchen))
+
+	answers := make(map[string]string)
+	for i := range clarification.Questions {
+		question := &clarification.Questions[i]
+		fmt.Fprintf(out, "\n%d. %s\n", i+1, question.Question)
+		if question.Why != "" {
+			fmt.Fprintf(out, "   (%s)\n", question.Why)
+		}
+		fmt.Fprintf(out, "   Assumption: %s\n> ", question.Assumption)
+
+		answer, err := waitForLine(ctx, provider, clarification.ID, lines, interval)
+		if err != nil {
+			return nil, err
+		}
+		answer = strings.TrimSpace(answer)
+		if strings.EqualFold(answer, "proceed") {
+			break
+		}
+		if answer != "" {
+			answers[question.ID] = answer
+		}
+	}
+	return answers, nil
+}
+
+// waitForLine waits for a line of input while the clarification is still pending.
+func waitForLine(ctx context.Context, provider ClarificationProvider, id string, lines <-chan string, interval time.Duration) (string, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return "", errInputClosed
+			}
+			return line, nil
+		case <-ticker.C:
+			if pending := provider.PendingClarification(); pending == nil || pending.ID != id {
+				return "", errClarificationGone
+			}
+		case <-ctx.Done():
+			return "", fmt.Errorf("clarification prompt cancelled: %w", ctx.Err())
+		}
+	}
+}
+
+// readLines delivers the lines of in on a channel that is closed at the end of input.
+func readLines(ctx context.Context, in io.Reader) <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(in)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return lines
+}
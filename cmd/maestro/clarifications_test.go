@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"orchestrator/pkg/architect"
+)
+
+// fakeClarifier records the answers given to a single pending clarification.
+type fakeClarifier struct {
+	pending  *architect.SpecClarification
+	answered chan map[string]string
+	mu       sync.Mutex
+}
+
+func (f *fakeClarifier) PendingClarification() *architect.SpecClarification {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.pending
+}
+
+func (f *fakeClarifier) AnswerClarification(id string, answers map[string]string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.pending == nil || f.pending.ID != id {
+		return architect.ErrNoPendingClarification
+	}
+	f.pending = nil
+	f.answered <- answers
+	return nil
+}
+
+// TestPromptForClarifications tests answering spec questions on the terminal.
+func TestPromptForClarifications(t *testing.T) {
+	provider := &fakeClarifier{
+		answered: make(chan map[string]string, 1),
+		pending: &architect.SpecClarification{
+			ID:       "clarify-1",
+			Deadline: time.Now().Add(time.Minute),
+			Questions: []architect.ClarificationQuestion{
+				{ID: "q1", Question: "Which database?", Why: "Affects the data layer", Assumption: "SQLite"},
+				{ID: "q2", Question: "Is auth required?", Assumption: "No"},
+				{ID: "q3", Question: "Which port?", Assumption: "8080"},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var out bytes.Buffer
+	// Blank line keeps the first assumption, "proceed" keeps the rest.
+	go promptForClarifications(ctx, provider, strings.NewReader("\nyes, with OAuth\nproceed\n"), &out, 10*time.Millisecond)
+
+	select {
+	case answers := <-provider.answered:
+		if len(answers) != 1 || answers["q2"] != "yes, with OAuth" {
+			t.Errorf("Expected only q2 to be answered, got %v", answers)
+		}
+	case <-ctx.Done():
+		t.Fatal("Timed out waiting for answers")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"orchestrator/pkg/agent"
 	"orchestrator/pkg/config"
 	"orchestrator/pkg/dispatch"
+	"orchestrator/pkg/persistence"
 	"orchestrator/pkg/proto"
 )
 
@@ -64,6 +65,9 @@ func (f *BootstrapFlow) Run(ctx context.Context, k *kernel.Kernel) error {
 	}
 	supervisor.RegisterAgent(ctx, "architect-001", string(agent.TypeArchitect), architect)
 
+	// Keep stories that coders were working on before a restart assigned to them
+	restoreInFlightStories(k, architect)
+
 	// Create and register coder agents based on config
 	numCoders := updatedConfig.Agents.MaxCoders
 	for i := 0; i < numCoders; i++ {
@@ -77,6 +81,9 @@ func (f *BootstrapFlow) Run(ctx context.Context, k *kernel.Kernel) error {
 
 	k.Logger.Info("✅ Created and registered architect and %d coders", numCoders)
 
+	// Let the operator answer the architect's questions about the spec
+	startClarificationPrompt(ctx, architect)
+
 	// Inject spec into architect
 	if specErr := InjectSpec(k.Dispatcher, "bootstrap", specContent); specErr != nil {
 		return fmt.Errorf("failed to inject spec content: %w", specErr)
@@ -114,6 +121,39 @@ type StateProvider interface {
 	GetCurrentState() proto.State
 }
 
+// StoryRestorer interface for agents that can take back stories in flight before a restart.
+type StoryRestorer interface {
+	RestoreInFlightStory(story *persistence.Story, agentID string)
+}
+
+// restoreInFlightStories hands the stories found in coder checkpoints to the architect.
+// It must run before the coders are created, since they resume their stories on start.
+func restoreInFlightStories(k *kernel.Kernel, architect dispatch.Agent) {
+	inFlight, err := k.InFlightStories()
+	if err != nil {
+		k.Logger.Warn("Failed to check for in-flight stories: %v", err)
+		return
+	}
+	if len(inFlight) == 0 {
+		return
+	}
+
+	restorer, ok := architect.(StoryRestorer)
+	if !ok {
+		k.Logger.Warn("Architect cannot restore in-flight stories; discarding %d coder checkpoints", len(inFlight))
+		for i := range inFlight {
+			if err := k.StateStore.DeleteState(inFlight[i].AgentID); err != nil {
+				k.Logger.Warn("Failed to remove checkpoint for %s: %v", inFlight[i].AgentID, err)
+			}
+		}
+		return
+	}
+	for i := range inFlight {
+		restorer.RestoreInFlightStory(inFlight[i].Story, inFlight[i].AgentID)
+	}
+	k.Logger.Info("♻️  Restored %d in-flight stories", len(inFlight))
+}
+
 // waitForArchitectCompletion waits for the architect to reach a terminal state.
 // This preserves the existing waitForArchitectCompletion logic.
 func (f *BootstrapFlow) waitForArchitectCompletion(ctx context.Context, architect dispatch.Agent) (proto.State, error) {
@@ -187,6 +227,9 @@ func (f *OrchestratorFlow) Run(ctx context.Context, k *kernel.Kernel) error {
 	}
 	supervisor.RegisterAgent(ctx, "architect-001", string(agent.TypeArchitect), architect)
 
+	// Keep stories that coders were working on before a restart assigned to them
+	restoreInFlightStories(k, architect)
+
 	// Create and register coder agents based on config
 	numCoders := k.Config.Agents.MaxCoders
 	for i := 0; i < numCoders; i++ {
@@ -200,6 +243,9 @@ func (f *OrchestratorFlow) Run(ctx context.Context, k *kernel.Kernel) error {
 
 	k.Logger.Info("✅ Created and registered architect and %d coders", numCoders)
 
+	// Let the operator answer the architect's questions about specs
+	startClarificationPrompt(ctx, architect)
+
 	// Handle initial spec if provided
 	if f.specFile != "" {
 		specContent, err := os.ReadFile(f.specFile)
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"orchestrator/pkg/architect"
+	"orchestrator/pkg/config"
+	"orchestrator/pkg/persistence"
+)
+
+// runGraphCommand implements `maestro graph`: it prints the story dependency graph stored in the
+// project database. A running orchestrator serves the live graph at /api/graph instead.
+func runGraphCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("graph", flag.ContinueOnError)
+	var (
+		format     = fs.String("format", architect.GraphFormatDOT, "Output format: dot, mermaid or json")
+		specID     = fs.String("spec", "", "Only include stories of this spec")
+		projectDir = fs.String("projectdir", ".", "Project directory")
+	)
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("invalid graph arguments: %w", err)
+	}
+
+	stories, err := loadProjectStories(*projectDir, *specID)
+	if err != nil {
+		return err
+	}
+	graph := architect.NewStoryGraph(stories)
+
+	if *format == architect.GraphFormatJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(graph); err != nil {
+			return fmt.Errorf("failed to encode graph: %w", err)
+		}
+		return nil
+	}
+
+	rendered, err := graph.Render(*format)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, rendered)
+	return err
+}
+
+// loadProjectStories opens the project database read-only and loads the stories of a spec, or all stories.
+func loadProjectStories(projectDir, specID string) ([]*persistence.Story, error) {
+	dbPath := filepath.Join(projectDir, ".maestro", config.DatabaseFilename)
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("no database found at %s - run maestro first: %w", dbPath, err)
+	}
+
+	db, err := persistence.OpenDatabaseReadOnly(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	return loadStories(persistence.NewDatabaseOperations(db), specID)
+}
+
+// loadStories loads the stories of a spec, or all stories, with their dependencies.
+func loadStories(ops *persistence.DatabaseOperations, specID string) ([]*persistence.Story, error) {
+	if specID != "" {
+		stories, err := ops.GetStoriesBySpec(specID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load stories of spec %s: %w", specID, err)
+		}
+		return stories, nil
+	}
+
+	stories, err := ops.GetAllStories()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stories: %w", err)
+	}
+	for _, story := range stories {
+		dependencies, err := ops.GetStoryDependencies(story.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load dependencies of story %s: %w", story.ID, err)
+		}
+		story.DependsOn = dependencies
+	}
+	return stories, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"orchestrator/pkg/config"
+	"orchestrator/pkg/persistence"
+)
+
+// TestRunGraphCommand tests exporting the story graph from the project database.
+func TestRunGraphCommand(t *testing.T) {
+	projectDir := t.TempDir()
+	if err := runGraphCommand([]string{"--projectdir", projectDir}, &bytes.Buffer{}); err == nil {
+		t.Fatal("Expected an error without a database")
+	}
+
+	maestroDir := filepath.Join(projectDir, ".maestro")
+	if err := os.MkdirAll(maestroDir, 0755); err != nil {
+		t.Fatalf("Failed to create .maestro: %v", err)
+	}
+	db, err := persistence.InitializeDatabase(filepath.Join(maestroDir, config.DatabaseFilename))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	ops := persistence.NewDatabaseOperations(db)
+	if err := ops.UpsertSpec(&persistence.Spec{ID: "spec-1", Content: "# Spec", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("Failed to insert spec: %v", err)
+	}
+	for _, story := range []*persistence.Story{
+		{ID: "001", SpecID: "spec-1", Title: "Set up project", Status: persistence.StatusDone, StoryType: "devops", CreatedAt: time.Now()},
+		{ID: "002", SpecID: "spec-1", Title: "Add login", Status: persistence.StatusCoding, StoryType: "app", AssignedAgent: "coder-001", CreatedAt: time.Now()},
+	} {
+		if err := ops.UpsertStory(story); err != nil {
+			t.Fatalf("Failed to insert story %s: %v", story.ID, err)
+		}
+	}
+	if err := ops.AddStoryDependency("002", "001"); err != nil {
+		t.Fatalf("Failed to add dependency: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Failed to close database: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := runGraphCommand([]string{"--projectdir", projectDir, "--format", "mermaid"}, &out); err != nil {
+		t.Fatalf("runGraphCommand failed: %v", err)
+	}
+	for _, want := range []string{"flowchart LR", "s_001 --> s_002", "coding · coder-001"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := runGraphCommand([]string{"--projectdir", projectDir, "--spec", "other"}, &out); err != nil {
+		t.Fatalf("runGraphCommand failed: %v", err)
+	}
+	if strings.Contains(out.String(), "->") {
+		t.Errorf("Expected no edges for an unknown spec, got:\n%s", out.String())
+	}
+}
@@ -15,6 +15,29 @@ import (
 )
 
 func main() {
+	// Subcommands are dispatched before flag parsing
+	if len(os.Args) > 1 && os.Args[1] == "mcp" {
+		if err := runMCPCommand(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "MCP mode failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "graph" {
+		if err := runGraphCommand(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Graph export failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "schedule" {
+		if err := runScheduleCommand(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Schedule dry run failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Parse command line flags
 	var (
 		gitRepo    = flag.String("git-repo", "", "Git repository URL for bootstrap mode")
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"orchestrator/internal/kernel"
+	"orchestrator/pkg/config"
+	"orchestrator/pkg/logx"
+	"orchestrator/pkg/mcpserver"
+	"orchestrator/pkg/tools"
+)
+
+// MCP transports supported by `maestro mcp`.
+const (
+	mcpTransportStdio = "stdio"
+	mcpTransportHTTP  = "http"
+
+	// mcpTokenEnv supplies the bearer token for the http transport.
+	mcpTokenEnv = "MAESTRO_MCP_TOKEN"
+)
+
+// runMCPCommand implements `maestro mcp`: it runs the orchestrator and serves an MCP endpoint
+// that IDE assistants can use to query and steer the run.
+func runMCPCommand(args []string) error {
+	fs := flag.NewFlagSet("mcp", flag.ContinueOnError)
+	var (
+		transport   = fs.String("transport", mcpTransportStdio, "MCP transport: stdio or http")
+		host        = fs.String("host", "127.0.0.1", "Address the http transport listens on")
+		port        = fs.Int("port", 8090, "Port for the http transport")
+		token       = fs.String("token", os.Getenv(mcpTokenEnv), "Bearer token for the http transport (default $"+mcpTokenEnv+", or a generated token)")
+		projectDir  = fs.String("projectdir", ".", "Project directory")
+		exposeTools = fs.String("expose-tools", "", "Comma-separated Maestro tools to expose in addition to project operations (only read-only tools are accepted)")
+	)
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("invalid mcp arguments: %w", err)
+	}
+	if *transport != mcpTransportStdio && *transport != mcpTransportHTTP {
+		return fmt.Errorf("unknown transport %q (expected %s or %s)", *transport, mcpTransportStdio, mcpTransportHTTP)
+	}
+	if !configExists(*projectDir) {
+		return fmt.Errorf("no configuration found at %s/.maestro/config.json - run maestro in bootstrap mode first", *projectDir)
+	}
+
+	if *transport == mcpTransportHTTP && *token == "" {
+		generated, err := generateMCPToken()
+		if err != nil {
+			return err
+		}
+		*token = generated
+		fmt.Fprintf(os.Stderr, "MCP bearer token: %s\n", *token)
+	}
+
+	logger := logx.NewLogger("maestro-mcp")
+	logger.Info("Starting Maestro in MCP mode (%s)", *transport)
+
+	k, ctx, err := initializeKernel(*projectDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize kernel: %w", err)
+	}
+	defer func() {
+		if stopErr := k.Stop(); stopErr != nil {
+			logger.Error("Error stopping kernel: %v", stopErr)
+		}
+	}()
+
+	addr := net.JoinHostPort(*host, strconv.Itoa(*port))
+	flow := NewMCPFlow(*transport, addr, *token, *projectDir, splitToolList(*exposeTools), os.Stdout)
+	return flow.Run(ctx, k)
+}
+
+// generateMCPToken returns a random bearer token for the http transport.
+func generateMCPToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate MCP token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// splitToolList parses a comma-separated tool list.
+func splitToolList(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// MCPFlow runs the main orchestrator flow alongside an MCP server.
+type MCPFlow struct {
+	out         io.Writer // Receives stdio protocol frames
+	transport   string
+	addr        string
+	token       string
+	projectDir  string
+	exposeTools []string
+}
+
+// NewMCPFlow creates a new MCP flow. addr and token configure the http transport; out receives
+// protocol frames on the stdio transport.
+func NewMCPFlow(transport, addr, token, projectDir string, exposeTools []string, out io.Writer) *MCPFlow {
+	return &MCPFlow{
+		out:         out,
+		transport:   transport,
+		addr:        addr,
+		token:       token,
+		projectDir:  projectDir,
+		exposeTools: exposeTools,
+	}
+}
+
+// Run starts the agents and serves MCP until the client disconnects (stdio) or the
+// context is cancelled.
+func (f *MCPFlow) Run(ctx context.Context, k *kernel.Kernel) error {
+	server := mcpserver.NewServer("maestro", config.SchemaVersion)
+	mcpserver.RegisterProjectTools(server, k.Dispatcher)
+
+	if len(f.exposeTools) > 0 {
+		// No executor: only read-only tools are exposed.
+		agentCtx := tools.AgentContext{
+			WorkDir:  f.projectDir,
+			ReadOnly: true,
+		}
+		if err := mcpserver.RegisterRegistryTools(server, agentCtx, f.exposeTools); err != nil {
+			return fmt.Errorf("failed to expose tools: %w", err)
+		}
+	}
+
+	flowCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The orchestrator flow blocks until flowCtx is cancelled.
+	flowErr := make(chan error, 1)
+	go func() {
+		flowErr <- NewMainFlow("", false).Run(flowCtx, k)
+	}()
+
+	var serveErr error
+	switch f.transport {
+	case mcpTransportHTTP:
+		serveErr = server.StartHTTP(flowCtx, f.addr, f.token)
+	default:
+		serveErr = server.ServeStdio(flowCtx, os.Stdin, f.out)
+		k.Logger.Info("MCP client disconnected, shutting down")
+	}
+
+	cancel()
+	if err := <-flowErr; err != nil {
+		return fmt.Errorf("orchestrator flow failed: %w", err)
+	}
+	if serveErr != nil {
+		return fmt.Errorf("MCP server failed: %w", serveErr)
+	}
+	return nil
+}
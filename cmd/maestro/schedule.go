@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"orchestrator/pkg/architect"
+	"orchestrator/pkg/config"
+)
+
+// defaultScheduleMaxCoders matches the max_coders default of a new project config.
+const defaultScheduleMaxCoders = 2
+
+// runScheduleCommand implements `maestro schedule`: a dry run that prints the schedule the
+// architect would follow for the unfinished stories in the project database. Nothing is dispatched.
+func runScheduleCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("schedule", flag.ContinueOnError)
+	var (
+		maxCoders  = fs.Int("max-coders", 0, "Number of coders to schedule for (default: agents.max_coders from the project config)")
+		specID     = fs.String("spec", "", "Only include stories of this spec")
+		projectDir = fs.String("projectdir", ".", "Project directory")
+		format     = fs.String("format", "text", "Output format: text or json")
+	)
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("invalid schedule arguments: %w", err)
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unknown schedule format %q (expected text or json)", *format)
+	}
+
+	coders := *maxCoders
+	if coders <= 0 {
+		var err error
+		if coders, err = projectMaxCoders(*projectDir); err != nil {
+			return err
+		}
+	}
+
+	stories, err := loadProjectStories(*projectDir, *specID)
+	if err != nil {
+		return err
+	}
+	projection := architect.NewQueueFromStories(stories).ProjectSchedule(coders)
+
+	if *format == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(projection); err != nil {
+			return fmt.Errorf("failed to encode schedule: %w", err)
+		}
+		return nil
+	}
+	_, err = io.WriteString(out, projection.Format())
+	return err
+}
+
+// projectMaxCoders reads agents.max_coders from the project config without validating the rest of
+// it, so a dry run works without API keys or tools.
+func projectMaxCoders(projectDir string) (int, error) {
+	configPath := filepath.Join(projectDir, config.ProjectConfigDir, config.ProjectConfigFilename)
+	data, err := os.ReadFile(configPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return defaultScheduleMaxCoders, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read config file %s: %w", configPath, err)
+	}
+
+	var cfg config.Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return 0, fmt.Errorf("failed to parse config JSON %s: %w", configPath, err)
+	}
+	if cfg.Agents == nil || cfg.Agents.MaxCoders <= 0 {
+		return defaultScheduleMaxCoders, nil
+	}
+	return cfg.Agents.MaxCoders, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"orchestrator/pkg/architect"
+	"orchestrator/pkg/config"
+	"orchestrator/pkg/persistence"
+)
+
+// TestRunScheduleCommand tests the scheduling dry run against the project database.
+func TestRunScheduleCommand(t *testing.T) {
+	projectDir := t.TempDir()
+	maestroDir := filepath.Join(projectDir, ".maestro")
+	if err := os.MkdirAll(maestroDir, 0755); err != nil {
+		t.Fatalf("Failed to create .maestro: %v", err)
+	}
+	db, err := persistence.InitializeDatabase(filepath.Join(maestroDir, config.DatabaseFilename))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	ops := persistence.NewDatabaseOperations(db)
+	if err := ops.UpsertSpec(&persistence.Spec{ID: "spec-1", Content: "# Spec", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("Failed to insert spec: %v", err)
+	}
+	for _, story := range []*persistence.Story{
+		{ID: "001", SpecID: "spec-1", Title: "Set up project", Status: persistence.StatusNew, StoryType: "devops", EstimatedPoints: 2, CreatedAt: time.Now()},
+		{ID: "002", SpecID: "spec-1", Title: "Add login", Status: persistence.StatusNew, StoryType: "app", EstimatedPoints: 3, CreatedAt: time.Now()},
+		{ID: "003", SpecID: "spec-1", Title: "Fix typo", Status: persistence.StatusNew, StoryType: "app", EstimatedPoints: 1, Priority: 1, CreatedAt: time.Now()},
+	} {
+		if err := ops.UpsertStory(story); err != nil {
+			t.Fatalf("Failed to insert story %s: %v", story.ID, err)
+		}
+	}
+	if err := ops.AddStoryDependency("002", "001"); err != nil {
+		t.Fatalf("Failed to add dependency: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Failed to close database: %v", err)
+	}
+
+	// Without a project config the default of two coders applies
+	var out bytes.Buffer
+	if err := runScheduleCommand([]string{"--projectdir", projectDir}, &out); err != nil {
+		t.Fatalf("runScheduleCommand failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "Projected schedule for 2 coder(s): 6 points of work finish after 5 points") {
+		t.Errorf("Unexpected schedule:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := runScheduleCommand([]string{"--projectdir", projectDir, "--max-coders", "1", "--format", "json"}, &out); err != nil {
+		t.Fatalf("runScheduleCommand failed: %v", err)
+	}
+	var projection architect.ScheduleProjection
+	if err := json.Unmarshal(out.Bytes(), &projection); err != nil {
+		t.Fatalf("Failed to decode schedule: %v", err)
+	}
+	if projection.Makespan != 6 || len(projection.Stories) != 3 || projection.Stories[0].ID != "003" {
+		t.Errorf("Expected the prioritized story first on a single coder, got %+v", projection)
+	}
+
+	if err := runScheduleCommand([]string{"--projectdir", projectDir, "--format", "svg"}, &out); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -12,7 +12,9 @@ import (
 	"orchestrator/pkg/coder"
 	"orchestrator/pkg/config"
 	"orchestrator/pkg/dispatch"
+	"orchestrator/pkg/logx"
 	"orchestrator/pkg/persistence"
+	"orchestrator/pkg/state"
 )
 
 // AgentFactory creates agents with minimal dependencies.
@@ -20,13 +22,15 @@ import (
 type AgentFactory struct {
 	dispatcher         *dispatch.Dispatcher
 	persistenceChannel chan<- *persistence.Request
+	stateStore         *state.Store // Coder checkpoints; nil disables resume
 }
 
 // NewAgentFactory creates a new lightweight agent factory.
-func NewAgentFactory(dispatcher *dispatch.Dispatcher, persistenceChannel chan<- *persistence.Request) *AgentFactory {
+func NewAgentFactory(dispatcher *dispatch.Dispatcher, persistenceChannel chan<- *persistence.Request, stateStore *state.Store) *AgentFactory {
 	return &AgentFactory{
 		dispatcher:         dispatcher,
 		persistenceChannel: persistenceChannel,
+		stateStore:         stateStore,
 	}
 }
 
@@ -132,6 +136,15 @@ func (f *AgentFactory) createCoder(ctx context.Context, agentID string) (dispatc
 	if err != nil {
 		return nil, fmt.Errorf("failed to create coder %s: %w", agentID, err)
 	}
+	coderAgent.SetPersistenceChannel(f.persistenceChannel)
+
+	// Pick up the story this coder was working on before a restart, if any
+	coderAgent.SetStateStore(f.stateStore)
+	if storyID, err := coderAgent.RestoreCheckpoint(); err != nil {
+		logx.Warnf("Coder %s starting fresh: %v", agentID, err)
+	} else if storyID != "" {
+		logx.Infof("Coder %s resuming story %s", agentID, storyID)
+	}
 
 	// Attach to dispatcher
 	f.dispatcher.Attach(coderAgent)
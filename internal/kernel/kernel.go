@@ -13,11 +13,15 @@ import (
 	_ "github.com/mattn/go-sqlite3" // SQLite driver
 
 	"orchestrator/pkg/build"
+	"orchestrator/pkg/coder"
 	"orchestrator/pkg/config"
 	"orchestrator/pkg/dispatch"
 	"orchestrator/pkg/limiter"
 	"orchestrator/pkg/logx"
 	"orchestrator/pkg/persistence"
+	"orchestrator/pkg/proto"
+	"orchestrator/pkg/state"
+	"orchestrator/pkg/tools"
 	"orchestrator/pkg/webui"
 )
 
@@ -39,7 +43,9 @@ type Kernel struct {
 	Database           *sql.DB
 	PersistenceChannel chan *persistence.Request
 	BuildService       *build.Service
+	StateStore         *state.Store // Coder checkpoints for resuming in-flight stories
 	WebServer          *webui.Server
+	MCPManager         *tools.MCPManager
 
 	// Runtime state
 	projectDir string
@@ -87,6 +93,12 @@ func (k *Kernel) initializeServices() error {
 		return fmt.Errorf("failed to initialize database: %w", err)
 	}
 
+	// Create state store for coder checkpoints
+	k.StateStore, err = state.NewStore(filepath.Join(k.projectDir, ".maestro", "states"))
+	if err != nil {
+		return fmt.Errorf("failed to create state store: %w", err)
+	}
+
 	// Create build service
 	k.BuildService = build.NewBuildService()
 
@@ -122,6 +134,69 @@ func (k *Kernel) initializeDatabase() error {
 	return nil
 }
 
+// InFlightStory is a story a coder was working on when the orchestrator stopped.
+type InFlightStory struct {
+	AgentID string
+	State   proto.State
+	Story   *persistence.Story
+}
+
+// InFlightStories finds coders whose checkpoint shows them mid-story, so the architect
+// can keep those stories assigned while the coders resume them. Checkpoints for stories
+// that are unknown, already done or no longer resumable are removed, so those coders
+// start fresh.
+func (k *Kernel) InFlightStories() ([]InFlightStory, error) {
+	if k.StateStore == nil || k.Database == nil {
+		return nil, nil
+	}
+
+	agentIDs, err := k.StateStore.ListAgents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agent checkpoints: %w", err)
+	}
+
+	ops := persistence.NewDatabaseOperations(k.Database)
+	var inFlight []InFlightStory
+	for _, agentID := range agentIDs {
+		savedState, data, err := k.StateStore.LoadState(agentID)
+		if err != nil {
+			k.Logger.Warn("Discarding unreadable checkpoint for %s: %v", agentID, err)
+			k.discardCheckpoint(agentID)
+			continue
+		}
+
+		storyID, _ := data[coder.KeyStoryID].(string)
+		if storyID == "" || !coder.IsResumableState(proto.State(savedState)) {
+			k.discardCheckpoint(agentID)
+			continue
+		}
+
+		story, err := ops.GetStoryByID(storyID)
+		if err != nil || story == nil {
+			k.Logger.Warn("Discarding checkpoint for %s: story %s not found: %v", agentID, storyID, err)
+			k.discardCheckpoint(agentID)
+			continue
+		}
+		if story.Status == persistence.StatusDone {
+			k.Logger.Info("Discarding checkpoint for %s: story %s is already done", agentID, storyID)
+			k.discardCheckpoint(agentID)
+			continue
+		}
+
+		k.Logger.Info("♻️  Found in-flight story %s on %s (%s)", storyID, agentID, savedState)
+		inFlight = append(inFlight, InFlightStory{AgentID: agentID, State: proto.State(savedState), Story: story})
+	}
+
+	return inFlight, nil
+}
+
+// discardCheckpoint removes an agent's checkpoint, logging failures.
+func (k *Kernel) discardCheckpoint(agentID string) {
+	if err := k.StateStore.DeleteState(agentID); err != nil {
+		k.Logger.Warn("Failed to remove checkpoint for %s: %v", agentID, err)
+	}
+}
+
 // Start begins all kernel services in the correct order.
 // This replaces the scattered startup logic from the old orchestrator files.
 func (k *Kernel) Start() error {
@@ -139,6 +214,11 @@ func (k *Kernel) Start() error {
 	// Start persistence worker (after dispatcher)
 	k.startPersistenceWorker()
 
+	// Connect to external MCP servers so their tools are registered before agents start
+	if k.Config.MCP != nil && len(k.Config.MCP.Servers) > 0 {
+		k.MCPManager = tools.StartMCPServers(k.ctx, k.Config.MCP.Servers)
+	}
+
 	k.running = true
 	k.Logger.Info("Kernel services started successfully")
 	return nil
@@ -175,6 +255,11 @@ func (k *Kernel) Stop() error {
 		}
 	}
 
+	// Shut down external MCP servers
+	if k.MCPManager != nil {
+		k.MCPManager.Close()
+	}
+
 	// Close database
 	if k.Database != nil {
 		if err := k.Database.Close(); err != nil {
@@ -273,6 +358,15 @@ func (k *Kernel) processPersistenceRequest(req *persistence.Request, ops *persis
 				k.Logger.Info("Successfully added story dependency: %s -> %s", deps.StoryID, deps.DependsOn)
 			}
 		}
+
+	case persistence.OpRemoveStoryDependency:
+		if deps, ok := req.Data.(*persistence.StoryDependency); ok {
+			if err := ops.RemoveStoryDependency(deps.StoryID, deps.DependsOn); err != nil {
+				k.Logger.Error("Failed to remove story dependency %s -> %s: %v", deps.StoryID, deps.DependsOn, err)
+			} else {
+				k.Logger.Info("Successfully removed story dependency: %s -> %s", deps.StoryID, deps.DependsOn)
+			}
+		}
 	case persistence.OpBatchUpsertStoriesWithDependencies:
 		if batchReq, ok := req.Data.(*persistence.BatchUpsertStoriesWithDependenciesRequest); ok {
 			if err := ops.BatchUpsertStoriesWithDependencies(batchReq); err != nil {
@@ -294,6 +388,28 @@ func (k *Kernel) processPersistenceRequest(req *persistence.Request, ops *persis
 			}
 		}
 
+	case persistence.OpGetSpecByID:
+		if specID, ok := req.Data.(string); ok && req.Response != nil {
+			spec, err := ops.GetSpecByID(specID)
+			if err != nil {
+				k.Logger.Error("Failed to get spec %s: %v", specID, err)
+				req.Response <- err
+			} else {
+				req.Response <- spec
+			}
+		}
+
+	case persistence.OpGetStoriesBySpec:
+		if specID, ok := req.Data.(string); ok && req.Response != nil {
+			stories, err := ops.GetStoriesBySpec(specID)
+			if err != nil {
+				k.Logger.Error("Failed to get stories for spec %s: %v", specID, err)
+				req.Response <- err
+			} else {
+				req.Response <- stories
+			}
+		}
+
 	case persistence.OpUpsertAgentRequest:
 		if agentRequest, ok := req.Data.(*persistence.AgentRequest); ok {
 			if err := ops.UpsertAgentRequest(agentRequest); err != nil {
@@ -321,6 +437,86 @@ func (k *Kernel) processPersistenceRequest(req *persistence.Request, ops *persis
 			}
 		}
 
+	case persistence.OpUpsertStoryNotes:
+		if notes, ok := req.Data.(*persistence.StoryNotes); ok {
+			if err := ops.UpsertStoryNotes(notes); err != nil {
+				k.Logger.Error("Failed to upsert notes for story %s: %v", notes.StoryID, err)
+			} else {
+				k.Logger.Debug("Successfully upserted notes for story: %s", notes.StoryID)
+			}
+		}
+
+	case persistence.OpGetStoryNotes:
+		if storyID, ok := req.Data.(string); ok && req.Response != nil {
+			notes, err := ops.GetStoryNotes(storyID)
+			if err != nil {
+				k.Logger.Error("Failed to get notes for story %s: %v", storyID, err)
+				req.Response <- err
+			} else {
+				req.Response <- notes
+			}
+		}
+
+	case persistence.OpRecordFlakyTest:
+		if test, ok := req.Data.(*persistence.FlakyTest); ok {
+			if err := ops.RecordFlakyTest(test); err != nil {
+				k.Logger.Error("Failed to record flaky test %s: %v", test.Name, err)
+			} else {
+				k.Logger.Debug("Recorded flaky test: %s", test.Name)
+			}
+		}
+
+	case persistence.OpRecordStoryHandoff:
+		if handoff, ok := req.Data.(*persistence.StoryHandoff); ok {
+			if err := ops.RecordStoryHandoff(handoff); err != nil {
+				k.Logger.Error("Failed to record handoff for story %s: %v", handoff.StoryID, err)
+			} else {
+				k.Logger.Debug("Recorded handoff for story: %s", handoff.StoryID)
+			}
+		}
+
+	case persistence.OpGetStoryHandoffs:
+		if storyID, ok := req.Data.(string); ok && req.Response != nil {
+			handoffs, err := ops.GetStoryHandoffs(storyID)
+			if err != nil {
+				k.Logger.Error("Failed to get handoffs for story %s: %v", storyID, err)
+				req.Response <- err
+			} else {
+				req.Response <- handoffs
+			}
+		}
+
+	case persistence.OpRecordEstimateOutcome:
+		if outcome, ok := req.Data.(*persistence.EstimateOutcome); ok {
+			if err := ops.RecordEstimateOutcome(outcome); err != nil {
+				k.Logger.Error("Failed to record estimate outcome for story %s: %v", outcome.StoryID, err)
+			} else {
+				k.Logger.Debug("Recorded estimate outcome for story: %s", outcome.StoryID)
+			}
+		}
+
+	case persistence.OpGetEstimateOutcomes:
+		if limit, ok := req.Data.(int); ok && req.Response != nil {
+			outcomes, err := ops.GetEstimateOutcomes(limit)
+			if err != nil {
+				k.Logger.Error("Failed to get estimate outcomes: %v", err)
+				req.Response <- err
+			} else {
+				req.Response <- outcomes
+			}
+		}
+
+	case persistence.OpGetFlakyTests:
+		if req.Response != nil {
+			tests, err := ops.GetFlakyTests()
+			if err != nil {
+				k.Logger.Error("Failed to get flaky tests: %v", err)
+				req.Response <- err
+			} else {
+				req.Response <- tests
+			}
+		}
+
 	default:
 		k.Logger.Error("Unknown persistence operation: %v", req.Operation)
 		if req.Response != nil {
@@ -10,6 +10,7 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 
 	"orchestrator/pkg/config"
+	"orchestrator/pkg/persistence"
 )
 
 // createTestConfig creates a minimal valid config for testing.
@@ -248,3 +249,53 @@ func TestKernelContextCancellation(t *testing.T) {
 		t.Error("Kernel context should be done after cancellation")
 	}
 }
+
+// TestKernelInFlightStories tests detection of coder checkpoints for unfinished stories.
+func TestKernelInFlightStories(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := createTestConfig()
+
+	kernel, err := NewKernel(context.Background(), &cfg, tempDir)
+	if err != nil {
+		t.Fatalf("NewKernel failed: %v", err)
+	}
+	defer kernel.Stop()
+
+	ops := persistence.NewDatabaseOperations(kernel.Database)
+	if err := ops.UpsertSpec(&persistence.Spec{ID: "spec-1", Content: "spec"}); err != nil {
+		t.Fatalf("Failed to create spec: %v", err)
+	}
+	for id, status := range map[string]string{"story-a": persistence.StatusCoding, "story-b": persistence.StatusDone} {
+		story := &persistence.Story{ID: id, SpecID: "spec-1", Title: id, Content: "content", Status: status, StoryType: "app"}
+		if err := ops.UpsertStory(story); err != nil {
+			t.Fatalf("Failed to create story %s: %v", id, err)
+		}
+	}
+
+	checkpoints := map[string]struct{ state, storyID string }{
+		"coder-001": {"CODING", "story-a"},
+		"coder-002": {"TESTING", "story-b"},   // story already done
+		"coder-003": {"PLANNING", "story-zz"}, // story unknown
+	}
+	for agentID, cp := range checkpoints {
+		if err := kernel.StateStore.SaveState(agentID, cp.state, map[string]any{"story_id": cp.storyID}); err != nil {
+			t.Fatalf("SaveState failed: %v", err)
+		}
+	}
+
+	inFlight, err := kernel.InFlightStories()
+	if err != nil {
+		t.Fatalf("InFlightStories failed: %v", err)
+	}
+	if len(inFlight) != 1 || inFlight[0].AgentID != "coder-001" || inFlight[0].Story.ID != "story-a" {
+		t.Fatalf("Expected only story-a on coder-001, got %+v", inFlight)
+	}
+
+	agents, err := kernel.StateStore.ListAgents()
+	if err != nil {
+		t.Fatalf("ListAgents failed: %v", err)
+	}
+	if len(agents) != 1 || agents[0] != "coder-001" {
+		t.Errorf("Expected stale checkpoints to be removed, remaining: %v", agents)
+	}
+}
@@ -66,7 +66,7 @@ type Supervisor struct {
 // NewSupervisor creates a new supervisor with the given kernel.
 func NewSupervisor(k *kernel.Kernel) *Supervisor {
 	// Create the agent factory with kernel dependencies (lightweight)
-	agentFactory := factory.NewAgentFactory(k.Dispatcher, k.PersistenceChannel)
+	agentFactory := factory.NewAgentFactory(k.Dispatcher, k.PersistenceChannel, k.StateStore)
 
 	return &Supervisor{
 		Kernel:        k,
@@ -6,7 +6,6 @@ import (
 	"fmt"
 
 	"orchestrator/pkg/logx"
-	"orchestrator/pkg/persistence"
 )
 
 // ExternalAPI provides methods for external operations outside the FSM.
@@ -71,7 +70,7 @@ func (api *ExternalAPI) isStoryUnblocked(storyID string) bool {
 			api.logger.Warn("Dependency %s not found for story %s", depID, storyID)
 			return false
 		}
-		if depStory.GetStatus() != StoryStatus(persistence.StatusDone) {
+		if !depStory.IsFinished() {
 			api.logger.Debug("Story %s blocked by incomplete dependency %s (status: %s)",
 				storyID, depID, depStory.Status)
 			return false
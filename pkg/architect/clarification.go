@@ -0,0 +1,234 @@
+package architect
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"orchestrator/pkg/config"
+	"orchestrator/pkg/proto"
+	"orchestrator/pkg/templates"
+)
+
+// maxClarificationQuestions bounds how many questions about a spec are put to a human.
+const maxClarificationQuestions = 10
+
+// ErrNoPendingClarification is returned when answers are given for a clarification that is not
+// waiting for them, e.g. because it timed out or was already answered.
+var ErrNoPendingClarification = errors.New("no pending spec clarification")
+
+// ClarificationQuestion is an ambiguity in a spec that the architect asks a human about.
+type ClarificationQuestion struct {
+	ID         string `json:"id"`
+	Question   string `json:"question"`
+	Why        string `json:"why,omitempty"`
+	Assumption string `json:"assumption"`       // Used when the question is not answered
+	Answer     string `json:"answer,omitempty"` // Set once a human answers
+}
+
+// SpecClarification is a set of questions about a spec that waits for a human's answers
+// before the architect generates stories.
+type SpecClarification struct {
+	AskedAt   time.Time               `json:"asked_at"`
+	Deadline  time.Time               `json:"deadline"`
+	ID        string                  `json:"id"`
+	SpecFile  string                  `json:"spec_file,omitempty"`
+	Questions []ClarificationQuestion `json:"questions"`
+}
+
+// clarificationBoard holds the clarification awaiting answers. Answers arrive from the web UI
+// or CLI on other goroutines while the architect waits in SCOPING.
+type clarificationBoard struct {
+	pending *SpecClarification
+	answers chan map[string]string
+	mu      sync.Mutex
+}
+
+// post publishes a clarification and returns the channel its answers are delivered on.
+func (b *clarificationBoard) post(clarification *SpecClarification) <-chan map[string]string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = clarification
+	b.answers = make(chan map[string]string, 1)
+	return b.answers
+}
+
+// clear withdraws the pending clarification.
+func (b *clarificationBoard) clear() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = nil
+	b.answers = nil
+}
+
+// PendingClarification returns a copy of the spec clarification waiting for answers, or nil
+// when there is none.
+func (d *Driver) PendingClarification() *SpecClarification {
+	d.clarifications.mu.Lock()
+	defer d.clarifications.mu.Unlock()
+	if d.clarifications.pending == nil {
+		return nil
+	}
+	clarification := *d.clarifications.pending
+	clarification.Questions = append([]ClarificationQuestion(nil), clarification.Questions...)
+	return &clarification
+}
+
+// AnswerClarification answers the pending spec clarification with the given ID. answers maps
+// question IDs to answers; unanswered questions proceed with their assumption, so empty
+// answers proceed with all assumptions.
+func (d *Driver) AnswerClarification(id string, answers map[string]string) error {
+	d.clarifications.mu.Lock()
+	defer d.clarifications.mu.Unlock()
+	if d.clarifications.pending == nil || d.clarifications.pending.ID != id {
+		return ErrNoPendingClarification
+	}
+
+	cleaned := make(map[string]string, len(answers))
+	for questionID, answer := range answers {
+		if answer = strings.TrimSpace(answer); answer != "" {
+			cleaned[questionID] = answer
+		}
+	}
+	d.clarifications.answers <- cleaned
+	d.clarifications.pending = nil
+	d.clarifications.answers = nil
+	return nil
+}
+
+// specClarificationEnabled reports whether the architect asks about ambiguities in a spec
+// before generating stories.
+func specClarificationEnabled() bool {
+	cfg, err := config.GetConfig()
+	return err == nil && cfg.Agents != nil && cfg.Agents.SpecClarification
+}
+
+// clarificationTimeout returns how long to wait for answers before proceeding with assumptions.
+func clarificationTimeout() time.Duration {
+	cfg, err := config.GetConfig()
+	if err != nil || cfg.Agents == nil || cfg.Agents.ClarificationTimeout <= 0 {
+		return config.DefaultClarificationTimeout
+	}
+	return cfg.Agents.ClarificationTimeout
+}
+
+// clarifySpec asks a human about the ambiguities the LLM finds in a spec and returns the spec
+// with the answers, or the assumptions for unanswered questions, folded in.
+func (d *Driver) clarifySpec(ctx context.Context, specContent, specFile string) (string, error) {
+	questions, err := d.generateClarificationQuestions(ctx, specContent, specFile)
+	if err != nil {
+		return "", err
+	}
+	if len(questions) == 0 {
+		d.logger.Info("📋 Spec has no open questions, generating stories")
+		return specContent, nil
+	}
+
+	timeout := clarificationTimeout()
+	now := time.Now().UTC()
+	clarification := &SpecClarification{
+		ID:        proto.GenerateApprovalID(),
+		SpecFile:  specFile,
+		Questions: questions,
+		AskedAt:   now,
+		Deadline:  now.Add(timeout),
+	}
+	answersCh := d.clarifications.post(clarification)
+	defer d.clarifications.clear()
+
+	d.logger.Info("❓ Spec has %d open questions - answer them in the web UI or CLI within %v, or stories are generated with the stated assumptions", len(questions), timeout)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case answers := <-answersCh:
+		for i := range questions {
+			questions[i].Answer = answers[questions[i].ID]
+		}
+		d.logger.Info("✅ Received answers to %d of %d spec questions", len(answers), len(questions))
+	case <-timer.C:
+		d.logger.Warn("Spec questions were not answered within %v, proceeding with assumptions", timeout)
+	case <-ctx.Done():
+		return "", fmt.Errorf("spec clarification cancelled: %w", ctx.Err())
+	}
+
+	return foldClarifications(specContent, questions), nil
+}
+
+// generateClarificationQuestions asks the LLM for the questions a human should answer about a spec.
+func (d *Driver) generateClarificationQuestions(ctx context.Context, specContent, specFile string) ([]ClarificationQuestion, error) {
+	if d.renderer == nil {
+		return nil, fmt.Errorf("template renderer not available")
+	}
+
+	templateData := &templates.TemplateData{
+		TaskContent: specContent,
+		Extra: map[string]any{
+			"spec_file_path": specFile,
+			"max_questions":  maxClarificationQuestions,
+		},
+	}
+	prompt, err := d.renderer.RenderWithUserInstructions(templates.SpecClarificationTemplate, templateData, d.workDir, "ARCHITECT")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render spec clarification template: %w", err)
+	}
+
+	response, err := d.callLLMWithTemplate(ctx, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get LLM response for spec clarification: %w", err)
+	}
+	return parseClarificationQuestions(response)
+}
+
+// parseClarificationQuestions extracts the questions from the LLM's clarification response.
+func parseClarificationQuestions(response string) ([]ClarificationQuestion, error) {
+	jsonStart := strings.Index(response, "{")
+	jsonEnd := strings.LastIndex(response, "}")
+	if jsonStart == -1 || jsonEnd <= jsonStart {
+		return nil, fmt.Errorf("no valid JSON found in spec clarification response")
+	}
+
+	var parsed struct {
+		Questions []ClarificationQuestion `json:"questions"`
+	}
+	if err := json.Unmarshal([]byte(response[jsonStart:jsonEnd+1]), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse spec clarification JSON: %w", err)
+	}
+
+	questions := make([]ClarificationQuestion, 0, len(parsed.Questions))
+	for i := range parsed.Questions {
+		question := parsed.Questions[i]
+		question.Question = strings.TrimSpace(question.Question)
+		if question.Question == "" {
+			continue
+		}
+		question.ID = fmt.Sprintf("q%d", len(questions)+1)
+		question.Answer = ""
+		questions = append(questions, question)
+		if len(questions) == maxClarificationQuestions {
+			break
+		}
+	}
+	return questions, nil
+}
+
+// foldClarifications appends the questions and their answers, or the assumptions made for
+// unanswered ones, to the spec so story generation and the spec record include them.
+func foldClarifications(specContent string, questions []ClarificationQuestion) string {
+	var sb strings.Builder
+	sb.WriteString(strings.TrimRight(specContent, "\n"))
+	sb.WriteString("\n\n## Clarifications\n\nQuestions about this specification, answered before stories were generated. These take precedence over the text above.\n")
+	for i := range questions {
+		sb.WriteString(fmt.Sprintf("\n**Q:** %s\n", questions[i].Question))
+		if questions[i].Answer != "" {
+			sb.WriteString(fmt.Sprintf("**A:** %s\n", questions[i].Answer))
+		} else {
+			sb.WriteString(fmt.Sprintf("**Assumed (not answered):** %s\n", questions[i].Assumption))
+		}
+	}
+	return sb.String()
+}
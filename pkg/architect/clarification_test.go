@@ -0,0 +1,91 @@
+package architect
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseClarificationQuestions(t *testing.T) {
+	response := "Here are my questions:\n```json\n" + `{"questions": [
+		{"question": "Which database should be used?", "why": "Affects the data layer", "assumption": "SQLite"},
+		{"question": "   ", "assumption": "ignored"},
+		{"id": "custom", "question": "Is auth required?", "assumption": "No", "answer": "stale"}
+	]}` + "\n```"
+
+	questions, err := parseClarificationQuestions(response)
+	if err != nil {
+		t.Fatalf("parseClarificationQuestions failed: %v", err)
+	}
+	if len(questions) != 2 {
+		t.Fatalf("Expected 2 questions, got %d: %+v", len(questions), questions)
+	}
+	if questions[0].ID != "q1" || questions[1].ID != "q2" {
+		t.Errorf("Expected sequential IDs, got %q and %q", questions[0].ID, questions[1].ID)
+	}
+	if questions[1].Answer != "" {
+		t.Errorf("Expected answers from the LLM to be dropped, got %q", questions[1].Answer)
+	}
+
+	if _, err := parseClarificationQuestions("no questions here"); err == nil {
+		t.Error("Expected an error for a response without JSON")
+	}
+}
+
+func TestFoldClarifications(t *testing.T) {
+	spec := foldClarifications("# Spec\n\nBuild a todo app.\n\n", []ClarificationQuestion{
+		{ID: "q1", Question: "Which database?", Assumption: "SQLite", Answer: "PostgreSQL"},
+		{ID: "q2", Question: "Is auth required?", Assumption: "No"},
+	})
+
+	for _, want := range []string{
+		"Build a todo app.\n\n## Clarifications\n",
+		"**Q:** Which database?\n**A:** PostgreSQL\n",
+		"**Q:** Is auth required?\n**Assumed (not answered):** No\n",
+	} {
+		if !strings.Contains(spec, want) {
+			t.Errorf("Expected folded spec to contain %q, got:\n%s", want, spec)
+		}
+	}
+}
+
+func TestAnswerClarification(t *testing.T) {
+	d := &Driver{clarifications: &clarificationBoard{}}
+	if d.PendingClarification() != nil {
+		t.Fatal("Expected no pending clarification")
+	}
+
+	answersCh := d.clarifications.post(&SpecClarification{
+		ID:        "clarify-1",
+		Deadline:  time.Now().Add(time.Minute),
+		Questions: []ClarificationQuestion{{ID: "q1", Question: "Which database?", Assumption: "SQLite"}},
+	})
+
+	pending := d.PendingClarification()
+	if pending == nil || pending.ID != "clarify-1" {
+		t.Fatalf("Expected pending clarification, got %+v", pending)
+	}
+	pending.Questions[0].Answer = "mutated"
+	if d.PendingClarification().Questions[0].Answer != "" {
+		t.Error("Expected PendingClarification to return a copy")
+	}
+
+	if err := d.AnswerClarification("other", nil); !errors.Is(err, ErrNoPendingClarification) {
+		t.Errorf("Expected ErrNoPendingClarification for an unknown ID, got %v", err)
+	}
+	if err := d.AnswerClarification("clarify-1", map[string]string{"q1": "  PostgreSQL ", "q2": "  "}); err != nil {
+		t.Fatalf("AnswerClarification failed: %v", err)
+	}
+
+	answers := <-answersCh
+	if len(answers) != 1 || answers["q1"] != "PostgreSQL" {
+		t.Errorf("Expected trimmed non-empty answers, got %v", answers)
+	}
+	if d.PendingClarification() != nil {
+		t.Error("Expected clarification to be withdrawn once answered")
+	}
+	if err := d.AnswerClarification("clarify-1", nil); !errors.Is(err, ErrNoPendingClarification) {
+		t.Errorf("Expected a second answer to be rejected, got %v", err)
+	}
+}
@@ -38,18 +38,15 @@ func (d *Driver) handleDispatching(ctx context.Context) (proto.State, error) {
 		d.logger.Info("🚀 DISPATCHING: queue initialized - %d stories (%d ready)",
 			summary["total_stories"], summary["ready_stories"])
 		d.stateData["queue_summary"] = summary
+		d.logger.Info("🚀 DISPATCHING: %s", d.queue.ProjectSchedule(schedulerMaxCoders()).Format())
 	}
 
 	// Log current queue state for debugging
 	d.logQueueState()
 
-	// Check if there are ready stories to dispatch.
-	if story := d.queue.NextReadyStory(); story != nil {
-		d.logger.Info("🚀 DISPATCHING: Found ready story %s (%s), dispatching to coder", story.ID, story.Title)
-		// Attempt to dispatch the story (error handling is internal)
-		_ = d.dispatchReadyStory(ctx, story.ID)
-		// Transition to MONITORING after dispatch attempt (successful or not)
-		d.logger.Info("🚀 DISPATCHING → MONITORING: Story dispatched, returning to monitor coder progress")
+	// Release ready stories in scheduling order while coders are free.
+	if released := d.releaseReadyStories(ctx); released > 0 {
+		d.logger.Info("🚀 DISPATCHING → MONITORING: %d stories dispatched, returning to monitor coder progress", released)
 		return StateMonitoring, nil
 	}
 
@@ -99,6 +96,9 @@ func (d *Driver) sendStoryToDispatcher(ctx context.Context, storyID string) erro
 		storyMsg.SetPayload(proto.KeyEstimatedPoints, story.EstimatedPoints)
 		storyMsg.SetPayload(proto.KeyDependsOn, story.DependsOn)
 		storyMsg.SetPayload(proto.KeyStoryType, story.StoryType)
+		if story.GetMetadata().TestFirst {
+			storyMsg.SetPayload(proto.KeyTestFirst, true)
+		}
 
 		// Use story content from the queue (set during SCOPING)
 		content := story.Content
@@ -124,6 +124,7 @@ func (d *Driver) sendStoryToDispatcher(ctx context.Context, storyID string) erro
 	if err := d.queue.UpdateStoryStatus(storyID, StatusPending); err != nil {
 		return fmt.Errorf("failed to mark story as pending: %w", err)
 	}
+	d.queue.MarkDispatched(storyID)
 
 	return nil
 }
@@ -223,10 +224,8 @@ func (d *Driver) detectDeadlock() bool {
 	}
 
 	// Check if any stories are in progress - not a deadlock
-	inProgressStories := d.queue.GetStoriesByStatus(StatusAssigned)
-	if len(inProgressStories) > 0 {
-		d.logger.Debug("🚀 DISPATCHING: No deadlock - %d stories in progress: %v",
-			len(inProgressStories), d.getStoryIDs(inProgressStories))
+	if inFlight := d.queue.InFlightCount(); inFlight > 0 {
+		d.logger.Debug("🚀 DISPATCHING: No deadlock - %d stories dispatched or in progress", inFlight)
 		return false
 	}
 
@@ -257,15 +256,6 @@ func (d *Driver) detectDeadlock() bool {
 	return true
 }
 
-// getStoryIDs extracts story IDs from a slice of QueuedStory pointers.
-func (d *Driver) getStoryIDs(stories []*QueuedStory) []string {
-	ids := make([]string, len(stories))
-	for i, story := range stories {
-		ids[i] = story.ID
-	}
-	return ids
-}
-
 // persistQueueState saves the current queue state to the state store.
 func (d *Driver) persistQueueState() error {
 	queueData, err := d.queue.ToJSON()
@@ -6,6 +6,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"orchestrator/pkg/agent"
@@ -16,6 +17,7 @@ import (
 	"orchestrator/pkg/persistence"
 	"orchestrator/pkg/proto"
 	"orchestrator/pkg/templates"
+	"orchestrator/pkg/tools"
 )
 
 // Story content constants.
@@ -41,10 +43,16 @@ type Driver struct {
 	replyCh            <-chan *proto.AgentMsg      // Read-only channel for replies
 	persistenceChannel chan<- *persistence.Request // Channel for database operations
 	externalAPI        *ExternalAPI                // API for external operations outside FSM
+	clarifications     *clarificationBoard         // Spec questions awaiting a human's answers
+	revisions          *revisionBoard              // Spec revisions and change sets awaiting approval
+	estimates          *EstimateCalibration        // Estimate-versus-actual history of completed stories
+	requests           *requestPool                // Concurrent request reviews and their latencies
+	modelConfig        *config.Model               // Architect model, also used for per-review contexts
 	stateData          map[string]any
 	architectID        string
 	workDir            string // Workspace directory
 	currentState       proto.State
+	stateMutex         sync.RWMutex // Guards currentState for readers outside the FSM goroutine
 }
 
 // NewDriver creates a new architect driver instance.
@@ -80,6 +88,11 @@ func NewDriver(architectID string, modelConfig *config.Model, llmClient agent.LL
 		logger:             logger,
 		persistenceChannel: persistenceChannel,
 		externalAPI:        externalAPI,
+		clarifications:     &clarificationBoard{},
+		revisions:          newRevisionBoard(),
+		estimates:          NewEstimateCalibration(nil),
+		requests:           newRequestPool(modelConnections(modelConfig)),
+		modelConfig:        modelConfig,
 		// Channels will be set during Attach()
 		specCh:      nil,
 		questionsCh: nil,
@@ -238,7 +251,9 @@ func (d *Driver) Run(ctx context.Context) error {
 	go d.processStatusUpdates(ctx)
 
 	// Start in WAITING state, ready to receive specs.
+	d.stateMutex.Lock()
 	d.currentState = StateWaiting
+	d.stateMutex.Unlock()
 	d.stateData = make(map[string]any)
 	d.stateData["started_at"] = time.Now().UTC()
 
@@ -308,8 +323,10 @@ func (d *Driver) processCurrentState(ctx context.Context) (proto.State, error) {
 
 // transitionTo moves the driver to a new state and persists it.
 func (d *Driver) transitionTo(_ context.Context, newState proto.State, additionalData map[string]any) {
+	d.stateMutex.Lock()
 	oldState := d.currentState
 	d.currentState = newState
+	d.stateMutex.Unlock()
 
 	// Add transition metadata.
 	d.stateData["previous_state"] = oldState.String()
@@ -333,7 +350,10 @@ func (d *Driver) transitionTo(_ context.Context, newState proto.State, additiona
 }
 
 // GetCurrentState returns the current state of the driver.
+// It is safe to call from request reviews running outside the FSM goroutine.
 func (d *Driver) GetCurrentState() proto.State {
+	d.stateMutex.RLock()
+	defer d.stateMutex.RUnlock()
 	return d.currentState
 }
 
@@ -367,13 +387,13 @@ func (d *Driver) GetContextSummary() string {
 }
 
 // handleLLMResponse handles LLM responses with proper empty response logic (same as coder).
-func (d *Driver) handleLLMResponse(resp agent.CompletionResponse) error {
+func (d *Driver) handleLLMResponse(cm *contextmgr.ContextManager, resp agent.CompletionResponse) error {
 	// TODO: REMOVE DEBUG LOGGING - temporary debugging for middleware hang
 
 	if resp.Content != "" {
 		// Case 1: Normal response with content
 		// TODO: REMOVE DEBUG LOGGING - temporary debugging for middleware hang
-		d.contextManager.AddAssistantMessage(resp.Content)
+		cm.AddAssistantMessage(resp.Content)
 		// TODO: REMOVE DEBUG LOGGING - temporary debugging for middleware hang
 		return nil
 	}
@@ -386,7 +406,7 @@ func (d *Driver) handleLLMResponse(resp agent.CompletionResponse) error {
 			toolNames[i] = resp.ToolCalls[i].Name
 		}
 		placeholder := fmt.Sprintf("Tool %s invoked", strings.Join(toolNames, ", "))
-		d.contextManager.AddAssistantMessage(placeholder)
+		cm.AddAssistantMessage(placeholder)
 		// TODO: REMOVE DEBUG LOGGING - temporary debugging for middleware hang
 		return nil
 	}
@@ -403,6 +423,15 @@ func (d *Driver) GetQueue() *Queue {
 	return d.queue
 }
 
+// RestoreInFlightStory registers a story a coder is resuming after a restart.
+func (d *Driver) RestoreInFlightStory(story *persistence.Story, agentID string) {
+	if d.queue == nil || story == nil {
+		return
+	}
+	d.queue.RestoreStory(story, agentID)
+	d.logger.Info("♻️  Restored in-flight story %s assigned to %s", story.ID, agentID)
+}
+
 // GetStoryList returns all stories with their current status for external access.
 func (d *Driver) GetStoryList() []*QueuedStory {
 	if d.queue == nil {
@@ -411,6 +440,14 @@ func (d *Driver) GetStoryList() []*QueuedStory {
 	return d.queue.GetAllStories()
 }
 
+// GetStoryGraph returns the current story dependency graph for external access.
+func (d *Driver) GetStoryGraph() *StoryGraph {
+	if d.queue == nil {
+		return NewStoryGraph(nil)
+	}
+	return d.queue.Graph()
+}
+
 // GetEscalationHandler returns the escalation handler for external access.
 func (d *Driver) GetEscalationHandler() *EscalationHandler {
 	return d.escalationHandler
@@ -423,14 +460,14 @@ func (d *Driver) GetExternalAPI() *ExternalAPI {
 
 // buildMessagesWithContext creates completion messages with context history (same as coder).
 // This centralizes the pattern used across architect LLM calls with context isolation.
-func (d *Driver) buildMessagesWithContext(initialPrompt string) []agent.CompletionMessage {
+func (d *Driver) buildMessagesWithContext(cm *contextmgr.ContextManager, initialPrompt string) []agent.CompletionMessage {
 	messages := []agent.CompletionMessage{
 		{Role: agent.RoleUser, Content: initialPrompt},
 	}
 
 	// Add conversation history from context manager (same as coder pattern).
 	// For architect, we typically reset context between templates, but if context exists, include it.
-	contextMessages := d.contextManager.GetMessages()
+	contextMessages := cm.GetMessages()
 	for i := range contextMessages {
 		msg := &contextMessages[i]
 		messages = append(messages, agent.CompletionMessage{
@@ -445,13 +482,19 @@ func (d *Driver) buildMessagesWithContext(initialPrompt string) []agent.Completi
 // callLLMWithTemplate renders a template and gets LLM response using the same pattern as coder.
 // This helper centralizes the architect's LLM call pattern with proper context management.
 func (d *Driver) callLLMWithTemplate(ctx context.Context, prompt string) (string, error) {
+	return d.callLLM(ctx, d.contextManager, prompt)
+}
+
+// callLLM is callLLMWithTemplate with an explicit context manager, so that request reviews running
+// concurrently each keep their own conversation.
+func (d *Driver) callLLM(ctx context.Context, cm *contextmgr.ContextManager, prompt string) (string, error) {
 	// Flush user buffer before LLM request (same as coder)
-	if err := d.contextManager.FlushUserBuffer(); err != nil {
+	if err := cm.FlushUserBuffer(); err != nil {
 		return "", fmt.Errorf("failed to flush user buffer: %w", err)
 	}
 
 	// Build messages with context (same pattern as coder)
-	messages := d.buildMessagesWithContext(prompt)
+	messages := d.buildMessagesWithContext(cm, prompt)
 
 	req := agent.CompletionRequest{
 		Messages:  messages,
@@ -463,7 +506,7 @@ func (d *Driver) callLLMWithTemplate(ctx context.Context, prompt string) (string
 		d.llmClient.GetDefaultConfig().Name, len(messages), req.MaxTokens)
 
 	start := time.Now()
-	resp, err := d.llmClient.Complete(ctx, req)
+	resp, err := d.complete(ctx, req)
 	duration := time.Since(start)
 
 	if err != nil {
@@ -474,13 +517,109 @@ func (d *Driver) callLLMWithTemplate(ctx context.Context, prompt string) (string
 	d.logger.Info("✅ LLM call completed in %.3gs, response length: %d chars", duration.Seconds(), len(resp.Content))
 
 	// Handle LLM response with proper empty response logic (same as coder)
-	if err := d.handleLLMResponse(resp); err != nil {
+	if err := d.handleLLMResponse(cm, resp); err != nil {
 		return "", fmt.Errorf("LLM response handling failed: %w", err)
 	}
 
 	return resp.Content, nil
 }
 
+// complete sends one completion request once a model connection is free. Every architect LLM call
+// goes through here, so concurrent request reviews never exceed the model's max_connections.
+func (d *Driver) complete(ctx context.Context, req agent.CompletionRequest) (agent.CompletionResponse, error) {
+	if d.requests == nil {
+		return d.llmClient.Complete(ctx, req) //nolint:wrapcheck // Callers wrap LLM errors
+	}
+	if err := d.requests.acquire(ctx); err != nil {
+		return agent.CompletionResponse{}, err
+	}
+	defer d.requests.release()
+	return d.llmClient.Complete(ctx, req) //nolint:wrapcheck // Callers wrap LLM errors
+}
+
+// maxExternalToolIterations bounds the tool-use loop when answering with external tools.
+const maxExternalToolIterations = 5
+
+// callLLMWithExternalTools is like callLLM but lets the LLM call tools provided by configured MCP
+// servers. Tool results are fed back through the context manager until the LLM answers without
+// further tool calls or the iteration limit is reached.
+func (d *Driver) callLLMWithExternalTools(ctx context.Context, cm *contextmgr.ContextManager, prompt string) (string, error) {
+	toolNames := tools.ExternalToolNames(config.MCPAgentArchitect)
+	if len(toolNames) == 0 {
+		return d.callLLM(ctx, cm, prompt)
+	}
+
+	provider := tools.NewProvider(tools.AgentContext{
+		ReadOnly:        true,
+		NetworkDisabled: true,
+		WorkDir:         d.workDir,
+	}, toolNames)
+	toolMetas := provider.List()
+	definitions := make([]tools.ToolDefinition, 0, len(toolMetas))
+	//nolint:gocritic // rangeValCopy: Direct access is clearer than pointer dereferencing
+	for _, meta := range toolMetas {
+		definitions = append(definitions, tools.ToolDefinition(meta))
+	}
+
+	for iteration := 0; iteration < maxExternalToolIterations; iteration++ {
+		if err := cm.FlushUserBuffer(); err != nil {
+			return "", fmt.Errorf("failed to flush user buffer: %w", err)
+		}
+
+		req := agent.CompletionRequest{
+			Messages:  d.buildMessagesWithContext(cm, prompt),
+			MaxTokens: agent.ArchitectMaxTokens,
+			Tools:     definitions,
+		}
+
+		resp, err := d.complete(ctx, req)
+		if err != nil {
+			return "", fmt.Errorf("LLM completion failed: %w", err)
+		}
+		if err := d.handleLLMResponse(cm, resp); err != nil {
+			return "", fmt.Errorf("LLM response handling failed: %w", err)
+		}
+		if len(resp.ToolCalls) == 0 {
+			return resp.Content, nil
+		}
+
+		for i := range resp.ToolCalls {
+			d.executeExternalToolCall(ctx, cm, provider, &resp.ToolCalls[i])
+		}
+	}
+
+	return "", fmt.Errorf("no final answer after %d tool iterations", maxExternalToolIterations)
+}
+
+// executeExternalToolCall runs one external tool call and records its outcome in the context.
+func (d *Driver) executeExternalToolCall(ctx context.Context, cm *contextmgr.ContextManager, provider *tools.ToolProvider, toolCall *agent.ToolCall) {
+	d.logger.Info("Executing external tool: %s", toolCall.Name)
+
+	tool, err := provider.Get(toolCall.Name)
+	if err != nil {
+		cm.AddMessage("tool", fmt.Sprintf("%s error: %v", toolCall.Name, err))
+		return
+	}
+
+	result, err := tool.Exec(ctx, toolCall.Parameters)
+	if err != nil {
+		cm.AddMessage("tool", fmt.Sprintf("%s error: %v", toolCall.Name, err))
+		return
+	}
+
+	output := fmt.Sprintf("%v", result)
+	if resultMap, ok := result.(map[string]any); ok {
+		output, _ = resultMap["output"].(string)
+		if success, ok := resultMap["success"].(bool); ok && !success {
+			output = "failed: " + output
+		}
+	}
+	if strings.TrimSpace(output) == "" {
+		output = "[no output]"
+	}
+	cm.AddMessage("tool", fmt.Sprintf("%s output: %s", toolCall.Name, output))
+}
+
 // processStatusUpdates runs as a goroutine to process story status updates from coders.
 // This provides a non-blocking way for coders to update story status without waiting for architect availability.
 func (d *Driver) processStatusUpdates(ctx context.Context) {
@@ -504,6 +643,18 @@ func (d *Driver) processStatusUpdates(ctx context.Context) {
 				return
 			}
 
+			if statusUpdate.Todos != nil {
+				if err := d.queue.UpdateStoryTodos(statusUpdate.StoryID, statusUpdate.Todos); err != nil {
+					d.logger.Error("❌ Failed to update todo progress for story %s: %v", statusUpdate.StoryID, err)
+				}
+			}
+			if statusUpdate.FlakyTests != nil {
+				d.scheduleFlakyTestFix(statusUpdate)
+			}
+			if statusUpdate.Status == "" {
+				continue
+			}
+
 			d.logger.Info("📊 Processing status update: story %s → %s", statusUpdate.StoryID, statusUpdate.Status)
 
 			// Convert string status to StoryStatus and update via queue
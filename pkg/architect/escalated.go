@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"orchestrator/pkg/persistence"
 	"orchestrator/pkg/proto"
 )
 
@@ -116,9 +117,27 @@ func (d *Driver) sendAbandonAndRequeue(ctx context.Context) error {
 	story.CompletedAt = nil
 	story.LastUpdated = time.Now().UTC()
 
+	// Record why the story was abandoned for the coder that picks it up next.
+	persistence.PersistStoryHandoff(&persistence.StoryHandoff{
+		StoryID:   storyID,
+		AgentID:   agentID,
+		Reason:    "Escalation timeout exceeded - the architect abandoned the attempt",
+		Attempted: describeEscalation(latestEscalation),
+		CreatedAt: time.Now().UTC(),
+	}, d.persistenceChannel)
+
 	// Trigger ready notification if dependencies are met.
 	d.queue.checkAndNotifyReady()
 
 	d.logger.Info("abandoned story %s due to escalation timeout and re-queued", storyID)
 	return nil
 }
+
+// describeEscalation summarizes an unresolved escalation for a story handoff.
+func describeEscalation(escalation *EscalationEntry) string {
+	description := fmt.Sprintf("The attempt was escalated (%s) and not resolved in time.", escalation.Type)
+	if escalation.Question != "" {
+		description += "\nOpen question: " + escalation.Question
+	}
+	return description
+}
@@ -36,6 +36,10 @@ type EscalationEntry struct {
 	ResolvedAt    *time.Time     `json:"resolved_at,omitempty"`
 	Resolution    string         `json:"resolution,omitempty"`
 	HumanOperator string         `json:"human_operator,omitempty"`
+
+	// Options offered by the coder, so a human can answer with a single choice.
+	Options           []string `json:"options,omitempty"`
+	RecommendedOption string   `json:"recommended_option,omitempty"`
 }
 
 // EscalationSummary provides an overview of escalation status.
@@ -80,6 +84,9 @@ func (eh *EscalationHandler) EscalateBusinessQuestion(_ context.Context, pending
 		EscalatedAt: time.Now().UTC(),
 		Status:      "pending",
 		Priority:    eh.determinePriority(pendingQ.Question, pendingQ.Context),
+
+		Options:           pendingQ.Options,
+		RecommendedOption: pendingQ.RecommendedOption,
 	}
 
 	// Store escalation.
@@ -361,6 +368,28 @@ func (eh *EscalationHandler) ResolveEscalation(escalationID, resolution, humanOp
 	return nil
 }
 
+// ResolveEscalationWithOption resolves an escalation by choosing one of its options,
+// given as option text or 1-based number. It returns the chosen option.
+func (eh *EscalationHandler) ResolveEscalationWithOption(escalationID, choice, humanOperator string) (string, error) {
+	escalation, exists := eh.escalations[escalationID]
+	if !exists {
+		return "", fmt.Errorf("escalation %s not found", escalationID)
+	}
+	if len(escalation.Options) == 0 {
+		return "", fmt.Errorf("escalation %s has no options to choose from", escalationID)
+	}
+
+	option := matchOption(choice, escalation.Options)
+	if option == "" {
+		return "", fmt.Errorf("%q is not one of the options for escalation %s: %v", choice, escalationID, escalation.Options)
+	}
+
+	if err := eh.ResolveEscalation(escalationID, option, humanOperator); err != nil {
+		return "", err
+	}
+	return option, nil
+}
+
 // AcknowledgeEscalation marks an escalation as acknowledged (seen by human).
 func (eh *EscalationHandler) AcknowledgeEscalation(escalationID, humanOperator string) error {
 	escalation, exists := eh.escalations[escalationID]
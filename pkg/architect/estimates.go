@@ -0,0 +1,328 @@
+package architect
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"orchestrator/pkg/config"
+	"orchestrator/pkg/persistence"
+)
+
+const (
+	// minCalibrationSamples is how many completed stories the history needs before it adjusts estimates.
+	minCalibrationSamples = 3
+	// estimateHistoryLimit bounds how many recent estimate outcomes calibrate new estimates.
+	estimateHistoryLimit = 200
+)
+
+// StoryEstimate is the architect's estimate of a story, made during story generation. The cost is
+// derived from the tokens.
+type StoryEstimate struct {
+	Risk    string
+	Tokens  int64
+	CostUSD float64
+	Points  int
+}
+
+// normalizeRisk maps a risk level from the LLM to a known level, defaulting to medium.
+func normalizeRisk(risk string) string {
+	switch strings.ToLower(strings.TrimSpace(risk)) {
+	case persistence.RiskLow:
+		return persistence.RiskLow
+	case persistence.RiskHigh:
+		return persistence.RiskHigh
+	default:
+		return persistence.RiskMedium
+	}
+}
+
+// riskRank orders risk levels for scheduling; unknown levels rank as medium.
+func riskRank(risk string) int {
+	switch normalizeRisk(risk) {
+	case persistence.RiskLow:
+		return 0
+	case persistence.RiskHigh:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// actuals is the average of what a group of completed stories actually took.
+type actuals struct {
+	Tokens     float64
+	CostUSD    float64
+	Iterations float64
+	Samples    int
+}
+
+func (a *actuals) add(outcome *persistence.EstimateOutcome, weight float64) {
+	a.Tokens += float64(outcome.ActualTokens) * weight
+	a.CostUSD += outcome.ActualCostUSD * weight
+	a.Iterations += float64(outcome.Iterations) * weight
+	a.Samples++
+}
+
+func (a *actuals) average() {
+	if a.Samples > 0 {
+		a.Tokens /= float64(a.Samples)
+		a.CostUSD /= float64(a.Samples)
+		a.Iterations /= float64(a.Samples)
+	}
+}
+
+// EstimateCalibration summarizes how completed stories' estimates compared with what they actually
+// took. Calibrating against actuals rather than against earlier estimates keeps corrections from
+// compounding.
+type EstimateCalibration struct {
+	mutex    sync.RWMutex
+	outcomes []*persistence.EstimateOutcome // Most recent first
+	byPoints map[int]*actuals               // Actuals of stories estimated at each point value
+	perPoint actuals                        // Actuals per estimated point across all stories
+	ratio    float64                        // Actual over estimated tokens, 0 when unknown
+	loaded   bool                           // Whether the history was read from the database
+}
+
+// NewEstimateCalibration builds a calibration from estimate outcomes, most recent first.
+func NewEstimateCalibration(outcomes []*persistence.EstimateOutcome) *EstimateCalibration {
+	c := &EstimateCalibration{}
+	c.setOutcomes(outcomes)
+	return c
+}
+
+// setOutcomes replaces the history and recomputes the averages. Callers hold the write lock.
+func (c *EstimateCalibration) setOutcomes(outcomes []*persistence.EstimateOutcome) {
+	if len(outcomes) > estimateHistoryLimit {
+		outcomes = outcomes[:estimateHistoryLimit]
+	}
+	c.outcomes = outcomes
+	c.byPoints = make(map[int]*actuals)
+	c.perPoint = actuals{}
+
+	var estimated, actual float64
+	for _, outcome := range outcomes {
+		if outcome.EstimatedPoints < 1 || outcome.ActualTokens <= 0 {
+			continue // Without an estimate or actuals there is nothing to compare
+		}
+		bucket := c.byPoints[outcome.EstimatedPoints]
+		if bucket == nil {
+			bucket = &actuals{}
+			c.byPoints[outcome.EstimatedPoints] = bucket
+		}
+		bucket.add(outcome, 1)
+		c.perPoint.add(outcome, 1/float64(outcome.EstimatedPoints))
+		if outcome.EstimatedTokens > 0 {
+			estimated += float64(outcome.EstimatedTokens)
+			actual += float64(outcome.ActualTokens)
+		}
+	}
+	for _, bucket := range c.byPoints {
+		bucket.average()
+	}
+	c.perPoint.average()
+	c.ratio = 0
+	if estimated > 0 {
+		c.ratio = actual / estimated
+	}
+}
+
+// Record adds the outcome of a story that just completed to the history.
+func (c *EstimateCalibration) Record(outcome *persistence.EstimateOutcome) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.setOutcomes(append([]*persistence.EstimateOutcome{outcome}, c.outcomes...))
+}
+
+// Samples returns how many completed stories the calibration is based on.
+func (c *EstimateCalibration) Samples() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.perPoint.Samples
+}
+
+// expected returns what a story of the given size is expected to take according to the history:
+// the average of stories with the same estimate when there are enough of them, otherwise the
+// average per point scaled to the size.
+func (c *EstimateCalibration) expected(points int) (actuals, bool) {
+	if bucket := c.byPoints[points]; bucket != nil && bucket.Samples >= minCalibrationSamples {
+		return *bucket, true
+	}
+	if c.perPoint.Samples < minCalibrationSamples {
+		return actuals{}, false
+	}
+	scale := float64(max(points, 1))
+	return actuals{
+		Tokens:     c.perPoint.Tokens * scale,
+		CostUSD:    c.perPoint.CostUSD * scale,
+		Iterations: c.perPoint.Iterations * scale,
+		Samples:    c.perPoint.Samples,
+	}, true
+}
+
+// Calibrate adjusts an estimate from story generation with the history. A token estimate is
+// averaged with what stories of the same size actually took, or taken from the history when the
+// LLM gave none. The cost follows from the tokens at the historical cost per token, or at
+// fallbackCostPerToken while there is no history.
+func (c *EstimateCalibration) Calibrate(estimate StoryEstimate, fallbackCostPerToken float64) StoryEstimate {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	estimate.Risk = normalizeRisk(estimate.Risk)
+	estimate.Tokens = max(estimate.Tokens, 0)
+	costPerToken := fallbackCostPerToken
+	if history, ok := c.expected(estimate.Points); ok {
+		if estimate.Tokens > 0 {
+			estimate.Tokens = (estimate.Tokens + int64(history.Tokens)) / 2
+		} else {
+			estimate.Tokens = int64(history.Tokens)
+		}
+		if history.Tokens > 0 {
+			costPerToken = history.CostUSD / history.Tokens
+		}
+	}
+	estimate.CostUSD = float64(estimate.Tokens) * costPerToken
+	return estimate
+}
+
+// PromptSummary describes the history for the spec analysis prompt, so the LLM can calibrate its
+// own estimates. It is empty while there is no history.
+func (c *EstimateCalibration) PromptSummary() string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if c.perPoint.Samples == 0 {
+		return ""
+	}
+	points := make([]int, 0, len(c.byPoints))
+	for p := range c.byPoints {
+		points = append(points, p)
+	}
+	sort.Ints(points)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Completed stories in this project (%d) actually took, by estimated points:\n", c.perPoint.Samples)
+	for _, p := range points {
+		bucket := c.byPoints[p]
+		fmt.Fprintf(&sb, "- %d point(s): %d stories, on average %s tokens, $%.2f and %.0f LLM round trips\n",
+			p, bucket.Samples, formatTokens(int64(bucket.Tokens)), bucket.CostUSD, bucket.Iterations)
+	}
+	if c.ratio > 0 {
+		fmt.Fprintf(&sb, "Actual token use was %.1fx the token estimates.\n", c.ratio)
+	}
+	return sb.String()
+}
+
+// BudgetSummary compares a story's estimate with what it has used so far, for budget reviews.
+func (c *EstimateCalibration) BudgetSummary(story *QueuedStory, spentTokens int64, spentCostUSD float64) string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if story.EstimatedPoints < 1 && story.EstimatedTokens <= 0 {
+		return "No estimate recorded for this story."
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Estimated %d point(s), %s tokens ($%.2f), %s risk. ",
+		story.EstimatedPoints, formatTokens(story.EstimatedTokens), story.EstimatedCostUSD, normalizeRisk(story.Risk))
+	fmt.Fprintf(&sb, "Used so far: %s tokens ($%.2f)", formatTokens(spentTokens), spentCostUSD)
+	if story.EstimatedTokens > 0 {
+		fmt.Fprintf(&sb, ", %.1fx the token estimate", float64(spentTokens)/float64(story.EstimatedTokens))
+	}
+	sb.WriteString(".")
+	if history, ok := c.expected(story.EstimatedPoints); ok {
+		fmt.Fprintf(&sb, " Completed stories of this size took %s tokens ($%.2f) and %.0f LLM round trips on average.",
+			formatTokens(int64(history.Tokens)), history.CostUSD, history.Iterations)
+	}
+	return sb.String()
+}
+
+// formatTokens renders a token count compactly, e.g. 1.2M or 350k.
+func formatTokens(tokens int64) string {
+	switch {
+	case tokens >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(tokens)/1_000_000)
+	case tokens >= 1_000:
+		return fmt.Sprintf("%dk", tokens/1_000)
+	default:
+		return fmt.Sprintf("%d", tokens)
+	}
+}
+
+// newEstimateOutcome compares a completed story's estimates with its actuals.
+func newEstimateOutcome(story *QueuedStory) *persistence.EstimateOutcome {
+	completedAt := time.Now().UTC()
+	if story.CompletedAt != nil {
+		completedAt = *story.CompletedAt
+	}
+	return &persistence.EstimateOutcome{
+		CompletedAt:      completedAt,
+		StoryID:          story.ID,
+		StoryType:        story.StoryType,
+		Risk:             story.Risk,
+		EstimatedTokens:  story.EstimatedTokens,
+		ActualTokens:     story.TokensUsed,
+		EstimatedCostUSD: story.EstimatedCostUSD,
+		ActualCostUSD:    story.CostUSD,
+		EstimatedPoints:  story.EstimatedPoints,
+		Iterations:       story.Iterations,
+	}
+}
+
+// coderCostPerToken returns the price of a coder model token, or 0 when it is not configured.
+func coderCostPerToken() float64 {
+	cfg, err := config.GetConfig()
+	if err != nil || cfg.Agents == nil {
+		return 0
+	}
+	cost, err := config.CalculateCost(cfg.Agents.CoderModel, 1_000_000, 0)
+	if err != nil {
+		return 0
+	}
+	return cost / 1_000_000
+}
+
+// estimateCalibration returns the estimate history, reading it from the database the first time.
+// A failed read is retried on the next call.
+func (d *Driver) estimateCalibration(ctx context.Context) *EstimateCalibration {
+	if d.estimates == nil {
+		d.estimates = NewEstimateCalibration(nil)
+	}
+	c := d.estimates
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.loaded || d.persistenceChannel == nil {
+		return c
+	}
+
+	result, err := d.queryDatabase(ctx, persistence.OpGetEstimateOutcomes, estimateHistoryLimit)
+	if err != nil {
+		d.logger.Warn("📏 Failed to load estimate history: %v", err)
+		return c
+	}
+	outcomes, _ := result.([]*persistence.EstimateOutcome)
+	c.setOutcomes(outcomes)
+	c.loaded = true
+	d.logger.Info("📏 Loaded estimate history of %d completed stories", c.perPoint.Samples)
+	return c
+}
+
+// recordEstimateOutcome compares a completed story's estimates with its actuals, logs the
+// comparison and adds it to the history. Stories without actuals are skipped: with metrics
+// disabled every story would look free and skew later estimates.
+func (d *Driver) recordEstimateOutcome(ctx context.Context, story *QueuedStory) {
+	if story.EstimatedPoints < 1 || story.TokensUsed <= 0 {
+		return
+	}
+	outcome := newEstimateOutcome(story)
+	if outcome.EstimatedTokens > 0 {
+		d.logger.Info("📏 Story %s estimate vs actual: %s/%s tokens (%.1fx), $%.2f/$%.2f, %d LLM round trips, %s risk",
+			story.ID, formatTokens(outcome.EstimatedTokens), formatTokens(outcome.ActualTokens),
+			float64(outcome.ActualTokens)/float64(outcome.EstimatedTokens),
+			outcome.EstimatedCostUSD, outcome.ActualCostUSD, outcome.Iterations, normalizeRisk(outcome.Risk))
+	}
+	d.estimateCalibration(ctx).Record(outcome)
+	persistence.PersistEstimateOutcome(outcome, d.persistenceChannel)
+}
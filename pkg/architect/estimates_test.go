@@ -0,0 +1,76 @@
+package architect
+
+import (
+	"strings"
+	"testing"
+
+	"orchestrator/pkg/persistence"
+)
+
+func TestEstimateCalibration(t *testing.T) {
+	calibration := NewEstimateCalibration(nil)
+
+	// Without history the LLM's token estimate stands and the cost follows from the model price
+	estimate := calibration.Calibrate(StoryEstimate{Points: 2, Tokens: 100000, Risk: "HIGH"}, 0.000003)
+	if estimate.Tokens != 100000 || estimate.CostUSD != 0.3 || estimate.Risk != persistence.RiskHigh {
+		t.Errorf("Expected the uncalibrated estimate, got %+v", estimate)
+	}
+	if calibration.PromptSummary() != "" {
+		t.Errorf("Expected no prompt summary without history, got %q", calibration.PromptSummary())
+	}
+
+	// Three 2-point stories took 300k tokens and $0.60 on average
+	for _, tokens := range []int64{200000, 300000, 400000} {
+		calibration.Record(&persistence.EstimateOutcome{
+			StoryID: "s", EstimatedPoints: 2, EstimatedTokens: 100000,
+			ActualTokens: tokens, ActualCostUSD: float64(tokens) * 0.000002, Iterations: 10,
+		})
+	}
+	// Outcomes without actuals are ignored
+	calibration.Record(&persistence.EstimateOutcome{StoryID: "free", EstimatedPoints: 2})
+	if calibration.Samples() != 3 {
+		t.Fatalf("Expected 3 samples, got %d", calibration.Samples())
+	}
+
+	estimate = calibration.Calibrate(StoryEstimate{Points: 2, Tokens: 100000}, 0.000003)
+	if estimate.Tokens != 200000 || estimate.Risk != persistence.RiskMedium {
+		t.Errorf("Expected the estimate averaged with the history, got %+v", estimate)
+	}
+	if estimate.CostUSD < 0.399 || estimate.CostUSD > 0.401 {
+		t.Errorf("Expected the historical cost per token, got %v", estimate.CostUSD)
+	}
+
+	// Sizes without enough history scale the average per point
+	estimate = calibration.Calibrate(StoryEstimate{Points: 4}, 0)
+	if estimate.Tokens != 600000 {
+		t.Errorf("Expected 600k tokens from the per-point average, got %+v", estimate)
+	}
+
+	summary := calibration.PromptSummary()
+	for _, want := range []string{"Completed stories in this project (3)", "2 point(s): 3 stories, on average 300k tokens, $0.60 and 10 LLM round trips", "3.0x the token estimates"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Expected prompt summary to contain %q, got:\n%s", want, summary)
+		}
+	}
+
+	story := &QueuedStory{Story: persistence.Story{ID: "s", EstimatedPoints: 2, EstimatedTokens: 200000, EstimatedCostUSD: 0.4, Risk: persistence.RiskLow}}
+	budget := calibration.BudgetSummary(story, 500000, 1.0)
+	for _, want := range []string{"Estimated 2 point(s), 200k tokens ($0.40), low risk", "500k tokens ($1.00), 2.5x the token estimate", "took 300k tokens"} {
+		if !strings.Contains(budget, want) {
+			t.Errorf("Expected budget summary to contain %q, got %q", want, budget)
+		}
+	}
+}
+
+func TestNewEstimateOutcome(t *testing.T) {
+	story := &QueuedStory{Story: persistence.Story{
+		ID: "s", StoryType: storyTypeApp, Risk: persistence.RiskHigh,
+		EstimatedPoints: 3, EstimatedTokens: 150000, EstimatedCostUSD: 0.45,
+		TokensUsed: 210000, CostUSD: 0.63, Iterations: 14,
+	}}
+	outcome := newEstimateOutcome(story)
+	if outcome.StoryID != "s" || outcome.EstimatedTokens != 150000 || outcome.ActualTokens != 210000 ||
+		outcome.ActualCostUSD != 0.63 || outcome.Iterations != 14 || outcome.Risk != persistence.RiskHigh || outcome.CompletedAt.IsZero() {
+		t.Errorf("Unexpected outcome: %+v", outcome)
+	}
+}
@@ -0,0 +1,33 @@
+package architect
+
+import (
+	"orchestrator/pkg/persistence"
+	"orchestrator/pkg/proto"
+)
+
+// scheduleFlakyTestFix handles a coder's report of flaky tests by scheduling a fix-up story
+// in the reporting story's spec, or adding the tests to the fix-up story already pending.
+func (d *Driver) scheduleFlakyTestFix(update *proto.StoryStatusUpdate) {
+	if d.queue == nil || len(update.FlakyTests) == 0 {
+		return
+	}
+
+	specID := ""
+	if story, exists := d.queue.GetStory(update.StoryID); exists {
+		specID = story.SpecID
+	}
+
+	fixStory, err := d.queue.ScheduleFlakyTestFix(specID, update.FlakyTests)
+	if err != nil {
+		d.logger.Error("❌ Failed to schedule flaky test fix for story %s: %v", update.StoryID, err)
+		return
+	}
+	if fixStory == nil {
+		d.logger.Info("🎲 Flaky tests reported by story %s are already scheduled for a fix", update.StoryID)
+		return
+	}
+
+	d.logger.Info("🎲 %d flaky test(s) reported by story %s, scheduled in fix-up story %s",
+		len(update.FlakyTests), update.StoryID, fixStory.ID)
+	persistence.PersistStory(fixStory.ToPersistenceStory(), d.persistenceChannel)
+}
@@ -0,0 +1,45 @@
+package architect
+
+import (
+	"strings"
+	"testing"
+
+	"orchestrator/pkg/proto"
+)
+
+func TestQueueScheduleFlakyTestFix(t *testing.T) {
+	q := NewQueue(nil)
+
+	first, err := q.ScheduleFlakyTestFix("spec", []proto.FlakyTest{{Suite: "app/pkg/cache", Name: "TestEviction"}})
+	if err != nil || first == nil {
+		t.Fatalf("Expected a fix-up story to be scheduled, got %v (err %v)", first, err)
+	}
+	if first.Title != FlakyTestFixTitle || first.SpecID != "spec" || first.GetStatus() != StatusPending {
+		t.Errorf("Unexpected fix-up story: %+v", first.Story)
+	}
+
+	// Already-listed tests do not change the story
+	again, err := q.ScheduleFlakyTestFix("spec", []proto.FlakyTest{{Suite: "app/pkg/cache", Name: "TestEviction"}})
+	if err != nil || again != nil {
+		t.Errorf("Expected a repeated report to be ignored, got %v (err %v)", again, err)
+	}
+
+	// New tests are added to the pending story instead of creating another
+	updated, err := q.ScheduleFlakyTestFix("spec", []proto.FlakyTest{{Name: "test_upload"}})
+	if err != nil || updated == nil || updated.ID != first.ID {
+		t.Fatalf("Expected the pending fix-up story to be updated, got %v (err %v)", updated, err)
+	}
+	if !strings.Contains(updated.Content, "`TestEviction` (app/pkg/cache)") || !strings.Contains(updated.Content, "`test_upload`") {
+		t.Errorf("Expected both tests in the fix-up story, got:\n%s", updated.Content)
+	}
+	if len(q.GetAllStories()) != 1 {
+		t.Errorf("Expected a single fix-up story, got %d stories", len(q.GetAllStories()))
+	}
+
+	// Once the fix-up story is done, new flakes get a new story
+	first.SetStatus(StatusDone)
+	next, err := q.ScheduleFlakyTestFix("spec", []proto.FlakyTest{{Name: "TestEviction", Suite: "app/pkg/cache"}})
+	if err != nil || next == nil || next.ID == first.ID {
+		t.Errorf("Expected a new fix-up story after the first was done, got %v (err %v)", next, err)
+	}
+}
@@ -0,0 +1,233 @@
+package architect
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"orchestrator/pkg/persistence"
+)
+
+// Graph export formats.
+const (
+	GraphFormatJSON    = "json"
+	GraphFormatDOT     = "dot"
+	GraphFormatMermaid = "mermaid"
+)
+
+// graphStatusMissing marks a node for a dependency that is not in the graph.
+const graphStatusMissing = "missing"
+
+// GraphNode is a story in the dependency graph.
+type GraphNode struct {
+	ID              string  `json:"id"`
+	Title           string  `json:"title"`
+	Status          string  `json:"status"`
+	StoryType       string  `json:"story_type,omitempty"`
+	AssignedAgent   string  `json:"assigned_agent,omitempty"`
+	CostUSD         float64 `json:"cost_usd"`
+	EstimatedPoints int     `json:"estimated_points"`
+}
+
+// GraphEdge points from a dependency to the story that depends on it.
+type GraphEdge struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	InCycle bool   `json:"in_cycle,omitempty"`
+}
+
+// StoryGraph is a snapshot of the story dependency DAG.
+type StoryGraph struct {
+	Nodes  []GraphNode `json:"nodes"`
+	Edges  []GraphEdge `json:"edges"`
+	Cycles [][]string  `json:"cycles,omitempty"`
+}
+
+// NewStoryGraph builds the dependency graph of stories loaded outside the queue, e.g. from the database.
+func NewStoryGraph(stories []*persistence.Story) *StoryGraph {
+	return NewQueueFromStories(stories).Graph()
+}
+
+// Graph returns a snapshot of the queue's dependency graph. Dependencies on stories that are not
+// in the queue are kept as nodes with status "missing".
+func (q *Queue) Graph() *StoryGraph {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	graph := &StoryGraph{
+		Nodes:  []GraphNode{},
+		Edges:  []GraphEdge{},
+		Cycles: q.DetectCycles(),
+	}
+
+	cycleEdges := make(map[[2]string]bool)
+	for _, cycle := range graph.Cycles {
+		// Cycles are listed in dependency order and end where they started
+		for i := 0; i+1 < len(cycle); i++ {
+			cycleEdges[[2]string{cycle[i+1], cycle[i]}] = true
+		}
+	}
+
+	ids := make([]string, 0, len(q.stories))
+	for id := range q.stories {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	missing := make(map[string]bool)
+	for _, id := range ids {
+		story := q.stories[id]
+		graph.Nodes = append(graph.Nodes, GraphNode{
+			ID:              story.ID,
+			Title:           story.Title,
+			Status:          story.Status,
+			StoryType:       story.StoryType,
+			AssignedAgent:   story.AssignedAgent,
+			CostUSD:         story.CostUSD,
+			EstimatedPoints: story.EstimatedPoints,
+		})
+		for _, depID := range story.DependsOn {
+			graph.Edges = append(graph.Edges, GraphEdge{From: depID, To: id, InCycle: cycleEdges[[2]string{depID, id}]})
+			if _, exists := q.stories[depID]; !exists && !missing[depID] {
+				missing[depID] = true
+				graph.Nodes = append(graph.Nodes, GraphNode{ID: depID, Title: "unknown story", Status: graphStatusMissing})
+			}
+		}
+	}
+
+	return graph
+}
+
+// Render returns the graph in DOT or Mermaid format.
+func (g *StoryGraph) Render(format string) (string, error) {
+	switch format {
+	case GraphFormatDOT:
+		return g.DOT(), nil
+	case GraphFormatMermaid:
+		return g.Mermaid(), nil
+	default:
+		return "", fmt.Errorf("unknown graph format %q (expected %s or %s)", format, GraphFormatDOT, GraphFormatMermaid)
+	}
+}
+
+// graphColors are the fill colors of nodes by story status.
+var graphColors = map[string]string{
+	string(StatusNew):       "#e5e7eb",
+	string(StatusPending):   "#fef3c7",
+	string(StatusAssigned):  "#dbeafe",
+	string(StatusPlanning):  "#e0e7ff",
+	string(StatusCoding):    "#ede9fe",
+	string(StatusDone):      "#d1fae5",
+	string(StatusCancelled): "#f3f4f6",
+	graphStatusMissing:      "#fee2e2",
+}
+
+// graphColor returns the fill color for a status.
+func graphColor(status string) string {
+	if color, ok := graphColors[status]; ok {
+		return color
+	}
+	return "#ffffff"
+}
+
+// nodeDetails describes a node's status, assignee and cost on one line.
+func nodeDetails(node *GraphNode) string {
+	details := []string{node.Status}
+	if node.AssignedAgent != "" {
+		details = append(details, node.AssignedAgent)
+	}
+	if node.CostUSD > 0 {
+		details = append(details, fmt.Sprintf("$%.2f", node.CostUSD))
+	}
+	return strings.Join(details, " · ")
+}
+
+// DOT renders the graph in Graphviz DOT format.
+func (g *StoryGraph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph stories {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\"];\n")
+	for i := range g.Nodes {
+		node := &g.Nodes[i]
+		style := ""
+		if node.Status == graphStatusMissing {
+			style = ", style=\"rounded,filled,dashed\""
+		}
+		fmt.Fprintf(&sb, "  %s [label=%s, fillcolor=%q%s];\n",
+			dotQuote(node.ID), dotQuote(node.ID+": "+node.Title+"\n"+nodeDetails(node)), graphColor(node.Status), style)
+	}
+	for _, edge := range g.Edges {
+		attrs := ""
+		if edge.InCycle {
+			attrs = " [color=\"red\", penwidth=2]"
+		}
+		fmt.Fprintf(&sb, "  %s -> %s%s;\n", dotQuote(edge.From), dotQuote(edge.To), attrs)
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// dotQuote quotes a DOT identifier or label.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + strings.ReplaceAll(s, "\n", `\n`) + `"`
+}
+
+// mermaidIDPattern matches characters Mermaid does not accept in node IDs.
+var mermaidIDPattern = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// Mermaid renders the graph as a Mermaid flowchart.
+func (g *StoryGraph) Mermaid() string {
+	nodeID := func(id string) string {
+		return "s_" + mermaidIDPattern.ReplaceAllString(id, "_")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	statuses := make(map[string]bool)
+	for i := range g.Nodes {
+		node := &g.Nodes[i]
+		fmt.Fprintf(&sb, "  %s[\"%s<br/>%s\"]\n", nodeID(node.ID), mermaidEscape(node.ID+": "+node.Title), mermaidEscape(nodeDetails(node)))
+		statuses[node.Status] = true
+	}
+
+	var cycleLinks []string
+	for i, edge := range g.Edges {
+		fmt.Fprintf(&sb, "  %s --> %s\n", nodeID(edge.From), nodeID(edge.To))
+		if edge.InCycle {
+			cycleLinks = append(cycleLinks, fmt.Sprint(i))
+		}
+	}
+	if len(cycleLinks) > 0 {
+		fmt.Fprintf(&sb, "  linkStyle %s stroke:#dc2626,stroke-width:2px\n", strings.Join(cycleLinks, ","))
+	}
+
+	// Style nodes by status with one class per status in use
+	ordered := make([]string, 0, len(statuses))
+	for status := range statuses {
+		ordered = append(ordered, status)
+	}
+	sort.Strings(ordered)
+	for _, status := range ordered {
+		class := "status_" + mermaidIDPattern.ReplaceAllString(status, "_")
+		fmt.Fprintf(&sb, "  classDef %s fill:%s,stroke:#6b7280\n", class, graphColor(status))
+		var members []string
+		for i := range g.Nodes {
+			if g.Nodes[i].Status == status {
+				members = append(members, nodeID(g.Nodes[i].ID))
+			}
+		}
+		fmt.Fprintf(&sb, "  class %s %s\n", strings.Join(members, ","), class)
+	}
+	return sb.String()
+}
+
+// mermaidEscape makes text safe inside a quoted Mermaid label.
+func mermaidEscape(s string) string {
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	s = strings.ReplaceAll(s, "<", "#lt;")
+	return strings.ReplaceAll(s, ">", "#gt;")
+}
@@ -0,0 +1,79 @@
+package architect
+
+import (
+	"strings"
+	"testing"
+
+	"orchestrator/pkg/persistence"
+)
+
+func TestQueueGraph(t *testing.T) {
+	q := NewQueue(nil)
+	q.AddStory("001", "spec", "Set up project", "setup", storyTypeDevOps, nil, 1)
+	q.AddStory("002", "spec", "Add \"login\"", "login", storyTypeApp, []string{"001", "999"}, 2)
+	if err := q.UpdateStoryStatus("001", StatusDone); err != nil {
+		t.Fatalf("Failed to finish 001: %v", err)
+	}
+	story, _ := q.GetStory("002")
+	story.AssignedAgent = "coder-001"
+	story.CostUSD = 1.5
+	story.SetStatus(StatusCoding)
+
+	graph := q.Graph()
+	if len(graph.Nodes) != 3 || len(graph.Edges) != 2 || len(graph.Cycles) != 0 {
+		t.Fatalf("Unexpected graph: %+v", graph)
+	}
+	if graph.Nodes[2].ID != "999" || graph.Nodes[2].Status != graphStatusMissing {
+		t.Errorf("Expected unknown dependency as a missing node, got %+v", graph.Nodes[2])
+	}
+	if graph.Edges[0].From != "001" || graph.Edges[0].To != "002" {
+		t.Errorf("Expected edges to point from dependency to dependent, got %+v", graph.Edges[0])
+	}
+
+	dot := graph.DOT()
+	for _, want := range []string{
+		`"001" -> "002";`,
+		`"002" [label="002: Add \"login\"\ncoding · coder-001 · $1.50", fillcolor="#ede9fe"];`,
+		`style="rounded,filled,dashed"`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("Expected DOT to contain %q, got:\n%s", want, dot)
+		}
+	}
+
+	mermaid := graph.Mermaid()
+	for _, want := range []string{
+		"flowchart LR\n",
+		`s_002["002: Add #quot;login#quot;<br/>coding · coder-001 · $1.50"]`,
+		"s_001 --> s_002\n",
+		"class s_001 status_done\n",
+	} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("Expected Mermaid to contain %q, got:\n%s", want, mermaid)
+		}
+	}
+}
+
+func TestStoryGraphCycles(t *testing.T) {
+	graph := NewStoryGraph([]*persistence.Story{
+		{ID: "a", Title: "A", Status: string(StatusNew), DependsOn: []string{"b"}},
+		{ID: "b", Title: "B", Status: string(StatusNew), DependsOn: []string{"a"}},
+		{ID: "c", Title: "C", Status: string(StatusNew), DependsOn: []string{"a"}},
+	})
+
+	if len(graph.Cycles) != 1 {
+		t.Fatalf("Expected one cycle, got %v", graph.Cycles)
+	}
+	for _, edge := range graph.Edges {
+		if edge.InCycle != (edge.To != "c") {
+			t.Errorf("Unexpected cycle marking for %s -> %s", edge.From, edge.To)
+		}
+	}
+	if !strings.Contains(graph.Mermaid(), "linkStyle 0,1 stroke:#dc2626") {
+		t.Errorf("Expected cycle links to be highlighted, got:\n%s", graph.Mermaid())
+	}
+
+	if _, err := graph.Render("svg"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -18,8 +18,10 @@ func (d *Driver) handleMonitoring(ctx context.Context) (proto.State, error) {
 	}
 
 	// In monitoring state, we wait for either:
-	// 1. Coder questions/requests (transition to REQUEST).
-	// 2. Heartbeat to check for new ready stories.
+	// 1. Coder questions/requests (reviewed concurrently, merges and requeues transition to REQUEST).
+	// 2. Finished reviews (transition to REQUEST, where the response is sent).
+	// 3. Spec revisions and decisions on their change sets.
+	// 4. Heartbeat to dispatch ready stories while coders are free.
 	select {
 	case questionMsg, ok := <-d.questionsCh:
 		if !ok {
@@ -29,12 +31,25 @@ func (d *Driver) handleMonitoring(ctx context.Context) (proto.State, error) {
 		if questionMsg == nil {
 			return StateMonitoring, nil
 		}
-		// Store the question for processing in REQUEST state.
-		d.stateData["current_request"] = questionMsg
-		return StateRequest, nil
+		return d.acceptRequest(ctx, questionMsg, StateMonitoring), nil
+
+	case request := <-d.requests.results:
+		// Reviews finish concurrently, but their responses and queue updates are applied one at a time.
+		return d.reviewedRequest(request), nil
+
+	case revision := <-d.revisions.submitted:
+		d.startSpecRevision(ctx, revision)
+		return StateMonitoring, nil
+
+	case decision := <-d.revisions.decisions:
+		d.handleChangeSetDecision(ctx, decision)
+		return StateMonitoring, nil
 
 	case <-time.After(HeartbeatInterval):
-		// Heartbeat debug logging.
+		// Fill coders freed by requeued or failed stories.
+		if released := d.releaseReadyStories(ctx); released > 0 {
+			d.logger.Info("🧑‍💻 MONITORING: dispatched %d ready stories to free coders", released)
+		}
 		return StateMonitoring, nil
 
 	case <-ctx.Done():
@@ -0,0 +1,118 @@
+package architect
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"orchestrator/pkg/proto"
+	"orchestrator/pkg/utils"
+)
+
+// selectedOptionPrefix starts the line in which the LLM names its choice for a
+// multiple-choice question.
+const selectedOptionPrefix = "SELECTED:"
+
+// questionPayload extracts the structured question from a REQUEST message. Plain string
+// questions are returned as the payload text.
+func questionPayload(msg *proto.AgentMsg) (proto.QuestionRequestPayload, bool) {
+	raw, exists := msg.GetPayload(proto.KeyQuestion)
+	if !exists {
+		return proto.QuestionRequestPayload{}, false
+	}
+
+	switch q := raw.(type) {
+	case proto.QuestionRequestPayload:
+		return q, true
+	case *proto.QuestionRequestPayload:
+		if q == nil {
+			return proto.QuestionRequestPayload{}, false
+		}
+		return *q, true
+	case string:
+		return proto.QuestionRequestPayload{Text: q}, true
+	case map[string]any:
+		payload := proto.QuestionRequestPayload{
+			Text:              utils.GetMapFieldOr[string](q, "text", ""),
+			Context:           utils.GetMapFieldOr[string](q, "context", ""),
+			Urgency:           utils.GetMapFieldOr[string](q, "urgency", ""),
+			RecommendedOption: utils.GetMapFieldOr[string](q, "recommended_option", ""),
+			Category:          proto.QuestionCategory(utils.GetMapFieldOr[string](q, "category", "")),
+			NonBlocking:       utils.GetMapFieldOr[bool](q, "non_blocking", false),
+		}
+		if options, ok := q["options"].([]any); ok {
+			for _, option := range options {
+				if s, ok := option.(string); ok {
+					payload.Options = append(payload.Options, s)
+				}
+			}
+		}
+		return payload, true
+	default:
+		return proto.QuestionRequestPayload{Text: fmt.Sprintf("%v", raw)}, true
+	}
+}
+
+// buildQuestionPrompt renders a question for the LLM. Multiple-choice questions list the
+// options and the coder's recommendation and ask for the choice on a SELECTED line.
+func buildQuestionPrompt(q *proto.QuestionRequestPayload) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Answer this coding question: %s", q.Text))
+	if q.Context != "" {
+		sb.WriteString(fmt.Sprintf("\n\nContext: %s", q.Context))
+	}
+	if q.Category != "" {
+		sb.WriteString(fmt.Sprintf("\n\nCategory: %s", q.Category))
+	}
+
+	if len(q.Options) == 0 {
+		return sb.String()
+	}
+
+	sb.WriteString("\n\nThe coder offered these options:\n")
+	for i, option := range q.Options {
+		sb.WriteString(fmt.Sprintf("%d. %s", i+1, option))
+		if option == q.RecommendedOption {
+			sb.WriteString(" (coder's recommendation)")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(fmt.Sprintf("\nStart your answer with a line \"%s <option text>\" naming the option you choose, "+
+		"or \"%s none\" if none of them is right, then explain your reasoning.", selectedOptionPrefix, selectedOptionPrefix))
+	return sb.String()
+}
+
+// parseSelectedOption finds the option named on the answer's SELECTED line. The line may
+// give the option text or its 1-based number. It returns "" when no option was chosen.
+func parseSelectedOption(answer string, options []string) string {
+	if len(options) == 0 {
+		return ""
+	}
+
+	for _, line := range strings.Split(answer, "\n") {
+		line = strings.TrimSpace(strings.Trim(strings.TrimSpace(line), "*"))
+		if !strings.HasPrefix(strings.ToUpper(line), selectedOptionPrefix) {
+			continue
+		}
+		choice := strings.TrimSpace(line[len(selectedOptionPrefix):])
+		return matchOption(choice, options)
+	}
+	return ""
+}
+
+// matchOption resolves a choice given as option text (case-insensitive) or 1-based number.
+func matchOption(choice string, options []string) string {
+	choice = strings.Trim(strings.TrimSpace(choice), "\"'`.")
+	if choice == "" {
+		return ""
+	}
+	if n, err := strconv.Atoi(choice); err == nil && n >= 1 && n <= len(options) {
+		return options[n-1]
+	}
+	for _, option := range options {
+		if strings.EqualFold(option, choice) {
+			return option
+		}
+	}
+	return ""
+}
@@ -41,6 +41,11 @@ type PendingQuestion struct {
 	Status     string         `json:"status"` // "pending", "answered", "escalated"
 	Answer     string         `json:"answer,omitempty"`
 	AnsweredAt *time.Time     `json:"answered_at,omitempty"`
+
+	Options           []string               `json:"options,omitempty"`            // Candidate answers offered by the coder
+	RecommendedOption string                 `json:"recommended_option,omitempty"` // The coder's preferred option
+	Category          proto.QuestionCategory `json:"category,omitempty"`           // technical, business or environment
+	SelectedOption    string                 `json:"selected_option,omitempty"`    // Option chosen in the answer
 }
 
 // NewQuestionHandler creates a new question handler.
@@ -93,6 +98,12 @@ func (qh *QuestionHandler) HandleQuestion(ctx context.Context, msg *proto.AgentM
 		}
 	}
 
+	// Structured questions carry their text in the unified protocol payload.
+	structured, _ := questionPayload(msg)
+	if question == "" {
+		question = structured.Text
+	}
+
 	if storyID == "" || question == "" {
 		return fmt.Errorf("invalid question message: missing story_id or question (storyID='%s', question='%s')", storyID, question)
 	}
@@ -106,6 +117,10 @@ func (qh *QuestionHandler) HandleQuestion(ctx context.Context, msg *proto.AgentM
 		Context:  make(map[string]any),
 		AskedAt:  time.Now().UTC(),
 		Status:   "pending",
+
+		Options:           structured.Options,
+		RecommendedOption: structured.RecommendedOption,
+		Category:          structured.Category,
 	}
 
 	// Copy relevant context from message payload.
@@ -119,7 +134,7 @@ func (qh *QuestionHandler) HandleQuestion(ctx context.Context, msg *proto.AgentM
 	qh.pendingQuestions[pendingQ.ID] = pendingQ
 
 	// Check if this is a business question that should be escalated.
-	if qh.isBusinessQuestion(question, pendingQ.Context) {
+	if qh.isBusinessQuestion(pendingQ) {
 		return qh.escalateQuestion(ctx, pendingQ)
 	}
 
@@ -141,8 +156,17 @@ func (qh *QuestionHandler) answerTechnicalQuestion(ctx context.Context, pendingQ
 	}
 
 	// Prepare template data for Q&A prompt.
+	taskContent := pendingQ.Question
+	if len(pendingQ.Options) > 0 {
+		taskContent = buildQuestionPrompt(&proto.QuestionRequestPayload{
+			Text:              pendingQ.Question,
+			Options:           pendingQ.Options,
+			RecommendedOption: pendingQ.RecommendedOption,
+			Category:          pendingQ.Category,
+		})
+	}
 	templateData := &templates.TemplateData{
-		TaskContent: pendingQ.Question,
+		TaskContent: taskContent,
 		Extra: map[string]any{
 			"story_id":         pendingQ.StoryID,
 			"story_title":      story.Title,
@@ -169,6 +193,7 @@ func (qh *QuestionHandler) answerTechnicalQuestion(ctx context.Context, pendingQ
 	// Update question record.
 	now := time.Now().UTC()
 	pendingQ.Answer = answer
+	pendingQ.SelectedOption = parseSelectedOption(answer, pendingQ.Options)
 	pendingQ.Status = questionStatusAnswered
 	pendingQ.AnsweredAt = &now
 
@@ -180,6 +205,9 @@ func (qh *QuestionHandler) answerTechnicalQuestion(ctx context.Context, pendingQ
 func (qh *QuestionHandler) sendMockAnswer(ctx context.Context, pendingQ *PendingQuestion) error {
 	mockAnswer := fmt.Sprintf("Mock answer for question: %s\n\nThis is a simulated technical response that would normally be generated by the LLM based on the question context and story details.", pendingQ.Question)
 
+	// The coder's recommendation is the only informed choice available without an LLM.
+	pendingQ.SelectedOption = pendingQ.RecommendedOption
+
 	// Update question record.
 	now := time.Now().UTC()
 	pendingQ.Answer = mockAnswer
@@ -207,6 +235,9 @@ func (qh *QuestionHandler) sendAnswerToAgent(ctx context.Context, pendingQ *Pend
 	resultMsg.Payload["story_id"] = pendingQ.StoryID
 	resultMsg.Payload[proto.KeyAnswer] = pendingQ.Answer
 	resultMsg.Payload["answered_at"] = pendingQ.AnsweredAt.Format(time.RFC3339)
+	if pendingQ.SelectedOption != "" {
+		resultMsg.Payload[proto.KeySelectedOption] = pendingQ.SelectedOption
+	}
 
 	// Add metadata.
 	resultMsg.SetMetadata("question_type", "technical")
@@ -230,7 +261,17 @@ func (qh *QuestionHandler) sendResponseEffect(ctx context.Context, msg *proto.Ag
 }
 
 // isBusinessQuestion determines if a question requires business-level escalation.
-func (qh *QuestionHandler) isBusinessQuestion(question string, context map[string]any) bool {
+// An explicit category from the coder takes precedence over keyword matching.
+func (qh *QuestionHandler) isBusinessQuestion(pendingQ *PendingQuestion) bool {
+	switch pendingQ.Category {
+	case proto.QuestionCategoryBusiness:
+		return true
+	case proto.QuestionCategoryTechnical, proto.QuestionCategoryEnvironment:
+		return false
+	}
+
+	question, context := pendingQ.Question, pendingQ.Context
+
 	// Check for business-related keywords or flags.
 	businessKeywords := []string{
 		"business", "requirement", "stakeholder", "customer",
@@ -3,12 +3,15 @@ package architect
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"orchestrator/pkg/logx"
 	"orchestrator/pkg/persistence"
+	"orchestrator/pkg/proto"
 )
 
 // StoryStatus represents the status of a story (canonical source of truth).
@@ -27,6 +30,8 @@ const (
 	StatusCoding StoryStatus = "coding"
 	// StatusDone indicates work is completed and merged.
 	StatusDone StoryStatus = "done"
+	// StatusCancelled indicates a spec revision dropped the story before work on it started.
+	StatusCancelled StoryStatus = "cancelled"
 )
 
 // QueuedStory embeds the unified Story type with architect-specific methods.
@@ -44,6 +49,11 @@ func (s *QueuedStory) SetStatus(status StoryStatus) {
 	s.Status = string(status)
 }
 
+// IsFinished reports whether the story needs no more work: it is done or was cancelled.
+func (s *QueuedStory) IsFinished() bool {
+	return s.GetStatus() == StatusDone || s.GetStatus() == StatusCancelled
+}
+
 // NewQueuedStory creates a new QueuedStory from a persistence.Story.
 func NewQueuedStory(story *persistence.Story) *QueuedStory {
 	return &QueuedStory{Story: *story}
@@ -60,6 +70,7 @@ func (s *QueuedStory) ToPersistenceStory() *persistence.Story {
 type Queue struct {
 	mutex              sync.RWMutex // Protects all story operations
 	stories            map[string]*QueuedStory
+	dispatched         map[string]bool             // Pending stories sent to coders but not yet claimed
 	readyStoryCh       chan<- string               // Channel to notify when stories become ready
 	persistenceChannel chan<- *persistence.Request // Channel for database operations
 }
@@ -68,11 +79,22 @@ type Queue struct {
 func NewQueue(persistenceChannel chan<- *persistence.Request) *Queue {
 	return &Queue{
 		stories:            make(map[string]*QueuedStory),
+		dispatched:         make(map[string]bool),
 		persistenceChannel: persistenceChannel,
 		// readyStoryCh will be set by SetReadyChannel.
 	}
 }
 
+// NewQueueFromStories creates a queue without persistence holding stories loaded from the
+// database, for read-only views such as the story graph and the projected schedule.
+func NewQueueFromStories(stories []*persistence.Story) *Queue {
+	q := NewQueue(nil)
+	for _, story := range stories {
+		q.stories[story.ID] = NewQueuedStory(story)
+	}
+	return q
+}
+
 // SetPersistenceChannel sets the persistence channel for database operations.
 func (q *Queue) SetPersistenceChannel(ch chan<- *persistence.Request) {
 	q.persistenceChannel = ch
@@ -94,7 +116,7 @@ func (q *Queue) AddStory(storyID, specID, title, content, storyType string, depe
 			Title:           title,
 			Content:         content, // Story content from requirement description
 			ApprovedPlan:    "",      // Plan will be set during approval
-			Priority:        estimatedPoints,
+			Priority:        0,       // Explicit priority is set separately, see SetStoryPriority
 			DependsOn:       dependencies,
 			EstimatedPoints: estimatedPoints,
 			AssignedAgent:   "",
@@ -113,6 +135,24 @@ func (q *Queue) AddStory(storyID, specID, title, content, storyType string, depe
 	q.checkAndNotifyReady()
 }
 
+// RestoreStory puts a story that a coder is resuming after a restart back into the queue
+// as assigned to that coder, so it is not dispatched again and its reviews are accepted.
+func (q *Queue) RestoreStory(story *persistence.Story, agentID string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	restored := NewQueuedStory(story)
+	switch restored.GetStatus() {
+	case StatusAssigned, StatusPlanning, StatusCoding:
+	default:
+		restored.SetStatus(StatusAssigned)
+	}
+	restored.AssignedAgent = agentID
+	restored.LastUpdated = time.Now().UTC()
+
+	q.stories[story.ID] = restored
+}
+
 // FlushToDatabase writes all in-memory stories and dependencies to the database for persistence.
 // This uses the new persistence functions and ensures proper ordering (stories first, then dependencies).
 func (q *Queue) FlushToDatabase() {
@@ -131,26 +171,32 @@ func (q *Queue) FlushToDatabase() {
 			dbStatus = persistence.StatusCoding
 		case StatusDone:
 			dbStatus = persistence.StatusDone
+		case StatusCancelled:
+			dbStatus = persistence.StatusCancelled
 		default:
 			dbStatus = persistence.StatusNew
 		}
 
 		// Convert QueuedStory to persistence.Story with complete data
 		dbStory := &persistence.Story{
-			ID:            queuedStory.ID,
-			SpecID:        queuedStory.SpecID,
-			Title:         queuedStory.Title,
-			Content:       queuedStory.Content,      // Now includes story content
-			ApprovedPlan:  queuedStory.ApprovedPlan, // Now includes approved plan
-			Status:        dbStatus,
-			Priority:      queuedStory.Priority,
-			CreatedAt:     queuedStory.LastUpdated,
-			StartedAt:     queuedStory.StartedAt,
-			CompletedAt:   queuedStory.CompletedAt,
-			AssignedAgent: queuedStory.AssignedAgent,
-			StoryType:     queuedStory.StoryType,
-			TokensUsed:    0,   // Metrics data added during completion
-			CostUSD:       0.0, // Metrics data added during completion
+			ID:               queuedStory.ID,
+			SpecID:           queuedStory.SpecID,
+			Title:            queuedStory.Title,
+			Content:          queuedStory.Content,      // Now includes story content
+			ApprovedPlan:     queuedStory.ApprovedPlan, // Now includes approved plan
+			Status:           dbStatus,
+			Priority:         queuedStory.Priority,
+			EstimatedPoints:  queuedStory.EstimatedPoints,
+			EstimatedTokens:  queuedStory.EstimatedTokens,
+			EstimatedCostUSD: queuedStory.EstimatedCostUSD,
+			Risk:             queuedStory.Risk,
+			CreatedAt:        queuedStory.LastUpdated,
+			StartedAt:        queuedStory.StartedAt,
+			CompletedAt:      queuedStory.CompletedAt,
+			AssignedAgent:    queuedStory.AssignedAgent,
+			StoryType:        queuedStory.StoryType,
+			TokensUsed:       0,   // Metrics data added during completion
+			CostUSD:          0.0, // Metrics data added during completion
 		}
 
 		persistence.PersistStory(dbStory, q.persistenceChannel)
@@ -169,24 +215,12 @@ func (q *Queue) FlushToDatabase() {
 
 // Database loading methods have been removed - the queue is canonical.
 
-// NextReadyStory returns the next story that's ready to be worked on.
+// NextReadyStory returns the ready story that should be dispatched next, see ScheduleReadyStories.
 func (q *Queue) NextReadyStory() *QueuedStory {
-	ready := q.GetReadyStories()
+	ready := q.ScheduleReadyStories()
 	if len(ready) == 0 {
 		return nil
 	}
-
-	// Sort by priority (higher first), then by estimated points (smaller first), then by ID for deterministic ordering.
-	sort.Slice(ready, func(i, j int) bool {
-		if ready[i].Priority == ready[j].Priority {
-			if ready[i].EstimatedPoints == ready[j].EstimatedPoints {
-				return ready[i].ID < ready[j].ID
-			}
-			return ready[i].EstimatedPoints < ready[j].EstimatedPoints
-		}
-		return ready[i].Priority > ready[j].Priority // Higher priority first
-	})
-
 	return ready[0]
 }
 
@@ -208,17 +242,17 @@ func (q *Queue) GetReadyStories() []*QueuedStory {
 	return ready
 }
 
-// AllStoriesCompleted checks if all stories in the queue are completed.
+// AllStoriesCompleted checks if all stories in the queue are completed or cancelled.
 func (q *Queue) AllStoriesCompleted() bool {
 	for _, story := range q.stories {
-		if story.GetStatus() != StatusDone {
+		if !story.IsFinished() {
 			return false
 		}
 	}
 	return true
 }
 
-// areDependenciesMet checks if all dependencies for a story are completed.
+// areDependenciesMet checks if all dependencies for a story are completed or cancelled.
 func (q *Queue) areDependenciesMet(story *QueuedStory) bool {
 	for _, depID := range story.DependsOn {
 		dep, exists := q.stories[depID]
@@ -226,7 +260,7 @@ func (q *Queue) areDependenciesMet(story *QueuedStory) bool {
 			// Dependency doesn't exist - consider it as not met.
 			return false
 		}
-		if dep.GetStatus() != StatusDone {
+		if !dep.IsFinished() {
 			return false
 		}
 	}
@@ -255,6 +289,9 @@ func (q *Queue) checkAndNotifyReady() {
 // RequeueStory resets a story to pending status and clears the approved plan for fresh start.
 // This should be used when a coder errors out and a new coder needs to start from scratch.
 func (q *Queue) RequeueStory(storyID string) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
 	story, exists := q.stories[storyID]
 	if !exists {
 		return fmt.Errorf("story %s not found", storyID)
@@ -262,6 +299,7 @@ func (q *Queue) RequeueStory(storyID string) error {
 
 	// Clear assignment, approved plan, and reset to pending
 	story.SetStatus(StatusPending)
+	delete(q.dispatched, storyID)
 	story.AssignedAgent = ""
 	story.ApprovedPlan = "" // Clear approved plan for fresh start
 	story.StartedAt = nil
@@ -272,8 +310,181 @@ func (q *Queue) RequeueStory(storyID string) error {
 	return nil
 }
 
+// SplitStory replaces a story with the given sub-stories. Each sub-story inherits the
+// original's spec, type, priority and dependencies, plus the earlier sub-stories it names;
+// stories that depended on the original are rewired to depend on every sub-story. The
+// original itself is left for the caller to mark done. It returns the new stories and all
+// dependency edges added, for persistence.
+func (q *Queue) SplitStory(storyID string, subStories []proto.SubStorySpec) ([]*QueuedStory, []*persistence.StoryDependency, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	original, exists := q.stories[storyID]
+	if !exists {
+		return nil, nil, fmt.Errorf("story %s not found", storyID)
+	}
+	if original.GetStatus() == StatusDone {
+		return nil, nil, fmt.Errorf("story %s is already done", storyID)
+	}
+	if len(subStories) == 0 {
+		return nil, nil, fmt.Errorf("no sub-stories to split story %s into", storyID)
+	}
+
+	points := original.EstimatedPoints / len(subStories)
+	if points < 1 {
+		points = 1
+	}
+	tokens := original.EstimatedTokens / int64(len(subStories))
+	costUSD := original.EstimatedCostUSD / float64(len(subStories))
+
+	now := time.Now().UTC()
+	created := make([]*QueuedStory, 0, len(subStories))
+	newIDs := make([]string, 0, len(subStories))
+	var dependencies []*persistence.StoryDependency
+	for i := range subStories {
+		sub := &subStories[i]
+		id, err := persistence.GenerateStoryID()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate story ID: %w", err)
+		}
+
+		dependsOn := append([]string{}, original.DependsOn...)
+		for _, dep := range sub.DependsOn {
+			if dep < 0 || dep >= i {
+				return nil, nil, fmt.Errorf("sub-story %d can only depend on earlier sub-stories, got %d", i+1, dep+1)
+			}
+			dependsOn = append(dependsOn, newIDs[dep])
+		}
+		for _, depID := range dependsOn {
+			dependencies = append(dependencies, &persistence.StoryDependency{StoryID: id, DependsOn: depID})
+		}
+
+		story := &QueuedStory{
+			Story: persistence.Story{
+				ID:               id,
+				SpecID:           original.SpecID,
+				Title:            sub.Title,
+				Content:          sub.Content,
+				Priority:         original.Priority,
+				DependsOn:        dependsOn,
+				EstimatedPoints:  points,
+				EstimatedTokens:  tokens,
+				EstimatedCostUSD: costUSD,
+				Risk:             original.Risk,
+				LastUpdated:      now,
+				CreatedAt:        now,
+				StoryType:        original.StoryType,
+			},
+		}
+		story.SetStatus(StatusPending)
+
+		created = append(created, story)
+		newIDs = append(newIDs, id)
+	}
+
+	// Only add the stories once every sub-story is valid
+	for _, story := range created {
+		q.stories[story.ID] = story
+	}
+
+	for _, story := range q.stories {
+		if !slices.Contains(story.DependsOn, storyID) {
+			continue
+		}
+		story.DependsOn = append(story.DependsOn, newIDs...)
+		story.LastUpdated = now
+		for _, id := range newIDs {
+			dependencies = append(dependencies, &persistence.StoryDependency{StoryID: story.ID, DependsOn: id})
+		}
+	}
+
+	return created, dependencies, nil
+}
+
+// FlakyTestFixTitle is the title of the fix-up story scheduled for flaky tests.
+const FlakyTestFixTitle = "Fix flaky tests"
+
+// ScheduleFlakyTestFix makes sure a pending fix-up story covers the given flaky tests.
+// Tests are added to an existing unfinished fix-up story when there is one, so repeated
+// reports do not pile up duplicate stories; tests that story already lists are skipped.
+// It returns the new or updated story, or nil if nothing changed.
+func (q *Queue) ScheduleFlakyTestFix(specID string, flakyTests []proto.FlakyTest) (*QueuedStory, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	var existing *QueuedStory
+	for _, story := range q.stories {
+		if story.Title == FlakyTestFixTitle && story.GetStatus() != StatusDone {
+			existing = story
+			break
+		}
+	}
+
+	content := ""
+	if existing != nil {
+		content = existing.Content
+	}
+	var added []string
+	for i := range flakyTests {
+		line := formatFlakyTestLine(&flakyTests[i])
+		if !strings.Contains(content, line) && !slices.Contains(added, line) {
+			added = append(added, line)
+		}
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now().UTC()
+	if existing != nil {
+		if existing.GetStatus() != StatusPending {
+			// A coder is already working on it; the tests will be reported again if still flaky
+			return nil, nil
+		}
+		existing.Content = strings.TrimRight(existing.Content, "\n") + "\n" + strings.Join(added, "\n")
+		existing.LastUpdated = now
+		return existing, nil
+	}
+
+	id, err := persistence.GenerateStoryID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate story ID: %w", err)
+	}
+	story := &QueuedStory{
+		Story: persistence.Story{
+			ID:     id,
+			SpecID: specID,
+			Title:  FlakyTestFixTitle,
+			Content: "These tests failed during a story's test run and then passed when re-run in isolation. " +
+				"Find the source of the nondeterminism (timing, ordering, shared state, external resources) and make each test reliable. " +
+				"Do not delete or skip the tests.\n\n" + strings.Join(added, "\n"),
+			Priority:        1,
+			EstimatedPoints: 2,
+			LastUpdated:     now,
+			CreatedAt:       now,
+			StoryType:       string(proto.StoryTypeApp),
+		},
+	}
+	story.SetStatus(StatusPending)
+	q.stories[id] = story
+
+	q.checkAndNotifyReady()
+	return story, nil
+}
+
+// formatFlakyTestLine renders a flaky test as a line of the fix-up story.
+func formatFlakyTestLine(test *proto.FlakyTest) string {
+	if test.Suite == "" {
+		return fmt.Sprintf("- `%s`", test.Name)
+	}
+	return fmt.Sprintf("- `%s` (%s)", test.Name, test.Suite)
+}
+
 // SetApprovedPlan sets the approved plan for a story.
 func (q *Queue) SetApprovedPlan(storyID, approvedPlan string) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
 	story, exists := q.stories[storyID]
 	if !exists {
 		return fmt.Errorf("story %s not found", storyID)
@@ -285,12 +496,75 @@ func (q *Queue) SetApprovedPlan(storyID, approvedPlan string) error {
 	return nil
 }
 
+// SetStoryPriority sets a story's explicit scheduling priority; higher values are dispatched first.
+func (q *Queue) SetStoryPriority(storyID string, priority int) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	story, exists := q.stories[storyID]
+	if !exists {
+		return fmt.Errorf("story %s not found", storyID)
+	}
+	story.Priority = priority
+	return nil
+}
+
+// SetStoryEstimate records a story's complexity, token, cost and risk estimate.
+func (q *Queue) SetStoryEstimate(storyID string, estimate StoryEstimate) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	story, exists := q.stories[storyID]
+	if !exists {
+		return fmt.Errorf("story %s not found", storyID)
+	}
+	if estimate.Points > 0 {
+		story.EstimatedPoints = estimate.Points
+	}
+	story.EstimatedTokens = estimate.Tokens
+	story.EstimatedCostUSD = estimate.CostUSD
+	story.Risk = estimate.Risk
+	return nil
+}
+
 // GetStory returns a story by ID.
 func (q *Queue) GetStory(storyID string) (*QueuedStory, bool) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
 	story, exists := q.stories[storyID]
 	return story, exists
 }
 
+// GetStorySnapshot returns a copy of a story taken under the queue lock, for readers outside the
+// FSM goroutine such as background reviews.
+func (q *Queue) GetStorySnapshot(storyID string) (QueuedStory, bool) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+	story, exists := q.stories[storyID]
+	if !exists {
+		return QueuedStory{}, false
+	}
+	return *story, true
+}
+
+// SpecStories returns copies of the queued stories of a spec, for readers outside the FSM goroutine.
+func (q *Queue) SpecStories(specID string) []*persistence.Story {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	stories := make([]*persistence.Story, 0)
+	for _, queued := range q.stories {
+		if queued.SpecID != specID {
+			continue
+		}
+		copied := queued.Story
+		copied.DependsOn = append([]string(nil), queued.DependsOn...)
+		stories = append(stories, &copied)
+	}
+	sort.Slice(stories, func(i, j int) bool { return stories[i].ID < stories[j].ID })
+	return stories
+}
+
 // GetAllStories returns all stories in the queue.
 func (q *Queue) GetAllStories() []*QueuedStory {
 	stories := make([]*QueuedStory, 0, len(q.stories))
@@ -393,6 +667,7 @@ func (q *Queue) FromJSON(data []byte) error {
 	}
 
 	q.stories = make(map[string]*QueuedStory)
+	q.dispatched = make(map[string]bool)
 	for _, story := range stories {
 		q.stories[story.ID] = story
 	}
@@ -441,6 +716,7 @@ func (q *Queue) UpdateStoryStatus(storyID string, status StoryStatus) error {
 
 	story.SetStatus(status)
 	story.LastUpdated = time.Now().UTC()
+	delete(q.dispatched, storyID)
 	q.mutex.Unlock() // Release before persistence
 
 	// Persist to database (no mutex needed)
@@ -450,3 +726,23 @@ func (q *Queue) UpdateStoryStatus(storyID string, status StoryStatus) error {
 
 	return nil
 }
+
+// UpdateStoryTodos records a story's plan-todo progress and persists it with the story.
+func (q *Queue) UpdateStoryTodos(storyID string, todos []proto.StoryTodo) error {
+	q.mutex.Lock()
+	story, exists := q.stories[storyID]
+	if !exists {
+		q.mutex.Unlock()
+		return fmt.Errorf("story %s not found in queue", storyID)
+	}
+
+	story.Todos = todos
+	story.LastUpdated = time.Now().UTC()
+	q.mutex.Unlock() // Release before persistence
+
+	if q.persistenceChannel != nil {
+		persistence.PersistStory(story.ToPersistenceStory(), q.persistenceChannel)
+	}
+
+	return nil
+}
@@ -0,0 +1,319 @@
+package architect
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"orchestrator/pkg/config"
+	"orchestrator/pkg/contextmgr"
+	"orchestrator/pkg/proto"
+)
+
+const (
+	// reviewResultsBuffer is how many finished reviews can wait for the FSM before reviewers block.
+	reviewResultsBuffer = 64
+
+	// latencyWindow is how many recent requests of each kind the latency percentiles cover.
+	latencyWindow = 100
+)
+
+// trackedRequest is a coder request on its way through the architect. It is received in WAITING
+// or MONITORING, reviewed by the LLM outside the FSM goroutine if that is all it needs, and
+// applied in REQUEST, where the response is sent and the queue is updated.
+type trackedRequest struct {
+	receivedAt time.Time
+	reviewedAt time.Time // Zero until the review finished
+	msg        *proto.AgentMsg
+	story      *QueuedStory // Copy of the request's story taken on the FSM goroutine, nil if unknown
+	review     *requestReview
+	err        error
+}
+
+// requestReview is the LLM's verdict on a question or approval request. Producing it has no side
+// effects; acting on it is left to REQUEST.
+type requestReview struct {
+	answer         string // Answer to a question or feedback on an approval request
+	selectedOption string // Option chosen for a multiple-choice question
+	approved       bool
+}
+
+// requestPool runs request reviews concurrently. Its connection slots bound every LLM call the
+// architect makes, including the FSM's own, by the model's max_connections.
+type requestPool struct {
+	slots   chan struct{}
+	results chan *trackedRequest
+	latency *RequestLatencyRecorder
+	pending atomic.Int64 // Requests received but not yet answered
+}
+
+// newRequestPool creates a pool with the given number of model connections.
+func newRequestPool(connections int) *requestPool {
+	if connections < 1 {
+		connections = 1
+	}
+	return &requestPool{
+		slots:   make(chan struct{}, connections),
+		results: make(chan *trackedRequest, reviewResultsBuffer),
+		latency: NewRequestLatencyRecorder(),
+	}
+}
+
+// modelConnections returns the connection limit of the architect model, or 1 if it has none.
+func modelConnections(modelConfig *config.Model) int {
+	if modelConfig == nil || modelConfig.MaxConnections < 1 {
+		return 1
+	}
+	return modelConfig.MaxConnections
+}
+
+// acquire blocks until a model connection is free.
+func (p *requestPool) acquire(ctx context.Context) error {
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for an LLM connection cancelled: %w", ctx.Err())
+	}
+}
+
+// release frees a model connection taken by acquire.
+func (p *requestPool) release() {
+	<-p.slots
+}
+
+// reviewable reports whether a request only needs an LLM review before it can be applied, so the
+// review can run concurrently with other requests. Merges and requeues change the repository or
+// the queue and go to REQUEST directly.
+func reviewable(msg *proto.AgentMsg) bool {
+	if msg.Type != proto.MsgTypeREQUEST {
+		return false
+	}
+	kind, _ := proto.GetTypedPayload[string](msg, proto.KeyKind)
+	switch proto.RequestKind(kind) {
+	case proto.RequestKindQuestion:
+		return true
+	case proto.RequestKindApproval:
+		requestType, _ := proto.GetTypedPayload[string](msg, "request_type")
+		return requestType != "merge"
+	default:
+		return false
+	}
+}
+
+// requestLabel names a request for latency metrics: the approval type for approvals, otherwise the
+// request kind.
+func requestLabel(msg *proto.AgentMsg) string {
+	kind, _ := proto.GetTypedPayload[string](msg, proto.KeyKind)
+	if proto.RequestKind(kind) == proto.RequestKindApproval {
+		if requestType, _ := proto.GetTypedPayload[string](msg, "request_type"); requestType == "merge" {
+			return string(proto.RequestKindMerge)
+		}
+		if approvalType, ok := proto.GetTypedPayload[string](msg, "approval_type"); ok && approvalType != "" {
+			return approvalType
+		}
+	}
+	if kind == "" {
+		return string(msg.Type)
+	}
+	return kind
+}
+
+// acceptRequest records a coder request received in WAITING or MONITORING. Requests that only need
+// a review are reviewed in the background and the FSM stays in its current state; anything else
+// is handed to REQUEST right away.
+func (d *Driver) acceptRequest(ctx context.Context, msg *proto.AgentMsg, current proto.State) proto.State {
+	d.persistAgentRequest(msg)
+	request := &trackedRequest{msg: msg, receivedAt: time.Now()}
+	d.requests.pending.Add(1)
+
+	if !reviewable(msg) {
+		d.stateData["current_request"] = request
+		return StateRequest
+	}
+	// The review must not touch the queue, which the FSM keeps changing while it runs
+	request.story = d.requestStory(msg)
+	go d.reviewInBackground(ctx, request)
+	return current
+}
+
+// requestStory returns a copy of the story a request is about, or nil if it names no queued story.
+func (d *Driver) requestStory(msg *proto.AgentMsg) *QueuedStory {
+	storyID, _ := proto.GetTypedPayload[string](msg, proto.KeyStoryID)
+	if storyID == "" || d.queue == nil {
+		return nil
+	}
+	story, exists := d.queue.GetStorySnapshot(storyID)
+	if !exists {
+		return nil
+	}
+	return &story
+}
+
+// reviewInBackground reviews a request outside the FSM goroutine and hands it back to the FSM.
+func (d *Driver) reviewInBackground(ctx context.Context, request *trackedRequest) {
+	request.review, request.err = d.reviewRequest(ctx, request.msg, request.story)
+	request.reviewedAt = time.Now()
+
+	select {
+	case d.requests.results <- request:
+	case <-ctx.Done():
+	}
+}
+
+// reviewRequest asks the LLM about a question or approval request. Each review has its own
+// conversation, so concurrent reviews don't see each other's prompts, and reads the request's
+// story from the given copy rather than the queue.
+func (d *Driver) reviewRequest(ctx context.Context, msg *proto.AgentMsg, story *QueuedStory) (*requestReview, error) {
+	cm := contextmgr.NewContextManagerWithModel(d.modelConfig)
+	if kind, _ := proto.GetTypedPayload[string](msg, proto.KeyKind); proto.RequestKind(kind) == proto.RequestKindQuestion {
+		return d.reviewQuestion(ctx, cm, msg)
+	}
+	return d.reviewApproval(ctx, cm, msg, story), nil
+}
+
+// reviewedRequest stores a finished review for REQUEST to apply.
+func (d *Driver) reviewedRequest(request *trackedRequest) proto.State {
+	d.stateData["current_request"] = request
+	return StateRequest
+}
+
+// recordRequestLatency records how long a request took from receipt to its response.
+func (d *Driver) recordRequestLatency(request *trackedRequest) {
+	label := requestLabel(request.msg)
+	sample := d.requests.latency.Record(label, request.receivedAt, request.reviewedAt, time.Now())
+	d.logger.Info("⏱️ Answered %s request from %s in %s (review %s, waiting for REQUEST %s)",
+		label, request.msg.FromAgent, sample.Total.Round(time.Millisecond),
+		sample.Review.Round(time.Millisecond), sample.Apply.Round(time.Millisecond))
+}
+
+// GetRequestLatencies returns latency statistics of the requests the architect has answered.
+func (d *Driver) GetRequestLatencies() RequestLatencyReport {
+	report := d.requests.latency.Report()
+	report.Pending = int(d.requests.pending.Load())
+	report.Connections = cap(d.requests.slots)
+	return report
+}
+
+// RequestLatencySample is the latency of one answered request.
+type RequestLatencySample struct {
+	Review time.Duration // Receipt until the LLM review finished; zero for requests without one
+	Apply  time.Duration // Review (or receipt) until REQUEST sent the response
+	Total  time.Duration
+}
+
+// RequestLatencyStats summarizes the latency of one kind of request. Durations are in milliseconds.
+type RequestLatencyStats struct {
+	Kind        string `json:"kind"`
+	Count       int    `json:"count"`
+	Window      int    `json:"window"` // Recent requests the percentiles cover
+	AvgReviewMS int64  `json:"avg_review_ms"`
+	AvgApplyMS  int64  `json:"avg_apply_ms"`
+	AvgTotalMS  int64  `json:"avg_total_ms"`
+	P50TotalMS  int64  `json:"p50_total_ms"`
+	P95TotalMS  int64  `json:"p95_total_ms"`
+	MaxTotalMS  int64  `json:"max_total_ms"`
+	LastTotalMS int64  `json:"last_total_ms"`
+}
+
+// RequestLatencyReport is the latency of architect requests by kind.
+type RequestLatencyReport struct {
+	Kinds       []RequestLatencyStats `json:"kinds"`
+	Pending     int                   `json:"pending"`     // Requests received but not yet answered
+	Connections int                   `json:"connections"` // Concurrent LLM calls allowed
+}
+
+// latencySeries holds the totals of one request kind and its recent samples.
+type latencySeries struct {
+	recent     []time.Duration // Totals of the last latencyWindow requests, oldest overwritten first
+	review     time.Duration
+	apply      time.Duration
+	total      time.Duration
+	max        time.Duration
+	last       time.Duration
+	count      int
+	nextRecent int
+}
+
+// RequestLatencyRecorder records how long the architect takes to answer requests, by kind.
+type RequestLatencyRecorder struct {
+	series map[string]*latencySeries
+	mutex  sync.Mutex
+}
+
+// NewRequestLatencyRecorder creates an empty recorder.
+func NewRequestLatencyRecorder() *RequestLatencyRecorder {
+	return &RequestLatencyRecorder{series: make(map[string]*latencySeries)}
+}
+
+// Record adds a request received at receivedAt and answered at answeredAt. reviewedAt is zero for
+// requests that were applied without a separate review.
+func (r *RequestLatencyRecorder) Record(kind string, receivedAt, reviewedAt, answeredAt time.Time) RequestLatencySample {
+	sample := RequestLatencySample{Total: answeredAt.Sub(receivedAt), Apply: answeredAt.Sub(receivedAt)}
+	if !reviewedAt.IsZero() {
+		sample.Review = reviewedAt.Sub(receivedAt)
+		sample.Apply = answeredAt.Sub(reviewedAt)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	s, exists := r.series[kind]
+	if !exists {
+		s = &latencySeries{}
+		r.series[kind] = s
+	}
+	s.count++
+	s.review += sample.Review
+	s.apply += sample.Apply
+	s.total += sample.Total
+	s.max = max(s.max, sample.Total)
+	s.last = sample.Total
+	if len(s.recent) < latencyWindow {
+		s.recent = append(s.recent, sample.Total)
+	} else {
+		s.recent[s.nextRecent] = sample.Total
+		s.nextRecent = (s.nextRecent + 1) % latencyWindow
+	}
+	return sample
+}
+
+// Report returns the statistics of every request kind, sorted by kind.
+func (r *RequestLatencyRecorder) Report() RequestLatencyReport {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	report := RequestLatencyReport{Kinds: make([]RequestLatencyStats, 0, len(r.series))}
+	for kind, s := range r.series {
+		totals := slices.Clone(s.recent)
+		slices.Sort(totals)
+
+		count := time.Duration(s.count)
+		report.Kinds = append(report.Kinds, RequestLatencyStats{
+			Kind:        kind,
+			Count:       s.count,
+			Window:      len(totals),
+			AvgReviewMS: (s.review / count).Milliseconds(),
+			AvgApplyMS:  (s.apply / count).Milliseconds(),
+			AvgTotalMS:  (s.total / count).Milliseconds(),
+			P50TotalMS:  percentile(totals, 50).Milliseconds(),
+			P95TotalMS:  percentile(totals, 95).Milliseconds(),
+			MaxTotalMS:  s.max.Milliseconds(),
+			LastTotalMS: s.last.Milliseconds(),
+		})
+	}
+	sort.Slice(report.Kinds, func(i, j int) bool { return report.Kinds[i].Kind < report.Kinds[j].Kind })
+	return report
+}
+
+// percentile returns the nearest-rank percentile of sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
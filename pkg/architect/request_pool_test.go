@@ -0,0 +1,295 @@
+package architect
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"orchestrator/pkg/agent"
+	"orchestrator/pkg/config"
+	"orchestrator/pkg/persistence"
+	"orchestrator/pkg/proto"
+)
+
+// blockingLLMClient answers once released and records how many calls ran at the same time.
+type blockingLLMClient struct {
+	release chan struct{}
+	mutex   sync.Mutex
+	active  int
+	peak    int
+}
+
+func (c *blockingLLMClient) Complete(ctx context.Context, _ agent.CompletionRequest) (agent.CompletionResponse, error) {
+	c.mutex.Lock()
+	c.active++
+	c.peak = max(c.peak, c.active)
+	c.mutex.Unlock()
+	defer func() {
+		c.mutex.Lock()
+		c.active--
+		c.mutex.Unlock()
+	}()
+
+	select {
+	case <-c.release:
+		return agent.CompletionResponse{Content: "Use the existing client."}, nil
+	case <-ctx.Done():
+		return agent.CompletionResponse{}, ctx.Err()
+	}
+}
+
+func (c *blockingLLMClient) Stream(_ context.Context, _ agent.CompletionRequest) (<-chan agent.StreamChunk, error) {
+	return nil, nil
+}
+
+func (c *blockingLLMClient) GetDefaultConfig() config.Model {
+	return config.Model{Name: "test-model"}
+}
+
+func (c *blockingLLMClient) calls() (active, peak int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.active, c.peak
+}
+
+func newQuestion(from string) *proto.AgentMsg {
+	msg := proto.NewAgentMsg(proto.MsgTypeREQUEST, from, "architect")
+	msg.SetPayload(proto.KeyKind, string(proto.RequestKindQuestion))
+	msg.SetPayload(proto.KeyQuestion, "Which HTTP client should I use?")
+	return msg
+}
+
+func TestConcurrentRequestReviews(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &blockingLLMClient{release: make(chan struct{})}
+	d := NewDriver("architect", &config.Model{Name: "test-model", MaxConnections: 2}, client, nil, t.TempDir(),
+		make(chan *persistence.Request, 10))
+
+	for _, coder := range []string{"coder-001", "coder-002", "coder-003"} {
+		if next := d.acceptRequest(ctx, newQuestion(coder), StateMonitoring); next != StateMonitoring {
+			t.Fatalf("Expected a question to be reviewed without leaving MONITORING, got %s", next)
+		}
+	}
+
+	// Two reviews hold both connections, the third waits for one
+	deadline := time.Now().Add(5 * time.Second)
+	for active, _ := client.calls(); active < 2; active, _ = client.calls() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected two concurrent reviews, got %d", active)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	merge := proto.NewAgentMsg(proto.MsgTypeREQUEST, "coder-004", "architect")
+	merge.SetPayload(proto.KeyKind, string(proto.RequestKindMerge))
+	if next := d.acceptRequest(ctx, merge, StateMonitoring); next != StateRequest {
+		t.Errorf("Expected a merge to go to REQUEST directly, got %s", next)
+	}
+	if request, ok := d.stateData["current_request"].(*trackedRequest); !ok || request.msg != merge || request.review != nil {
+		t.Errorf("Expected the merge to be stored unreviewed, got %+v", d.stateData["current_request"])
+	}
+
+	close(client.release)
+	answered := make(map[string]bool)
+	for range 3 {
+		select {
+		case request := <-d.requests.results:
+			if request.err != nil || request.review == nil || request.review.answer != "Use the existing client." {
+				t.Errorf("Unexpected review: %+v", request)
+			}
+			if request.reviewedAt.Before(request.receivedAt) {
+				t.Errorf("Expected the review to finish after receipt, got %+v", request)
+			}
+			answered[request.msg.FromAgent] = true
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for reviews")
+		}
+	}
+	if len(answered) != 3 {
+		t.Errorf("Expected reviews of all three questions, got %v", answered)
+	}
+	if _, peak := client.calls(); peak != 2 {
+		t.Errorf("Expected at most two concurrent LLM calls, got %d", peak)
+	}
+	if report := d.GetRequestLatencies(); report.Pending != 4 || report.Connections != 2 {
+		t.Errorf("Expected four pending requests on two connections, got %+v", report)
+	}
+}
+
+func TestRequestLabel(t *testing.T) {
+	approval := proto.NewAgentMsg(proto.MsgTypeREQUEST, "coder-001", "architect")
+	approval.SetPayload(proto.KeyKind, string(proto.RequestKindApproval))
+	approval.SetPayload("approval_type", string(proto.ApprovalTypeCode))
+
+	merge := proto.NewAgentMsg(proto.MsgTypeREQUEST, "coder-001", "architect")
+	merge.SetPayload(proto.KeyKind, string(proto.RequestKindApproval))
+	merge.SetPayload("request_type", "merge")
+
+	requeue := proto.NewAgentMsg(proto.MsgTypeREQUEST, "coder-001", "architect")
+	requeue.SetPayload(proto.KeyKind, string(proto.RequestKindRequeue))
+
+	for _, tc := range []struct {
+		msg        *proto.AgentMsg
+		label      string
+		reviewable bool
+	}{
+		{newQuestion("coder-001"), string(proto.RequestKindQuestion), true},
+		{approval, string(proto.ApprovalTypeCode), true},
+		{merge, string(proto.RequestKindMerge), false},
+		{requeue, string(proto.RequestKindRequeue), false},
+	} {
+		if got := requestLabel(tc.msg); got != tc.label {
+			t.Errorf("Expected label %q, got %q", tc.label, got)
+		}
+		if got := reviewable(tc.msg); got != tc.reviewable {
+			t.Errorf("Expected %s to be reviewable=%v", tc.label, tc.reviewable)
+		}
+	}
+}
+
+func TestRequestLatencyRecorder(t *testing.T) {
+	r := NewRequestLatencyRecorder()
+	start := time.Now()
+	for i := 1; i <= 10; i++ {
+		received := start.Add(time.Duration(i) * time.Second)
+		r.Record("code", received, received.Add(time.Duration(i)*time.Second), received.Add(time.Duration(i+1)*time.Second))
+	}
+	sample := r.Record("merge", start, time.Time{}, start.Add(3*time.Second))
+	if sample.Review != 0 || sample.Apply != 3*time.Second {
+		t.Errorf("Expected an unreviewed request to count as applying, got %+v", sample)
+	}
+
+	report := r.Report()
+	if len(report.Kinds) != 2 || report.Kinds[0].Kind != "code" || report.Kinds[1].Kind != "merge" {
+		t.Fatalf("Expected code and merge statistics, got %+v", report.Kinds)
+	}
+	code := report.Kinds[0]
+	if code.Count != 10 || code.AvgReviewMS != 5500 || code.AvgApplyMS != 1000 || code.AvgTotalMS != 6500 {
+		t.Errorf("Unexpected averages: %+v", code)
+	}
+	if code.P50TotalMS != 6000 || code.P95TotalMS != 11000 || code.MaxTotalMS != 11000 || code.LastTotalMS != 11000 {
+		t.Errorf("Unexpected percentiles: %+v", code)
+	}
+}
+
+// TestConcurrentApprovalReviewsReadStorySnapshots runs approval reviews in the background while
+// the FSM goroutine adds stories and updates the story they review; run with -race.
+func TestConcurrentApprovalReviewsReadStorySnapshots(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	persistenceCh := make(chan *persistence.Request, 10)
+	go func() {
+		for {
+			select {
+			case req := <-persistenceCh:
+				if req.Response != nil {
+					req.Response <- []*persistence.EstimateOutcome{}
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	approvalTypes := []proto.ApprovalType{
+		proto.ApprovalTypeCode, proto.ApprovalTypeCompletion, proto.ApprovalTypeBudgetReview,
+		proto.ApprovalTypeSplit, proto.ApprovalTypeTests,
+	}
+	client := &blockingLLMClient{release: make(chan struct{})}
+	d := NewDriver("architect", &config.Model{Name: "test-model", MaxConnections: len(approvalTypes)}, client, nil, t.TempDir(), persistenceCh)
+	d.queue.AddStory("001", "spec-1", "Add login", "Let users log in.", "app", nil, 2)
+
+	for _, approvalType := range approvalTypes {
+		msg := proto.NewAgentMsg(proto.MsgTypeREQUEST, "coder-001", "architect")
+		msg.SetPayload(proto.KeyKind, string(proto.RequestKindApproval))
+		msg.SetPayload("approval_type", string(approvalType))
+		msg.SetPayload(proto.KeyStoryID, "001")
+		msg.SetPayload("origin", "CODING")
+		msg.SetPayload("content", "Done.")
+		if next := d.acceptRequest(ctx, msg, StateMonitoring); next != StateMonitoring {
+			t.Fatalf("Expected a %s review in the background, got %s", approvalType, next)
+		}
+	}
+
+	// Change the queue the way the FSM does until every review is waiting on the LLM
+	deadline := time.Now().Add(5 * time.Second)
+	for i := 0; ; i++ {
+		if active, _ := client.calls(); active == len(approvalTypes) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected every review to reach the LLM")
+		}
+		d.queue.AddStory(fmt.Sprintf("new-%d", i), "spec-1", "New story", "More work.", "app", nil, 1)
+		if err := d.queue.SetApprovedPlan("001", fmt.Sprintf("Plan revision %d", i)); err != nil {
+			t.Fatalf("SetApprovedPlan failed: %v", err)
+		}
+		if err := d.queue.SetStoryEstimate("001", StoryEstimate{Points: 1 + i%3, Tokens: int64(1000 * i), Risk: "low"}); err != nil {
+			t.Fatalf("SetStoryEstimate failed: %v", err)
+		}
+		if story, exists := d.queue.GetStory("001"); exists {
+			story.SetStatus(StatusCoding)
+			story.Title = fmt.Sprintf("Add login %d", i)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	close(client.release)
+	for range approvalTypes {
+		select {
+		case request := <-d.requests.results:
+			if request.err != nil || request.review == nil {
+				t.Errorf("Unexpected review: %+v", request)
+			}
+			if request.story == nil || request.story.ID != "001" {
+				t.Errorf("Expected the review to carry a copy of story 001, got %+v", request.story)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for reviews")
+		}
+	}
+}
+
+func TestReviewFailedResponse(t *testing.T) {
+	d := NewDriver("architect", &config.Model{Name: "test-model"}, nil, nil, t.TempDir(), make(chan *persistence.Request, 10))
+	reviewErr := fmt.Errorf("llm unavailable")
+
+	question := newQuestion("coder-001")
+	question.SetPayload(proto.KeyStoryID, "story-1")
+	response := d.reviewFailedResponse(question, reviewErr)
+	if kind, _ := proto.GetTypedPayload[string](response, proto.KeyKind); kind != string(proto.ResponseKindQuestion) {
+		t.Errorf("Expected a question response, got kind %q", kind)
+	}
+	if answer, _ := proto.GetTypedPayload[string](response, proto.KeyAnswer); !strings.Contains(answer, "llm unavailable") {
+		t.Errorf("Expected the answer to explain the failure, got %q", answer)
+	}
+	if response.ToAgent != "coder-001" || response.ParentMsgID != question.ID {
+		t.Errorf("Expected a reply to the question, got %+v", response)
+	}
+
+	approval := proto.NewAgentMsg(proto.MsgTypeREQUEST, "coder-002", "architect")
+	approval.SetPayload(proto.KeyKind, string(proto.RequestKindApproval))
+	approval.SetPayload("approval_type", string(proto.ApprovalTypeCode))
+	approval.SetPayload("approval_id", "approval-1")
+	approval.SetPayload(proto.KeyStoryID, "story-2")
+	response = d.reviewFailedResponse(approval, reviewErr)
+	result, ok := proto.GetTypedPayload[*proto.ApprovalResult](response, "approval_result")
+	if !ok {
+		t.Fatalf("Expected an approval result, got %+v", response.Payload)
+	}
+	if result.Status != proto.ApprovalStatusNeedsChanges || result.Type != proto.ApprovalTypeCode || result.RequestID != "approval-1" {
+		t.Errorf("Expected NEEDS_CHANGES for the code approval, got %+v", result)
+	}
+	if status, _ := proto.GetTypedPayload[string](response, "status"); status != string(proto.ApprovalStatusNeedsChanges) {
+		t.Errorf("Expected status NEEDS_CHANGES, got %q", status)
+	}
+	if storyID, _ := proto.GetTypedPayload[string](response, proto.KeyStoryID); storyID != "story-2" {
+		t.Errorf("Expected the story ID to be copied, got %q", storyID)
+	}
+}
@@ -0,0 +1,324 @@
+package architect
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"orchestrator/pkg/config"
+)
+
+// maxStoryPriority is the highest explicit priority spec analysis may give a story.
+const maxStoryPriority = 3
+
+// storyWeight is the projected duration of a story in estimated points; unestimated stories count as one.
+func storyWeight(story *QueuedStory) int {
+	if story.EstimatedPoints < 1 {
+		return 1
+	}
+	return story.EstimatedPoints
+}
+
+// isInFlight reports whether a story occupies a coder: it was dispatched or a coder is working on it.
+func (q *Queue) isInFlight(story *QueuedStory) bool {
+	switch story.GetStatus() {
+	case StatusAssigned, StatusPlanning, StatusCoding:
+		return true
+	case StatusPending:
+		return q.dispatched[story.ID]
+	default:
+		return false
+	}
+}
+
+// criticalPaths returns, for each unfinished story, the length in points of the longest chain of
+// unfinished work that starts with the story and runs through the stories depending on it.
+func (q *Queue) criticalPaths() map[string]int {
+	dependents := make(map[string][]string)
+	for id, story := range q.stories {
+		for _, depID := range story.DependsOn {
+			dependents[depID] = append(dependents[depID], id)
+		}
+	}
+
+	paths := make(map[string]int)
+	visiting := make(map[string]bool)
+	var pathOf func(id string) int
+	pathOf = func(id string) int {
+		if length, done := paths[id]; done {
+			return length
+		}
+		story := q.stories[id]
+		if visiting[id] || story.IsFinished() {
+			return 0 // Cycles are reported by DetectCycles
+		}
+		visiting[id] = true
+		longest := 0
+		for _, dependentID := range dependents[id] {
+			longest = max(longest, pathOf(dependentID))
+		}
+		visiting[id] = false
+		paths[id] = storyWeight(story) + longest
+		return paths[id]
+	}
+
+	for id := range q.stories {
+		pathOf(id)
+	}
+	return paths
+}
+
+// rankStories orders stories for scheduling: explicit priority first, then the longest critical
+// path, then the highest risk so surprises surface early, then the largest estimate so long stories
+// do not end up alone at the end of a spec.
+func rankStories(stories []*QueuedStory, paths map[string]int) {
+	sort.Slice(stories, func(i, j int) bool {
+		a, b := stories[i], stories[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if paths[a.ID] != paths[b.ID] {
+			return paths[a.ID] > paths[b.ID]
+		}
+		if riskRank(a.Risk) != riskRank(b.Risk) {
+			return riskRank(a.Risk) > riskRank(b.Risk)
+		}
+		if a.EstimatedPoints != b.EstimatedPoints {
+			return a.EstimatedPoints > b.EstimatedPoints
+		}
+		return a.ID < b.ID
+	})
+}
+
+// ScheduleReadyStories returns the ready stories that have not been dispatched yet, in the order
+// they should be released to coders.
+func (q *Queue) ScheduleReadyStories() []*QueuedStory {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var ready []*QueuedStory
+	for _, story := range q.stories {
+		if story.GetStatus() == StatusPending && !q.dispatched[story.ID] && q.areDependenciesMet(story) {
+			ready = append(ready, story)
+		}
+	}
+	rankStories(ready, q.criticalPaths())
+	return ready
+}
+
+// InFlightCount returns the number of stories dispatched to or being worked on by coders.
+func (q *Queue) InFlightCount() int {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	count := 0
+	for _, story := range q.stories {
+		if q.isInFlight(story) {
+			count++
+		}
+	}
+	return count
+}
+
+// MarkDispatched records that a pending story was sent to the coders, so it is not released twice.
+// Any later status change clears the mark.
+func (q *Queue) MarkDispatched(storyID string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if _, exists := q.stories[storyID]; exists {
+		q.dispatched[storyID] = true
+	}
+}
+
+// ProjectedStory is a story's place in a projected schedule. Times are in estimated points from now.
+type ProjectedStory struct {
+	ID           string `json:"id"`
+	Title        string `json:"title"`
+	Status       string `json:"status"`
+	Coder        int    `json:"coder"`
+	Start        int    `json:"start"`
+	Finish       int    `json:"finish"`
+	Priority     int    `json:"priority"`
+	CriticalPath int    `json:"critical_path"`
+	Risk         string `json:"risk,omitempty"`
+}
+
+// ScheduleProjection is the schedule the scheduler would follow for the unfinished stories.
+type ScheduleProjection struct {
+	Stories      []ProjectedStory `json:"stories"`
+	Blocked      []string         `json:"blocked,omitempty"` // Stories waiting on a cycle or a missing dependency
+	MaxCoders    int              `json:"max_coders"`
+	Makespan     int              `json:"makespan"`      // Projected points until all stories are done
+	CriticalPath int              `json:"critical_path"` // Lower bound on the makespan with unlimited coders
+	TotalPoints  int              `json:"total_points"`
+
+	// Estimated spend of the scheduled stories
+	EstimatedTokens  int64   `json:"estimated_tokens"`
+	EstimatedCostUSD float64 `json:"estimated_cost_usd"`
+}
+
+// ProjectSchedule simulates releasing the unfinished stories to maxCoders coders in scheduling
+// order, assuming each story takes its estimated points. Stories already in flight start first.
+func (q *Queue) ProjectSchedule(maxCoders int) *ScheduleProjection {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	maxCoders = max(maxCoders, 1)
+	paths := q.criticalPaths()
+	projection := &ScheduleProjection{Stories: []ProjectedStory{}, MaxCoders: maxCoders}
+
+	finish := make(map[string]int)
+	var inFlight, waiting []*QueuedStory
+	for _, story := range q.stories {
+		switch {
+		case story.IsFinished():
+			finish[story.ID] = 0
+		case q.isInFlight(story):
+			inFlight = append(inFlight, story)
+		default:
+			waiting = append(waiting, story)
+		}
+		if paths[story.ID] > projection.CriticalPath {
+			projection.CriticalPath = paths[story.ID]
+		}
+	}
+	rankStories(inFlight, paths)
+	rankStories(waiting, paths)
+
+	coders := make([]int, maxCoders) // Time each coder becomes free
+	schedule := func(story *QueuedStory, readyAt int) {
+		coder := earliestFree(coders)
+		start := max(coders[coder], readyAt)
+		coders[coder] = start + storyWeight(story)
+		finish[story.ID] = coders[coder]
+		projection.TotalPoints += storyWeight(story)
+		projection.EstimatedTokens += story.EstimatedTokens
+		projection.EstimatedCostUSD += story.EstimatedCostUSD
+		projection.Makespan = max(projection.Makespan, coders[coder])
+		projection.Stories = append(projection.Stories, ProjectedStory{
+			ID:           story.ID,
+			Title:        story.Title,
+			Status:       story.Status,
+			Coder:        coder + 1,
+			Start:        start,
+			Finish:       coders[coder],
+			Priority:     story.Priority,
+			CriticalPath: paths[story.ID],
+			Risk:         story.Risk,
+		})
+	}
+
+	for _, story := range inFlight {
+		schedule(story, 0)
+	}
+
+	// List scheduling: the next free coder takes the story it can start first, and of those the
+	// best-ranked one.
+	for len(waiting) > 0 {
+		freeAt := coders[earliestFree(coders)]
+		pick, pickReadyAt := -1, 0
+		for i, story := range waiting {
+			readyAt, scheduled := dependenciesFinishAt(story, finish)
+			if !scheduled {
+				continue
+			}
+			if pick == -1 || max(readyAt, freeAt) < max(pickReadyAt, freeAt) {
+				pick, pickReadyAt = i, readyAt
+			}
+		}
+		if pick == -1 {
+			break
+		}
+		schedule(waiting[pick], pickReadyAt)
+		waiting = append(waiting[:pick], waiting[pick+1:]...)
+	}
+
+	for _, story := range waiting {
+		projection.Blocked = append(projection.Blocked, story.ID)
+	}
+	sort.Strings(projection.Blocked)
+	return projection
+}
+
+// earliestFree returns the coder that becomes free first.
+func earliestFree(coders []int) int {
+	earliest := 0
+	for i := range coders {
+		if coders[i] < coders[earliest] {
+			earliest = i
+		}
+	}
+	return earliest
+}
+
+// dependenciesFinishAt returns when all of a story's dependencies are projected to finish, and
+// false if any of them is not scheduled yet.
+func dependenciesFinishAt(story *QueuedStory, finish map[string]int) (int, bool) {
+	readyAt := 0
+	for _, depID := range story.DependsOn {
+		at, scheduled := finish[depID]
+		if !scheduled {
+			return 0, false
+		}
+		readyAt = max(readyAt, at)
+	}
+	return readyAt, true
+}
+
+// Format renders the projection as a table.
+func (p *ScheduleProjection) Format() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Projected schedule for %d coder(s): %d points of work finish after %d points (critical path %d)\n",
+		p.MaxCoders, p.TotalPoints, p.Makespan, p.CriticalPath)
+	if p.EstimatedTokens > 0 {
+		fmt.Fprintf(&sb, "Estimated spend: %s tokens ($%.2f)\n", formatTokens(p.EstimatedTokens), p.EstimatedCostUSD)
+	}
+	sb.WriteString("\n")
+
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CODER\tSTART\tFINISH\tSTORY\tSTATUS\tPRIORITY\tCRITICAL PATH\tRISK\tTITLE")
+	for i := range p.Stories {
+		story := &p.Stories[i]
+		risk := story.Risk
+		if risk == "" {
+			risk = "-"
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%s\t%s\t%d\t%d\t%s\t%s\n",
+			story.Coder, story.Start, story.Finish, story.ID, story.Status, story.Priority, story.CriticalPath, risk, story.Title)
+	}
+	_ = tw.Flush()
+
+	if len(p.Blocked) > 0 {
+		fmt.Fprintf(&sb, "\nBlocked by a dependency cycle or a missing dependency: %s\n", strings.Join(p.Blocked, ", "))
+	}
+	return sb.String()
+}
+
+// schedulerMaxCoders returns how many stories may be in flight at once.
+func schedulerMaxCoders() int {
+	cfg, err := config.GetConfig()
+	if err != nil || cfg.Agents == nil || cfg.Agents.MaxCoders <= 0 {
+		return 1
+	}
+	return cfg.Agents.MaxCoders
+}
+
+// releaseReadyStories dispatches ready stories in scheduling order until max_coders stories are in
+// flight, and returns how many it dispatched.
+func (d *Driver) releaseReadyStories(ctx context.Context) int {
+	capacity := schedulerMaxCoders() - d.queue.InFlightCount()
+	released := 0
+	for _, story := range d.queue.ScheduleReadyStories() {
+		if released >= capacity {
+			break
+		}
+		if err := d.dispatchReadyStory(ctx, story.ID); err != nil {
+			d.logger.Warn("Failed to dispatch story %s: %v", story.ID, err)
+			continue
+		}
+		released++
+	}
+	return released
+}
@@ -0,0 +1,102 @@
+package architect
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func storyIDs(stories []*QueuedStory) []string {
+	ids := make([]string, len(stories))
+	for i, story := range stories {
+		ids[i] = story.ID
+	}
+	return ids
+}
+
+func TestScheduleReadyStories(t *testing.T) {
+	q := NewQueue(nil)
+	q.AddStory("small", "spec", "Small leaf", "", storyTypeApp, nil, 1)
+	q.AddStory("large", "spec", "Large leaf", "", storyTypeApp, nil, 3)
+	q.AddStory("root", "spec", "Start of the chain", "", storyTypeApp, nil, 1)
+	q.AddStory("chain", "spec", "End of the chain", "", storyTypeApp, []string{"root"}, 5)
+	q.AddStory("urgent", "spec", "Urgent", "", storyTypeApp, nil, 1)
+	if err := q.SetStoryPriority("urgent", 2); err != nil {
+		t.Fatalf("SetStoryPriority failed: %v", err)
+	}
+
+	// Priority first, then the longest chain (root: 6), then the largest estimate
+	want := []string{"urgent", "root", "large", "small"}
+	if got := storyIDs(q.ScheduleReadyStories()); !slices.Equal(got, want) {
+		t.Errorf("Expected order %v, got %v", want, got)
+	}
+	if next := q.NextReadyStory(); next == nil || next.ID != "urgent" {
+		t.Errorf("Expected urgent story next, got %+v", next)
+	}
+
+	q.MarkDispatched("urgent")
+	if got := storyIDs(q.ScheduleReadyStories()); slices.Contains(got, "urgent") {
+		t.Errorf("Expected dispatched story to be skipped, got %v", got)
+	}
+	if q.InFlightCount() != 1 {
+		t.Errorf("Expected one story in flight, got %d", q.InFlightCount())
+	}
+
+	// A requeue returns the story to the schedule
+	if err := q.UpdateStoryStatus("urgent", StatusPending); err != nil {
+		t.Fatalf("UpdateStoryStatus failed: %v", err)
+	}
+	if q.InFlightCount() != 0 || q.NextReadyStory().ID != "urgent" {
+		t.Error("Expected a requeued story to be schedulable again")
+	}
+
+	// Risk breaks ties between stories of the same priority and critical path
+	if err := q.SetStoryEstimate("small", StoryEstimate{Points: 3, Risk: "high"}); err != nil {
+		t.Fatalf("SetStoryEstimate failed: %v", err)
+	}
+	want = []string{"urgent", "root", "small", "large"}
+	if got := storyIDs(q.ScheduleReadyStories()); !slices.Equal(got, want) {
+		t.Errorf("Expected order %v, got %v", want, got)
+	}
+}
+
+func TestProjectSchedule(t *testing.T) {
+	q := NewQueue(nil)
+	q.AddStory("a", "spec", "Long chain start", "", storyTypeApp, nil, 3)
+	q.AddStory("b", "spec", "Long chain end", "", storyTypeApp, []string{"a"}, 2)
+	q.AddStory("c", "spec", "Quick fix", "", storyTypeApp, nil, 1)
+	q.AddStory("d", "spec", "Another quick fix", "", storyTypeApp, nil, 1)
+	q.AddStory("e", "spec", "Needs a missing story", "", storyTypeApp, []string{"missing"}, 1)
+
+	projection := q.ProjectSchedule(2)
+	if projection.Makespan != 5 || projection.CriticalPath != 5 || projection.TotalPoints != 7 {
+		t.Errorf("Expected makespan 5 on the critical path with 7 points scheduled, got %+v", projection)
+	}
+	if !slices.Equal(projection.Blocked, []string{"e"}) {
+		t.Errorf("Expected e to be blocked, got %v", projection.Blocked)
+	}
+
+	starts := make(map[string][2]int)
+	for _, story := range projection.Stories {
+		starts[story.ID] = [2]int{story.Coder, story.Start}
+	}
+	if starts["a"] != [2]int{1, 0} || starts["b"] != [2]int{2, 3} {
+		t.Errorf("Expected the critical chain to start first, got %v", starts)
+	}
+
+	output := projection.Format()
+	for _, want := range []string{"Projected schedule for 2 coder(s): 7 points of work finish after 5 points (critical path 5)", "Long chain start", "missing dependency: e"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	// In-flight stories keep their coder
+	if err := q.UpdateStoryStatus("c", StatusCoding); err != nil {
+		t.Fatalf("UpdateStoryStatus failed: %v", err)
+	}
+	projection = q.ProjectSchedule(1)
+	if projection.Stories[0].ID != "c" || projection.Makespan != 7 {
+		t.Errorf("Expected the in-flight story first on a single coder, got %+v", projection)
+	}
+}
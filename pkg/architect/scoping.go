@@ -24,11 +24,15 @@ type Requirement struct {
 	Description        string            `json:"description"`
 	AcceptanceCriteria []string          `json:"acceptance_criteria"`
 	EstimatedPoints    int               `json:"estimated_points"`
+	EstimatedTokens    int64             `json:"estimated_tokens"`
+	EstimatedCostUSD   float64           `json:"estimated_cost_usd"`
+	Risk               string            `json:"risk"` // "low", "medium" or "high"
 	Priority           int               `json:"priority"`
 	Dependencies       []string          `json:"dependencies"`
 	Tags               []string          `json:"tags"`
 	Details            map[string]string `json:"details"`
 	StoryType          string            `json:"story_type"` // "devops" or "app"
+	TestFirst          bool              `json:"test_first"` // Coder gets failing tests approved before coding
 }
 
 // handleScoping processes the scoping phase (platform detection, bootstrap, spec analysis and story generation).
@@ -69,6 +73,23 @@ func (d *Driver) handleScoping(ctx context.Context) (proto.State, error) {
 		}
 	}
 
+	// Spec Clarification - answers to questions about the spec become part of it.
+	specContent := string(rawSpecContent)
+	if clarified, exists := d.stateData["clarified_spec_content"].(string); exists {
+		specContent = clarified
+	} else if _, parsed := d.stateData["spec_parsing_completed_at"]; !parsed && specClarificationEnabled() && d.llmClient != nil {
+		clarified, clarifyErr := d.clarifySpec(ctx, specContent, specFile)
+		if clarifyErr != nil {
+			if ctx.Err() != nil {
+				return StateError, clarifyErr
+			}
+			d.logger.Warn("Spec clarification failed, generating stories from the spec as written: %v", clarifyErr)
+			clarified = specContent
+		}
+		specContent = clarified
+		d.stateData["clarified_spec_content"] = specContent
+	}
+
 	// Spec Analysis - check if spec already parsed.
 	var requirements []Requirement
 	if _, exists := d.stateData["spec_parsing_completed_at"]; !exists {
@@ -77,7 +98,7 @@ func (d *Driver) handleScoping(ctx context.Context) (proto.State, error) {
 			return StateError, fmt.Errorf("LLM client not available - spec analysis requires LLM")
 		}
 
-		requirements, err = d.parseSpecWithLLM(ctx, string(rawSpecContent), specFile)
+		requirements, err = d.parseSpecWithLLM(ctx, specContent, specFile)
 		if err != nil {
 			return StateError, fmt.Errorf("LLM spec analysis failed: %w", err)
 		}
@@ -85,7 +106,7 @@ func (d *Driver) handleScoping(ctx context.Context) (proto.State, error) {
 
 		// Store parsed requirements.
 		d.stateData["requirements"] = requirements
-		d.stateData["raw_spec_content"] = string(rawSpecContent)
+		d.stateData["raw_spec_content"] = specContent
 		d.stateData["spec_parsing_completed_at"] = time.Now().UTC()
 	} else {
 		// Reload requirements from state data.
@@ -102,7 +123,7 @@ func (d *Driver) handleScoping(ctx context.Context) (proto.State, error) {
 		// Generate stories from LLM-analyzed requirements.
 		if d.persistenceChannel != nil {
 			// Use database-aware story generation from requirements.
-			specID, storyIDs, err := d.generateStoriesFromRequirements(requirements, string(rawSpecContent))
+			specID, storyIDs, err := d.generateStoriesFromRequirements(requirements, specContent)
 			if err != nil {
 				return StateError, fmt.Errorf("failed to generate stories from requirements: %w", err)
 			}
@@ -138,8 +159,9 @@ func (d *Driver) parseSpecWithLLM(ctx context.Context, rawSpecContent, specFile
 	templateData := &templates.TemplateData{
 		TaskContent: rawSpecContent,
 		Extra: map[string]any{
-			"spec_file_path": specFile,
-			"mode":           "llm_analysis",
+			"spec_file_path":   specFile,
+			"mode":             "llm_analysis",
+			"estimate_history": d.estimateCalibration(ctx).PromptSummary(),
 		},
 	}
 
@@ -157,7 +179,29 @@ func (d *Driver) parseSpecWithLLM(ctx context.Context, rawSpecContent, specFile
 	d.stateData["llm_analysis"] = llmAnalysis
 
 	// Parse LLM response to extract requirements.
-	return d.parseSpecAnalysisJSON(llmAnalysis)
+	requirements, err := d.parseSpecAnalysisJSON(llmAnalysis)
+	if err != nil {
+		return nil, err
+	}
+	d.calibrateRequirements(ctx, requirements)
+	return requirements, nil
+}
+
+// calibrateRequirements adjusts the requirements' token and cost estimates with the estimate history.
+func (d *Driver) calibrateRequirements(ctx context.Context, requirements []Requirement) {
+	calibration := d.estimateCalibration(ctx)
+	costPerToken := coderCostPerToken()
+	for i := range requirements {
+		req := &requirements[i]
+		estimate := calibration.Calibrate(StoryEstimate{
+			Points: req.EstimatedPoints,
+			Tokens: req.EstimatedTokens,
+			Risk:   req.Risk,
+		}, costPerToken)
+		req.EstimatedTokens = estimate.Tokens
+		req.EstimatedCostUSD = estimate.CostUSD
+		req.Risk = estimate.Risk
+	}
 }
 
 // generateStoriesFromRequirements converts LLM-analyzed requirements into database stories.
@@ -201,6 +245,22 @@ func (d *Driver) generateStoriesFromRequirements(requirements []Requirement, spe
 		// Update canonical queue with story and dependencies
 		d.queue.AddStory(storyID, specID, req.Title, req.Description, req.StoryType, dependencies, req.EstimatedPoints)
 		d.logger.Debug("Added story %s to queue with dependencies: %v", storyID, dependencies)
+		if req.Priority > 0 {
+			if err := d.queue.SetStoryPriority(storyID, req.Priority); err != nil {
+				d.logger.Warn("Failed to set priority of story %s: %v", storyID, err)
+			}
+		}
+		estimate := StoryEstimate{Points: req.EstimatedPoints, Tokens: req.EstimatedTokens, CostUSD: req.EstimatedCostUSD, Risk: req.Risk}
+		if err := d.queue.SetStoryEstimate(storyID, estimate); err != nil {
+			d.logger.Warn("Failed to set estimate of story %s: %v", storyID, err)
+		}
+		if req.TestFirst {
+			if story, exists := d.queue.GetStory(storyID); exists {
+				if err := story.SetMetadata(persistence.StoryMetadata{TestFirst: true}); err != nil {
+					d.logger.Warn("Failed to mark story %s as test-first: %v", storyID, err)
+				}
+			}
+		}
 
 		// Collect dependencies for batch operation (don't send individually)
 		for _, depID := range dependencies {
@@ -257,11 +317,16 @@ func (d *Driver) requirementToStory(storyID, specID string, req *Requirement) *p
 		Title:      req.Title,
 		Content:    content,
 		Status:     persistence.StatusNew,
-		Priority:   req.EstimatedPoints, // Use points as priority
+		Priority:   req.Priority,
 		CreatedAt:  time.Now(),
 		TokensUsed: 0,
 		CostUSD:    0.0,
 		StoryType:  req.StoryType, // Use story type from requirement
+
+		EstimatedPoints:  req.EstimatedPoints,
+		EstimatedTokens:  req.EstimatedTokens,
+		EstimatedCostUSD: req.EstimatedCostUSD,
+		Risk:             req.Risk,
 	}
 }
 
@@ -407,8 +472,12 @@ func (d *Driver) parseSpecAnalysisJSON(response string) ([]Requirement, error) {
 			Description        string   `json:"description"`
 			AcceptanceCriteria []string `json:"acceptance_criteria"`
 			EstimatedPoints    int      `json:"estimated_points"`
+			EstimatedTokens    int64    `json:"estimated_tokens,omitempty"`
+			Risk               string   `json:"risk,omitempty"`
 			Dependencies       []string `json:"dependencies,omitempty"`
 			StoryType          string   `json:"story_type,omitempty"` // Add story type field
+			TestFirst          bool     `json:"test_first,omitempty"`
+			Priority           int      `json:"priority,omitempty"`
 		} `json:"requirements"`
 		NextAction string `json:"next_action"`
 	}
@@ -446,8 +515,12 @@ func (d *Driver) parseSpecAnalysisJSON(response string) ([]Requirement, error) {
 			Description:        req.Description,
 			AcceptanceCriteria: req.AcceptanceCriteria,
 			EstimatedPoints:    req.EstimatedPoints,
+			EstimatedTokens:    req.EstimatedTokens,
+			Risk:               normalizeRisk(req.Risk),
 			Dependencies:       req.Dependencies,
 			StoryType:          req.StoryType,
+			TestFirst:          req.TestFirst,
+			Priority:           min(max(req.Priority, 0), maxStoryPriority),
 		}
 
 		// Validate and set reasonable defaults.
@@ -0,0 +1,797 @@
+package architect
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"orchestrator/pkg/contextmgr"
+	"orchestrator/pkg/persistence"
+	"orchestrator/pkg/proto"
+	"orchestrator/pkg/templates"
+)
+
+// databaseQueryTimeout bounds how long the architect waits for a database query.
+const databaseQueryTimeout = 5 * time.Second
+
+// Spec revision states reported by SpecRevisionStatus.
+const (
+	RevisionIdle      = "idle"      // No revision submitted
+	RevisionProposing = "proposing" // The architect is working out the change set
+	RevisionPending   = "pending"   // A change set awaits approval
+	RevisionFailed    = "failed"    // The last revision could not be turned into a change set
+)
+
+var (
+	// ErrRevisionPending is returned when a spec revision is submitted while another is still
+	// being proposed or awaiting approval.
+	ErrRevisionPending = errors.New("a spec revision is already pending")
+	// ErrNoPendingChangeSet is returned when deciding on a change set that is not awaiting
+	// approval, e.g. because it was already decided.
+	ErrNoPendingChangeSet = errors.New("no pending spec change set")
+)
+
+// NewStoryChange is a story a spec revision adds.
+type NewStoryChange struct {
+	Key              string   `json:"key"` // Refers to the story within the change set until it has an ID
+	Title            string   `json:"title"`
+	Content          string   `json:"content"`
+	StoryType        string   `json:"story_type"`
+	Reason           string   `json:"reason,omitempty"`
+	DependsOn        []string `json:"depends_on,omitempty"` // Existing story IDs or keys of earlier new stories
+	EstimatedPoints  int      `json:"estimated_points"`
+	EstimatedTokens  int64    `json:"estimated_tokens,omitempty"`
+	EstimatedCostUSD float64  `json:"estimated_cost_usd,omitempty"`
+	Risk             string   `json:"risk,omitempty"`
+}
+
+// StoryModification is a new title and content for a story that has not started.
+type StoryModification struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// StoryCancellation drops a story that has not started.
+type StoryCancellation struct {
+	ID     string `json:"id"`
+	Title  string `json:"title,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// DependencyChange adds or removes dependencies of a story that has not started.
+type DependencyChange struct {
+	StoryID string   `json:"story_id"`
+	Reason  string   `json:"reason,omitempty"`
+	Add     []string `json:"add,omitempty"` // Existing story IDs or new story keys
+	Remove  []string `json:"remove,omitempty"`
+}
+
+// SpecChangeSet is the set of story changes proposed for a revision of an existing spec.
+// It is applied only after a human approves it.
+type SpecChangeSet struct {
+	ProposedAt        time.Time           `json:"proposed_at"`
+	ID                string              `json:"id"`
+	SpecID            string              `json:"spec_id"`
+	Summary           string              `json:"summary"`
+	NewStories        []NewStoryChange    `json:"new_stories"`
+	ModifiedStories   []StoryModification `json:"modified_stories"`
+	CancelledStories  []StoryCancellation `json:"cancelled_stories"`
+	DependencyChanges []DependencyChange  `json:"dependency_changes"`
+	Skipped           []string            `json:"skipped,omitempty"` // Proposed changes dropped, with the reason
+
+	specContent string               // Revised spec, stored when the change set is applied
+	spec        *persistence.Spec    // The spec as it was before the revision
+	baseline    []*persistence.Story // The spec's stories the change set was worked out against
+}
+
+// IsEmpty reports whether the change set changes no stories.
+func (cs *SpecChangeSet) IsEmpty() bool {
+	return len(cs.NewStories) == 0 && len(cs.ModifiedStories) == 0 &&
+		len(cs.CancelledStories) == 0 && len(cs.DependencyChanges) == 0
+}
+
+// SpecRevisionStatus describes where the architect is with the last submitted spec revision.
+type SpecRevisionStatus struct {
+	ChangeSet *SpecChangeSet `json:"change_set,omitempty"`
+	State     string         `json:"state"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// specRevision is a revised spec submitted for an existing spec ID.
+type specRevision struct {
+	queued  []*persistence.Story // Copies of the spec's queued stories, taken on the FSM goroutine
+	specID  string
+	content string
+}
+
+// changeSetDecision is a human's verdict on a change set.
+type changeSetDecision struct {
+	changeSet *SpecChangeSet
+	approved  bool
+}
+
+// revisionBoard holds the spec revision in progress. Revisions and decisions arrive from the
+// web UI or MCP on other goroutines and are picked up by the architect in WAITING or MONITORING.
+type revisionBoard struct {
+	pending   *SpecChangeSet
+	submitted chan specRevision
+	decisions chan changeSetDecision
+	failure   string
+	proposing bool
+	mu        sync.Mutex
+}
+
+// newRevisionBoard creates an empty revision board.
+func newRevisionBoard() *revisionBoard {
+	return &revisionBoard{
+		submitted: make(chan specRevision, 1),
+		decisions: make(chan changeSetDecision, 1),
+	}
+}
+
+// propose publishes a change set for approval.
+func (b *revisionBoard) propose(changeSet *SpecChangeSet) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = changeSet
+	b.proposing = false
+	b.failure = ""
+}
+
+// fail records that the submitted revision could not be turned into a change set.
+func (b *revisionBoard) fail(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.proposing = false
+	b.failure = err.Error()
+}
+
+// SubmitSpecRevision submits a revised version of an existing spec. The architect works out
+// the story changes it implies and holds them for approval; see SpecRevisionStatus.
+func (d *Driver) SubmitSpecRevision(specID, content string) error {
+	specID = strings.TrimSpace(specID)
+	if specID == "" || strings.TrimSpace(content) == "" {
+		return fmt.Errorf("spec ID and revised content are required")
+	}
+
+	d.revisions.mu.Lock()
+	defer d.revisions.mu.Unlock()
+	if d.revisions.proposing || d.revisions.pending != nil {
+		return ErrRevisionPending
+	}
+	select {
+	case d.revisions.submitted <- specRevision{specID: specID, content: content}:
+	default:
+		return ErrRevisionPending
+	}
+	d.revisions.proposing = true
+	d.revisions.failure = ""
+	return nil
+}
+
+// SpecRevisionStatus returns the state of the last submitted spec revision, with a copy of
+// its change set while that awaits approval.
+func (d *Driver) SpecRevisionStatus() SpecRevisionStatus {
+	d.revisions.mu.Lock()
+	defer d.revisions.mu.Unlock()
+	switch {
+	case d.revisions.pending != nil:
+		changeSet := *d.revisions.pending
+		return SpecRevisionStatus{State: RevisionPending, ChangeSet: &changeSet}
+	case d.revisions.proposing:
+		return SpecRevisionStatus{State: RevisionProposing}
+	case d.revisions.failure != "":
+		return SpecRevisionStatus{State: RevisionFailed, Error: d.revisions.failure}
+	default:
+		return SpecRevisionStatus{State: RevisionIdle}
+	}
+}
+
+// DecideChangeSet approves or rejects the pending change set with the given ID.
+func (d *Driver) DecideChangeSet(id string, approve bool) error {
+	d.revisions.mu.Lock()
+	defer d.revisions.mu.Unlock()
+	if d.revisions.pending == nil || d.revisions.pending.ID != id {
+		return ErrNoPendingChangeSet
+	}
+	d.revisions.decisions <- changeSetDecision{changeSet: d.revisions.pending, approved: approve}
+	d.revisions.pending = nil
+	return nil
+}
+
+// startSpecRevision copies the spec's queued stories and proposes the revision in the background.
+// The copies are taken here, on the FSM goroutine, because the queue keeps changing meanwhile.
+func (d *Driver) startSpecRevision(ctx context.Context, revision specRevision) {
+	revision.queued = d.queue.SpecStories(revision.specID)
+	go d.proposeSpecRevision(ctx, revision)
+}
+
+// proposeSpecRevision works out the change set for a submitted revision and holds it for approval.
+// It runs outside the FSM goroutine so coder requests are not held up by the revision's LLM call;
+// it reads the queue only through the revision's copies, and the revision board is the only state
+// it changes.
+func (d *Driver) proposeSpecRevision(ctx context.Context, revision specRevision) {
+	d.logger.Info("📝 Received revision of spec %s, working out story changes", revision.specID)
+	changeSet, err := d.buildSpecChangeSet(ctx, revision)
+	if err != nil {
+		d.logger.Error("❌ Failed to work out story changes for revised spec %s: %v", revision.specID, err)
+		d.revisions.fail(err)
+		return
+	}
+
+	d.revisions.propose(changeSet)
+	d.logger.Info("📝 Change set %s for spec %s awaits approval: %d new, %d modified, %d cancelled stories, %d dependency changes",
+		changeSet.ID, changeSet.SpecID, len(changeSet.NewStories), len(changeSet.ModifiedStories),
+		len(changeSet.CancelledStories), len(changeSet.DependencyChanges))
+}
+
+// handleChangeSetDecision applies an approved change set, or drops a rejected one.
+func (d *Driver) handleChangeSetDecision(ctx context.Context, decision changeSetDecision) {
+	changeSet := decision.changeSet
+	if !decision.approved {
+		d.logger.Info("🚫 Change set %s for spec %s was rejected", changeSet.ID, changeSet.SpecID)
+		return
+	}
+	if err := d.applySpecChangeSet(ctx, changeSet); err != nil {
+		d.logger.Error("❌ Failed to apply change set %s for spec %s: %v", changeSet.ID, changeSet.SpecID, err)
+		d.revisions.fail(err)
+	}
+}
+
+// buildSpecChangeSet asks the LLM how the spec's stories must change for the revision and
+// keeps only the changes that are valid for the stories' current state.
+func (d *Driver) buildSpecChangeSet(ctx context.Context, revision specRevision) (*SpecChangeSet, error) {
+	if d.llmClient == nil || d.renderer == nil {
+		return nil, fmt.Errorf("LLM client and template renderer are required to revise a spec")
+	}
+
+	spec, err := d.loadSpec(ctx, revision.specID)
+	if err != nil {
+		return nil, err
+	}
+	stories, err := d.loadSpecStories(ctx, revision.specID, revision.queued)
+	if err != nil {
+		return nil, err
+	}
+	if len(stories) == 0 {
+		return nil, fmt.Errorf("spec %s has no stories to revise", revision.specID)
+	}
+
+	templateData := &templates.TemplateData{
+		TaskContent: revision.content,
+		Extra: map[string]any{
+			"previous_spec": spec.Content,
+			"stories":       formatRevisionStories(stories),
+		},
+	}
+	prompt, err := d.renderer.RenderWithUserInstructions(templates.SpecRevisionTemplate, templateData, d.workDir, "ARCHITECT")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render spec revision template: %w", err)
+	}
+
+	// A conversation of its own, since the FSM keeps using the architect's context meanwhile
+	response, err := d.callLLM(ctx, contextmgr.NewContextManagerWithModel(d.modelConfig), prompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get LLM response for spec revision: %w", err)
+	}
+
+	changeSet, err := parseSpecChangeSet(response)
+	if err != nil {
+		return nil, err
+	}
+	validateChangeSet(changeSet, stories)
+	calibration, costPerToken := d.estimateCalibration(ctx), coderCostPerToken()
+	for i := range changeSet.NewStories {
+		change := &changeSet.NewStories[i]
+		estimate := calibration.Calibrate(StoryEstimate{Points: change.EstimatedPoints, Tokens: change.EstimatedTokens, Risk: change.Risk}, costPerToken)
+		change.EstimatedTokens, change.EstimatedCostUSD, change.Risk = estimate.Tokens, estimate.CostUSD, estimate.Risk
+	}
+
+	changeSet.ID = proto.GenerateApprovalID()
+	changeSet.SpecID = revision.specID
+	changeSet.ProposedAt = time.Now().UTC()
+	changeSet.specContent = revision.content
+	changeSet.spec = spec
+	changeSet.baseline = stories
+	return changeSet, nil
+}
+
+// loadSpec reads a spec from the database.
+func (d *Driver) loadSpec(ctx context.Context, specID string) (*persistence.Spec, error) {
+	result, err := d.queryDatabase(ctx, persistence.OpGetSpecByID, specID)
+	if err != nil {
+		return nil, err
+	}
+	spec, ok := result.(*persistence.Spec)
+	if !ok {
+		return nil, fmt.Errorf("unexpected spec query result %T", result)
+	}
+	return spec, nil
+}
+
+// loadSpecStories returns the spec's stories from the database, with the given copies of the
+// queued stories taking precedence since the queue is canonical.
+func (d *Driver) loadSpecStories(ctx context.Context, specID string, queued []*persistence.Story) ([]*persistence.Story, error) {
+	result, err := d.queryDatabase(ctx, persistence.OpGetStoriesBySpec, specID)
+	if err != nil {
+		return nil, err
+	}
+	stored, ok := result.([]*persistence.Story)
+	if !ok {
+		return nil, fmt.Errorf("unexpected stories query result %T", result)
+	}
+
+	inQueue := make(map[string]*persistence.Story, len(queued))
+	for _, story := range queued {
+		inQueue[story.ID] = story
+	}
+	stories := make([]*persistence.Story, 0, len(stored)+len(queued))
+	seen := make(map[string]bool, len(stored))
+	for _, story := range stored {
+		if current, exists := inQueue[story.ID]; exists {
+			story = current
+		}
+		stories = append(stories, story)
+		seen[story.ID] = true
+	}
+	for _, story := range queued {
+		if !seen[story.ID] {
+			stories = append(stories, story)
+		}
+	}
+
+	sort.SliceStable(stories, func(i, j int) bool { return stories[i].CreatedAt.Before(stories[j].CreatedAt) })
+	return stories, nil
+}
+
+// queryDatabase sends a query to the persistence worker and waits for its result.
+func (d *Driver) queryDatabase(ctx context.Context, operation string, data any) (any, error) {
+	if d.persistenceChannel == nil {
+		return nil, fmt.Errorf("persistence channel not available")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, databaseQueryTimeout)
+	defer cancel()
+
+	response := make(chan interface{}, 1)
+	select {
+	case d.persistenceChannel <- &persistence.Request{Operation: operation, Data: data, Response: response}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out sending %s query: %w", operation, ctx.Err())
+	}
+
+	select {
+	case result := <-response:
+		if err, isErr := result.(error); isErr {
+			return nil, err
+		}
+		return result, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out waiting for %s query: %w", operation, ctx.Err())
+	}
+}
+
+// formatRevisionStories describes the spec's stories for the spec revision template.
+func formatRevisionStories(stories []*persistence.Story) string {
+	var sb strings.Builder
+	for _, story := range stories {
+		sb.WriteString(fmt.Sprintf("### %s: %s\n", story.ID, story.Title))
+		sb.WriteString(fmt.Sprintf("- Status: %s\n- Type: %s\n", story.Status, story.StoryType))
+		if len(story.DependsOn) > 0 {
+			sb.WriteString(fmt.Sprintf("- Depends on: %s\n", strings.Join(story.DependsOn, ", ")))
+		}
+		sb.WriteString("\n")
+		sb.WriteString(strings.TrimSpace(story.Content))
+		sb.WriteString("\n\n")
+	}
+	return sb.String()
+}
+
+// parseSpecChangeSet extracts the change set from the LLM's spec revision response.
+func parseSpecChangeSet(response string) (*SpecChangeSet, error) {
+	jsonStart := strings.Index(response, "{")
+	jsonEnd := strings.LastIndex(response, "}")
+	if jsonStart == -1 || jsonEnd <= jsonStart {
+		return nil, fmt.Errorf("no valid JSON found in spec revision response")
+	}
+
+	var changeSet SpecChangeSet
+	if err := json.Unmarshal([]byte(response[jsonStart:jsonEnd+1]), &changeSet); err != nil {
+		return nil, fmt.Errorf("failed to parse spec revision JSON: %w", err)
+	}
+	changeSet.Skipped = nil
+	return &changeSet, nil
+}
+
+// storyNotStarted reports whether no coder has picked up the story yet, so it may still be
+// modified or cancelled.
+func storyNotStarted(story *persistence.Story) bool {
+	return (story.Status == persistence.StatusNew || story.Status == persistence.StatusPending) && story.AssignedAgent == ""
+}
+
+// validateChangeSet drops proposed changes that do not fit the spec's stories, recording each
+// in Skipped: changes to stories that do not exist or have started, references to unknown
+// stories, and dependencies that would create a cycle.
+//
+//nolint:cyclop // Each kind of change has its own checks
+func validateChangeSet(changeSet *SpecChangeSet, stories []*persistence.Story) {
+	skip := func(format string, args ...any) {
+		changeSet.Skipped = append(changeSet.Skipped, fmt.Sprintf(format, args...))
+	}
+
+	byID := make(map[string]*persistence.Story, len(stories))
+	graph := make(map[string][]string, len(stories))
+	hasDevOps := false
+	for _, story := range stories {
+		byID[story.ID] = story
+		graph[story.ID] = append([]string(nil), story.DependsOn...)
+		if story.StoryType == storyTypeDevOps && story.Status != persistence.StatusCancelled {
+			hasDevOps = true
+		}
+	}
+	changeable := func(action, id string) bool {
+		story, exists := byID[id]
+		switch {
+		case !exists:
+			skip("%s %s: the spec has no such story", action, id)
+			return false
+		case !storyNotStarted(story):
+			skip("%s %s (%s): the story is already %s", action, id, story.Title, story.Status)
+			return false
+		}
+		return true
+	}
+
+	// Cancellations first, so other changes cannot build on cancelled stories
+	cancelled := make(map[string]bool)
+	cancellations := changeSet.CancelledStories[:0]
+	for _, cancellation := range changeSet.CancelledStories {
+		if cancelled[cancellation.ID] || !changeable("Cancel", cancellation.ID) {
+			continue
+		}
+		cancellation.Title = byID[cancellation.ID].Title
+		cancelled[cancellation.ID] = true
+		cancellations = append(cancellations, cancellation)
+	}
+	changeSet.CancelledStories = cancellations
+
+	modifications := changeSet.ModifiedStories[:0]
+	for _, modification := range changeSet.ModifiedStories {
+		if cancelled[modification.ID] || !changeable("Modify", modification.ID) {
+			continue
+		}
+		story := byID[modification.ID]
+		modification.Title = strings.TrimSpace(modification.Title)
+		if modification.Title == "" {
+			modification.Title = story.Title
+		}
+		if strings.TrimSpace(modification.Content) == "" {
+			modification.Content = story.Content
+		}
+		if modification.Title == story.Title && modification.Content == story.Content {
+			continue
+		}
+		modifications = append(modifications, modification)
+	}
+	changeSet.ModifiedStories = modifications
+
+	// reaches reports whether from depends on to, directly or transitively.
+	reaches := func(from, to string) bool {
+		visited := make(map[string]bool)
+		stack := []string{from}
+		for len(stack) > 0 {
+			id := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if id == to {
+				return true
+			}
+			if visited[id] {
+				continue
+			}
+			visited[id] = true
+			stack = append(stack, graph[id]...)
+		}
+		return false
+	}
+	dependable := func(id string) bool {
+		if _, exists := byID[id]; exists {
+			return !cancelled[id]
+		}
+		_, isNew := graph[id]
+		return isNew
+	}
+
+	newStories := changeSet.NewStories[:0]
+	for i := range changeSet.NewStories {
+		story := changeSet.NewStories[i]
+		story.Title = strings.TrimSpace(story.Title)
+		if story.Title == "" {
+			continue
+		}
+		story.Key = strings.TrimSpace(story.Key)
+		if story.Key == "" {
+			story.Key = fmt.Sprintf("new-%d", i+1)
+		}
+		if _, taken := graph[story.Key]; taken {
+			skip("New story %q: key %s is already used", story.Title, story.Key)
+			continue
+		}
+		if !proto.IsValidStoryType(story.StoryType) {
+			story.StoryType = string(proto.StoryTypeApp)
+		}
+		if story.StoryType == storyTypeDevOps && hasDevOps {
+			skip("New story %q: the spec already has a devops story", story.Title)
+			continue
+		}
+		if story.EstimatedPoints < 1 || story.EstimatedPoints > 5 {
+			story.EstimatedPoints = 2
+		}
+
+		dependsOn := make([]string, 0, len(story.DependsOn))
+		for _, dep := range story.DependsOn {
+			if !dependable(dep) {
+				skip("New story %q: dependency on %s dropped, no such story", story.Title, dep)
+				continue
+			}
+			dependsOn = append(dependsOn, dep)
+		}
+		story.DependsOn = dependsOn
+		graph[story.Key] = dependsOn
+		if story.StoryType == storyTypeDevOps {
+			hasDevOps = true
+		}
+		newStories = append(newStories, story)
+	}
+	changeSet.NewStories = newStories
+
+	dependencyChanges := changeSet.DependencyChanges[:0]
+	for _, change := range changeSet.DependencyChanges {
+		if cancelled[change.StoryID] || !changeable("Change dependencies of", change.StoryID) {
+			continue
+		}
+
+		var remove []string
+		for _, dep := range change.Remove {
+			if index := indexOf(graph[change.StoryID], dep); index >= 0 {
+				graph[change.StoryID] = append(graph[change.StoryID][:index:index], graph[change.StoryID][index+1:]...)
+				remove = append(remove, dep)
+			}
+		}
+
+		var add []string
+		for _, dep := range change.Add {
+			switch {
+			case dep == change.StoryID || indexOf(graph[change.StoryID], dep) >= 0:
+				continue
+			case !dependable(dep):
+				skip("Make %s depend on %s: no such story", change.StoryID, dep)
+				continue
+			case reaches(dep, change.StoryID):
+				skip("Make %s depend on %s: this would create a dependency cycle", change.StoryID, dep)
+				continue
+			}
+			graph[change.StoryID] = append(graph[change.StoryID], dep)
+			add = append(add, dep)
+		}
+
+		if len(add) == 0 && len(remove) == 0 {
+			continue
+		}
+		change.Add, change.Remove = add, remove
+		dependencyChanges = append(dependencyChanges, change)
+	}
+	changeSet.DependencyChanges = dependencyChanges
+}
+
+// indexOf returns the index of value in values, or -1.
+func indexOf(values []string, value string) int {
+	for i := range values {
+		if values[i] == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// applySpecChangeSet applies an approved change set to the queue, persists the changed stories,
+// dependencies and revised spec, and dispatches stories that became ready.
+func (d *Driver) applySpecChangeSet(ctx context.Context, changeSet *SpecChangeSet) error {
+	result, err := d.queue.ApplyChangeSet(changeSet)
+	if err != nil {
+		return err
+	}
+
+	stories := make([]*persistence.Story, 0, len(result.created)+len(result.updated))
+	for _, story := range result.created {
+		stories = append(stories, story.ToPersistenceStory())
+	}
+	for _, story := range result.updated {
+		stories = append(stories, story.ToPersistenceStory())
+	}
+	if d.persistenceChannel != nil {
+		if len(stories) > 0 || len(result.added) > 0 {
+			d.persistenceChannel <- &persistence.Request{
+				Operation: persistence.OpBatchUpsertStoriesWithDependencies,
+				Data: &persistence.BatchUpsertStoriesWithDependenciesRequest{
+					Stories:      stories,
+					Dependencies: result.added,
+				},
+				Response: nil, // Fire-and-forget
+			}
+		}
+		for _, dependency := range result.removed {
+			persistence.PersistDependencyRemoval(dependency.StoryID, dependency.DependsOn, d.persistenceChannel)
+		}
+
+		// The revision keeps the spec's creation and processing times and records its own
+		revisedAt := time.Now().UTC()
+		spec := &persistence.Spec{ID: changeSet.SpecID, Content: changeSet.specContent, CreatedAt: revisedAt, RevisedAt: &revisedAt}
+		if changeSet.spec != nil {
+			spec.CreatedAt, spec.ProcessedAt = changeSet.spec.CreatedAt, changeSet.spec.ProcessedAt
+		}
+		persistence.PersistSpec(spec, d.persistenceChannel)
+	}
+
+	d.logger.Info("✅ Applied change set %s to spec %s: %d new, %d updated stories", changeSet.ID, changeSet.SpecID, len(result.created), len(result.updated))
+	for _, note := range result.skipped {
+		d.logger.Warn("Change set %s: skipped %s", changeSet.ID, note)
+	}
+
+	d.releaseReadyStories(ctx)
+	return nil
+}
+
+// changeSetResult lists what applying a change set changed in the queue.
+type changeSetResult struct {
+	created []*QueuedStory
+	updated []*QueuedStory // Modified, cancelled or with changed dependencies
+	adopted []*QueuedStory // Stories of the spec that were only in the database
+	added   []*persistence.StoryDependency
+	removed []*persistence.StoryDependency
+	skipped []string
+}
+
+// ApplyChangeSet applies an approved spec change set. Stories of the spec that are only in the
+// database, e.g. after a restart, are put back in the queue first so dependencies on them
+// resolve. Changes to stories that started since the change set was proposed are skipped.
+func (q *Queue) ApplyChangeSet(changeSet *SpecChangeSet) (*changeSetResult, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	result := &changeSetResult{}
+	for _, stored := range changeSet.baseline {
+		if _, exists := q.stories[stored.ID]; exists {
+			continue
+		}
+		story := NewQueuedStory(stored)
+		story.DependsOn = append([]string(nil), stored.DependsOn...)
+		if !story.IsFinished() {
+			story.SetStatus(StatusPending)
+			story.AssignedAgent = ""
+		}
+		q.stories[story.ID] = story
+		result.adopted = append(result.adopted, story)
+	}
+
+	now := time.Now().UTC()
+	ids := make(map[string]string, len(changeSet.NewStories)) // New story key -> story ID
+	for i := range changeSet.NewStories {
+		id, err := persistence.GenerateStoryID()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate story ID: %w", err)
+		}
+		ids[changeSet.NewStories[i].Key] = id
+	}
+	resolve := func(ref string) string {
+		if id, isNew := ids[ref]; isNew {
+			return id
+		}
+		return ref
+	}
+
+	// changeable returns a story the change set may still change, skipping it otherwise.
+	changeable := func(action, id string) *QueuedStory {
+		story, exists := q.stories[id]
+		if !exists {
+			result.skipped = append(result.skipped, fmt.Sprintf("%s %s: story is no longer in the queue", action, id))
+			return nil
+		}
+		if story.GetStatus() != StatusPending || story.AssignedAgent != "" {
+			result.skipped = append(result.skipped, fmt.Sprintf("%s %s (%s): the story started since the change set was proposed", action, id, story.Title))
+			return nil
+		}
+		return story
+	}
+	updated := make(map[string]bool)
+
+	for i := range changeSet.NewStories {
+		change := &changeSet.NewStories[i]
+		story := &QueuedStory{
+			Story: persistence.Story{
+				ID:               ids[change.Key],
+				SpecID:           changeSet.SpecID,
+				Title:            change.Title,
+				Content:          change.Content,
+				EstimatedPoints:  change.EstimatedPoints,
+				EstimatedTokens:  change.EstimatedTokens,
+				EstimatedCostUSD: change.EstimatedCostUSD,
+				Risk:             change.Risk,
+				StoryType:        change.StoryType,
+				LastUpdated:      now,
+				CreatedAt:        now,
+			},
+		}
+		story.SetStatus(StatusPending)
+		for _, dep := range change.DependsOn {
+			depID := resolve(dep)
+			story.DependsOn = append(story.DependsOn, depID)
+			result.added = append(result.added, &persistence.StoryDependency{StoryID: story.ID, DependsOn: depID})
+		}
+		q.stories[story.ID] = story
+		result.created = append(result.created, story)
+	}
+
+	for i := range changeSet.ModifiedStories {
+		change := &changeSet.ModifiedStories[i]
+		if story := changeable("Modify", change.ID); story != nil {
+			story.Title = change.Title
+			story.Content = change.Content
+			story.LastUpdated = now
+			updated[story.ID] = true
+		}
+	}
+
+	for i := range changeSet.CancelledStories {
+		change := &changeSet.CancelledStories[i]
+		if story := changeable("Cancel", change.ID); story != nil {
+			story.SetStatus(StatusCancelled)
+			story.CompletedAt = &now
+			story.LastUpdated = now
+			updated[story.ID] = true
+		}
+	}
+
+	for i := range changeSet.DependencyChanges {
+		change := &changeSet.DependencyChanges[i]
+		story := changeable("Change dependencies of", change.StoryID)
+		if story == nil {
+			continue
+		}
+		for _, dep := range change.Remove {
+			if index := indexOf(story.DependsOn, dep); index >= 0 {
+				story.DependsOn = append(story.DependsOn[:index:index], story.DependsOn[index+1:]...)
+				result.removed = append(result.removed, &persistence.StoryDependency{StoryID: story.ID, DependsOn: dep})
+			}
+		}
+		for _, dep := range change.Add {
+			depID := resolve(dep)
+			story.DependsOn = append(story.DependsOn, depID)
+			result.added = append(result.added, &persistence.StoryDependency{StoryID: story.ID, DependsOn: depID})
+		}
+		story.LastUpdated = now
+		updated[story.ID] = true
+	}
+
+	for id := range updated {
+		result.updated = append(result.updated, q.stories[id])
+	}
+	sort.Slice(result.updated, func(i, j int) bool { return result.updated[i].ID < result.updated[j].ID })
+
+	q.checkAndNotifyReady()
+	return result, nil
+}
+
+// IsReady reports whether a story is pending with all its dependencies finished.
+func (q *Queue) IsReady(storyID string) bool {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+	story, exists := q.stories[storyID]
+	return exists && story.GetStatus() == StatusPending && q.areDependenciesMet(story)
+}
@@ -0,0 +1,305 @@
+package architect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"orchestrator/pkg/config"
+	"orchestrator/pkg/persistence"
+)
+
+func revisionBaseline() []*persistence.Story {
+	return []*persistence.Story{
+		{ID: "infra", SpecID: "spec", Title: "Infrastructure", Content: "infra", Status: persistence.StatusDone, StoryType: storyTypeDevOps},
+		{ID: "api", SpecID: "spec", Title: "API", Content: "api", Status: persistence.StatusCoding, StoryType: storyTypeApp, AssignedAgent: "coder-001", DependsOn: []string{"infra"}},
+		{ID: "export", SpecID: "spec", Title: "XML export", Content: "xml", Status: persistence.StatusNew, StoryType: storyTypeApp, DependsOn: []string{"api"}},
+		{ID: "report", SpecID: "spec", Title: "Report", Content: "report", Status: persistence.StatusNew, StoryType: storyTypeApp, DependsOn: []string{"api"}},
+	}
+}
+
+func TestParseSpecChangeSet(t *testing.T) {
+	response := "```json\n" + `{
+		"summary": "Swap XML export for CSV",
+		"new_stories": [{"key": "new-1", "title": "CSV export", "content": "csv", "story_type": "app", "estimated_points": 3, "depends_on": ["api"]}],
+		"cancelled_stories": [{"id": "export", "reason": "XML export removed"}],
+		"skipped": ["should be ignored"]
+	}` + "\n```"
+
+	changeSet, err := parseSpecChangeSet(response)
+	if err != nil {
+		t.Fatalf("parseSpecChangeSet failed: %v", err)
+	}
+	if changeSet.Summary != "Swap XML export for CSV" || len(changeSet.NewStories) != 1 || len(changeSet.CancelledStories) != 1 {
+		t.Errorf("Unexpected change set: %+v", changeSet)
+	}
+	if len(changeSet.Skipped) != 0 {
+		t.Errorf("Expected skipped notes from the LLM to be dropped, got %v", changeSet.Skipped)
+	}
+
+	if _, err := parseSpecChangeSet("nothing to change"); err == nil {
+		t.Error("Expected an error for a response without JSON")
+	}
+}
+
+func TestValidateChangeSet(t *testing.T) {
+	changeSet := &SpecChangeSet{
+		NewStories: []NewStoryChange{
+			{Key: "new-1", Title: "CSV export", Content: "csv", StoryType: "bogus", EstimatedPoints: 9, DependsOn: []string{"api", "export", "missing"}},
+			{Key: "new-2", Title: "Second infra", StoryType: storyTypeDevOps},
+			{Title: "  "},
+		},
+		ModifiedStories: []StoryModification{
+			{ID: "report", Content: "report with CSV"},
+			{ID: "api", Title: "API v2"},
+			{ID: "export", Title: "Cancelled anyway"},
+		},
+		CancelledStories: []StoryCancellation{{ID: "export"}, {ID: "infra"}},
+		DependencyChanges: []DependencyChange{
+			{StoryID: "report", Add: []string{"new-1"}, Remove: []string{"api"}},
+			{StoryID: "api", Add: []string{"report"}},
+		},
+	}
+
+	validateChangeSet(changeSet, revisionBaseline())
+
+	if len(changeSet.CancelledStories) != 1 || changeSet.CancelledStories[0].ID != "export" || changeSet.CancelledStories[0].Title != "XML export" {
+		t.Errorf("Expected only the unstarted export story to be cancelled, got %+v", changeSet.CancelledStories)
+	}
+	if len(changeSet.ModifiedStories) != 1 || changeSet.ModifiedStories[0].ID != "report" || changeSet.ModifiedStories[0].Title != "Report" {
+		t.Errorf("Expected only the report story to be modified, keeping its title, got %+v", changeSet.ModifiedStories)
+	}
+
+	if len(changeSet.NewStories) != 1 {
+		t.Fatalf("Expected one new story, got %+v", changeSet.NewStories)
+	}
+	story := changeSet.NewStories[0]
+	if story.StoryType != storyTypeApp || story.EstimatedPoints != 2 || !slices.Equal(story.DependsOn, []string{"api"}) {
+		t.Errorf("Expected a normalized new story depending on api only, got %+v", story)
+	}
+
+	if len(changeSet.DependencyChanges) != 1 {
+		t.Fatalf("Expected one dependency change, got %+v", changeSet.DependencyChanges)
+	}
+	change := changeSet.DependencyChanges[0]
+	if change.StoryID != "report" || !slices.Equal(change.Add, []string{"new-1"}) || !slices.Equal(change.Remove, []string{"api"}) {
+		t.Errorf("Unexpected dependency change: %+v", change)
+	}
+
+	skipped := strings.Join(changeSet.Skipped, "\n")
+	for _, want := range []string{"Cancel infra (Infrastructure): the story is already done", "Modify api (API): the story is already coding", "already has a devops story", "dependency on missing dropped"} {
+		if !strings.Contains(skipped, want) {
+			t.Errorf("Expected skipped notes to mention %q, got:\n%s", want, skipped)
+		}
+	}
+}
+
+func TestValidateChangeSetRejectsCycles(t *testing.T) {
+	changeSet := &SpecChangeSet{
+		NewStories:        []NewStoryChange{{Key: "new-1", Title: "Audit log", DependsOn: []string{"report"}}},
+		DependencyChanges: []DependencyChange{{StoryID: "report", Add: []string{"new-1"}}},
+	}
+
+	validateChangeSet(changeSet, revisionBaseline())
+
+	if len(changeSet.DependencyChanges) != 0 {
+		t.Errorf("Expected the cyclic dependency to be dropped, got %+v", changeSet.DependencyChanges)
+	}
+	if len(changeSet.Skipped) != 1 || !strings.Contains(changeSet.Skipped[0], "dependency cycle") {
+		t.Errorf("Expected a cycle note, got %v", changeSet.Skipped)
+	}
+}
+
+func TestQueueApplyChangeSet(t *testing.T) {
+	q := NewQueue(nil)
+	q.AddStory("api", "spec", "API", "api", storyTypeApp, nil, 2)
+	if err := q.UpdateStoryStatus("api", StatusDone); err != nil {
+		t.Fatalf("Failed to finish api: %v", err)
+	}
+	q.AddStory("report", "spec", "Report", "report", storyTypeApp, []string{"api", "export"}, 2)
+
+	changeSet := &SpecChangeSet{
+		SpecID: "spec",
+		NewStories: []NewStoryChange{
+			{Key: "new-1", Title: "CSV export", Content: "csv", StoryType: storyTypeApp, EstimatedPoints: 3, DependsOn: []string{"api"}},
+		},
+		ModifiedStories:   []StoryModification{{ID: "report", Title: "Report", Content: "report with CSV"}},
+		CancelledStories:  []StoryCancellation{{ID: "export"}},
+		DependencyChanges: []DependencyChange{{StoryID: "report", Add: []string{"new-1"}}},
+		baseline: []*persistence.Story{
+			{ID: "export", SpecID: "spec", Title: "XML export", Content: "xml", Status: persistence.StatusNew, StoryType: storyTypeApp},
+		},
+	}
+
+	result, err := q.ApplyChangeSet(changeSet)
+	if err != nil {
+		t.Fatalf("ApplyChangeSet failed: %v", err)
+	}
+	if len(result.adopted) != 1 || result.adopted[0].ID != "export" {
+		t.Errorf("Expected the export story to be adopted from the database, got %+v", result.adopted)
+	}
+	if len(result.created) != 1 || len(result.updated) != 2 || len(result.skipped) != 0 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+
+	created := result.created[0]
+	if created.Title != "CSV export" || created.SpecID != "spec" || !slices.Equal(created.DependsOn, []string{"api"}) {
+		t.Errorf("Unexpected new story: %+v", created.Story)
+	}
+	if !q.IsReady(created.ID) {
+		t.Error("Expected the new story to be ready since api is done")
+	}
+
+	export, _ := q.GetStory("export")
+	if export.GetStatus() != StatusCancelled || !export.IsFinished() {
+		t.Errorf("Expected export to be cancelled, got %s", export.GetStatus())
+	}
+	report, _ := q.GetStory("report")
+	if report.Content != "report with CSV" || !slices.Equal(report.DependsOn, []string{"api", "export", created.ID}) {
+		t.Errorf("Expected report to be revised and depend on the new story, got %+v", report.Story)
+	}
+	if q.IsReady("report") {
+		t.Error("Expected report to wait for the new story")
+	}
+	if len(result.added) != 2 {
+		t.Errorf("Expected dependencies of the new story and report to be persisted, got %d", len(result.added))
+	}
+}
+
+func TestQueueApplyChangeSetSkipsStartedStories(t *testing.T) {
+	q := NewQueue(nil)
+	q.AddStory("report", "spec", "Report", "report", storyTypeApp, nil, 2)
+	if err := q.UpdateStoryStatus("report", StatusAssigned); err != nil {
+		t.Fatalf("Failed to assign report: %v", err)
+	}
+
+	result, err := q.ApplyChangeSet(&SpecChangeSet{
+		SpecID:           "spec",
+		ModifiedStories:  []StoryModification{{ID: "report", Title: "Report", Content: "changed"}},
+		CancelledStories: []StoryCancellation{{ID: "report"}},
+	})
+	if err != nil {
+		t.Fatalf("ApplyChangeSet failed: %v", err)
+	}
+	if len(result.updated) != 0 || len(result.skipped) != 2 {
+		t.Errorf("Expected both changes to be skipped, got %+v", result)
+	}
+	if report, _ := q.GetStory("report"); report.Content != "report" || report.GetStatus() != StatusAssigned {
+		t.Errorf("Expected the started story to be untouched, got %+v", report.Story)
+	}
+}
+
+func TestSpecRevisionBoard(t *testing.T) {
+	d := &Driver{revisions: newRevisionBoard()}
+	if status := d.SpecRevisionStatus(); status.State != RevisionIdle {
+		t.Fatalf("Expected idle, got %+v", status)
+	}
+
+	if err := d.SubmitSpecRevision("spec", "  "); err == nil {
+		t.Error("Expected empty revisions to be rejected")
+	}
+	if err := d.SubmitSpecRevision("spec", "revised"); err != nil {
+		t.Fatalf("SubmitSpecRevision failed: %v", err)
+	}
+	if err := d.SubmitSpecRevision("spec", "again"); !errors.Is(err, ErrRevisionPending) {
+		t.Errorf("Expected ErrRevisionPending while proposing, got %v", err)
+	}
+	if status := d.SpecRevisionStatus(); status.State != RevisionProposing {
+		t.Errorf("Expected proposing, got %+v", status)
+	}
+
+	revision := <-d.revisions.submitted
+	if revision.specID != "spec" || revision.content != "revised" {
+		t.Errorf("Unexpected revision: %+v", revision)
+	}
+	d.revisions.propose(&SpecChangeSet{ID: "cs-1", SpecID: "spec"})
+	if status := d.SpecRevisionStatus(); status.State != RevisionPending || status.ChangeSet == nil || status.ChangeSet.ID != "cs-1" {
+		t.Fatalf("Expected pending change set, got %+v", status)
+	}
+
+	if err := d.DecideChangeSet("other", true); !errors.Is(err, ErrNoPendingChangeSet) {
+		t.Errorf("Expected ErrNoPendingChangeSet for an unknown ID, got %v", err)
+	}
+	if err := d.DecideChangeSet("cs-1", true); err != nil {
+		t.Fatalf("DecideChangeSet failed: %v", err)
+	}
+	if decision := <-d.revisions.decisions; !decision.approved || decision.changeSet.ID != "cs-1" {
+		t.Errorf("Unexpected decision: %+v", decision)
+	}
+	if status := d.SpecRevisionStatus(); status.State != RevisionIdle {
+		t.Errorf("Expected idle once decided, got %+v", status)
+	}
+
+	d.revisions.fail(errors.New("spec spec not found"))
+	if status := d.SpecRevisionStatus(); status.State != RevisionFailed || status.Error != "spec spec not found" {
+		t.Errorf("Expected failure to be reported, got %+v", status)
+	}
+}
+
+func TestSpecRevisionProposedInBackground(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	persistenceCh := make(chan *persistence.Request, 10)
+	go func() {
+		for {
+			select {
+			case req := <-persistenceCh:
+				switch req.Operation {
+				case persistence.OpGetSpecByID:
+					req.Response <- &persistence.Spec{ID: "spec", Content: "original"}
+				case persistence.OpGetStoriesBySpec:
+					req.Response <- revisionBaseline()
+				default:
+					req.Response <- nil
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	client := &blockingLLMClient{release: make(chan struct{})}
+	d := NewDriver("architect", &config.Model{Name: "test-model", MaxConnections: 2}, client, nil, t.TempDir(), persistenceCh)
+	d.queue.AddStory("queued", "spec", "Queued story", "Queued work.", "app", nil, 2)
+
+	if err := d.SubmitSpecRevision("spec", "revised"); err != nil {
+		t.Fatalf("SubmitSpecRevision failed: %v", err)
+	}
+	next, err := d.handleWaiting(ctx)
+	if err != nil || next != StateWaiting {
+		t.Fatalf("Expected to stay in WAITING, got %s (%v)", next, err)
+	}
+
+	// The proposal waits on the LLM while the FSM is free to keep changing the queue
+	deadline := time.Now().Add(5 * time.Second)
+	for i := 0; ; i++ {
+		if active, _ := client.calls(); active == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the revision to be proposed in the background")
+		}
+		d.queue.AddStory(fmt.Sprintf("new-%d", i), "spec", "New story", "More work.", "app", nil, 1)
+		time.Sleep(time.Millisecond)
+	}
+	if status := d.SpecRevisionStatus(); status.State != RevisionProposing {
+		t.Errorf("Expected proposing while the LLM call runs, got %+v", status)
+	}
+
+	// The canned answer is not a change set, so the proposal fails
+	close(client.release)
+	for status := d.SpecRevisionStatus(); status.State == RevisionProposing; status = d.SpecRevisionStatus() {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the proposal to finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if status := d.SpecRevisionStatus(); status.State != RevisionFailed {
+		t.Errorf("Expected the unparseable proposal to fail, got %+v", status)
+	}
+}
@@ -0,0 +1,82 @@
+package architect
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"orchestrator/pkg/persistence"
+	"orchestrator/pkg/proto"
+	"orchestrator/pkg/templates"
+)
+
+// generateSplitReviewPrompt creates the prompt for reviewing a coder's proposed story split.
+func (d *Driver) generateSplitReviewPrompt(story *QueuedStory, content any) string {
+	var title, original string
+	if story != nil {
+		title = story.Title
+		original = story.Content
+	}
+
+	if d.renderer != nil {
+		prompt, err := d.renderer.Render(templates.SplitReviewTemplate, &templates.TemplateData{
+			Extra: map[string]any{
+				"Title":    title,
+				"Original": original,
+				"Content":  content,
+			},
+		})
+		if err == nil {
+			return prompt
+		}
+		d.logger.Error("Failed to render split review template: %v", err)
+	}
+
+	return fmt.Sprintf("Review this proposal to split story %q into sub-stories. Respond with APPROVED, NEEDS_CHANGES or REJECTED and your reasoning.\n\nOriginal story:\n%s\n\n%v",
+		title, original, content)
+}
+
+// applyStorySplit replaces the story of an approved split request with its sub-stories,
+// persists them with their dependencies and accepts the original story as done, which
+// releases the coder and makes the first sub-story ready for dispatch.
+func (d *Driver) applyStorySplit(ctx context.Context, requestMsg *proto.AgentMsg) error {
+	storyID, _ := proto.GetTypedPayload[string](requestMsg, proto.KeyStoryID)
+	if storyID == "" {
+		return fmt.Errorf("split request is missing story_id")
+	}
+	split, exists := proto.GetTypedPayload[*proto.StorySplitPayload](requestMsg, proto.KeyStorySplit)
+	if !exists || split == nil {
+		return fmt.Errorf("split request for story %s has no proposed sub-stories", storyID)
+	}
+	if d.queue == nil {
+		return fmt.Errorf("no queue available")
+	}
+
+	subStories, dependencies, err := d.queue.SplitStory(storyID, split.Stories)
+	if err != nil {
+		return fmt.Errorf("failed to split story %s: %w", storyID, err)
+	}
+
+	ids := make([]string, 0, len(subStories))
+	stories := make([]*persistence.Story, 0, len(subStories))
+	for _, story := range subStories {
+		ids = append(ids, story.ID)
+		stories = append(stories, story.ToPersistenceStory())
+	}
+	d.logger.Info("✂️ Split story %s into %d sub-stories: %s", storyID, len(subStories), strings.Join(ids, ", "))
+
+	if d.persistenceChannel != nil {
+		d.persistenceChannel <- &persistence.Request{
+			Operation: persistence.OpBatchUpsertStoriesWithDependencies,
+			Data: &persistence.BatchUpsertStoriesWithDependenciesRequest{
+				Stories:      stories,
+				Dependencies: dependencies,
+			},
+			Response: nil, // Fire-and-forget
+		}
+	}
+
+	summary := fmt.Sprintf("Split into %d sub-stories: %s", len(subStories), strings.Join(ids, ", "))
+	d.handleWorkAccepted(ctx, storyID, "split", nil, nil, &summary)
+	return nil
+}
@@ -0,0 +1,70 @@
+package architect
+
+import (
+	"slices"
+	"testing"
+
+	"orchestrator/pkg/proto"
+)
+
+func TestQueueSplitStory(t *testing.T) {
+	q := NewQueue(nil)
+	q.AddStory("base", "spec", "Base", "base work", "app", nil, 1)
+	q.AddStory("big", "spec", "Big story", "too much work", "app", []string{"base"}, 6)
+	q.AddStory("after", "spec", "After", "follow-up work", "app", []string{"big"}, 2)
+
+	created, deps, err := q.SplitStory("big", []proto.SubStorySpec{
+		{Title: "Part 1", Content: "first"},
+		{Title: "Part 2", Content: "second", DependsOn: []int{0}},
+		{Title: "Part 3", Content: "third"},
+	})
+	if err != nil {
+		t.Fatalf("SplitStory failed: %v", err)
+	}
+	if len(created) != 3 {
+		t.Fatalf("Expected 3 sub-stories, got %d", len(created))
+	}
+
+	first, second := created[0], created[1]
+	if first.SpecID != "spec" || first.StoryType != "app" || first.EstimatedPoints != 2 {
+		t.Errorf("Expected sub-story to inherit spec and type and share points, got %+v", first.Story)
+	}
+	if !slices.Equal(first.DependsOn, []string{"base"}) {
+		t.Errorf("Expected first sub-story to inherit the original's dependencies, got %v", first.DependsOn)
+	}
+	if !slices.Equal(second.DependsOn, []string{"base", first.ID}) {
+		t.Errorf("Expected second sub-story to depend on base and part 1, got %v", second.DependsOn)
+	}
+
+	after, _ := q.GetStory("after")
+	for _, story := range created {
+		if !slices.Contains(after.DependsOn, story.ID) {
+			t.Errorf("Expected dependent story to be rewired onto sub-story %s, got %v", story.ID, after.DependsOn)
+		}
+	}
+
+	// base<-part1, base<-part2, part1<-part2, base<-part3, and after<-each sub-story
+	if len(deps) != 7 {
+		t.Errorf("Expected 7 dependency edges, got %d", len(deps))
+	}
+}
+
+func TestQueueSplitStoryInvalid(t *testing.T) {
+	q := NewQueue(nil)
+	q.AddStory("big", "spec", "Big story", "too much work", "app", nil, 3)
+
+	if _, _, err := q.SplitStory("missing", []proto.SubStorySpec{{Title: "a", Content: "a"}}); err == nil {
+		t.Error("Expected splitting an unknown story to fail")
+	}
+
+	_, _, err := q.SplitStory("big", []proto.SubStorySpec{
+		{Title: "a", Content: "a", DependsOn: []int{1}},
+		{Title: "b", Content: "b"},
+	})
+	if err == nil {
+		t.Error("Expected a forward dependency to be rejected")
+	}
+	if len(q.GetAllStories()) != 1 {
+		t.Errorf("Expected a rejected split to leave the queue unchanged, got %d stories", len(q.GetAllStories()))
+	}
+}
@@ -0,0 +1,34 @@
+package architect
+
+import (
+	"fmt"
+
+	"orchestrator/pkg/templates"
+)
+
+// generateTestDesignReviewPrompt creates the prompt for reviewing the tests a coder wrote
+// for a test-first story before implementing it.
+func (d *Driver) generateTestDesignReviewPrompt(queued *QueuedStory, content any) string {
+	var title, story string
+	if queued != nil {
+		title = queued.Title
+		story = queued.Content
+	}
+
+	if d.renderer != nil {
+		prompt, err := d.renderer.Render(templates.TestDesignReviewTemplate, &templates.TemplateData{
+			Extra: map[string]any{
+				"Title":   title,
+				"Story":   story,
+				"Content": content,
+			},
+		})
+		if err == nil {
+			return prompt
+		}
+		d.logger.Error("Failed to render test design review template: %v", err)
+	}
+
+	return fmt.Sprintf("Review these tests written before implementing story %q. Once approved they are frozen and the implementation must make them pass. Respond with APPROVED, NEEDS_CHANGES or REJECTED and your reasoning.\n\nStory:\n%s\n\n%v",
+		title, story, content)
+}
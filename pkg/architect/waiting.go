@@ -7,7 +7,9 @@ import (
 	"orchestrator/pkg/proto"
 )
 
-// handleWaiting blocks until a spec message or question is received.
+// handleWaiting blocks until a spec message, a merge or requeue request, or a finished request
+// review is received. Revisions of earlier specs and new request reviews are started without
+// leaving WAITING.
 func (d *Driver) handleWaiting(ctx context.Context) (proto.State, error) {
 	select {
 	case <-ctx.Done():
@@ -37,12 +39,17 @@ func (d *Driver) handleWaiting(ctx context.Context) (proto.State, error) {
 			// This shouldn't happen with proper channel management, but handle gracefully
 			return StateWaiting, nil
 		}
-		// Question message received, transitioning to REQUEST state for processing
-
-		// Store the question for processing in REQUEST state.
-		d.stateData["current_request"] = questionMsg
-
-		return StateRequest, nil
+		// Reviews run in the background; merges and requeues go to REQUEST right away
+		return d.acceptRequest(ctx, questionMsg, StateWaiting), nil
+	case request := <-d.requests.results:
+		// A reviewed request is answered in REQUEST.
+		return d.reviewedRequest(request), nil
+	case revision := <-d.revisions.submitted:
+		d.startSpecRevision(ctx, revision)
+		return StateWaiting, nil
+	case decision := <-d.revisions.decisions:
+		d.handleChangeSetDecision(ctx, decision)
+		return StateWaiting, nil
 	}
 }
 
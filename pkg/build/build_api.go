@@ -217,6 +217,33 @@ func (s *Service) GetBackendInfo(projectRoot string) (*BackendInfo, error) {
 	}, nil
 }
 
+// Coverage runs the test suite with coverage for the project's backend.
+// Returns an error if the backend cannot measure coverage.
+func (s *Service) Coverage(ctx context.Context, projectRoot string, timeout time.Duration) (*CoverageReport, string, error) {
+	backend, err := s.getBackend(projectRoot)
+	if err != nil {
+		return nil, "", err
+	}
+	collector, ok := backend.(CoverageCollector)
+	if !ok {
+		return nil, "", fmt.Errorf("coverage is not supported for the %s backend", backend.Name())
+	}
+
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var output strings.Builder
+	report, err := collector.Coverage(execCtx, projectRoot, &output)
+	if err != nil {
+		return nil, output.String(), fmt.Errorf("%s coverage failed: %w", backend.Name(), err)
+	}
+	s.logger.Info("Coverage for %s: %.1f%% across %d files", projectRoot, report.Percent(), len(report.Files))
+	return report, output.String(), nil
+}
+
 // BackendInfo provides information about a detected backend.
 type BackendInfo struct {
 	Name        string    `json:"name"`
@@ -0,0 +1,447 @@
+package build
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CoverageCollector is implemented by backends that can measure line coverage.
+type CoverageCollector interface {
+	// Coverage runs the test suite with coverage enabled and returns per-file line coverage.
+	Coverage(ctx context.Context, root string, stream io.Writer) (*CoverageReport, error)
+}
+
+// FileCoverage records which instrumented lines of a file were executed.
+type FileCoverage struct {
+	Lines map[int]bool // Instrumented line number -> executed
+}
+
+// Percent returns the share of instrumented lines that were executed.
+func (f *FileCoverage) Percent() float64 {
+	return percent(f.covered(), len(f.Lines))
+}
+
+// covered returns the number of executed lines.
+func (f *FileCoverage) covered() int {
+	n := 0
+	for _, hit := range f.Lines {
+		if hit {
+			n++
+		}
+	}
+	return n
+}
+
+// mark records a line as instrumented; a line is covered if any block covering it ran.
+func (f *FileCoverage) mark(line int, hit bool) {
+	f.Lines[line] = f.Lines[line] || hit
+}
+
+// CoverageReport is per-file line coverage keyed by repository-relative path.
+type CoverageReport struct {
+	Framework string
+	Files     map[string]*FileCoverage
+}
+
+// newCoverageReport creates an empty report.
+func newCoverageReport(framework string) *CoverageReport {
+	return &CoverageReport{Framework: framework, Files: make(map[string]*FileCoverage)}
+}
+
+// file returns the coverage entry for a path, creating it if needed.
+func (r *CoverageReport) file(path string) *FileCoverage {
+	fc, ok := r.Files[path]
+	if !ok {
+		fc = &FileCoverage{Lines: make(map[int]bool)}
+		r.Files[path] = fc
+	}
+	return fc
+}
+
+// Percent returns overall line coverage.
+func (r *CoverageReport) Percent() float64 {
+	covered, total := 0, 0
+	for _, fc := range r.Files {
+		covered += fc.covered()
+		total += len(fc.Lines)
+	}
+	return percent(covered, total)
+}
+
+// ChangedFileCoverage is the coverage of the changed lines of one file.
+//
+//nolint:govet // JSON serialization struct, logical order preferred
+type ChangedFileCoverage struct {
+	Path           string  `json:"path"`
+	ChangedLines   int     `json:"changed_lines"` // Changed lines that are instrumented
+	CoveredLines   int     `json:"covered_lines"`
+	UncoveredLines []int   `json:"uncovered_lines,omitempty"`
+	FilePercent    float64 `json:"file_percent"` // Coverage of the whole file
+}
+
+// ChangedCoverage summarizes coverage of the lines changed on a branch.
+//
+//nolint:govet // JSON serialization struct, logical order preferred
+type ChangedCoverage struct {
+	Files        []ChangedFileCoverage `json:"files"`
+	ChangedLines int                   `json:"changed_lines"`
+	CoveredLines int                   `json:"covered_lines"`
+	Overall      float64               `json:"overall_percent"`
+}
+
+// ChangedCoverage intersects the report with changed lines per file. Changed lines that are
+// not instrumented (comments, declarations, non-source files) are ignored.
+func (r *CoverageReport) ChangedCoverage(changed map[string][]int) *ChangedCoverage {
+	result := &ChangedCoverage{Files: []ChangedFileCoverage{}, Overall: r.Percent()}
+
+	paths := make([]string, 0, len(changed))
+	for path := range changed {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		fc, ok := r.Files[path]
+		if !ok {
+			continue
+		}
+		entry := ChangedFileCoverage{Path: path, FilePercent: fc.Percent()}
+		for _, line := range changed[path] {
+			hit, instrumented := fc.Lines[line]
+			if !instrumented {
+				continue
+			}
+			entry.ChangedLines++
+			if hit {
+				entry.CoveredLines++
+			} else {
+				entry.UncoveredLines = append(entry.UncoveredLines, line)
+			}
+		}
+		if entry.ChangedLines == 0 {
+			continue
+		}
+		result.ChangedLines += entry.ChangedLines
+		result.CoveredLines += entry.CoveredLines
+		result.Files = append(result.Files, entry)
+	}
+	return result
+}
+
+// Percent returns coverage of changed lines; 100 when no instrumented lines changed.
+func (c *ChangedCoverage) Percent() float64 {
+	if c.ChangedLines == 0 {
+		return 100
+	}
+	return percent(c.CoveredLines, c.ChangedLines)
+}
+
+// Summary renders the changed-line coverage for review prompts and tool output.
+func (c *ChangedCoverage) Summary() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Changed-line coverage: %.1f%% (%d of %d changed lines covered); overall project coverage %.1f%%\n",
+		c.Percent(), c.CoveredLines, c.ChangedLines, c.Overall))
+	for i := range c.Files {
+		f := &c.Files[i]
+		sb.WriteString(fmt.Sprintf("- %s: %d/%d changed lines covered (file %.1f%%)", f.Path, f.CoveredLines, f.ChangedLines, f.FilePercent))
+		if len(f.UncoveredLines) > 0 {
+			sb.WriteString(fmt.Sprintf(", uncovered lines %s", formatLineRanges(f.UncoveredLines)))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// formatLineRanges renders sorted line numbers compactly, e.g. "3-5, 9".
+func formatLineRanges(lines []int) string {
+	sorted := append([]int(nil), lines...)
+	sort.Ints(sorted)
+
+	var parts []string
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && sorted[j+1] == sorted[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, strconv.Itoa(sorted[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", sorted[i], sorted[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
+
+// percent returns n/total as a percentage, treating an empty total as fully covered.
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(n) * 100 / float64(total)
+}
+
+// ParseGoCoverProfile parses a `go test -coverprofile` file. modules maps module paths to the
+// directory of their go.mod relative to the repository root; each file name is resolved against
+// the longest module path it starts with, so paths are repository-relative.
+func ParseGoCoverProfile(data []byte, modules map[string]string) (*CoverageReport, error) {
+	report := newCoverageReport("go")
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		// Format: name.go:line.column,line.column numberOfStatements count
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("malformed cover profile line: %q", line)
+		}
+		file, span, ok := strings.Cut(fields[0], ":")
+		if !ok {
+			return nil, fmt.Errorf("malformed cover profile block: %q", fields[0])
+		}
+		start, end, ok := strings.Cut(span, ",")
+		if !ok {
+			return nil, fmt.Errorf("malformed cover profile span: %q", span)
+		}
+		startLine, err1 := strconv.Atoi(strings.Split(start, ".")[0])
+		endLine, err2 := strconv.Atoi(strings.Split(end, ".")[0])
+		count, err3 := strconv.Atoi(fields[2])
+		if err1 != nil || err2 != nil || err3 != nil {
+			return nil, fmt.Errorf("malformed cover profile numbers: %q", line)
+		}
+
+		fc := report.file(goRepoPath(file, modules))
+		for l := startLine; l <= endLine; l++ {
+			fc.mark(l, count > 0)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cover profile: %w", err)
+	}
+	return report, nil
+}
+
+// ParseCoveragePyJSON parses a coverage.py JSON report (pytest --cov-report=json).
+func ParseCoveragePyJSON(data []byte) (*CoverageReport, error) {
+	var parsed struct {
+		Files map[string]struct {
+			ExecutedLines []int `json:"executed_lines"`
+			MissingLines  []int `json:"missing_lines"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse coverage.py JSON: %w", err)
+	}
+
+	report := newCoverageReport("python")
+	for path, lines := range parsed.Files {
+		fc := report.file(filepath.ToSlash(filepath.Clean(path)))
+		for _, l := range lines.ExecutedLines {
+			fc.mark(l, true)
+		}
+		for _, l := range lines.MissingLines {
+			fc.mark(l, false)
+		}
+	}
+	return report, nil
+}
+
+// ParseIstanbulJSON parses an istanbul coverage-final.json report (jest --coverage).
+// Absolute file paths are made relative to root.
+func ParseIstanbulJSON(data []byte, root string) (*CoverageReport, error) {
+	type position struct {
+		Line int `json:"line"`
+	}
+	var parsed map[string]struct {
+		Path         string `json:"path"`
+		StatementMap map[string]struct {
+			Start position `json:"start"`
+			End   position `json:"end"`
+		} `json:"statementMap"`
+		S map[string]int `json:"s"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse istanbul JSON: %w", err)
+	}
+
+	report := newCoverageReport("jest")
+	for key, entry := range parsed {
+		path := entry.Path
+		if path == "" {
+			path = key
+		}
+		if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+		fc := report.file(filepath.ToSlash(path))
+		for id, stmt := range entry.StatementMap {
+			for l := stmt.Start.Line; l <= stmt.End.Line; l++ {
+				fc.mark(l, entry.S[id] > 0)
+			}
+		}
+	}
+	return report, nil
+}
+
+// goRepoPath resolves a cover profile file name (import path plus file) to a path relative to
+// the repository root, using the module with the longest matching path.
+func goRepoPath(file string, modules map[string]string) string {
+	best := ""
+	for modulePath := range modules {
+		if strings.HasPrefix(file, modulePath+"/") && len(modulePath) > len(best) {
+			best = modulePath
+		}
+	}
+	if best == "" {
+		return file
+	}
+	return filepath.ToSlash(filepath.Join(modules[best], strings.TrimPrefix(file, best+"/")))
+}
+
+// goModules finds every go.mod under root and maps its module path to its directory relative
+// to root ("." for the root module). Vendored and hidden directories are skipped.
+func goModules(root string) map[string]string {
+	modules := make(map[string]string)
+	_ = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil //nolint:nilerr // Unreadable directories are skipped
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if p != root && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != "go.mod" {
+			return nil
+		}
+		dir := filepath.Dir(p)
+		if modulePath := goModulePath(dir); modulePath != "" {
+			if rel, relErr := filepath.Rel(root, dir); relErr == nil {
+				modules[modulePath] = filepath.ToSlash(rel)
+			}
+		}
+		return nil
+	})
+	return modules
+}
+
+// goModulePath reads the module path from dir/go.mod.
+func goModulePath(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
+
+// runCoverageCommand runs a coverage command, streaming its output. Test failures do not
+// abort coverage collection: the report is still parsed if it was written.
+func runCoverageCommand(ctx context.Context, root string, stream io.Writer, name string, args ...string) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = root
+	cmd.Stdout = stream
+	cmd.Stderr = stream
+
+	_, _ = fmt.Fprintf(stream, "$ %s %s\n", name, strings.Join(args, " "))
+	if err := cmd.Run(); err != nil {
+		_, _ = fmt.Fprintf(stream, "coverage command exited with error: %v\n", err)
+	}
+}
+
+// readCoverageFile reads a report written by a coverage command.
+func readCoverageFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("coverage report was not written: %w", err)
+	}
+	return data, nil
+}
+
+// Coverage runs go test with a cover profile in every module of the repository.
+func (g *GoBackend) Coverage(ctx context.Context, root string, stream io.Writer) (*CoverageReport, error) {
+	profileDir, err := os.MkdirTemp("", "maestro-coverage-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create coverage directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(profileDir) }()
+
+	modules := goModules(root)
+	dirs := make([]string, 0, len(modules))
+	for _, dir := range modules {
+		dirs = append(dirs, dir)
+	}
+	if len(dirs) == 0 {
+		dirs = append(dirs, ".")
+	}
+	sort.Strings(dirs)
+
+	var data []byte
+	for i, dir := range dirs {
+		profile := filepath.Join(profileDir, fmt.Sprintf("cover-%d.out", i))
+		runCoverageCommand(ctx, filepath.Join(root, dir), stream, "go", "test", "-count=1", "-coverprofile="+profile, "./...")
+		moduleData, readErr := readCoverageFile(profile)
+		if readErr != nil {
+			_, _ = fmt.Fprintf(stream, "no coverage for module in %s: %v\n", dir, readErr)
+			continue
+		}
+		data = append(data, moduleData...)
+	}
+	if data == nil {
+		return nil, fmt.Errorf("coverage report was not written for any module")
+	}
+	return ParseGoCoverProfile(data, modules)
+}
+
+// Coverage runs pytest with pytest-cov and a JSON report.
+func (p *PythonBackend) Coverage(ctx context.Context, root string, stream io.Writer) (*CoverageReport, error) {
+	reportDir, err := os.MkdirTemp("", "maestro-coverage-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create coverage directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(reportDir) }()
+
+	reportPath := filepath.Join(reportDir, "coverage.json")
+	runCoverageCommand(ctx, root, stream, "python", "-m", "pytest", "-q", "--cov=.", "--cov-report=json:"+reportPath)
+	data, err := readCoverageFile(reportPath)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCoveragePyJSON(data)
+}
+
+// Coverage runs jest with the istanbul JSON reporter.
+func (n *NodeBackend) Coverage(ctx context.Context, root string, stream io.Writer) (*CoverageReport, error) {
+	reportDir, err := os.MkdirTemp("", "maestro-coverage-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create coverage directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(reportDir) }()
+
+	runCoverageCommand(ctx, root, stream, "npx", "jest", "--coverage", "--coverageReporters=json", "--coverageDirectory="+reportDir)
+	data, err := readCoverageFile(filepath.Join(reportDir, "coverage-final.json"))
+	if err != nil {
+		return nil, err
+	}
+	return ParseIstanbulJSON(data, root)
+}
@@ -0,0 +1,124 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGoCoverProfile(t *testing.T) {
+	profile := `mode: set
+example.com/calc/calc.go:3.24,5.2 1 1
+example.com/calc/calc.go:7.24,9.2 1 0
+example.com/calc/internal/util.go:1.1,1.20 1 1
+`
+	report, err := ParseGoCoverProfile([]byte(profile), map[string]string{"example.com/calc": "."})
+	if err != nil {
+		t.Fatalf("ParseGoCoverProfile failed: %v", err)
+	}
+
+	calc := report.Files["calc.go"]
+	if calc == nil {
+		t.Fatalf("Expected module-relative path, got %v", report.Files)
+	}
+	if !calc.Lines[4] || calc.Lines[8] {
+		t.Errorf("Unexpected line coverage: %v", calc.Lines)
+	}
+	if _, ok := report.Files["internal/util.go"]; !ok {
+		t.Error("Expected nested package file")
+	}
+
+	changed := report.ChangedCoverage(map[string][]int{
+		"calc.go":   {4, 8, 100}, // line 100 is not instrumented
+		"README.md": {1},
+	})
+	if changed.ChangedLines != 2 || changed.CoveredLines != 1 {
+		t.Errorf("Unexpected changed coverage: %+v", changed)
+	}
+	if changed.Percent() != 50 {
+		t.Errorf("Expected 50%%, got %v", changed.Percent())
+	}
+	if !strings.Contains(changed.Summary(), "uncovered lines 8") {
+		t.Errorf("Expected uncovered lines in summary, got %s", changed.Summary())
+	}
+}
+
+func TestParseGoCoverProfile_NestedModules(t *testing.T) {
+	root := t.TempDir()
+	for dir, module := range map[string]string{".": "example.com/repo", "services/api": "example.com/api"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, dir, "go.mod"), []byte("module "+module+"\n\ngo 1.24\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	modules := goModules(root)
+	if modules["example.com/repo"] != "." || modules["example.com/api"] != "services/api" {
+		t.Fatalf("Unexpected modules: %v", modules)
+	}
+
+	profile := `mode: set
+example.com/repo/main.go:3.13,5.2 1 1
+mode: set
+example.com/api/handler/handler.go:3.24,5.2 1 1
+`
+	report, err := ParseGoCoverProfile([]byte(profile), modules)
+	if err != nil {
+		t.Fatalf("ParseGoCoverProfile failed: %v", err)
+	}
+	for _, file := range []string{"main.go", "services/api/handler/handler.go"} {
+		if _, ok := report.Files[file]; !ok {
+			t.Errorf("Expected %s in report, got %v", file, report.Files)
+		}
+	}
+}
+
+func TestParseGoCoverProfile_Malformed(t *testing.T) {
+	if _, err := ParseGoCoverProfile([]byte("mode: set\ngarbage\n"), nil); err == nil {
+		t.Error("Expected error for malformed profile")
+	}
+}
+
+func TestParseCoveragePyJSON(t *testing.T) {
+	data := `{"files": {"app/main.py": {"executed_lines": [1, 2, 4], "missing_lines": [5, 6]}}}`
+	report, err := ParseCoveragePyJSON([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseCoveragePyJSON failed: %v", err)
+	}
+	if pct := report.Files["app/main.py"].Percent(); pct != 60 {
+		t.Errorf("Expected 60%%, got %v", pct)
+	}
+}
+
+func TestParseIstanbulJSON(t *testing.T) {
+	data := `{"/repo/src/sum.js": {
+		"path": "/repo/src/sum.js",
+		"statementMap": {"0": {"start": {"line": 1}, "end": {"line": 1}}, "1": {"start": {"line": 3}, "end": {"line": 4}}},
+		"s": {"0": 2, "1": 0}
+	}}`
+	report, err := ParseIstanbulJSON([]byte(data), "/repo")
+	if err != nil {
+		t.Fatalf("ParseIstanbulJSON failed: %v", err)
+	}
+	fc := report.Files["src/sum.js"]
+	if fc == nil || !fc.Lines[1] || fc.Lines[3] || fc.Lines[4] {
+		t.Errorf("Unexpected coverage: %+v", report.Files)
+	}
+}
+
+func TestChangedCoverage_NoInstrumentedChanges(t *testing.T) {
+	report := newCoverageReport("go")
+	changed := report.ChangedCoverage(map[string][]int{"docs.md": {1, 2}})
+	if changed.Percent() != 100 {
+		t.Errorf("Expected 100%% when no instrumented lines changed, got %v", changed.Percent())
+	}
+}
+
+func TestFormatLineRanges(t *testing.T) {
+	if got := formatLineRanges([]int{9, 3, 4, 5, 11, 12}); got != "3-5, 9, 11-12" {
+		t.Errorf("Unexpected ranges %q", got)
+	}
+}
@@ -0,0 +1,485 @@
+package build
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Test case statuses reported in a TestReport.
+const (
+	TestStatusPassed  = "passed"
+	TestStatusFailed  = "failed"
+	TestStatusSkipped = "skipped"
+
+	// maxFailureOutput bounds the output kept per failing test.
+	maxFailureOutput = 4000
+)
+
+// TestCase is the result of a single test.
+//
+//nolint:govet // JSON serialization struct, logical order preferred
+type TestCase struct {
+	Name     string  `json:"name"`
+	Suite    string  `json:"suite,omitempty"` // Package, class or file the test belongs to
+	Status   string  `json:"status"`
+	Duration float64 `json:"duration_seconds"`
+	Output   string  `json:"output,omitempty"` // Assertion output for failing tests
+}
+
+// TestReport is a framework-independent summary of a test run.
+//
+//nolint:govet // JSON serialization struct, logical order preferred
+type TestReport struct {
+	Framework string     `json:"framework"`
+	Passed    int        `json:"passed"`
+	Failed    int        `json:"failed"`
+	Skipped   int        `json:"skipped"`
+	Duration  float64    `json:"duration_seconds"`
+	Failures  []TestCase `json:"failures"`
+}
+
+// Total returns the number of tests that ran or were skipped.
+func (r *TestReport) Total() int {
+	return r.Passed + r.Failed + r.Skipped
+}
+
+// Summary renders the report as text suitable for an LLM context or a review request.
+func (r *TestReport) Summary() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d passed, %d failed, %d skipped (%.2fs, %s)\n", r.Passed, r.Failed, r.Skipped, r.Duration, r.Framework))
+	for i := range r.Failures {
+		failure := &r.Failures[i]
+		sb.WriteString(fmt.Sprintf("\nFAIL %s", failure.Name))
+		if failure.Suite != "" {
+			sb.WriteString(fmt.Sprintf(" (%s)", failure.Suite))
+		}
+		sb.WriteString(fmt.Sprintf(" [%.2fs]\n", failure.Duration))
+		if failure.Output != "" {
+			sb.WriteString(failure.Output)
+			if !strings.HasSuffix(failure.Output, "\n") {
+				sb.WriteString("\n")
+			}
+		}
+	}
+	return sb.String()
+}
+
+// add records a test case in the counters and, if it failed, in the failure list.
+func (r *TestReport) add(tc *TestCase) {
+	switch tc.Status {
+	case TestStatusPassed:
+		r.Passed++
+	case TestStatusSkipped:
+		r.Skipped++
+	default:
+		r.Failed++
+		tc.Output = truncateFailureOutput(tc.Output)
+		r.Failures = append(r.Failures, *tc)
+	}
+}
+
+// goTestEvent is one line of `go test -json` output.
+type goTestEvent struct {
+	Action     string  `json:"Action"`
+	Package    string  `json:"Package"`
+	ImportPath string  `json:"ImportPath"`
+	Test       string  `json:"Test"`
+	Output     string  `json:"Output"`
+	Elapsed    float64 `json:"Elapsed"`
+}
+
+// ParseGoTestJSON parses the event stream produced by `go test -json`.
+// Packages that fail without a failing test (e.g. build errors) are reported as failures
+// named after the package.
+func ParseGoTestJSON(data []byte) (*TestReport, error) {
+	report := &TestReport{Framework: "go", Failures: []TestCase{}}
+	outputs := make(map[string]*strings.Builder)
+	failedTestsInPackage := make(map[string]int)
+	packageResults := make(map[string]goTestEvent)
+	var packageOrder []string
+	parsed := 0
+
+	appendOutput := func(key, text string) {
+		if outputs[key] == nil {
+			outputs[key] = &strings.Builder{}
+		}
+		outputs[key].WriteString(text)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] != '{' {
+			continue // Non-JSON noise such as compiler errors printed to stderr
+		}
+		var event goTestEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			continue
+		}
+		parsed++
+
+		switch event.Action {
+		case "build-output":
+			// ImportPath may carry a test variant suffix, e.g. "pkg [pkg.test]"
+			importPath, _, _ := strings.Cut(event.ImportPath, " ")
+			appendOutput(importPath, event.Output)
+		case "output":
+			appendOutput(event.Package+"\x00"+event.Test, event.Output)
+		case "pass", "fail", "skip":
+			if event.Test == "" {
+				if _, seen := packageResults[event.Package]; !seen {
+					packageOrder = append(packageOrder, event.Package)
+				}
+				packageResults[event.Package] = event
+				continue
+			}
+			tc := &TestCase{Name: event.Test, Suite: event.Package, Duration: event.Elapsed, Status: goStatus(event.Action)}
+			if tc.Status == TestStatusFailed {
+				failedTestsInPackage[event.Package]++
+				if out := outputs[event.Package+"\x00"+event.Test]; out != nil {
+					tc.Output = out.String()
+				}
+			}
+			report.add(tc)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read go test output: %w", err)
+	}
+	if parsed == 0 {
+		return nil, fmt.Errorf("no go test JSON events found")
+	}
+
+	for _, pkg := range packageOrder {
+		event := packageResults[pkg]
+		report.Duration += event.Elapsed
+		if event.Action != "fail" || failedTestsInPackage[pkg] > 0 {
+			continue
+		}
+		var out strings.Builder
+		for _, key := range []string{pkg, pkg + "\x00"} {
+			if buf := outputs[key]; buf != nil {
+				out.WriteString(buf.String())
+			}
+		}
+		report.add(&TestCase{Name: pkg, Status: TestStatusFailed, Duration: event.Elapsed, Output: out.String()})
+	}
+	return report, nil
+}
+
+// goStatus maps a go test action to a test status.
+func goStatus(action string) string {
+	switch action {
+	case "pass":
+		return TestStatusPassed
+	case "skip":
+		return TestStatusSkipped
+	default:
+		return TestStatusFailed
+	}
+}
+
+// junitSuite mirrors a JUnit <testsuite>, which may nest further suites.
+type junitSuite struct {
+	Name   string       `xml:"name,attr"`
+	Time   string       `xml:"time,attr"`
+	Suites []junitSuite `xml:"testsuite"`
+	Cases  []junitCase  `xml:"testcase"`
+}
+
+// junitCase mirrors a JUnit <testcase>.
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure"`
+	Error     *junitMessage `xml:"error"`
+	Skipped   *junitMessage `xml:"skipped"`
+	SystemOut string        `xml:"system-out"`
+}
+
+// junitMessage mirrors <failure>, <error> and <skipped> elements.
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// ParseJUnitXML parses a JUnit XML report as written by pytest --junitxml.
+// Both a <testsuites> root and a bare <testsuite> root are accepted.
+func ParseJUnitXML(data []byte) (*TestReport, error) {
+	var root struct {
+		XMLName xml.Name
+		junitSuite
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse JUnit XML: %w", err)
+	}
+	if root.XMLName.Local != "testsuites" && root.XMLName.Local != "testsuite" {
+		return nil, fmt.Errorf("unexpected JUnit root element <%s>", root.XMLName.Local)
+	}
+
+	report := &TestReport{Framework: "junit", Failures: []TestCase{}}
+	var walk func(suite *junitSuite)
+	walk = func(suite *junitSuite) {
+		for i := range suite.Cases {
+			c := &suite.Cases[i]
+			tc := &TestCase{Name: c.Name, Suite: c.ClassName, Status: TestStatusPassed, Duration: parseSeconds(c.Time)}
+			switch {
+			case c.Failure != nil:
+				tc.Status = TestStatusFailed
+				tc.Output = joinMessage(c.Failure)
+			case c.Error != nil:
+				tc.Status = TestStatusFailed
+				tc.Output = joinMessage(c.Error)
+			case c.Skipped != nil:
+				tc.Status = TestStatusSkipped
+			}
+			if tc.Suite == "" {
+				tc.Suite = suite.Name
+			}
+			report.add(tc)
+		}
+		for i := range suite.Suites {
+			walk(&suite.Suites[i])
+		}
+	}
+	walk(&root.junitSuite)
+
+	if root.XMLName.Local == "testsuite" || root.Time != "" {
+		report.Duration = parseSeconds(root.Time)
+	} else {
+		for i := range root.Suites {
+			report.Duration += parseSeconds(root.Suites[i].Time)
+		}
+	}
+	return report, nil
+}
+
+// joinMessage combines the message attribute and body of a JUnit failure element.
+func joinMessage(m *junitMessage) string {
+	text := strings.TrimSpace(m.Text)
+	if m.Message == "" || strings.Contains(text, m.Message) {
+		return text
+	}
+	if text == "" {
+		return m.Message
+	}
+	return m.Message + "\n" + text
+}
+
+// parseSeconds parses a JUnit time attribute, returning zero when absent or malformed.
+func parseSeconds(value string) float64 {
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0
+	}
+	return seconds
+}
+
+// jestReport mirrors the parts of `jest --json` output used here.
+type jestReport struct {
+	TestResults []struct {
+		Name             string `json:"name"`
+		Message          string `json:"message"`
+		Status           string `json:"status"`
+		StartTime        int64  `json:"startTime"`
+		EndTime          int64  `json:"endTime"`
+		AssertionResults []struct {
+			FullName        string   `json:"fullName"`
+			Status          string   `json:"status"`
+			Duration        *float64 `json:"duration"`
+			FailureMessages []string `json:"failureMessages"`
+		} `json:"assertionResults"`
+	} `json:"testResults"`
+}
+
+// ParseJestJSON parses the report written by `jest --json`.
+// Test files that fail to run (e.g. syntax errors) are reported as failures named after the file.
+func ParseJestJSON(data []byte) (*TestReport, error) {
+	var parsed jestReport
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse Jest JSON: %w", err)
+	}
+
+	report := &TestReport{Framework: "jest", Failures: []TestCase{}}
+	for i := range parsed.TestResults {
+		file := &parsed.TestResults[i]
+		if file.EndTime > file.StartTime {
+			report.Duration += float64(file.EndTime-file.StartTime) / 1000
+		}
+		if len(file.AssertionResults) == 0 && file.Status == TestStatusFailed {
+			report.add(&TestCase{Name: file.Name, Status: TestStatusFailed, Output: file.Message})
+			continue
+		}
+		for j := range file.AssertionResults {
+			assertion := &file.AssertionResults[j]
+			tc := &TestCase{Name: assertion.FullName, Suite: file.Name, Output: strings.Join(assertion.FailureMessages, "\n")}
+			if assertion.Duration != nil {
+				tc.Duration = *assertion.Duration / 1000
+			}
+			switch assertion.Status {
+			case TestStatusPassed:
+				tc.Status = TestStatusPassed
+			case TestStatusFailed:
+				tc.Status = TestStatusFailed
+			default: // pending, skipped, todo, disabled
+				tc.Status = TestStatusSkipped
+			}
+			report.add(tc)
+		}
+	}
+	return report, nil
+}
+
+//nolint:gochecknoglobals // Compiled once, read-only
+var (
+	// goFailedPackage matches a failing package summary, e.g. "FAIL\texample.com/calc\t0.01s"
+	// or "FAIL\texample.com/broken [build failed]".
+	goFailedPackage = regexp.MustCompile(`(?m)^FAIL\s+(\S+)\s+(?:[\d.]+s|\[|\(cached\))`)
+	// pytestFailedFile matches pytest's short summary, e.g. "FAILED tests/test_io.py::test_read - ...".
+	pytestFailedFile = regexp.MustCompile(`(?m)^(?:FAILED|ERROR) (\S+?\.py)(?:::|\s|$)`)
+	// jestFailedFile matches jest's per-file result, e.g. " FAIL  src/sum.test.js".
+	jestFailedFile = regexp.MustCompile(`(?m)^\s*FAIL\s+(\S+)`)
+	// goFailedTest matches a failing top-level Go test, e.g. "--- FAIL: TestDivide (0.00s)".
+	goFailedTest = regexp.MustCompile(`^--- FAIL: (\S+) \(`)
+	// pytestFailedTest matches a failing test in pytest's short summary.
+	pytestFailedTest = regexp.MustCompile(`^(?:FAILED|ERROR) (\S+?\.py)::(\S+)`)
+	// jestFailedTest matches the heading of a failing jest test, e.g. "● Math › divides".
+	jestFailedTest = regexp.MustCompile(`^\s*● (.+›.+)$`)
+	// ansiEscape matches terminal color codes, which jest adds to its output.
+	ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+)
+
+// FailedTestTargets returns the packages (Go) or test files (Python, JavaScript) that failed in
+// the plain output of a test run, in the order they appear. Re-running only these with
+// structured output explains the failure without running the whole suite again.
+func FailedTestTargets(backend, output string) []string {
+	var pattern *regexp.Regexp
+	switch backend {
+	case "go":
+		pattern = goFailedPackage
+	case "python":
+		pattern = pytestFailedFile
+	case "node":
+		pattern = jestFailedFile
+	default:
+		return nil
+	}
+
+	var targets []string
+	seen := make(map[string]bool)
+	for _, match := range pattern.FindAllStringSubmatch(ansiEscape.ReplaceAllString(output, ""), -1) {
+		if target := match[1]; !seen[target] {
+			seen[target] = true
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// ParseFailedTests returns the tests that failed in the plain output of a test run, named as
+// in the structured reports so they can be re-run in isolation. Packages or files that failed
+// without a failing test, such as build or collection errors, are reported as failures named
+// after the package or file. The returned tests carry no output.
+func ParseFailedTests(backend, output string) []TestCase {
+	output = ansiEscape.ReplaceAllString(output, "")
+	var failures []TestCase
+	switch backend {
+	case "go":
+		failures = parseGoFailedTests(output)
+	case "python":
+		failures = parsePytestFailedTests(output)
+	case "node":
+		failures = parseJestFailedTests(output)
+	default:
+		return nil
+	}
+
+	failedSuites := make(map[string]bool)
+	for i := range failures {
+		failedSuites[failures[i].Suite] = true
+	}
+	for _, target := range FailedTestTargets(backend, output) {
+		if !failedSuites[target] {
+			failures = append(failures, TestCase{Name: target, Status: TestStatusFailed})
+		}
+	}
+
+	// Runners such as jest repeat failures in a closing summary
+	var unique []TestCase
+	seen := make(map[string]bool)
+	for i := range failures {
+		key := failures[i].Suite + "\x00" + failures[i].Name
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, failures[i])
+		}
+	}
+	return unique
+}
+
+// parseGoFailedTests reads failing tests from `go test` output. A test belongs to the package
+// whose FAIL line follows it.
+func parseGoFailedTests(output string) []TestCase {
+	var failures, pending []TestCase
+	for _, line := range strings.Split(output, "\n") {
+		if match := goFailedTest.FindStringSubmatch(line); match != nil {
+			pending = append(pending, TestCase{Name: match[1], Status: TestStatusFailed})
+			continue
+		}
+		if match := goFailedPackage.FindStringSubmatch(line); match != nil {
+			for i := range pending {
+				pending[i].Suite = match[1]
+			}
+			failures = append(failures, pending...)
+			pending = nil
+		}
+	}
+	return append(failures, pending...)
+}
+
+// parsePytestFailedTests reads failing tests from pytest's short summary. The suite is the
+// test file.
+func parsePytestFailedTests(output string) []TestCase {
+	var failures []TestCase
+	for _, line := range strings.Split(output, "\n") {
+		if match := pytestFailedTest.FindStringSubmatch(line); match != nil {
+			parts := strings.Split(match[2], "::")
+			failures = append(failures, TestCase{Name: parts[len(parts)-1], Suite: match[1], Status: TestStatusFailed})
+		}
+	}
+	return failures
+}
+
+// parseJestFailedTests reads failing tests from jest output. A test belongs to the file of the
+// FAIL line before it.
+func parseJestFailedTests(output string) []TestCase {
+	var failures []TestCase
+	file := ""
+	for _, line := range strings.Split(output, "\n") {
+		if match := jestFailedFile.FindStringSubmatch(line); match != nil {
+			file = match[1]
+			continue
+		}
+		if match := jestFailedTest.FindStringSubmatch(line); match != nil {
+			name := strings.Join(strings.Fields(strings.ReplaceAll(match[1], "›", " ")), " ")
+			failures = append(failures, TestCase{Name: name, Suite: file, Status: TestStatusFailed})
+		}
+	}
+	return failures
+}
+
+// truncateFailureOutput keeps the end of long failure output, where assertions usually are.
+func truncateFailureOutput(output string) string {
+	if len(output) <= maxFailureOutput {
+		return output
+	}
+	return "[... earlier output truncated ...]\n" + output[len(output)-maxFailureOutput:]
+}
@@ -0,0 +1,170 @@
+package build
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseGoTestJSON(t *testing.T) {
+	output := `{"Action":"start","Package":"example.com/calc"}
+{"Action":"run","Package":"example.com/calc","Test":"TestAdd"}
+{"Action":"output","Package":"example.com/calc","Test":"TestAdd","Output":"=== RUN   TestAdd\n"}
+{"Action":"pass","Package":"example.com/calc","Test":"TestAdd","Elapsed":0.01}
+{"Action":"run","Package":"example.com/calc","Test":"TestDivide"}
+{"Action":"output","Package":"example.com/calc","Test":"TestDivide","Output":"    calc_test.go:12: got 1, want 2\n"}
+{"Action":"fail","Package":"example.com/calc","Test":"TestDivide","Elapsed":0.02}
+{"Action":"skip","Package":"example.com/calc","Test":"TestSlow","Elapsed":0}
+{"Action":"fail","Package":"example.com/calc","Elapsed":0.5}
+{"ImportPath":"example.com/broken [example.com/broken.test]","Action":"build-output","Output":"broken.go:3:1: syntax error\n"}
+{"Action":"fail","Package":"example.com/broken","Elapsed":0,"FailedBuild":"example.com/broken [example.com/broken.test]"}
+`
+	report, err := ParseGoTestJSON([]byte(output))
+	if err != nil {
+		t.Fatalf("ParseGoTestJSON failed: %v", err)
+	}
+
+	if report.Passed != 1 || report.Failed != 2 || report.Skipped != 1 {
+		t.Errorf("Unexpected counts: %+v", report)
+	}
+	if len(report.Failures) != 2 {
+		t.Fatalf("Expected 2 failures, got %+v", report.Failures)
+	}
+	if report.Failures[0].Name != "TestDivide" || !strings.Contains(report.Failures[0].Output, "got 1, want 2") {
+		t.Errorf("Unexpected test failure: %+v", report.Failures[0])
+	}
+	if report.Failures[1].Name != "example.com/broken" || !strings.Contains(report.Failures[1].Output, "syntax error") {
+		t.Errorf("Expected build failure to be reported, got %+v", report.Failures[1])
+	}
+	if !strings.Contains(report.Summary(), "1 passed, 2 failed, 1 skipped") {
+		t.Errorf("Unexpected summary: %s", report.Summary())
+	}
+}
+
+func TestParseGoTestJSON_NoEvents(t *testing.T) {
+	if _, err := ParseGoTestJSON([]byte("go: cannot find main module\n")); err == nil {
+		t.Error("Expected error for output without JSON events")
+	}
+}
+
+func TestParseJUnitXML(t *testing.T) {
+	xmlReport := `<?xml version="1.0" encoding="utf-8"?>
+<testsuites>
+  <testsuite name="pytest" errors="1" failures="1" skipped="1" tests="4" time="1.25">
+    <testcase classname="tests.test_math" name="test_add" time="0.001"/>
+    <testcase classname="tests.test_math" name="test_div" time="0.002">
+      <failure message="assert 1 == 2">def test_div():
+&gt;       assert 1 == 2
+E       assert 1 == 2</failure>
+    </testcase>
+    <testcase classname="tests.test_io" name="test_read" time="0.003">
+      <error message="FileNotFoundError">fixture missing</error>
+    </testcase>
+    <testcase classname="tests.test_io" name="test_slow" time="0">
+      <skipped message="slow"/>
+    </testcase>
+  </testsuite>
+</testsuites>`
+
+	report, err := ParseJUnitXML([]byte(xmlReport))
+	if err != nil {
+		t.Fatalf("ParseJUnitXML failed: %v", err)
+	}
+	if report.Passed != 1 || report.Failed != 2 || report.Skipped != 1 {
+		t.Errorf("Unexpected counts: %+v", report)
+	}
+	if report.Duration != 1.25 {
+		t.Errorf("Expected duration 1.25, got %v", report.Duration)
+	}
+	if report.Failures[0].Suite != "tests.test_math" || !strings.Contains(report.Failures[0].Output, "assert 1 == 2") {
+		t.Errorf("Unexpected failure: %+v", report.Failures[0])
+	}
+	if !strings.Contains(report.Failures[1].Output, "FileNotFoundError") {
+		t.Errorf("Expected error message in output, got %+v", report.Failures[1])
+	}
+}
+
+func TestParseJestJSON(t *testing.T) {
+	jestReport := `{
+  "numFailedTests": 1,
+  "testResults": [
+    {
+      "name": "/app/src/sum.test.js",
+      "status": "failed",
+      "startTime": 1000,
+      "endTime": 1500,
+      "assertionResults": [
+        {"fullName": "sum adds", "status": "passed", "duration": 3, "failureMessages": []},
+        {"fullName": "sum handles negatives", "status": "failed", "duration": 5, "failureMessages": ["Expected: -1\nReceived: 1"]},
+        {"fullName": "sum todo", "status": "todo", "duration": null, "failureMessages": []}
+      ]
+    },
+    {
+      "name": "/app/src/broken.test.js",
+      "status": "failed",
+      "message": "SyntaxError: Unexpected token",
+      "assertionResults": []
+    }
+  ]
+}`
+
+	report, err := ParseJestJSON([]byte(jestReport))
+	if err != nil {
+		t.Fatalf("ParseJestJSON failed: %v", err)
+	}
+	if report.Passed != 1 || report.Failed != 2 || report.Skipped != 1 {
+		t.Errorf("Unexpected counts: %+v", report)
+	}
+	if report.Duration != 0.5 {
+		t.Errorf("Expected duration 0.5, got %v", report.Duration)
+	}
+	if report.Failures[0].Name != "sum handles negatives" || !strings.Contains(report.Failures[0].Output, "Received: 1") {
+		t.Errorf("Unexpected failure: %+v", report.Failures[0])
+	}
+	if report.Failures[1].Name != "/app/src/broken.test.js" || !strings.Contains(report.Failures[1].Output, "SyntaxError") {
+		t.Errorf("Expected suite failure to be reported, got %+v", report.Failures[1])
+	}
+}
+
+func TestFailedTestTargets(t *testing.T) {
+	for _, tc := range []struct {
+		backend string
+		output  string
+		want    []string
+	}{
+		{"go", "ok  \texample.com/ok\t0.01s\n--- FAIL: TestDivide (0.00s)\nFAIL\nFAIL\texample.com/calc\t0.02s\nFAIL\texample.com/broken [build failed]\nFAIL\texample.com/calc\t0.02s\n",
+			[]string{"example.com/calc", "example.com/broken"}},
+		{"python", "FAILED tests/test_math.py::test_div - assert 1 == 2\nERROR tests/test_io.py - FileNotFoundError\nFAILED tests/test_math.py::test_mod\n",
+			[]string{"tests/test_math.py", "tests/test_io.py"}},
+		{"node", "\x1b[1m\x1b[31m FAIL \x1b[39m\x1b[22m src/sum.test.js\n PASS  src/ok.test.js\n",
+			[]string{"src/sum.test.js"}},
+		{"make", "FAIL\texample.com/calc\t0.02s\n", nil},
+	} {
+		got := FailedTestTargets(tc.backend, tc.output)
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%s: expected %v, got %v", tc.backend, tc.want, got)
+		}
+	}
+}
+
+func TestParseFailedTests(t *testing.T) {
+	for _, tc := range []struct {
+		backend string
+		output  string
+		want    []string // suite/name
+	}{
+		{"go", "--- FAIL: TestDivide (0.00s)\n    calc_test.go:12: got 1, want 2\n--- FAIL: TestMod (0.00s)\n    --- FAIL: TestMod/zero (0.00s)\nFAIL\nFAIL\texample.com/calc\t0.02s\nok  \texample.com/ok\t0.01s\nFAIL\texample.com/broken [build failed]\n",
+			[]string{"example.com/calc/TestDivide", "example.com/calc/TestMod", "/example.com/broken"}},
+		{"python", "FAILED tests/test_math.py::TestDiv::test_div - assert 1 == 2\nERROR tests/test_io.py - FileNotFoundError\n",
+			[]string{"tests/test_math.py/test_div", "/tests/test_io.py"}},
+		{"node", " FAIL  src/sum.test.js\n  ● Math › divides\n\n    expect(received).toBe(expected)\n\n  ● Console\n\nSummary of all failing tests\n FAIL  src/sum.test.js\n  ● Math › divides\n",
+			[]string{"src/sum.test.js/Math divides"}},
+	} {
+		var got []string
+		for _, failure := range ParseFailedTests(tc.backend, tc.output) {
+			got = append(got, failure.Suite+"/"+failure.Name)
+		}
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%s: expected %v, got %v", tc.backend, tc.want, got)
+		}
+	}
+}
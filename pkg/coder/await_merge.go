@@ -3,6 +3,7 @@ package coder
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"orchestrator/pkg/agent"
@@ -31,7 +32,7 @@ func (c *Coder) handleAwaitMerge(ctx context.Context, sm *agent.BaseStateMachine
 }
 
 // processMergeResult processes the architect's merge response and determines next state.
-func (c *Coder) processMergeResult(_ context.Context, sm *agent.BaseStateMachine, result *git.MergeResult) (proto.State, bool, error) {
+func (c *Coder) processMergeResult(ctx context.Context, sm *agent.BaseStateMachine, result *git.MergeResult) (proto.State, bool, error) {
 	// Store completion timestamp
 	sm.SetStateData(KeyMergeCompletedAt, time.Now().UTC())
 
@@ -49,6 +50,17 @@ func (c *Coder) processMergeResult(_ context.Context, sm *agent.BaseStateMachine
 		}
 
 		c.logger.Info("🧑‍💻 Merge needs changes, transitioning to CODING: %s", feedback)
+		resetSelfReview(sm)
+
+		// For conflicts, merge the target branch locally so the coder gets a structured
+		// conflict report instead of the raw merge error
+		if strings.Contains(strings.ToLower(feedback), "conflict") {
+			targetBranch, _ := c.getTargetBranch() // Falls back to the default branch
+			if report := c.syncWithTarget(ctx, targetBranch); report != "" {
+				c.contextManager.AddMessage("architect", report)
+				return StateCoding, false, nil
+			}
+		}
 
 		// Use mini-template to format the merge failure message
 		if c.renderer != nil {
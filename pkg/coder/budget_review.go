@@ -71,6 +71,9 @@ func (c *Coder) processBudgetReviewStatus(sm *agent.BaseStateMachine, status pro
 		case string(StateCoding):
 			sm.SetStateData(string(stateDataKeyCodingIterations), 0)
 			return StateCoding, false, nil
+		case string(StateTestDesign):
+			sm.SetStateData(string(stateDataKeyTestDesignIterations), 0)
+			return StateTestDesign, false, nil
 		default:
 			return StateCoding, false, nil // default fallback
 		}
@@ -81,6 +84,7 @@ func (c *Coder) processBudgetReviewStatus(sm *agent.BaseStateMachine, status pro
 			c.logger.Info("🧑‍💻 Budget review needs changes from CODING, continuing CODING with feedback")
 			// Reset coding counter and inject feedback for coding improvements
 			sm.SetStateData(string(stateDataKeyCodingIterations), 0)
+			resetSelfReview(sm)
 			if feedback != "" {
 				// Use mini-template to format the feedback message
 				if c.renderer != nil {
@@ -310,6 +310,38 @@ func (c *CloneManager) createBranch(ctx context.Context, agentWorkDir, branchNam
 	return "", logx.Errorf("unable to find available branch name after %d attempts, last tried: %s", maxAttempts, branchName)
 }
 
+// ContinueFromBranch puts the work of a previous attempt, pushed to branch, into the current
+// story branch of the clone as staged changes on top of the base branch. The branch is fetched
+// from origin first, since clones are made from the mirror, which may not have it yet.
+func (c *CloneManager) ContinueFromBranch(ctx context.Context, agentWorkDir, branch string) error {
+	heads, err := c.gitRunner.Run(ctx, agentWorkDir, "ls-remote", "--heads", "origin", branch)
+	if err != nil {
+		return logx.Wrap(err, fmt.Sprintf("failed to look up branch %s on origin", branch))
+	}
+	if strings.TrimSpace(string(heads)) == "" {
+		return logx.Errorf("branch %s of the previous attempt does not exist on origin", branch)
+	}
+
+	remoteRef := "origin/" + branch
+	refspec := fmt.Sprintf("+refs/heads/%s:refs/remotes/%s", branch, remoteRef)
+	if _, err := c.gitRunner.Run(ctx, agentWorkDir, "fetch", "origin", refspec); err != nil {
+		return logx.Wrap(err, fmt.Sprintf("failed to fetch branch %s from origin", branch))
+	}
+	if _, err := c.gitRunner.Run(ctx, agentWorkDir, "reset", "--hard", remoteRef); err != nil {
+		return logx.Wrap(err, fmt.Sprintf("failed to reset to %s", remoteRef))
+	}
+
+	// Soft-reset to the fork point so the inherited work is reviewed and committed as part of this story.
+	mergeBase, err := c.gitRunner.Run(ctx, agentWorkDir, "merge-base", "HEAD", "origin/"+c.baseBranch)
+	if err != nil {
+		return logx.Wrap(err, fmt.Sprintf("failed to find merge base of %s and %s", remoteRef, c.baseBranch))
+	}
+	if _, err := c.gitRunner.Run(ctx, agentWorkDir, "reset", "--soft", strings.TrimSpace(string(mergeBase))); err != nil {
+		return logx.Wrap(err, "failed to unstage previous attempt's commits")
+	}
+	return nil
+}
+
 // getExistingBranches gets a list of all branches (local and remote) in the repository.
 func (c *CloneManager) getExistingBranches(ctx context.Context, agentWorkDir string) ([]string, error) {
 	// Get all branches (local and remote).
@@ -23,6 +23,7 @@ func (c *Coder) handleCodeReview(ctx context.Context, sm *agent.BaseStateMachine
 	storyID := utils.GetStateValueOr[string](sm, KeyStoryID, "")
 	testsPassed := utils.GetStateValueOr[bool](sm, KeyTestsPassed, false)
 	testOutput := utils.GetStateValueOr[string](sm, KeyTestOutput, "")
+	coverageSummary := utils.GetStateValueOr[string](sm, KeyCoverageSummary, "")
 	storyType := utils.GetStateValueOr[string](sm, proto.KeyStoryType, string(proto.StoryTypeApp))
 
 	var approvalEff *effect.ApprovalEffect
@@ -46,7 +47,7 @@ func (c *Coder) handleCodeReview(ctx context.Context, sm *agent.BaseStateMachine
 	gitDiff := c.getBranchDiff(ctx, baseBranch)
 
 	// Build comprehensive evidence section
-	evidence := c.buildCompletionEvidence(testsPassed, testOutput, gitDiff, storyType, workResult)
+	evidence := c.buildCompletionEvidence(testsPassed, testOutput, coverageSummary, gitDiff, storyType, workResult)
 
 	if !workResult.HasWork {
 		// No work detected - request completion approval
@@ -74,6 +75,7 @@ func (c *Coder) handleCodeReview(ctx context.Context, sm *agent.BaseStateMachine
 
 ## Implementation Analysis
 %s`, summary, evidence, confidence, originalStory, plan)
+		codeContent += c.buildPolicyViolationsSection(storyID)
 
 		approvalEff = effect.NewApprovalEffect(codeContent, "Story completion verified with evidence", proto.ApprovalTypeCompletion)
 		approvalEff.StoryID = storyID
@@ -108,6 +110,11 @@ func (c *Coder) handleCodeReview(ctx context.Context, sm *agent.BaseStateMachine
 
 ## Implementation Plan
 %s`, summary, evidence, confidence, gitDiff, originalStory, plan)
+		codeContent += c.buildSelfReviewSection()
+		codeContent += c.buildTodoReviewSection()
+		codeContent += c.buildFlakyTestsSection()
+		codeContent += c.buildApprovedTestsSection()
+		codeContent += c.buildPolicyViolationsSection(storyID)
 
 		approvalEff = effect.NewApprovalEffect(codeContent, "Code implementation requires architect review", proto.ApprovalTypeCode)
 		approvalEff.StoryID = storyID
@@ -158,6 +165,7 @@ func (c *Coder) processApprovalResult(_ context.Context, sm *agent.BaseStateMach
 		// Add feedback directly to context
 		feedbackMessage := fmt.Sprintf("Code review feedback - changes requested:\n\n%s\n\nPlease address these issues and continue implementation.", result.Feedback)
 		c.contextManager.AddMessage("architect-feedback", feedbackMessage)
+		resetSelfReview(sm)
 		return StateCoding, false, nil
 
 	case proto.ApprovalStatusRejected:
@@ -167,6 +175,7 @@ func (c *Coder) processApprovalResult(_ context.Context, sm *agent.BaseStateMach
 			// Return to CODING to do the work that was deemed missing
 			rejectionMessage := fmt.Sprintf("Code completion rejected by architect:\n\n%s\n\nPlease continue implementation to address these concerns.", result.Feedback)
 			c.contextManager.AddMessage("architect-rejection", rejectionMessage)
+			resetSelfReview(sm)
 			return StateCoding, false, nil
 		} else {
 			c.logger.Error("🧑‍💻 Code rejected by architect: %s", result.Feedback)
@@ -222,7 +231,7 @@ func (c *Coder) getBranchDiff(ctx context.Context, baseBranch string) string {
 }
 
 // buildCompletionEvidence builds evidence section based on story type and results.
-func (c *Coder) buildCompletionEvidence(testsPassed bool, testOutput, gitDiff, storyType string, workResult *git.WorkDoneResult) string {
+func (c *Coder) buildCompletionEvidence(testsPassed bool, testOutput, coverageSummary, gitDiff, storyType string, workResult *git.WorkDoneResult) string {
 	evidence := ""
 
 	// Add test evidence
@@ -238,6 +247,11 @@ func (c *Coder) buildCompletionEvidence(testsPassed bool, testOutput, gitDiff, s
 		}
 	}
 
+	// Add coverage of the changed lines
+	if coverageSummary != "" {
+		evidence += "📊 " + coverageSummary
+	}
+
 	// Add story-type specific evidence
 	if storyType == string(proto.StoryTypeDevOps) {
 		evidence += "🐳 DevOps story completed:\n"
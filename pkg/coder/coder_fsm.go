@@ -13,7 +13,9 @@ const (
 	StatePlanning     proto.State = "PLANNING"
 	StateCoding       proto.State = "CODING"
 	StateTesting      proto.State = "TESTING"
+	StateSelfReview   proto.State = "SELF_REVIEW"
 	StatePlanReview   proto.State = "PLAN_REVIEW"
+	StateTestDesign   proto.State = "TEST_DESIGN"
 	StateCodeReview   proto.State = "CODE_REVIEW"
 	StatePrepareMerge proto.State = "PREPARE_MERGE"
 	StateBudgetReview proto.State = "BUDGET_REVIEW"
@@ -58,6 +60,11 @@ const (
 	KeyTestsPassed             = "tests_passed"
 	KeyTestOutput              = "test_output"
 	KeyTestingCompletedAt      = "testing_completed_at"
+	KeyCoverageSummary         = "coverage_summary"
+	KeyFlakyTests              = "flaky_tests"
+	KeyTestFirst               = "test_first"
+	KeyApprovedTests           = "approved_tests"
+	KeyHandoffNotes            = "handoff_notes"
 	KeyCodeReviewCompletedAt   = "code_review_completed_at"
 	KeyMergeResult             = "merge_result"
 	KeyMergeCompletedAt        = "merge_completed_at"
@@ -66,6 +73,7 @@ const (
 	KeyRemoteBranchName        = "remote_branch_name"
 	KeyPlanningCompletedAt     = "planning_completed_at"
 	KeyCompletionSubmittedAt   = "completion_submitted_at"
+	KeyProposedSplit           = "proposed_split"
 	KeyTreeOutputCached        = "tree_output_cached"
 	KeyPlanningContextSaved    = "planning_context_saved"
 	KeyCodingContextSaved      = "coding_context_saved"
@@ -100,8 +108,8 @@ func ValidateState(state proto.State) error {
 // GetValidStates returns all valid states for coder agents.
 func GetValidStates() []proto.State {
 	return []proto.State{
-		proto.StateWaiting, StateSetup, StatePlanning, StateCoding, StateTesting,
-		StatePlanReview, StateCodeReview, StatePrepareMerge, StateBudgetReview, StateAwaitMerge, proto.StateDone, proto.StateError,
+		proto.StateWaiting, StateSetup, StatePlanning, StateCoding, StateTesting, StateSelfReview,
+		StatePlanReview, StateTestDesign, StateCodeReview, StatePrepareMerge, StateBudgetReview, StateAwaitMerge, proto.StateDone, proto.StateError,
 	}
 }
 
@@ -112,26 +120,32 @@ var CoderTransitions = map[proto.State][]proto.State{ //nolint:gochecknoglobals
 	// WAITING can transition to SETUP when receiving task assignment, ERROR during shutdown, or DONE for clean shutdown.
 	proto.StateWaiting: {StateSetup, proto.StateError, proto.StateDone},
 
-	// SETUP prepares workspace (mirror, clone, branch) then goes to PLANNING.
-	StateSetup: {StatePlanning, proto.StateError},
+	// SETUP prepares workspace (mirror, clone, branch) then goes to PLANNING, or reattaches a checkpointed workspace and resumes CODING or TESTING.
+	StateSetup: {StatePlanning, StateCoding, StateTesting, proto.StateError},
 
 	// PLANNING can submit plan for review or exceed budget (→BUDGET_REVIEW). Questions are handled inline via Effects.
 	StatePlanning: {StatePlanReview, StateBudgetReview},
 
-	// PLAN_REVIEW can approve plan (→CODING), approve completion (→DONE), request changes (→PLANNING), or abandon (→ERROR).
-	StatePlanReview: {StatePlanning, StateCoding, proto.StateDone, proto.StateError},
+	// PLAN_REVIEW can approve plan (→CODING, or →TEST_DESIGN for test-first stories), approve completion (→DONE), request changes (→PLANNING), or abandon (→ERROR).
+	StatePlanReview: {StatePlanning, StateCoding, StateTestDesign, proto.StateDone, proto.StateError},
+
+	// TEST_DESIGN can have its failing tests approved (→CODING), exceed budget (→BUDGET_REVIEW), or hit unrecoverable error.
+	StateTestDesign: {StateCoding, StateBudgetReview, proto.StateError},
 
 	// CODING can complete (→TESTING), exceed budget (→BUDGET_REVIEW), or hit unrecoverable error. Questions are handled inline via Effects.
 	StateCoding: {StateTesting, StateBudgetReview, proto.StateError},
 
-	// TESTING can pass (→CODE_REVIEW) or fail (→CODING).
-	StateTesting: {StateCoding, StateCodeReview},
+	// TESTING can pass (→CODE_REVIEW, or →SELF_REVIEW when self-review is enabled) or fail (→CODING).
+	StateTesting: {StateCoding, StateCodeReview, StateSelfReview},
+
+	// SELF_REVIEW can find issues to fix (→CODING), pass the diff on (→CODE_REVIEW), or hit unrecoverable error.
+	StateSelfReview: {StateCoding, StateCodeReview, proto.StateError},
 
 	// CODE_REVIEW can approve code (→PREPARE_MERGE), approve completion (→DONE), request changes (→CODING), or abandon (→ERROR).
 	StateCodeReview: {StatePrepareMerge, proto.StateDone, StateCoding, proto.StateError},
 
-	// BUDGET_REVIEW can continue (→CODING), pivot (→PLANNING), or abandon (→ERROR).
-	StateBudgetReview: {StatePlanning, StateCoding, proto.StateError},
+	// BUDGET_REVIEW can continue (→CODING or →TEST_DESIGN), pivot (→PLANNING), or abandon (→ERROR).
+	StateBudgetReview: {StatePlanning, StateCoding, StateTestDesign, proto.StateError},
 
 	// PREPARE_MERGE can commit and create PR (→AWAIT_MERGE), encounter recoverable git errors (→CODING), or hit unrecoverable errors (→ERROR).
 	StatePrepareMerge: {StateAwaitMerge, StateCoding, proto.StateError},
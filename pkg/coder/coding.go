@@ -98,6 +98,9 @@ func (c *Coder) executeCodingWithTemplate(ctx context.Context, sm *agent.BaseSta
 	// Log the rendered prompt for debugging
 	c.logger.Info("🧑‍💻 Starting coding phase for story_type '%s'", storyType)
 
+	// Pick up answers to any non-blocking questions asked earlier.
+	c.deliverQuestionAnswers()
+
 	// Get LLM response with MCP tool support.
 	// Flush user buffer before LLM request
 	if err := c.contextManager.FlushUserBuffer(); err != nil {
@@ -155,6 +158,12 @@ func (c *Coder) executeCodingWithTemplate(ctx context.Context, sm *agent.BaseSta
 		return StateTesting, false, nil
 	}
 
+	// Warn about, and then escalate, a failure the coder keeps repeating.
+	if budgetReviewEff, looping := c.checkFailureLoop(sm, StateCoding); looping {
+		sm.SetStateData("budget_review_effect", budgetReviewEff)
+		return StateBudgetReview, false, nil
+	}
+
 	// Continue in coding state for next iteration.
 	c.logger.Info("🧑‍💻 Coding iteration completed, continuing in CODING for more work")
 	return StateCoding, false, nil
@@ -207,12 +216,7 @@ func (c *Coder) executeMCPToolCalls(ctx context.Context, sm *agent.BaseStateMach
 
 		// Handle ask_question tool using Effects pattern.
 		if toolCall.Name == tools.ToolAskQuestion {
-			// Extract question details from tool arguments.
-			question := utils.GetMapFieldOr[string](toolCall.Parameters, "question", "")
-			context := utils.GetMapFieldOr[string](toolCall.Parameters, "context", "")
-			urgency := utils.GetMapFieldOr[string](toolCall.Parameters, "urgency", "medium")
-
-			if question == "" {
+			if utils.GetMapFieldOr[string](toolCall.Parameters, "question", "") == "" {
 				c.logger.Error("Ask question tool called without question parameter")
 				continue
 			}
@@ -220,36 +224,13 @@ func (c *Coder) executeMCPToolCalls(ctx context.Context, sm *agent.BaseStateMach
 			// Store coding context before asking question.
 			c.storeCodingContext(sm)
 
-			// Create question effect
-			eff := effect.NewQuestionEffect(question, context, urgency, string(StateCoding))
-
-			// Set story_id for dispatcher validation
-			storyID := utils.GetStateValueOr[string](sm, KeyStoryID, "")
-			eff.StoryID = storyID
-
-			c.logger.Info("🧑‍💻 Asking question")
-
-			// Execute the question effect (blocks until answer received)
-			result, err := c.ExecuteEffect(ctx, eff)
-			if err != nil {
+			// Blocks until the answer is received unless the question is non-blocking.
+			if err := c.askQuestion(ctx, sm, toolCall.Parameters, StateCoding); err != nil {
 				c.logger.Error("🧑‍💻 Failed to get answer: %v", err)
 				// Add error to context for LLM to handle
 				c.addComprehensiveToolFailureToContext(*toolCall, err)
 				continue
 			}
-
-			// Process the answer
-			if questionResult, ok := result.(*effect.QuestionResult); ok {
-				// Answer received from architect (logged to database only)
-
-				// Add the Q&A to context so the LLM can see it
-				qaContent := fmt.Sprintf("Question: %s\nAnswer: %s", question, questionResult.Answer)
-				c.contextManager.AddMessage("architect-answer", qaContent)
-
-				// Continue with coding using the answer
-			} else {
-				c.logger.Error("🧑‍💻 Invalid question result type: %T", result)
-			}
 		}
 
 		// Get tool from ToolProvider and execute.
@@ -262,6 +243,7 @@ func (c *Coder) executeMCPToolCalls(ctx context.Context, sm *agent.BaseStateMach
 		}
 
 		result, err := tool.Exec(ctx, toolCall.Parameters)
+		c.observeToolCall(toolCall, result, err)
 		if err != nil {
 			// Tool execution failures are recoverable - add comprehensive error to context for LLM to react.
 			c.logger.Info("Tool execution failed for %s: %v", toolCall.Name, err)
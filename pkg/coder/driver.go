@@ -21,7 +21,9 @@ import (
 	"orchestrator/pkg/effect"
 	execpkg "orchestrator/pkg/exec"
 	"orchestrator/pkg/logx"
+	"orchestrator/pkg/persistence"
 	"orchestrator/pkg/proto"
+	"orchestrator/pkg/state"
 	"orchestrator/pkg/templates"
 	"orchestrator/pkg/tools"
 	"orchestrator/pkg/utils"
@@ -52,7 +54,14 @@ type Coder struct {
 	longRunningExecutor     *execpkg.LongRunningDockerExec // Docker executor for container per story
 	planningToolProvider    *tools.ToolProvider            // Tools available during planning state
 	codingToolProvider      *tools.ToolProvider            // Tools available during coding state
+	testDesignToolProvider  *tools.ToolProvider            // Tools available during test design state
 	pendingApprovalRequest  *ApprovalRequest               // REQUEST→RESULT flow state
+	persistenceChannel      chan<- *persistence.Request    // Database worker channel (story notes, flaky tests)
+	notes                   *storyNotes                    // Scratchpad notes for the current story
+	loopDetector            *loopDetector                  // Repeated failure detection for the current story
+	stateStore              *state.Store                   // Checkpoints for resuming in-flight stories after a restart
+	openQuestions           map[string]string              // Non-blocking questions awaiting answers, by correlation ID
+	deferredAnswers         []*proto.AgentMsg              // Answers to open questions received while awaiting another response
 	pendingQuestion         *Question
 	storyCh                 <-chan *proto.AgentMsg // Channel to receive story messages
 	replyCh                 <-chan *proto.AgentMsg // Channel to receive replies (for future use)
@@ -84,20 +93,28 @@ func (r *Runtime) ReceiveMessage(ctx context.Context, expectedType proto.MsgType
 		return nil, fmt.Errorf("reply channel not available")
 	}
 
-	select {
-	case <-ctx.Done():
-		return nil, fmt.Errorf("receive message cancelled: %w", ctx.Err())
-	case msg, ok := <-r.coder.replyCh:
-		if !ok {
-			return nil, fmt.Errorf("reply channel closed unexpectedly")
-		}
-		if msg == nil {
-			return nil, fmt.Errorf("received nil message")
-		}
-		if msg.Type != expectedType {
-			return nil, fmt.Errorf("expected message type %s but received %s", expectedType, msg.Type)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("receive message cancelled: %w", ctx.Err())
+		case msg, ok := <-r.coder.replyCh:
+			if !ok {
+				return nil, fmt.Errorf("reply channel closed unexpectedly")
+			}
+			if msg == nil {
+				return nil, fmt.Errorf("received nil message")
+			}
+			// Answers to non-blocking questions can arrive while waiting on another
+			// response; set them aside for the next iteration.
+			if r.coder.isOpenQuestionAnswer(msg) {
+				r.coder.deferredAnswers = append(r.coder.deferredAnswers, msg)
+				continue
+			}
+			if msg.Type != expectedType {
+				return nil, fmt.Errorf("expected message type %s but received %s", expectedType, msg.Type)
+			}
+			return msg, nil
 		}
-		return msg, nil
 	}
 }
 
@@ -209,15 +226,18 @@ const (
 	stateDataKeyStartedAt                stateDataKey = "started_at"
 	stateDataKeyCodingIterations         stateDataKey = "coding_iterations"
 	stateDataKeyPlanningIterations       stateDataKey = "planning_iterations"
+	stateDataKeyTestDesignIterations     stateDataKey = "test_design_iterations"
 
 	// BUDGET_REVIEW and other state keys - removed unused constants.
 )
 
 // PlanTodo represents a single task item in the implementation plan.
 type PlanTodo struct {
-	ID          string `json:"id"`
-	Description string `json:"description"`
-	Completed   bool   `json:"completed"`
+	ID          string           `json:"id"`
+	Description string           `json:"description"`
+	Completed   bool             `json:"completed"`
+	Status      proto.TodoStatus `json:"status"`         // Progress reported via update_todo
+	Note        string           `json:"note,omitempty"` // Why the todo is blocked or was skipped
 }
 
 // Docker container constants.
@@ -676,7 +696,8 @@ func (c *Coder) checkLoopBudget(sm *agent.BaseStateMachine, key string, budget i
 	// Check if budget exceeded.
 	if iterationCount >= budget {
 		// Build comprehensive budget review content
-		content := c.buildBudgetReviewContent(sm, origin, iterationCount, budget)
+		header := fmt.Sprintf("Loop budget exceeded in %s state (%d/%d iterations). How should I proceed?", origin, iterationCount, budget)
+		content := c.buildBudgetReviewContent(sm, origin, header)
 
 		// Store origin state for later use.
 		sm.SetStateData(KeyOrigin, string(origin))
@@ -734,10 +755,14 @@ func (c *Coder) ProcessState(ctx context.Context) (proto.State, bool, error) {
 		nextState, done, err = c.handlePlanning(ctx, sm)
 	case StatePlanReview:
 		nextState, done, err = c.handlePlanReview(ctx, sm)
+	case StateTestDesign:
+		nextState, done, err = c.handleTestDesign(ctx, sm)
 	case StateCoding:
 		nextState, done, err = c.handleCoding(ctx, sm)
 	case StateTesting:
 		nextState, done, err = c.handleTesting(ctx, sm)
+	case StateSelfReview:
+		nextState, done, err = c.handleSelfReview(ctx, sm)
 	case StateCodeReview:
 		nextState, done, err = c.handleCodeReview(ctx, sm)
 	case StatePrepareMerge:
@@ -959,6 +984,7 @@ func (c *Coder) Step(ctx context.Context) (bool, error) {
 		if err := c.BaseStateMachine.TransitionTo(ctx, nextState, nil); err != nil {
 			return false, logx.Wrap(err, fmt.Sprintf("failed to transition to state %s", nextState))
 		}
+		c.checkpoint(nextState)
 	}
 
 	return done, nil
@@ -1124,9 +1150,11 @@ func (c *Coder) createPlanningToolProvider(storyType string) *tools.ToolProvider
 		ReadOnly:        true,                  // Planning is read-only
 		NetworkDisabled: true,                  // No network access during planning
 		WorkDir:         c.workDir,
+		Policy:          c.newPolicyEnforcer(storyType),
+		Notes:           c.notesStore(),
 	}
 
-	return tools.NewProvider(agentCtx, planningTools)
+	return tools.NewProvider(agentCtx, withExternalTools(planningTools))
 }
 
 // createCodingToolProvider creates a ToolProvider for the coding state.
@@ -1145,16 +1173,26 @@ func (c *Coder) createCodingToolProvider(storyType string) *tools.ToolProvider {
 		ReadOnly:        false,                 // Coding requires write access
 		NetworkDisabled: false,                 // May need network for builds/tests
 		WorkDir:         c.workDir,
+		Policy:          c.newPolicyEnforcer(storyType),
+		Notes:           c.notesStore(),
+		Todos:           c.todoTrackerForTools(),
+		BuildService:    c.buildService,
 	}
 
-	return tools.NewProvider(agentCtx, codingTools)
+	return tools.NewProvider(agentCtx, withExternalTools(codingTools))
 }
 
-// buildBudgetReviewContent creates comprehensive budget review content with story, plan, and context.
-func (c *Coder) buildBudgetReviewContent(sm *agent.BaseStateMachine, origin proto.State, iterationCount, budget int) string {
-	// Basic budget info
-	header := fmt.Sprintf("Loop budget exceeded in %s state (%d/%d iterations). How should I proceed?", origin, iterationCount, budget)
+// withExternalTools appends tools provided by configured MCP servers to a built-in tool list.
+// Returns a new slice so the shared tool lists in the tools package are never mutated.
+func withExternalTools(builtin []string) []string {
+	external := tools.ExternalToolNames(config.MCPAgentCoder)
+	result := make([]string, 0, len(builtin)+len(external))
+	result = append(result, builtin...)
+	return append(result, external...)
+}
 
+// buildBudgetReviewContent creates comprehensive budget review content with story, plan, and context.
+func (c *Coder) buildBudgetReviewContent(sm *agent.BaseStateMachine, origin proto.State, header string) string {
 	// Get story and plan context
 	storyID := utils.GetStateValueOr[string](sm, KeyStoryID, "")
 	taskContent := utils.GetStateValueOr[string](sm, string(stateDataKeyTaskContent), "")
@@ -209,7 +209,9 @@ func TestCoderStateConstants(t *testing.T) {
 		StatePlanning,
 		StateCoding,
 		StateTesting,
+		StateSelfReview,
 		StatePlanReview,
+		StateTestDesign,
 		StateCodeReview,
 		StateBudgetReview,
 		StateAwaitMerge,
@@ -0,0 +1,405 @@
+package coder
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"orchestrator/pkg/agent"
+	"orchestrator/pkg/build"
+	"orchestrator/pkg/config"
+	"orchestrator/pkg/persistence"
+	"orchestrator/pkg/proto"
+	"orchestrator/pkg/tools"
+	"orchestrator/pkg/utils"
+)
+
+const (
+	// maxFlakyCandidates bounds how many failing tests are re-run in isolation; larger failure
+	// sets are almost certainly real breakage and are returned to the coder as they are.
+	maxFlakyCandidates = 10
+
+	// flakyLoadTimeout bounds how long TESTING waits for the database to return known flaky tests.
+	flakyLoadTimeout = 5 * time.Second
+)
+
+// testTriage classifies the failures of a test run.
+type testTriage struct {
+	report      *build.TestReport
+	rawOutput   string           // Output of the original run, when no structured report has the failure details
+	blocking    []build.TestCase // Deterministic failures, and flaky tests in code the story changed
+	flaky       []build.TestCase // Failures that passed on a re-run or are recorded as flaky
+	quarantined []build.TestCase // Flaky tests in code the story did not change; these do not block
+}
+
+// flakyRetries returns how many isolated re-runs each failing test gets, or 0 when flaky
+// test detection is disabled.
+func flakyRetries() int {
+	cfg, err := config.GetConfig()
+	if err != nil || cfg.Build == nil || cfg.Build.FlakyRetries == 0 {
+		return config.DefaultFlakyRetries
+	}
+	if cfg.Build.FlakyRetries < 0 {
+		return 0
+	}
+	return cfg.Build.FlakyRetries
+}
+
+// triageTestFailures classifies the tests that failed in the original run, whose plain output is
+// testOutput, to tell flaky tests from deterministic failures. A failure is flaky when it passes
+// as the failing packages are re-run for structured results, passes when re-run in isolation, or
+// is recorded in the database as a known flaky test. Flaky tests are recorded and reported to the
+// architect; those in suites the story did not touch are quarantined so they do not block the
+// story. Returns nil when no failing test can be identified.
+func (c *Coder) triageTestFailures(ctx context.Context, sm *agent.BaseStateMachine, workspacePath, backend, testOutput string) *testTriage {
+	failures := build.ParseFailedTests(backend, testOutput)
+	report := c.structuredTestReport(ctx, workspacePath, backend, testOutput)
+	if len(failures) == 0 && (report == nil || report.Failed == 0) {
+		return nil
+	}
+	triage := &testTriage{report: report}
+	if report == nil {
+		triage.report = &build.TestReport{Framework: backend, Failed: len(failures), Failures: failures}
+		triage.rawOutput = testOutput
+	}
+
+	candidates, passedOnRerun := flakyCandidates(failures, report)
+	retries := flakyRetries()
+	if retries == 0 || len(candidates)+len(passedOnRerun) > maxFlakyCandidates {
+		triage.blocking = slices.Concat(candidates, passedOnRerun)
+		return triage
+	}
+
+	runTests := tools.NewRunTestsTool(c.longRunningExecutor, c.buildService, workspacePath)
+	known := c.knownFlakyTests(ctx)
+	var flaky []build.TestCase
+	for i := range passedOnRerun {
+		c.logger.Info("🎲 Test %s failed but passed when its package was re-run - flaky", passedOnRerun[i].Name)
+		flaky = append(flaky, passedOnRerun[i])
+	}
+	for i := range candidates {
+		failure := candidates[i]
+		switch {
+		case c.passesInIsolation(ctx, runTests, backend, &failure, retries):
+			c.logger.Info("🎲 Test %s failed but passed on an isolated re-run - flaky", failure.Name)
+		case known[flakyKey(failure.Suite, failure.Name)]:
+			c.logger.Info("🎲 Test %s failed again but is a known flaky test", failure.Name)
+		default:
+			triage.blocking = append(triage.blocking, failure)
+			continue
+		}
+		flaky = append(flaky, failure)
+	}
+
+	changedFiles := []string(nil)
+	if len(flaky) > 0 {
+		changedFiles = c.storyChangedFiles(ctx, workspacePath)
+	}
+	for i := range flaky {
+		triage.flaky = append(triage.flaky, flaky[i])
+		if changedFiles != nil && !suiteTouched(flaky[i].Suite, changedFiles) {
+			triage.quarantined = append(triage.quarantined, flaky[i])
+		} else {
+			triage.blocking = append(triage.blocking, flaky[i])
+		}
+	}
+
+	c.recordFlakyTests(triage.flaky)
+	return triage
+}
+
+// flakyCandidates matches the failures of the original run against the structured re-run of the
+// failing packages. Failures that failed again, with the re-run's output, are candidates for
+// isolated re-runs; failures that passed in the re-run are returned separately. Without original
+// failures, every failure of the re-run is a candidate.
+func flakyCandidates(failures []build.TestCase, report *build.TestReport) (candidates, passedOnRerun []build.TestCase) {
+	if report == nil {
+		return failures, nil
+	}
+	if len(failures) == 0 {
+		return report.Failures, nil
+	}
+
+	matched := make([]bool, len(report.Failures))
+	for i := range failures {
+		rerun := -1
+		for j := range report.Failures {
+			if sameTest(&failures[i], &report.Failures[j]) {
+				rerun = j
+				break
+			}
+		}
+		if rerun < 0 {
+			passedOnRerun = append(passedOnRerun, failures[i])
+			continue
+		}
+		if !matched[rerun] {
+			matched[rerun] = true
+			candidates = append(candidates, report.Failures[rerun])
+		}
+	}
+	// Failures only the re-run saw are real failures too
+	for j := range report.Failures {
+		if !matched[j] {
+			candidates = append(candidates, report.Failures[j])
+		}
+	}
+	return candidates, passedOnRerun
+}
+
+// sameTest reports whether a failure parsed from plain test output is the same test as one from
+// a structured report. Suites may be named differently (pytest prints files, JUnit reports dotted
+// class names), so they only need to refer to the same code.
+func sameTest(parsed, reported *build.TestCase) bool {
+	if parsed.Name != reported.Name {
+		return false
+	}
+	return parsed.Suite == reported.Suite || parsed.Suite == "" || suiteTouched(reported.Suite, []string{parsed.Suite})
+}
+
+// flakyKey identifies a test in the flaky test history.
+func flakyKey(suite, name string) string {
+	return suite + "\x00" + name
+}
+
+// knownFlakyTests returns the tests recorded as flaky by earlier stories, keyed by flakyKey.
+// Returns an empty set when the history is unavailable.
+func (c *Coder) knownFlakyTests(ctx context.Context) map[string]bool {
+	known := make(map[string]bool)
+	tests, err := c.queryFlakyTests(ctx)
+	if err != nil {
+		c.logger.Warn("Failed to load known flaky tests: %v", err)
+		return known
+	}
+	for _, test := range tests {
+		known[flakyKey(test.Suite, test.Name)] = true
+	}
+	return known
+}
+
+// queryFlakyTests fetches the flaky test history from the database. It returns nil when no
+// persistence channel is configured.
+func (c *Coder) queryFlakyTests(ctx context.Context) ([]*persistence.FlakyTest, error) {
+	if c.persistenceChannel == nil {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, flakyLoadTimeout)
+	defer cancel()
+
+	response := make(chan interface{}, 1)
+	select {
+	case c.persistenceChannel <- &persistence.Request{Operation: persistence.OpGetFlakyTests, Response: response}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out sending flaky test query: %w", ctx.Err())
+	}
+
+	select {
+	case result := <-response:
+		switch value := result.(type) {
+		case []*persistence.FlakyTest:
+			return value, nil
+		case error:
+			return nil, value
+		default:
+			return nil, fmt.Errorf("unexpected flaky test query result %T", result)
+		}
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out waiting for flaky tests: %w", ctx.Err())
+	}
+}
+
+// passesInIsolation re-runs a single failing test up to retries times and reports whether any
+// run passed.
+func (c *Coder) passesInIsolation(ctx context.Context, runTests *tools.RunTestsTool, backend string, failure *build.TestCase, retries int) bool {
+	args := isolatedTestArgs(backend, failure)
+	for attempt := 1; attempt <= retries; attempt++ {
+		result, err := runTests.Exec(ctx, args)
+		if err != nil {
+			c.logger.Debug("Isolated re-run of %s failed to run: %v", failure.Name, err)
+			return false
+		}
+		resultMap, ok := result.(map[string]any)
+		if !ok {
+			return false
+		}
+		success, _ := resultMap["success"].(bool)
+		passed, _ := resultMap["passed"].(int)
+		if success && passed > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// isolatedTestArgs builds run_tests arguments that select only the given test.
+func isolatedTestArgs(backend string, failure *build.TestCase) map[string]any {
+	args := map[string]any{}
+	switch backend {
+	case "go":
+		// Anchor each level of the test name so siblings with a common prefix are not run
+		parts := strings.Split(failure.Name, "/")
+		for i, part := range parts {
+			parts[i] = "^" + regexp.QuoteMeta(part) + "$"
+		}
+		args["test"] = strings.Join(parts, "/")
+		if failure.Suite != "" {
+			args["package"] = failure.Suite
+		}
+	case "node":
+		args["test"] = "^" + regexp.QuoteMeta(failure.Name) + "$"
+		if failure.Suite != "" {
+			args["file"] = failure.Suite
+		}
+	default:
+		args["test"] = failure.Name
+	}
+	return args
+}
+
+// storyChangedFiles lists the files changed on the story branch, including uncommitted and
+// untracked files. Returns nil when the changes cannot be determined.
+func (c *Coder) storyChangedFiles(ctx context.Context, workspacePath string) []string {
+	baseBranch, err := c.getTargetBranch()
+	if err != nil {
+		baseBranch = "main"
+	}
+
+	base := baseBranch
+	if mergeBase, err := runCheckpointGit(ctx, c.longRunningExecutor, workspacePath, "merge-base", baseBranch, "HEAD"); err == nil && strings.TrimSpace(mergeBase) != "" {
+		base = strings.TrimSpace(mergeBase)
+	}
+
+	changed, err := runCheckpointGit(ctx, c.longRunningExecutor, workspacePath, "diff", "--name-only", base)
+	if err != nil {
+		c.logger.Warn("Failed to list changed files, treating flaky tests as blocking: %v", err)
+		return nil
+	}
+	files := strings.Fields(changed)
+	if untracked, err := runCheckpointGit(ctx, c.longRunningExecutor, workspacePath, "ls-files", "--others", "--exclude-standard"); err == nil {
+		files = append(files, strings.Fields(untracked)...)
+	}
+	return files
+}
+
+// suiteTouched reports whether a test suite lies in code changed by the story. Suites are Go
+// package import paths, Python dotted module or class names, or JavaScript test file paths;
+// they match a changed file by directory or module path suffix. An unknown suite counts as
+// touched so that flaky tests are only ignored when the story clearly did not affect them.
+func suiteTouched(suite string, changedFiles []string) bool {
+	if suite == "" {
+		return true
+	}
+
+	suitePath := strings.TrimSuffix(suite, "/")
+	if !strings.Contains(suitePath, "/") {
+		suitePath = strings.ReplaceAll(suitePath, ".", "/")
+	}
+	candidates := []string{suitePath, strings.TrimSuffix(suitePath, path.Ext(suitePath)), path.Dir(suitePath)}
+
+	for _, file := range changedFiles {
+		targets := []string{strings.TrimSuffix(file, path.Ext(file))}
+		if dir := path.Dir(file); dir != "." {
+			targets = append(targets, dir)
+		}
+		for _, candidate := range candidates {
+			for _, target := range targets {
+				if candidate == target || strings.HasSuffix(candidate, "/"+target) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// recordFlakyTests persists flaky tests and reports them to the architect, which schedules
+// a fix-up story for them.
+func (c *Coder) recordFlakyTests(flaky []build.TestCase) {
+	if len(flaky) == 0 {
+		return
+	}
+
+	storyID := c.GetStoryID()
+	reported := make([]proto.FlakyTest, 0, len(flaky))
+	for i := range flaky {
+		output := truncateOutput(flaky[i].Output)
+		persistence.PersistFlakyTest(&persistence.FlakyTest{
+			Suite:       flaky[i].Suite,
+			Name:        flaky[i].Name,
+			LastStoryID: storyID,
+			LastOutput:  output,
+			LastSeen:    time.Now(),
+		}, c.persistenceChannel)
+		reported = append(reported, proto.FlakyTest{Suite: flaky[i].Suite, Name: flaky[i].Name, Output: output})
+	}
+
+	if c.dispatcher == nil {
+		return
+	}
+	if err := c.dispatcher.ReportFlakyTests(storyID, c.agentID, reported); err != nil {
+		c.logger.Warn("Failed to report flaky tests: %v", err)
+	}
+}
+
+// flakySummary describes the flaky tests of a triage for code review, or returns an empty
+// string when there were none.
+func (t *testTriage) flakySummary() string {
+	if len(t.flaky) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	for i := range t.flaky {
+		status := "blocking: in code changed by this story"
+		for j := range t.quarantined {
+			if t.quarantined[j].Name == t.flaky[i].Name && t.quarantined[j].Suite == t.flaky[i].Suite {
+				status = "ignored: not touched by this story, reported to the architect"
+				break
+			}
+		}
+		sb.WriteString(fmt.Sprintf("- %s", t.flaky[i].Name))
+		if t.flaky[i].Suite != "" {
+			sb.WriteString(fmt.Sprintf(" (%s)", t.flaky[i].Suite))
+		}
+		sb.WriteString(fmt.Sprintf(" - %s\n", status))
+	}
+	return sb.String()
+}
+
+// hasBlocking reports whether any failure should send the story back to the coder.
+func (t *testTriage) hasBlocking() bool {
+	return len(t.blocking) > 0
+}
+
+// failureMessage summarizes the blocking failures for the coder, noting any quarantined
+// flaky tests so the coder does not chase them.
+func (t *testTriage) failureMessage() string {
+	blocking := *t.report
+	blocking.Failed = len(t.blocking)
+	blocking.Failures = t.blocking
+	message := blocking.Summary()
+	flakyBlocking := len(t.flaky) - len(t.quarantined)
+	if flakyBlocking > 0 {
+		message += fmt.Sprintf("\n\n%d of these tests failed intermittently (they passed when re-run or are known to be flaky) and are in code this story changed - make them reliable.", flakyBlocking)
+	}
+	if len(t.quarantined) > 0 {
+		message += fmt.Sprintf("\n\nIgnored %d flaky test(s) outside the code this story changed; they have been reported for a separate fix.", len(t.quarantined))
+	}
+	if t.rawOutput != "" {
+		message += "\n\nTest output:\n" + truncateOutput(t.rawOutput)
+	}
+	return message
+}
+
+// buildFlakyTestsSection lists flaky tests seen while testing the story for code review.
+func (c *Coder) buildFlakyTestsSection() string {
+	summary := utils.GetStateValueOr[string](c.BaseStateMachine, KeyFlakyTests, "")
+	if summary == "" {
+		return ""
+	}
+	return "\n\n## Flaky Tests\nThese tests failed and then passed when re-run, or are known to be flaky:\n" + summary
+}
@@ -0,0 +1,122 @@
+package coder
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"orchestrator/pkg/build"
+	"orchestrator/pkg/logx"
+	"orchestrator/pkg/persistence"
+)
+
+func TestSuiteTouched(t *testing.T) {
+	changed := []string{"pkg/cache/lru.go", "src/api/client.js", "tests/test_math.py", "README.md"}
+
+	cases := map[string]bool{
+		"example.com/app/pkg/cache":         true,  // Go package containing a changed file
+		"example.com/app/pkg/cache/sub":     true,  // Go subpackage of a changed package
+		"example.com/app/pkg/server":        false, // Go package the story did not change
+		"/workspace/src/api/client.test.js": true,  // Jest file next to a changed file
+		"/workspace/src/ui/button.test.js":  false,
+		"tests.test_math":                   true, // Python module that was changed
+		"tests.test_math.TestAdd":           true, // Python class in a changed module
+		"other.test_strings":                false,
+		"":                                  true, // Unknown suites are never ignored
+	}
+	for suite, want := range cases {
+		if got := suiteTouched(suite, changed); got != want {
+			t.Errorf("suiteTouched(%q) = %v, want %v", suite, got, want)
+		}
+	}
+}
+
+func TestIsolatedTestArgs(t *testing.T) {
+	goArgs := isolatedTestArgs("go", &build.TestCase{Name: "TestParse/empty.input", Suite: "example.com/app/pkg/parse"})
+	if goArgs["test"] != `^TestParse$/^empty\.input$` || goArgs["package"] != "example.com/app/pkg/parse" {
+		t.Errorf("Unexpected Go args: %v", goArgs)
+	}
+
+	nodeArgs := isolatedTestArgs("node", &build.TestCase{Name: "client retries (x2)", Suite: "/workspace/src/client.test.js"})
+	if nodeArgs["test"] != `^client retries \(x2\)$` || nodeArgs["file"] != "/workspace/src/client.test.js" {
+		t.Errorf("Unexpected Jest args: %v", nodeArgs)
+	}
+
+	pythonArgs := isolatedTestArgs("python", &build.TestCase{Name: "test_add", Suite: "tests.test_math"})
+	if pythonArgs["test"] != "test_add" || len(pythonArgs) != 1 {
+		t.Errorf("Unexpected pytest args: %v", pythonArgs)
+	}
+}
+
+func TestTestTriageMessages(t *testing.T) {
+	broken := build.TestCase{Name: "TestBroken", Suite: "app/pkg/a", Output: "expected 1, got 2"}
+	racy := build.TestCase{Name: "TestRacy", Suite: "app/pkg/a"}
+	elsewhere := build.TestCase{Name: "TestTimeout", Suite: "app/pkg/b"}
+
+	triage := &testTriage{
+		report:      &build.TestReport{Framework: "go", Passed: 10, Failed: 3, Failures: []build.TestCase{broken, racy, elsewhere}},
+		blocking:    []build.TestCase{broken, racy},
+		flaky:       []build.TestCase{racy, elsewhere},
+		quarantined: []build.TestCase{elsewhere},
+	}
+	if !triage.hasBlocking() {
+		t.Fatal("Expected the triage to block")
+	}
+
+	message := triage.failureMessage()
+	if !strings.Contains(message, "10 passed, 2 failed") || strings.Contains(message, "FAIL TestTimeout") {
+		t.Errorf("Expected only blocking failures in the message, got:\n%s", message)
+	}
+	if !strings.Contains(message, "1 of these tests failed intermittently") || !strings.Contains(message, "Ignored 1 flaky test") {
+		t.Errorf("Expected the message to explain the flaky tests, got:\n%s", message)
+	}
+
+	summary := triage.flakySummary()
+	if !strings.Contains(summary, "TestRacy (app/pkg/a) - blocking") || !strings.Contains(summary, "TestTimeout (app/pkg/b) - ignored") {
+		t.Errorf("Unexpected flaky summary:\n%s", summary)
+	}
+}
+
+func TestFlakyCandidates(t *testing.T) {
+	// Parsed from `make test`: pytest prints files, the JUnit re-run reports dotted classes
+	failures := []build.TestCase{
+		{Name: "test_div", Suite: "tests/test_math.py"},
+		{Name: "test_timeout", Suite: "tests/test_net.py"},
+	}
+	report := &build.TestReport{Framework: "python", Passed: 5, Failed: 2, Failures: []build.TestCase{
+		{Name: "test_div", Suite: "tests.test_math", Output: "assert 1 == 2"},
+		{Name: "test_other", Suite: "tests.test_math", Output: "boom"},
+	}}
+
+	candidates, passed := flakyCandidates(failures, report)
+	if len(candidates) != 2 || candidates[0].Output != "assert 1 == 2" || candidates[1].Name != "test_other" {
+		t.Errorf("Expected the re-run failures as candidates, got %+v", candidates)
+	}
+	if len(passed) != 1 || passed[0].Name != "test_timeout" {
+		t.Errorf("Expected test_timeout to have passed on the re-run, got %+v", passed)
+	}
+
+	// Without a structured re-run every original failure is a candidate
+	if candidates, passed := flakyCandidates(failures, nil); len(candidates) != 2 || passed != nil {
+		t.Errorf("Expected original failures as candidates, got %+v / %+v", candidates, passed)
+	}
+}
+
+func TestKnownFlakyTests(t *testing.T) {
+	persistenceChannel := make(chan *persistence.Request, 1)
+	c := &Coder{logger: logx.NewLogger("test-coder"), persistenceChannel: persistenceChannel}
+
+	go func() {
+		req := <-persistenceChannel
+		if req.Operation != persistence.OpGetFlakyTests {
+			req.Response <- nil
+			return
+		}
+		req.Response <- []*persistence.FlakyTest{{Suite: "app/pkg/a", Name: "TestRacy", Occurrences: 3}}
+	}()
+
+	known := c.knownFlakyTests(context.Background())
+	if !known[flakyKey("app/pkg/a", "TestRacy")] || known[flakyKey("app/pkg/a", "TestBroken")] {
+		t.Errorf("Unexpected known flaky tests: %v", known)
+	}
+}
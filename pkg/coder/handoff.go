@@ -0,0 +1,210 @@
+package coder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"orchestrator/pkg/agent"
+	"orchestrator/pkg/config"
+	"orchestrator/pkg/persistence"
+	"orchestrator/pkg/utils"
+)
+
+const (
+	// handoffLoadTimeout bounds how long SETUP waits for the database to return handoffs.
+	handoffLoadTimeout = 5 * time.Second
+
+	// maxHandoffsShown bounds how many earlier attempts are described to the next coder.
+	maxHandoffsShown = 2
+
+	// maxHandoffText bounds each free-text field of a handoff record.
+	maxHandoffText = 2000
+)
+
+// handoffBranchEnabled reports whether an abandoned attempt's work is pushed so the next
+// coder starts from it instead of the target branch.
+func handoffBranchEnabled() bool {
+	cfg, err := config.GetConfig()
+	if err != nil || cfg.Agents == nil {
+		return false
+	}
+	return cfg.Agents.HandoffBranch
+}
+
+// recordHandoff stores what this attempt at the story tried and why it was abandoned, so the
+// coder that picks up the requeued story does not start from zero.
+func (c *Coder) recordHandoff(ctx context.Context, sm *agent.BaseStateMachine, reason string) {
+	storyID := utils.GetStateValueOr[string](sm, KeyStoryID, "")
+	if storyID == "" {
+		return
+	}
+
+	handoff := &persistence.StoryHandoff{
+		StoryID:        storyID,
+		AgentID:        c.agentID,
+		Reason:         truncateHandoffText(reason),
+		Attempted:      truncateHandoffText(c.describeAttempt(sm)),
+		LastTestOutput: truncateHandoffText(utils.GetStateValueOr[string](sm, KeyTestOutput, "")),
+		BranchName:     utils.GetStateValueOr[string](sm, KeyLocalBranchName, ""),
+		CreatedAt:      time.Now(),
+	}
+
+	if c.longRunningExecutor != nil && c.containerName != "" {
+		handoff.Files = c.storyChangedFiles(ctx, c.workDir)
+		if handoffBranchEnabled() && len(handoff.Files) > 0 && handoff.BranchName != "" {
+			handoff.BranchPushed = c.pushHandoffBranch(ctx, sm, handoff.BranchName)
+		}
+	}
+
+	persistence.PersistStoryHandoff(handoff, c.persistenceChannel)
+	c.logger.Info("🤝 Recorded handoff for story %s (%d changed files)", storyID, len(handoff.Files))
+}
+
+// describeAttempt summarizes the approach and progress of the current attempt.
+func (c *Coder) describeAttempt(sm *agent.BaseStateMachine) string {
+	var sb strings.Builder
+	if plan := utils.GetStateValueOr[string](sm, KeyPlan, ""); plan != "" {
+		sb.WriteString("Plan:\n")
+		sb.WriteString(plan)
+		sb.WriteString("\n")
+	}
+	if todos := getPlanTodos(sm); len(todos) > 0 {
+		sb.WriteString("\nTodos:\n")
+		for i := range todos {
+			status := string(todos[i].Status)
+			if status == "" {
+				status = "pending"
+			}
+			sb.WriteString(fmt.Sprintf("- [%s] %s", status, todos[i].Description))
+			if todos[i].Note != "" {
+				sb.WriteString(fmt.Sprintf(" (%s)", todos[i].Note))
+			}
+			sb.WriteString("\n")
+		}
+	}
+	if c.contextManager != nil && len(c.contextManager.GetMessages()) > 0 {
+		sb.WriteString("\nLast tool activity:\n")
+		sb.WriteString(c.getRecentToolActivity(5))
+	}
+	if sb.Len() == 0 {
+		return "The attempt ended before a plan was approved."
+	}
+	return sb.String()
+}
+
+// pushHandoffBranch commits the attempt's work and pushes the story branch so the next
+// coder can continue from it. Reports whether the push succeeded.
+func (c *Coder) pushHandoffBranch(ctx context.Context, sm *agent.BaseStateMachine, branch string) bool {
+	c.createWIPCheckpoint(ctx, "Handoff of abandoned attempt")
+	remoteBranch := utils.GetStateValueOr[string](sm, KeyRemoteBranchName, branch)
+	if err := c.pushBranch(ctx, branch, remoteBranch); err != nil {
+		c.logger.Warn("Failed to push handoff branch %s: %v", remoteBranch, err)
+		return false
+	}
+	return true
+}
+
+// loadStoryHandoffs loads the handoffs of earlier attempts at the story for the planning
+// template. When branch handoff is enabled and the last attempt pushed its work, the new
+// story branch starts from that work.
+func (c *Coder) loadStoryHandoffs(ctx context.Context, sm *agent.BaseStateMachine, storyID string) {
+	handoffs, err := c.queryStoryHandoffs(ctx, storyID)
+	if err != nil {
+		c.logger.Warn("Failed to load handoffs for story %s: %v", storyID, err)
+		return
+	}
+	if len(handoffs) == 0 {
+		return
+	}
+	c.logger.Info("🤝 Story %s has %d earlier abandoned attempts", storyID, len(handoffs))
+
+	latest := handoffs[0]
+	continued := false
+	if handoffBranchEnabled() && latest.BranchPushed && latest.BranchName != "" && c.cloneManager != nil {
+		if err := c.cloneManager.ContinueFromBranch(ctx, c.workDir, latest.BranchName); err != nil {
+			c.logger.Warn("Starting from the target branch, could not continue from %s: %v", latest.BranchName, err)
+		} else {
+			c.logger.Info("🤝 Continuing from the work of the previous attempt on %s", latest.BranchName)
+			continued = true
+		}
+	}
+	sm.SetStateData(KeyHandoffNotes, formatHandoffs(handoffs, continued))
+}
+
+// queryStoryHandoffs fetches a story's handoff records from the database, most recent first.
+// It returns nil when no persistence channel is configured.
+func (c *Coder) queryStoryHandoffs(ctx context.Context, storyID string) ([]*persistence.StoryHandoff, error) {
+	if c.persistenceChannel == nil {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, handoffLoadTimeout)
+	defer cancel()
+
+	response := make(chan interface{}, 1)
+	select {
+	case c.persistenceChannel <- &persistence.Request{Operation: persistence.OpGetStoryHandoffs, Data: storyID, Response: response}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out sending handoff query: %w", ctx.Err())
+	}
+
+	select {
+	case result := <-response:
+		switch value := result.(type) {
+		case []*persistence.StoryHandoff:
+			return value, nil
+		case error:
+			return nil, value
+		default:
+			return nil, fmt.Errorf("unexpected handoff query result %T", result)
+		}
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out waiting for handoffs: %w", ctx.Err())
+	}
+}
+
+// formatHandoffs describes the most recent abandoned attempts for the planning template.
+func formatHandoffs(handoffs []*persistence.StoryHandoff, continued bool) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("This story was attempted %d time(s) before and abandoned. Learn from what was tried instead of repeating it.\n", len(handoffs)))
+	if continued {
+		sb.WriteString(fmt.Sprintf("Your workspace continues from the work of the last attempt (branch %s), staged but not committed; review it with the git tool before building on it.\n", handoffs[0].BranchName))
+	}
+
+	for i, handoff := range handoffs {
+		if i == maxHandoffsShown {
+			sb.WriteString(fmt.Sprintf("\n(%d older attempts not shown)\n", len(handoffs)-maxHandoffsShown))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("\n### Attempt by %s (%s)\n", handoff.AgentID, handoff.CreatedAt.UTC().Format(time.RFC3339)))
+		sb.WriteString(fmt.Sprintf("**Why it was abandoned:** %s\n", handoff.Reason))
+		if handoff.BranchName != "" {
+			sb.WriteString(fmt.Sprintf("**Branch:** %s\n", handoff.BranchName))
+		}
+		if len(handoff.Files) > 0 {
+			sb.WriteString(fmt.Sprintf("**Files changed:** %s\n", strings.Join(handoff.Files, ", ")))
+		}
+		if handoff.Attempted != "" {
+			sb.WriteString("\n**What was tried:**\n")
+			sb.WriteString(handoff.Attempted)
+			sb.WriteString("\n")
+		}
+		if handoff.LastTestOutput != "" {
+			sb.WriteString("\n**Last test output:**\n```\n")
+			sb.WriteString(handoff.LastTestOutput)
+			sb.WriteString("\n```\n")
+		}
+	}
+	return sb.String()
+}
+
+// truncateHandoffText bounds a handoff field, keeping its end where failures are reported.
+func truncateHandoffText(text string) string {
+	text = strings.TrimSpace(text)
+	if len(text) <= maxHandoffText {
+		return text
+	}
+	return "... (truncated)\n" + text[len(text)-maxHandoffText:]
+}
@@ -0,0 +1,109 @@
+package coder
+
+import (
+	"context"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"orchestrator/pkg/persistence"
+)
+
+func TestFormatHandoffs(t *testing.T) {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	handoffs := []*persistence.StoryHandoff{
+		{
+			AgentID:        "coder-002",
+			Reason:         "Coder error: budget exceeded",
+			Attempted:      "Plan:\nAdd an LRU cache",
+			LastTestOutput: "FAIL TestEvict",
+			BranchName:     "story-042-2",
+			Files:          []string{"cache/lru.go", "cache/lru_test.go"},
+			CreatedAt:      created,
+		},
+		{AgentID: "coder-001", Reason: "Escalation timeout exceeded", CreatedAt: created},
+		{AgentID: "coder-003", Reason: "oldest", CreatedAt: created},
+	}
+
+	text := formatHandoffs(handoffs, true)
+	for _, want := range []string{
+		"attempted 3 time(s)",
+		"continues from the work of the last attempt (branch story-042-2), staged but not committed",
+		"### Attempt by coder-002 (2025-01-02T03:04:05Z)",
+		"**Why it was abandoned:** Coder error: budget exceeded",
+		"**Files changed:** cache/lru.go, cache/lru_test.go",
+		"Add an LRU cache",
+		"FAIL TestEvict",
+		"### Attempt by coder-001",
+		"(1 older attempts not shown)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("handoff text missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "oldest") {
+		t.Errorf("handoff text should only describe the %d most recent attempts:\n%s", maxHandoffsShown, text)
+	}
+
+	if text := formatHandoffs(handoffs[1:2], false); strings.Contains(text, "continues from") || strings.Contains(text, "**Branch:**") {
+		t.Errorf("unexpected branch details for an attempt without a branch:\n%s", text)
+	}
+}
+
+func TestTruncateHandoffTextKeepsTail(t *testing.T) {
+	text := strings.Repeat("x", maxHandoffText) + "FAIL at the end"
+	got := truncateHandoffText(text)
+	if !strings.HasSuffix(got, "FAIL at the end") || !strings.HasPrefix(got, "... (truncated)") {
+		t.Errorf("truncated text should keep the tail, got prefix %q", got[:20])
+	}
+	if got := truncateHandoffText("  short  "); got != "short" {
+		t.Errorf("truncateHandoffText(short) = %q", got)
+	}
+}
+
+func TestContinueFromBranch(t *testing.T) {
+	if _, err := osexec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	seed := filepath.Join(root, "seed")
+	clone := filepath.Join(root, "clone")
+	gitOutput(t, root, "init", "-q", "--bare", "-b", "main", remote)
+	gitOutput(t, root, "clone", "-q", remote, seed)
+	gitOutput(t, seed, "config", "user.email", "test@example.com")
+	gitOutput(t, seed, "config", "user.name", "Test")
+	writeSeed := func(name string) {
+		if err := os.WriteFile(filepath.Join(seed, name), []byte("package app\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		gitOutput(t, seed, "add", ".")
+		gitOutput(t, seed, "commit", "-q", "-m", "add "+name)
+	}
+	writeSeed("main.go")
+	gitOutput(t, seed, "push", "-q", "origin", "main")
+
+	// The clone predates the handoff branch, like a clone made from a stale mirror
+	gitOutput(t, root, "clone", "-q", remote, clone)
+	gitOutput(t, seed, "switch", "-q", "-c", "story-042")
+	writeSeed("feature.go")
+	gitOutput(t, seed, "push", "-q", "origin", "story-042")
+
+	cm := NewCloneManager(NewDefaultGitRunner(), root, remote, "main", ".mirrors", "story-{STORY_ID}")
+	ctx := context.Background()
+	if err := cm.ContinueFromBranch(ctx, clone, "story-042"); err != nil {
+		t.Fatalf("ContinueFromBranch failed: %v", err)
+	}
+	if staged := gitOutput(t, clone, "diff", "--cached", "--name-only"); staged != "feature.go" {
+		t.Errorf("Expected the previous attempt's work to be staged, got %q", staged)
+	}
+
+	err := cm.ContinueFromBranch(ctx, clone, "story-missing")
+	if err == nil || !strings.Contains(err.Error(), "does not exist on origin") {
+		t.Errorf("Expected a missing branch to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,318 @@
+package coder
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"orchestrator/pkg/agent"
+	"orchestrator/pkg/effect"
+	"orchestrator/pkg/proto"
+	"orchestrator/pkg/tools"
+	"orchestrator/pkg/utils"
+)
+
+const (
+	// loopWindow is how many recent tool calls are compared when looking for a loop.
+	loopWindow = 12
+
+	// loopThreshold is how many times the same failure must occur before the coder is warned.
+	loopThreshold = 3
+
+	// loopEscalationRepeats is how many more times a failure must occur after the warning
+	// before the loop is escalated to BUDGET_REVIEW.
+	loopEscalationRepeats = 2
+
+	// maxFingerprintText bounds the normalized text kept for each tool call.
+	maxFingerprintText = 200
+)
+
+//nolint:gochecknoglobals // Precompiled normalization patterns
+var (
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?|\b\d{2}:\d{2}:\d{2}(\.\d+)?\b`)
+	durationPattern  = regexp.MustCompile(`\b\d+(\.\d+)?(ns|µs|us|ms|s|m|h)\b`)
+	tempPathPattern  = regexp.MustCompile(`/tmp/[^\s:'"()]+`)
+	hexPattern       = regexp.MustCompile(`\b0x[0-9a-fA-F]+\b|\b[0-9a-f]{7,64}\b`)
+	spacePattern     = regexp.MustCompile(`\s+`)
+	errorLinePattern = regexp.MustCompile(`(?i)error|fail|panic|undefined|cannot|not found|no such|exception|traceback|denied`)
+)
+
+// toolFingerprint is the normalized identity of a tool call and its outcome.
+type toolFingerprint struct {
+	action string // Normalized shell command, or tool name and arguments
+	failed bool
+	errSig string // Normalized key line of a failure's output
+}
+
+// failureLoop describes a failure the coder keeps repeating.
+type failureLoop struct {
+	action  string // Set when the same call failed each time, empty when different calls hit the same error
+	errSig  string
+	repeats int
+}
+
+// loopDetector fingerprints recent tool calls to notice when the coder keeps repeating the
+// same failure, normalizing away paths, timestamps and other run-specific noise.
+type loopDetector struct {
+	warned  map[string]int // Repeat count at the time of the warning, by failure signature
+	recent  []toolFingerprint
+	workDir string
+}
+
+// newLoopDetector creates a loop detector that normalizes paths under workDir.
+func newLoopDetector(workDir string) *loopDetector {
+	return &loopDetector{workDir: workDir, warned: make(map[string]int)}
+}
+
+// observe records a tool call and its outcome. result is the tool result, or nil when the
+// call returned execErr.
+func (d *loopDetector) observe(toolCall *agent.ToolCall, result any, execErr error) {
+	fp := toolFingerprint{action: d.normalize(describeToolCall(toolCall))}
+
+	switch {
+	case execErr != nil:
+		fp.failed = true
+		fp.errSig = d.errorSignature(execErr.Error())
+	default:
+		if resultMap, ok := result.(map[string]any); ok {
+			if output, failed := toolFailureOutput(resultMap); failed {
+				fp.failed = true
+				fp.errSig = d.errorSignature(output)
+			}
+		}
+	}
+
+	d.recent = append(d.recent, fp)
+	if len(d.recent) > loopWindow {
+		d.recent = d.recent[len(d.recent)-loopWindow:]
+	}
+}
+
+// detect returns the most repeated failure among recent tool calls, or nil when no failure
+// occurred loopThreshold times.
+func (d *loopDetector) detect() *failureLoop {
+	byCall := make(map[string]int)
+	byError := make(map[string]int)
+	for _, fp := range d.recent {
+		if !fp.failed {
+			continue
+		}
+		byCall[fp.action+"\x00"+fp.errSig]++
+		byError[fp.errSig]++
+	}
+
+	var loop *failureLoop
+	for key, count := range byCall {
+		if count >= loopThreshold && (loop == nil || count > loop.repeats) {
+			parts := strings.SplitN(key, "\x00", 2)
+			loop = &failureLoop{action: parts[0], errSig: parts[1], repeats: count}
+		}
+	}
+	for errSig, count := range byError {
+		// Different commands hitting one error only counts when it is more telling than a repeated call
+		if count >= loopThreshold && (loop == nil || count > loop.repeats) {
+			loop = &failureLoop{errSig: errSig, repeats: count}
+		}
+	}
+	return loop
+}
+
+// warn records that the coder was warned about loop and reports whether this is the first
+// warning for it.
+func (d *loopDetector) warn(loop *failureLoop) bool {
+	if _, warned := d.warned[loop.errSig]; warned {
+		return false
+	}
+	d.warned[loop.errSig] = loop.repeats
+	return true
+}
+
+// persisted reports whether loop continued after the coder was warned about it.
+func (d *loopDetector) persisted(loop *failureLoop) bool {
+	warnedAt, warned := d.warned[loop.errSig]
+	return warned && loop.repeats >= warnedAt+loopEscalationRepeats
+}
+
+// reset forgets recent calls and warnings, e.g. after the architect gave guidance.
+func (d *loopDetector) reset() {
+	d.recent = nil
+	d.warned = make(map[string]int)
+}
+
+// evidence lists the recent tool calls that make up loop, for the architect.
+func (d *loopDetector) evidence(loop *failureLoop) string {
+	var sb strings.Builder
+	if loop.action != "" {
+		sb.WriteString(fmt.Sprintf("Ran `%s` %d times, failing each time with: %s\n", loop.action, loop.repeats, loop.errSig))
+	} else {
+		sb.WriteString(fmt.Sprintf("Hit the same error %d times: %s\n", loop.repeats, loop.errSig))
+	}
+	sb.WriteString("Recent tool calls (oldest first):\n")
+	for _, fp := range d.recent {
+		outcome := "ok"
+		if fp.failed {
+			outcome = "failed: " + fp.errSig
+		}
+		sb.WriteString(fmt.Sprintf("- %s -> %s\n", fp.action, outcome))
+	}
+	return sb.String()
+}
+
+// normalize strips run-specific noise from text so equivalent calls and failures compare equal.
+func (d *loopDetector) normalize(text string) string {
+	if d.workDir != "" {
+		text = strings.ReplaceAll(text, strings.TrimSuffix(d.workDir, "/")+"/", "")
+	}
+	text = strings.ReplaceAll(text, "/workspace/", "")
+	text = timestampPattern.ReplaceAllString(text, "<time>")
+	text = durationPattern.ReplaceAllString(text, "<duration>")
+	text = tempPathPattern.ReplaceAllString(text, "<tmp>")
+	text = hexPattern.ReplaceAllString(text, "<hex>")
+	text = strings.TrimSpace(spacePattern.ReplaceAllString(text, " "))
+	if len(text) > maxFingerprintText {
+		text = text[:maxFingerprintText]
+	}
+	return text
+}
+
+// errorSignature picks the line of a failure's output that identifies the error: the first
+// line that looks like an error, or the last line otherwise.
+func (d *loopDetector) errorSignature(output string) string {
+	var last string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if errorLinePattern.MatchString(line) {
+			return d.normalize(line)
+		}
+		last = line
+	}
+	if last == "" {
+		return "(no output)"
+	}
+	return d.normalize(last)
+}
+
+// describeToolCall renders a tool call as the command it ran, or its name and arguments.
+func describeToolCall(toolCall *agent.ToolCall) string {
+	if toolCall.Name == tools.ToolShell {
+		if cmd := utils.GetMapFieldOr[string](toolCall.Parameters, "cmd", ""); cmd != "" {
+			return cmd
+		}
+	}
+
+	keys := make([]string, 0, len(toolCall.Parameters))
+	for key := range toolCall.Parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	args := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := fmt.Sprintf("%v", toolCall.Parameters[key])
+		if len(value) > 60 {
+			value = value[:60]
+		}
+		args = append(args, fmt.Sprintf("%s=%s", key, value))
+	}
+	return strings.TrimSpace(toolCall.Name + " " + strings.Join(args, " "))
+}
+
+// toolFailureOutput reports whether a tool result is a failure, and the output describing it.
+func toolFailureOutput(resultMap map[string]any) (string, bool) {
+	if exitCode, ok := resultMap["exit_code"].(int); ok {
+		if exitCode == 0 {
+			return "", false
+		}
+		stdout, _ := resultMap["stdout"].(string)
+		stderr, _ := resultMap["stderr"].(string)
+		output := strings.TrimSpace(stderr + "\n" + stdout)
+		if output == "" {
+			output = fmt.Sprintf("exit code %d", exitCode)
+		}
+		return output, true
+	}
+
+	if success, ok := resultMap["success"].(bool); ok && !success {
+		errorMsg, _ := resultMap["error"].(string)
+		output, _ := resultMap["output"].(string)
+		return strings.TrimSpace(errorMsg + "\n" + output), true
+	}
+	return "", false
+}
+
+// loopIntervention tells the coder about the loop it is in and suggests a way out.
+func loopIntervention(loop *failureLoop) string {
+	var message string
+	if loop.action != "" {
+		message = fmt.Sprintf("You have run `%s` %d times and it failed the same way each time: %s\nRunning it again unchanged will not help.", loop.action, loop.repeats, loop.errSig)
+	} else {
+		message = fmt.Sprintf("You have hit the same error %d times: %s\nThe changes made between attempts did not fix it.", loop.repeats, loop.errSig)
+	}
+	return message + "\nConsider: " + loopSuggestion(loop.errSig) + "\nIf the loop continues, the architect will be asked to review your progress."
+}
+
+// loopSuggestion proposes a different approach based on the kind of error.
+func loopSuggestion(errSig string) string {
+	lower := strings.ToLower(errSig)
+	switch {
+	case strings.Contains(lower, "command not found") || strings.Contains(lower, "executable file not found"):
+		return "the command is not available in the container. Check with `which`, use a different tool, or ask the architect with ask_question."
+	case strings.Contains(lower, "no such file") || strings.Contains(lower, "not found"):
+		return "check the path exists with `ls` or `find` before using it; it may be relative to a different directory."
+	case strings.Contains(lower, "undefined") || strings.Contains(lower, "cannot use") || strings.Contains(lower, "syntax error") ||
+		strings.Contains(lower, "cannot find") || strings.Contains(lower, "import"):
+		return "read the code at the reported location and the definitions it refers to before editing again; the real cause may be in another file."
+	case strings.Contains(lower, "fail") || strings.Contains(lower, "assert") || strings.Contains(lower, "expected"):
+		return "run only the failing test with verbose output, compare the expected and actual values, and re-read the requirement it checks."
+	case strings.Contains(lower, "denied"):
+		return "the operation is not permitted here; find another way to do it or ask the architect with ask_question."
+	default:
+		return "stepping back to re-read the output and your plan, and trying a different approach or asking with ask_question."
+	}
+}
+
+// observeToolCall feeds a tool call of the current story to the loop detector.
+func (c *Coder) observeToolCall(toolCall *agent.ToolCall, result any, execErr error) {
+	if c.loopDetector == nil {
+		c.loopDetector = newLoopDetector(c.workDir)
+	}
+	c.loopDetector.observe(toolCall, result, execErr)
+}
+
+// checkFailureLoop looks for a failure the coder keeps repeating. The first time a loop is
+// seen the coder is told about it; if it continues, the loop is escalated to BUDGET_REVIEW
+// with its evidence.
+func (c *Coder) checkFailureLoop(sm *agent.BaseStateMachine, origin proto.State) (*effect.BudgetReviewEffect, bool) {
+	if c.loopDetector == nil {
+		return nil, false
+	}
+	loop := c.loopDetector.detect()
+	if loop == nil {
+		return nil, false
+	}
+
+	if c.loopDetector.warn(loop) {
+		c.logger.Info("🔁 Failure loop detected (%d repeats): %s", loop.repeats, loop.errSig)
+		c.contextManager.AddMessage("system", loopIntervention(loop))
+		return nil, false
+	}
+	if !c.loopDetector.persisted(loop) {
+		return nil, false
+	}
+
+	c.logger.Info("🔁 Failure loop persisted after warning (%d repeats), escalating to BUDGET_REVIEW", loop.repeats)
+	evidence := c.loopDetector.evidence(loop)
+	budgetReviewEff := effect.NewLoopDetectedBudgetReviewEffect(string(origin), evidence, loop.repeats)
+	budgetReviewEff.Content = c.buildBudgetReviewContent(sm, origin, budgetReviewEff.Content)
+	budgetReviewEff.StoryID = utils.GetStateValueOr[string](sm, KeyStoryID, "")
+	budgetReviewEff.ExtraPayload["story_id"] = budgetReviewEff.StoryID
+	budgetReviewEff.ExtraPayload["recent_activity"] = c.getRecentToolActivity(5)
+	budgetReviewEff.ExtraPayload["context_size"] = c.contextManager.CountTokens()
+
+	sm.SetStateData(KeyOrigin, string(origin))
+	c.loopDetector.reset()
+	return budgetReviewEff, true
+}
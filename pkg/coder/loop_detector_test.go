@@ -0,0 +1,99 @@
+package coder
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"orchestrator/pkg/agent"
+	"orchestrator/pkg/tools"
+)
+
+func shellCall(cmd string) *agent.ToolCall {
+	return &agent.ToolCall{Name: tools.ToolShell, Parameters: map[string]any{"cmd": cmd}}
+}
+
+func shellResult(exitCode int, stderr string) map[string]any {
+	return map[string]any{"exit_code": exitCode, "stdout": "", "stderr": stderr}
+}
+
+func TestLoopDetectorNormalizesNoise(t *testing.T) {
+	d := newLoopDetector("/home/agent/work")
+	a := d.normalize("2025-01-02T03:04:05Z /home/agent/work/pkg/x.go: build failed in 1.23s (/tmp/go-build123/b001) at 0xc000123")
+	b := d.normalize("2025-03-04T05:06:07Z /workspace/pkg/x.go:  build failed in 0.5s (/tmp/go-build999/b002) at 0xc000999")
+	if a != b {
+		t.Errorf("normalized texts differ:\n%q\n%q", a, b)
+	}
+}
+
+func TestLoopDetectorRepeatedCommand(t *testing.T) {
+	d := newLoopDetector("/workspace")
+	for i := 0; i < loopThreshold-1; i++ {
+		d.observe(shellCall("go build ./..."), shellResult(1, "# app\n./main.go:10:2: undefined: Foo"), nil)
+	}
+	d.observe(shellCall("ls"), shellResult(0, ""), nil)
+	if loop := d.detect(); loop != nil {
+		t.Fatalf("unexpected loop after %d failures: %+v", loopThreshold-1, loop)
+	}
+
+	d.observe(shellCall("go build ./..."), shellResult(1, "# app\n./main.go:10:2: undefined: Foo"), nil)
+	loop := d.detect()
+	if loop == nil {
+		t.Fatal("expected a loop")
+	}
+	if loop.action != "go build ./..." || loop.errSig != "./main.go:10:2: undefined: Foo" || loop.repeats != loopThreshold {
+		t.Errorf("unexpected loop: %+v", loop)
+	}
+	if message := loopIntervention(loop); !strings.Contains(message, "You have run `go build ./...` 3 times") || !strings.Contains(message, "read the code at the reported location") {
+		t.Errorf("unexpected intervention: %s", message)
+	}
+}
+
+func TestLoopDetectorSameErrorAcrossCommands(t *testing.T) {
+	d := newLoopDetector("/workspace")
+	d.observe(shellCall("go test ./..."), shellResult(1, "--- FAIL: TestAdd\n    add_test.go:9: expected 3, got 4"), nil)
+	d.observe(shellCall("go test ./calc"), shellResult(1, "--- FAIL: TestAdd\n    add_test.go:9: expected 3, got 4"), nil)
+	d.observe(&agent.ToolCall{Name: "run_tests", Parameters: map[string]any{"test": "TestAdd"}}, nil, errors.New("--- FAIL: TestAdd"))
+
+	loop := d.detect()
+	if loop == nil || loop.action != "" || loop.errSig != "--- FAIL: TestAdd" {
+		t.Fatalf("expected the same error across commands, got %+v", loop)
+	}
+	if suggestion := loopSuggestion(loop.errSig); !strings.Contains(suggestion, "failing test") {
+		t.Errorf("unexpected suggestion for a test failure: %s", suggestion)
+	}
+}
+
+func TestLoopDetectorEscalatesAfterWarning(t *testing.T) {
+	d := newLoopDetector("/workspace")
+	fail := func() {
+		d.observe(shellCall("make"), shellResult(127, "sh: make: command not found"), nil)
+	}
+	for i := 0; i < loopThreshold; i++ {
+		fail()
+	}
+
+	loop := d.detect()
+	if !d.warn(loop) {
+		t.Fatal("first detection should warn")
+	}
+	if d.warn(loop) || d.persisted(loop) {
+		t.Fatal("the same loop should not be warned about twice or escalated immediately")
+	}
+
+	for i := 0; i < loopEscalationRepeats; i++ {
+		fail()
+	}
+	loop = d.detect()
+	if !d.persisted(loop) {
+		t.Fatalf("loop should escalate after %d more failures, got %+v", loopEscalationRepeats, loop)
+	}
+	if evidence := d.evidence(loop); !strings.Contains(evidence, "Ran `make` 5 times") || !strings.Contains(evidence, "- make -> failed: sh: make: command not found") {
+		t.Errorf("unexpected evidence:\n%s", evidence)
+	}
+
+	d.reset()
+	if loop := d.detect(); loop != nil {
+		t.Errorf("reset should forget earlier calls, got %+v", loop)
+	}
+}
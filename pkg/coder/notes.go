@@ -0,0 +1,129 @@
+package coder
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"orchestrator/pkg/persistence"
+	"orchestrator/pkg/tools"
+)
+
+const (
+	// notesProvenance identifies the pinned notes section in the context manager.
+	notesProvenance = "story-notes"
+
+	// notesLoadTimeout bounds how long SETUP waits for the database to return existing notes.
+	notesLoadTimeout = 5 * time.Second
+)
+
+// storyNotes is the coder's tools.NotesStore for the current story. The notes are pinned
+// into the context on every write and persisted so that a coder retrying the story after
+// an ERROR requeue or an abandoned BUDGET_REVIEW starts with them.
+//
+//nolint:govet // fieldalignment: Logical grouping preferred over memory optimization
+type storyNotes struct {
+	coder         *Coder
+	storyID       string
+	content       string
+	inheritedFrom string // Agent that wrote the notes in a previous attempt, if any
+}
+
+// ReadNotes returns the current notes.
+func (n *storyNotes) ReadNotes(_ context.Context) (string, error) {
+	return n.content, nil
+}
+
+// WriteNotes replaces the notes, re-pins them and persists them to the database.
+func (n *storyNotes) WriteNotes(_ context.Context, content string) error {
+	n.content = content
+	n.inheritedFrom = ""
+	n.coder.pinStoryNotes()
+
+	persistence.PersistStoryNotes(&persistence.StoryNotes{
+		StoryID:   n.storyID,
+		Content:   content,
+		UpdatedBy: n.coder.agentID,
+		UpdatedAt: time.Now(),
+	}, n.coder.persistenceChannel)
+	return nil
+}
+
+// SetPersistenceChannel sets the channel used to reach the database worker.
+func (c *Coder) SetPersistenceChannel(persistenceChannel chan<- *persistence.Request) {
+	c.persistenceChannel = persistenceChannel
+}
+
+// loadStoryNotes prepares the notes store for a story, loading notes left by any
+// previous attempt, and pins them into the context.
+func (c *Coder) loadStoryNotes(ctx context.Context, storyID string) {
+	c.notes = &storyNotes{coder: c, storyID: storyID}
+
+	if previous, err := c.queryStoryNotes(ctx, storyID); err != nil {
+		c.logger.Warn("Failed to load notes for story %s: %v", storyID, err)
+	} else if previous != nil && previous.Content != "" {
+		c.notes.content = previous.Content
+		c.notes.inheritedFrom = previous.UpdatedBy
+		c.logger.Info("📝 Loaded %d characters of notes for story %s from %s", len(previous.Content), storyID, previous.UpdatedBy)
+	}
+
+	c.pinStoryNotes()
+}
+
+// queryStoryNotes fetches a story's notes from the database. It returns nil when no
+// persistence channel is configured or no notes exist.
+func (c *Coder) queryStoryNotes(ctx context.Context, storyID string) (*persistence.StoryNotes, error) {
+	if c.persistenceChannel == nil {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notesLoadTimeout)
+	defer cancel()
+
+	response := make(chan interface{}, 1)
+	select {
+	case c.persistenceChannel <- &persistence.Request{Operation: persistence.OpGetStoryNotes, Data: storyID, Response: response}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out sending notes query: %w", ctx.Err())
+	}
+
+	select {
+	case result := <-response:
+		switch value := result.(type) {
+		case *persistence.StoryNotes:
+			return value, nil
+		case error:
+			return nil, value
+		default:
+			return nil, fmt.Errorf("unexpected notes query result %T", result)
+		}
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out waiting for notes: %w", ctx.Err())
+	}
+}
+
+// pinStoryNotes pins the current story's notes into the context, or removes the pin
+// when there are none.
+func (c *Coder) pinStoryNotes() {
+	if c.contextManager == nil {
+		return
+	}
+	if c.notes == nil || c.notes.content == "" {
+		c.contextManager.Pin(notesProvenance, "")
+		return
+	}
+
+	header := "## Story Notes\nYour notes for this story (kept with the `notes` tool):"
+	if c.notes.inheritedFrom != "" && c.notes.inheritedFrom != c.agentID {
+		header = fmt.Sprintf("## Story Notes\nNotes left by a previous attempt at this story (%s). Verify them before relying on them and update them with the `notes` tool:", c.notes.inheritedFrom)
+	}
+	c.contextManager.Pin(notesProvenance, header+"\n\n"+c.notes.content)
+}
+
+// notesStore returns the notes store for tool providers, or nil before SETUP.
+func (c *Coder) notesStore() tools.NotesStore {
+	if c.notes == nil {
+		return nil
+	}
+	return c.notes
+}
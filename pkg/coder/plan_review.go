@@ -39,6 +39,14 @@ func (c *Coder) handlePlanReview(ctx context.Context, sm *agent.BaseStateMachine
 		eff = effect.NewCompletionApprovalEffectWithStoryID(summary, filesCreated, storyID)
 		c.contextManager.AddAssistantMessage("Completion review phase: requesting architect approval")
 
+	case proto.ApprovalTypeSplit:
+		split := utils.GetStateValueOr[*proto.StorySplitPayload](sm, KeyProposedSplit, nil)
+		if split == nil {
+			return proto.StateError, false, logx.Errorf("split review requested without a proposed split")
+		}
+		eff = effect.NewSplitApprovalEffectWithStoryID(split, c.GetStoryID())
+		c.contextManager.AddAssistantMessage("Split review phase: requesting architect approval")
+
 	default:
 		return proto.StateError, false, logx.Errorf("unsupported approval type: %s", approvalType)
 	}
@@ -116,6 +124,15 @@ func (c *Coder) handlePlanReviewApproval(ctx context.Context, sm *agent.BaseStat
 			}
 		}
 
+		// Share the approved todos so the architect can follow progress
+		c.publishTodoProgress(getPlanTodos(sm))
+
+		// Test-first stories get approved failing tests before any implementation
+		if c.isTestFirst(sm) {
+			c.logger.Info("🧑‍💻 Container reconfigured, transitioning to TEST_DESIGN")
+			return StateTestDesign, false, nil
+		}
+
 		c.logger.Info("🧑‍💻 Container reconfigured, transitioning to CODING")
 		return StateCoding, false, nil
 
@@ -129,6 +146,16 @@ func (c *Coder) handlePlanReviewApproval(ctx context.Context, sm *agent.BaseStat
 
 		return proto.StateDone, true, nil
 
+	case proto.ApprovalTypeSplit:
+		// The architect replaced the story with its sub-stories and marked it done;
+		// the coder is released to pick up the first sub-story
+		c.logger.Info("🧑‍💻 Story split approved by architect, transitioning to DONE")
+
+		sm.SetStateData(KeyStoryCompletedAt, time.Now().UTC())
+		sm.SetStateData(KeyCompletionStatus, "SPLIT")
+
+		return proto.StateDone, true, nil
+
 	default:
 		return proto.StateError, false, logx.Errorf("unsupported approval type in plan review: %s", approvalType)
 	}
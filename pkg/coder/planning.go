@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"orchestrator/pkg/agent"
-	"orchestrator/pkg/effect"
 	"orchestrator/pkg/logx"
 	"orchestrator/pkg/proto"
 	"orchestrator/pkg/templates"
@@ -88,6 +87,7 @@ func (c *Coder) handlePlanning(ctx context.Context, sm *agent.BaseStateMachine)
 		ToolDocumentation: c.planningToolProvider.GenerateToolDocumentation(),
 		Extra: map[string]any{
 			"story_type": storyType, // Include story type for template logic
+			"handoff":    utils.GetStateValueOr[string](sm, KeyHandoffNotes, ""),
 		},
 	}
 
@@ -107,6 +107,9 @@ func (c *Coder) handlePlanning(ctx context.Context, sm *agent.BaseStateMachine)
 	// Log the rendered prompt for debugging
 	c.logger.Info("🧑‍💻 Starting planning phase for story_type '%s'", storyType)
 
+	// Pick up answers to any non-blocking questions asked earlier.
+	c.deliverQuestionAnswers()
+
 	// Flush user buffer before LLM request
 	if err := c.contextManager.FlushUserBuffer(); err != nil {
 		return proto.StateError, false, fmt.Errorf("failed to flush user buffer: %w", err)
@@ -160,12 +163,7 @@ func (c *Coder) processPlanningToolCalls(ctx context.Context, sm *agent.BaseStat
 
 		// Handle ask_question tool using Effects pattern.
 		if toolCall.Name == tools.ToolAskQuestion {
-			// Extract question details from tool arguments.
-			question := utils.GetMapFieldOr[string](toolCall.Parameters, "question", "")
-			context := utils.GetMapFieldOr[string](toolCall.Parameters, "context", "")
-			urgency := utils.GetMapFieldOr[string](toolCall.Parameters, "urgency", "medium")
-
-			if question == "" {
+			if utils.GetMapFieldOr[string](toolCall.Parameters, "question", "") == "" {
 				c.logger.Error("Ask question tool called without question parameter")
 				continue
 			}
@@ -173,36 +171,12 @@ func (c *Coder) processPlanningToolCalls(ctx context.Context, sm *agent.BaseStat
 			// Store planning context before asking question.
 			c.storePlanningContext(sm)
 
-			// Create question effect
-			eff := effect.NewQuestionEffect(question, context, urgency, string(StatePlanning))
-
-			// Set story_id for dispatcher validation
-			storyID := utils.GetStateValueOr[string](sm, KeyStoryID, "")
-			eff.StoryID = storyID
-
-			c.logger.Info("🧑‍💻 Asking question during planning")
-
-			// Execute the question effect (blocks until answer received)
-			result, err := c.ExecuteEffect(ctx, eff)
-			if err != nil {
+			// Blocks until the answer is received unless the question is non-blocking.
+			if err := c.askQuestion(ctx, sm, toolCall.Parameters, StatePlanning); err != nil {
 				c.logger.Error("🧑‍💻 Failed to get answer: %v", err)
 				// Add error to context for LLM to handle
 				errorMsg := fmt.Sprintf("Question failed: %v", err)
 				c.contextManager.AddMessage("question-error", errorMsg)
-				continue
-			}
-
-			// Process the answer
-			if questionResult, ok := result.(*effect.QuestionResult); ok {
-				// Answer received from architect (logged to database only)
-
-				// Add the Q&A to context so the LLM can see it
-				qaContent := fmt.Sprintf("Question: %s\nAnswer: %s", question, questionResult.Answer)
-				c.contextManager.AddMessage("architect-answer", qaContent)
-
-				// Continue with planning using the answer
-			} else {
-				c.logger.Error("🧑‍💻 Invalid question result type: %T", result)
 			}
 			continue
 		}
@@ -217,6 +191,8 @@ func (c *Coder) processPlanningToolCalls(ctx context.Context, sm *agent.BaseStat
 		result, err := tool.Exec(ctx, toolCall.Parameters)
 		if err != nil {
 			c.logger.Info("Tool execution failed for %s: %v", toolCall.Name, err)
+			// Surface the failure (e.g. a policy violation) so the LLM can adjust.
+			c.addComprehensiveToolFailureToContext(*toolCall, err)
 			continue
 		}
 
@@ -265,6 +241,9 @@ func (c *Coder) handleToolStateTransition(ctx context.Context, sm *agent.BaseSta
 	case tools.ToolMarkStoryComplete:
 		return c.handleCompletionSubmissionDirect(ctx, sm, resultMap)
 
+	case tools.ToolProposeSplit:
+		return c.handleSplitProposalDirect(ctx, sm, resultMap)
+
 	case tools.ToolAskQuestion:
 		// Questions handled inline via Effects pattern
 		c.logger.Info("🧑‍💻 Question handled inline via Effects pattern, continuing in PLANNING")
@@ -282,19 +261,9 @@ func (c *Coder) handlePlanSubmissionDirect(_ context.Context, sm *agent.BaseStat
 	confidence := utils.GetMapFieldOr[string](resultMap, "confidence", "")
 	explorationSummary := utils.GetMapFieldOr[string](resultMap, "exploration_summary", "")
 	risks := utils.GetMapFieldOr[string](resultMap, "risks", "")
-	todos := utils.GetMapFieldOr[[]any](resultMap, "todos", []any{})
 
 	// Convert todos to structured format.
-	planTodos := make([]PlanTodo, len(todos))
-	for i, todoItem := range todos {
-		if todoMap, ok := utils.SafeAssert[map[string]any](todoItem); ok {
-			planTodos[i] = PlanTodo{
-				ID:          utils.GetMapFieldOr[string](todoMap, "id", ""),
-				Description: utils.GetMapFieldOr[string](todoMap, "description", ""),
-				Completed:   utils.GetMapFieldOr[bool](todoMap, "completed", false),
-			}
-		}
-	}
+	planTodos := parsePlanTodos(resultMap["todos"])
 
 	// Store plan data using typed constants.
 	sm.SetStateData(string(stateDataKeyPlan), plan)
@@ -339,6 +308,32 @@ func (c *Coder) handleCompletionSubmissionDirect(_ context.Context, sm *agent.Ba
 	return StatePlanReview, false, nil
 }
 
+// handleSplitProposalDirect processes propose_split tool results directly.
+func (c *Coder) handleSplitProposalDirect(_ context.Context, sm *agent.BaseStateMachine, resultMap map[string]any) (proto.State, bool, error) {
+	split := &proto.StorySplitPayload{
+		Reason:  utils.GetMapFieldOr[string](resultMap, "reason", ""),
+		Stories: utils.GetMapFieldOr[[]proto.SubStorySpec](resultMap, "stories", nil),
+	}
+	if len(split.Stories) == 0 {
+		c.logger.Warn("🧑‍💻 propose_split returned no sub-stories, staying in PLANNING")
+		return StatePlanning, false, nil
+	}
+
+	sm.SetStateData(KeyProposedSplit, split)
+
+	// Store split approval request for PLAN_REVIEW state to handle
+	c.pendingApprovalRequest = &ApprovalRequest{
+		ID:      proto.GenerateApprovalID(),
+		Content: split.Reason,
+		Reason:  fmt.Sprintf("Story split into %d sub-stories requires approval", len(split.Stories)),
+		Type:    proto.ApprovalTypeSplit,
+	}
+
+	c.logger.Info("🧑‍💻 Split into %d sub-stories proposed, transitioning to PLAN_REVIEW for approval via Effects", len(split.Stories))
+
+	return StatePlanReview, false, nil
+}
+
 // Context management placeholder helper methods for planning.
 func (c *Coder) getExplorationHistory() any { return []string{} }
 func (c *Coder) getFilesExamined() any      { return []string{} }
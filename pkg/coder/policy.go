@@ -0,0 +1,58 @@
+package coder
+
+import (
+	"fmt"
+	"strings"
+
+	"orchestrator/pkg/config"
+	"orchestrator/pkg/policy"
+)
+
+// newPolicyEnforcer loads the project command policy for the current story.
+// Returns nil when the project directory is unknown (e.g. standalone tests).
+// An invalid policy file fails closed so that a typo never silently disables enforcement.
+func (c *Coder) newPolicyEnforcer(storyType string) *policy.Enforcer {
+	maestroDir, err := config.GetProjectMaestroDir()
+	if err != nil {
+		c.logger.Debug("No project directory, command policy disabled: %v", err)
+		return nil
+	}
+
+	p, err := policy.Load(maestroDir)
+	if err != nil {
+		c.logger.Error("Invalid command policy, blocking all shell commands until fixed: %v", err)
+		p = policy.DenyAll("the project policy file is invalid: " + err.Error())
+	}
+
+	enforcer := policy.NewEnforcer(p, maestroDir, c.agentID, c.GetStoryID(), storyType)
+	if workspacePath, wsErr := config.GetContainerWorkspacePath(); wsErr == nil {
+		enforcer.DefaultWorkDir = workspacePath
+	}
+	return enforcer
+}
+
+// buildPolicyViolationsSection summarizes blocked commands for the architect's code review.
+// Returns an empty string when the story has no violations.
+func (c *Coder) buildPolicyViolationsSection(storyID string) string {
+	maestroDir, err := config.GetProjectMaestroDir()
+	if err != nil || storyID == "" {
+		return ""
+	}
+
+	entries, err := policy.ReadAudit(maestroDir, storyID)
+	if err != nil {
+		c.logger.Warn("Failed to read policy audit trail: %v", err)
+	}
+	if len(entries) == 0 {
+		return ""
+	}
+
+	var section strings.Builder
+	section.WriteString(fmt.Sprintf("\n\n## Policy Violations\n%d command(s) were blocked by the project policy:\n", len(entries)))
+	for i := range entries {
+		entry := &entries[i]
+		section.WriteString(fmt.Sprintf("- %s `%s` (%s): %s\n",
+			entry.Timestamp.Format("15:04:05"), entry.Command, entry.AgentID, entry.Reason))
+	}
+	return section.String()
+}
@@ -67,7 +67,14 @@ func (c *Coder) handlePrepareMerge(ctx context.Context, sm *agent.BaseStateMachi
 		return proto.StateError, false, logx.Wrap(commitErr, "git commit failed")
 	}
 
-	// Step 2: Push branch to remote
+	// Step 2: Merge the latest target branch and re-verify, so the merge doesn't fail on
+	// stories that were merged while this one was in progress
+	if feedback := c.syncWithTarget(ctx, targetBranch); feedback != "" {
+		c.contextManager.AddMessage("system", feedback)
+		return StateCoding, false, nil
+	}
+
+	// Step 3: Push branch to remote
 	if pushErr := c.pushBranch(ctx, localBranch, remoteBranch); pushErr != nil {
 		if c.isRecoverableGitError(pushErr) {
 			c.logger.Info("🔀 Git push failed (recoverable), returning to CODING: %v", pushErr)
@@ -84,7 +91,7 @@ func (c *Coder) handlePrepareMerge(ctx context.Context, sm *agent.BaseStateMachi
 		return proto.StateError, false, logx.Wrap(pushErr, "git push failed")
 	}
 
-	// Step 3: Create PR using GitHub CLI
+	// Step 4: Create PR using GitHub CLI
 	prURL, err := c.createPullRequest(ctx, storyID, remoteBranch, targetBranch)
 	if err != nil {
 		// Special handling for "No commits between" error - indicates work detection mismatch
@@ -119,7 +126,7 @@ func (c *Coder) handlePrepareMerge(ctx context.Context, sm *agent.BaseStateMachi
 
 	c.logger.Info("🔀 PR created successfully: %s", prURL)
 
-	// Step 4: Send merge request to architect
+	// Step 5: Send merge request to architect
 	mergeEff := effect.NewMergeEffect(storyID, prURL, remoteBranch)
 
 	// Execute merge effect - blocks until architect responds or times out
@@ -161,6 +168,9 @@ func (c *Coder) commitChanges(ctx context.Context, storyID string) error {
 		Timeout: 30 * time.Second,
 	}
 
+	// Fold checkpoint commits into the story commit to keep the PR history clean
+	c.squashWIPCheckpoints(ctx)
+
 	// Add all changes to staging area
 	result, err := c.longRunningExecutor.Run(ctx, []string{"git", "add", "-A"}, opts)
 	if err != nil {
@@ -168,11 +178,13 @@ func (c *Coder) commitChanges(ctx context.Context, storyID string) error {
 		return fmt.Errorf("git add failed: %w", err)
 	}
 
-	// Check if there are any changes to commit
+	// Check if there are any changes to commit; a merge of the target branch whose
+	// conflicts were resolved still has to be concluded
 	result, err = c.longRunningExecutor.Run(ctx, []string{"git", "diff", "--cached", "--exit-code"}, opts)
-	if err == nil {
+	if err == nil && result.ExitCode == 0 && !mergeInProgress(ctx, c.longRunningExecutor, c.workDir) {
 		// No changes staged for commit
 		c.logger.Info("🔀 No changes to commit")
+		c.BaseStateMachine.SetStateData(KeyCheckpointBase, "")
 		return nil
 	}
 
@@ -184,6 +196,9 @@ func (c *Coder) commitChanges(ctx context.Context, storyID string) error {
 		return fmt.Errorf("git commit failed: %w", err)
 	}
 
+	// Later checkpoints start from the story commit, which is about to be pushed
+	c.BaseStateMachine.SetStateData(KeyCheckpointBase, "")
+
 	c.logger.Info("🔀 Changes committed successfully")
 	return nil
 }
@@ -0,0 +1,137 @@
+package coder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"orchestrator/pkg/agent"
+	"orchestrator/pkg/effect"
+	"orchestrator/pkg/proto"
+	"orchestrator/pkg/utils"
+)
+
+// newQuestionEffect builds the question effect for an ask_question tool call.
+func newQuestionEffect(params map[string]any, originState proto.State, storyID string) *effect.AwaitQuestionEffect {
+	question := utils.GetMapFieldOr[string](params, "question", "")
+	questionContext := utils.GetMapFieldOr[string](params, "context", "")
+	urgency := utils.GetMapFieldOr[string](params, "urgency", "medium")
+
+	eff := effect.NewQuestionEffect(question, questionContext, urgency, string(originState))
+	eff.StoryID = storyID
+	eff.Options = questionOptions(params["options"])
+	eff.RecommendedOption = utils.GetMapFieldOr[string](params, "recommended", "")
+	eff.Category = proto.QuestionCategory(utils.GetMapFieldOr[string](params, "category", ""))
+	eff.NonBlocking = !utils.GetMapFieldOr[bool](params, "blocking", true)
+	return eff
+}
+
+// questionOptions converts the options tool argument to a string slice.
+func questionOptions(value any) []string {
+	switch options := value.(type) {
+	case []string:
+		return options
+	case []any:
+		result := make([]string, 0, len(options))
+		for _, option := range options {
+			if s, ok := option.(string); ok && strings.TrimSpace(s) != "" {
+				result = append(result, strings.TrimSpace(s))
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// askQuestion sends an ask_question tool call to the architect. Blocking questions wait
+// for the answer and add it to the context; non-blocking questions are recorded as open
+// and their answers are delivered by deliverQuestionAnswers on a later iteration.
+func (c *Coder) askQuestion(ctx context.Context, sm *agent.BaseStateMachine, params map[string]any, originState proto.State) error {
+	storyID := utils.GetStateValueOr[string](sm, KeyStoryID, "")
+	eff := newQuestionEffect(params, originState, storyID)
+
+	c.logger.Info("🧑‍💻 Asking question from %s (blocking: %t)", originState, !eff.NonBlocking)
+
+	result, err := c.ExecuteEffect(ctx, eff)
+	if err != nil {
+		return err
+	}
+
+	questionResult, ok := result.(*effect.QuestionResult)
+	if !ok {
+		return fmt.Errorf("invalid question result type: %T", result)
+	}
+
+	if questionResult.Pending {
+		if c.openQuestions == nil {
+			c.openQuestions = make(map[string]string)
+		}
+		c.openQuestions[questionResult.CorrelationID] = eff.Question
+		c.contextManager.AddMessage("question-sent", fmt.Sprintf(
+			"Question sent to the architect without waiting: %s\nContinue with other work; the answer will be shown to you when it arrives.", eff.Question))
+		return nil
+	}
+
+	c.contextManager.AddMessage("architect-answer", formatQuestionAnswer(eff.Question, questionResult.Answer, questionResult.SelectedOption))
+	return nil
+}
+
+// isOpenQuestionAnswer reports whether msg answers one of the coder's non-blocking questions.
+func (c *Coder) isOpenQuestionAnswer(msg *proto.AgentMsg) bool {
+	if len(c.openQuestions) == 0 || msg == nil || msg.Type != proto.MsgTypeRESPONSE {
+		return false
+	}
+	correlationID, _ := msg.GetPayload(proto.KeyCorrelationID)
+	id, ok := correlationID.(string)
+	if !ok {
+		return false
+	}
+	_, open := c.openQuestions[id]
+	return open
+}
+
+// deliverQuestionAnswers adds answers to non-blocking questions to the context. Answers
+// set aside while waiting on another response are delivered first, then any that are
+// already waiting on the reply channel.
+func (c *Coder) deliverQuestionAnswers() {
+poll:
+	for len(c.openQuestions) > len(c.deferredAnswers) && c.replyCh != nil {
+		select {
+		case msg, ok := <-c.replyCh:
+			if !ok {
+				break poll
+			}
+			if c.isOpenQuestionAnswer(msg) {
+				c.deferredAnswers = append(c.deferredAnswers, msg)
+			} else if msg != nil {
+				c.logger.Warn("Discarding unexpected %s message %s while checking for question answers", msg.Type, msg.ID)
+			}
+		default:
+			break poll
+		}
+	}
+
+	for _, msg := range c.deferredAnswers {
+		correlationID, _ := msg.GetPayload(proto.KeyCorrelationID)
+		id, _ := correlationID.(string)
+		question := c.openQuestions[id]
+		delete(c.openQuestions, id)
+
+		answer, _ := msg.GetPayload(proto.KeyAnswer)
+		answerStr, _ := answer.(string)
+		c.logger.Info("📥 Received answer to non-blocking question %s", id)
+		c.contextManager.AddMessage("architect-answer",
+			formatQuestionAnswer(question, answerStr, effect.SelectedOptionFromPayload(msg.Payload)))
+	}
+	c.deferredAnswers = nil
+}
+
+// formatQuestionAnswer renders a question and the architect's answer for the context.
+func formatQuestionAnswer(question, answer, selectedOption string) string {
+	content := fmt.Sprintf("Question: %s\nAnswer: %s", question, answer)
+	if selectedOption != "" {
+		content += "\nSelected option: " + selectedOption
+	}
+	return content
+}
@@ -0,0 +1,65 @@
+package coder
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"orchestrator/pkg/agent"
+	"orchestrator/pkg/contextmgr"
+	"orchestrator/pkg/logx"
+	"orchestrator/pkg/proto"
+)
+
+func TestNonBlockingQuestionAnswerIsDeferred(t *testing.T) {
+	replyCh := make(chan *proto.AgentMsg, 2)
+	c := &Coder{
+		BaseStateMachine: agent.NewBaseStateMachine("test-coder", proto.StateWaiting, nil, nil),
+		agentID:          "test-coder",
+		contextManager:   contextmgr.NewContextManager(),
+		logger:           logx.NewLogger("test-coder"),
+		replyCh:          replyCh,
+		openQuestions:    map[string]string{"corr-1": "Which logger?"},
+	}
+
+	answer := proto.NewAgentMsg(proto.MsgTypeRESPONSE, "architect", "test-coder")
+	answer.SetPayload(proto.KeyCorrelationID, "corr-1")
+	answer.SetPayload(proto.KeyAnswer, "Use logx.")
+	answer.SetPayload(proto.KeySelectedOption, "logx")
+	approval := proto.NewAgentMsg(proto.MsgTypeRESPONSE, "architect", "test-coder")
+	approval.SetPayload(proto.KeyStatus, "APPROVED")
+	replyCh <- answer
+	replyCh <- approval
+
+	// A blocking wait for another response skips over the question answer.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	msg, err := NewRuntime(c).ReceiveMessage(ctx, proto.MsgTypeRESPONSE)
+	if err != nil {
+		t.Fatalf("ReceiveMessage failed: %v", err)
+	}
+	if msg != approval {
+		t.Fatalf("Expected the approval response, got %+v", msg.Payload)
+	}
+	if len(c.deferredAnswers) != 1 {
+		t.Fatalf("Expected the answer to be deferred, got %d", len(c.deferredAnswers))
+	}
+
+	c.deliverQuestionAnswers()
+	if len(c.openQuestions) != 0 || len(c.deferredAnswers) != 0 {
+		t.Errorf("Expected the question to be closed, open=%v deferred=%d", c.openQuestions, len(c.deferredAnswers))
+	}
+	if err := c.contextManager.FlushUserBuffer(); err != nil {
+		t.Fatalf("FlushUserBuffer failed: %v", err)
+	}
+	var found bool
+	for _, m := range c.contextManager.GetMessages() {
+		if strings.Contains(m.Content, "Question: Which logger?\nAnswer: Use logx.\nSelected option: logx") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected the answer in the context")
+	}
+}
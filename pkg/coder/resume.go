@@ -0,0 +1,210 @@
+package coder
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"orchestrator/pkg/agent"
+	"orchestrator/pkg/logx"
+	"orchestrator/pkg/proto"
+	"orchestrator/pkg/state"
+	"orchestrator/pkg/utils"
+)
+
+// KeyResumeState holds the state a coder restored from a checkpoint continues from once
+// SETUP has reattached its workspace.
+const KeyResumeState = "resume_state"
+
+// checkpointKeys are the state data keys saved in a checkpoint. They are everything a
+// coder needs to pick a story up again; conversation context is rebuilt from the plan.
+var checkpointKeys = []string{ //nolint:gochecknoglobals
+	KeyStoryID,
+	KeyStoryMessageID,
+	proto.KeyStoryType,
+	string(stateDataKeyTaskContent),
+	string(stateDataKeyStartedAt),
+	KeyWorkspacePath,
+	KeyLocalBranchName,
+	KeyRemoteBranchName,
+	KeyCheckpointBase,
+	KeyBuildBackend,
+	string(stateDataKeyPlan),
+	string(stateDataKeyPlanConfidence),
+	string(stateDataKeyPlanTodos),
+	string(stateDataKeyExplorationSummary),
+	string(stateDataKeyPlanRisks),
+	KeyTestFirst,
+	KeyApprovedTests,
+	KeyHandoffNotes,
+}
+
+// IsResumableState reports whether a coder can continue a story from state after a
+// restart. These are the states whose handlers rebuild their context from state data;
+// review and merge states fall back to the last resumable state before them.
+func IsResumableState(s proto.State) bool {
+	return s == StatePlanning || s == StateCoding || s == StateTesting
+}
+
+// SetStateStore sets the store used to checkpoint in-flight stories across restarts.
+func (c *Coder) SetStateStore(store *state.Store) {
+	c.stateStore = store
+}
+
+// checkpoint records the coder's progress after a transition. Entering a resumable
+// state saves a checkpoint; finishing, failing or returning to WAITING removes it.
+// Other states keep the previous checkpoint so a restart resumes from the last safe state.
+func (c *Coder) checkpoint(newState proto.State) {
+	if c.stateStore == nil {
+		return
+	}
+
+	switch {
+	case IsResumableState(newState):
+		data := make(map[string]any, len(checkpointKeys))
+		for _, key := range checkpointKeys {
+			if value, exists := c.BaseStateMachine.GetStateValue(key); exists {
+				data[key] = value
+			}
+		}
+		if err := c.stateStore.SaveState(c.agentID, string(newState), data); err != nil {
+			c.logger.Warn("Failed to checkpoint %s state: %v", newState, err)
+		}
+	case newState == proto.StateWaiting || newState == proto.StateDone || newState == proto.StateError:
+		c.clearCheckpoint()
+	}
+}
+
+// clearCheckpoint removes the coder's checkpoint.
+func (c *Coder) clearCheckpoint() {
+	if c.stateStore == nil {
+		return
+	}
+	if err := c.stateStore.DeleteState(c.agentID); err != nil {
+		c.logger.Warn("Failed to clear checkpoint: %v", err)
+	}
+}
+
+// RestoreCheckpoint loads a checkpoint left by a previous run into state data. The coder
+// then goes from WAITING to SETUP, which reattaches the workspace and continues from the
+// checkpointed state. It returns the story being resumed, or "" when there is nothing to resume.
+func (c *Coder) RestoreCheckpoint() (string, error) {
+	if c.stateStore == nil {
+		return "", nil
+	}
+
+	savedState, data, err := c.stateStore.LoadState(c.agentID)
+	if err != nil {
+		c.clearCheckpoint()
+		return "", fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+	if savedState == "" {
+		return "", nil
+	}
+
+	storyID := utils.GetMapFieldOr[string](data, KeyStoryID, "")
+	taskContent := utils.GetMapFieldOr[string](data, string(stateDataKeyTaskContent), "")
+	if !IsResumableState(proto.State(savedState)) || storyID == "" || taskContent == "" {
+		c.clearCheckpoint()
+		return "", fmt.Errorf("discarding unusable checkpoint (state %q, story %q)", savedState, storyID)
+	}
+
+	for key, value := range data {
+		c.BaseStateMachine.SetStateData(key, value)
+	}
+	c.BaseStateMachine.SetStateData(KeyResumeState, savedState)
+	c.logger.Info("♻️  Restored checkpoint for story %s in %s", storyID, savedState)
+	return storyID, nil
+}
+
+// resumeSetup reattaches the workspace of a checkpointed story instead of cloning a fresh
+// one, restarts the container and returns the state to continue from. A workspace that is
+// missing or no longer on the story branch is an error, so the story is requeued cleanly.
+func (c *Coder) resumeSetup(ctx context.Context, sm *agent.BaseStateMachine, resumeState proto.State) (proto.State, bool, error) {
+	sm.SetStateData(KeyResumeState, "")
+
+	storyID := utils.GetStateValueOr[string](sm, KeyStoryID, "")
+	workspacePath := utils.GetStateValueOr[string](sm, KeyWorkspacePath, "")
+	branch := utils.GetStateValueOr[string](sm, KeyLocalBranchName, "")
+
+	// The lease lets the supervisor requeue the story if resuming fails.
+	if c.dispatcher != nil {
+		c.dispatcher.SetLease(c.agentID, storyID)
+	}
+
+	if err := c.validateResumeWorkspace(ctx, workspacePath, branch); err != nil {
+		c.clearCheckpoint()
+		return proto.StateError, false, logx.Wrap(err, fmt.Sprintf("cannot resume story %s", storyID))
+	}
+
+	c.workDir = workspacePath
+	c.logger.Info("♻️  Resuming story %s in %s with workspace %s (branch %s)", storyID, resumeState, workspacePath, branch)
+
+	if err := c.setupScratchSpace(storyID); err != nil {
+		c.logger.Warn("Failed to set up scratch space, large outputs will be truncated: %v", err)
+	}
+	c.loadStoryNotes(ctx, storyID)
+
+	// Planning runs in a read-only container; coding and testing need a writable one.
+	if c.longRunningExecutor != nil {
+		readonly := resumeState == StatePlanning
+		purpose := strings.ToLower(string(resumeState))
+		if err := c.configureWorkspaceMount(ctx, readonly, purpose); err != nil {
+			return proto.StateError, false, logx.Wrap(err, fmt.Sprintf("failed to configure %s container", purpose))
+		}
+	}
+
+	if todos := getPlanTodos(sm); len(todos) > 0 {
+		c.publishTodoProgress(todos)
+	}
+
+	status := "coding"
+	if resumeState == StatePlanning {
+		status = "planning"
+	}
+	if c.dispatcher != nil {
+		if err := c.dispatcher.UpdateStoryStatus(storyID, status); err != nil {
+			c.logger.Warn("Failed to update story status to %s: %v", status, err)
+		}
+	}
+
+	c.contextManager.AddMessage("resume", fmt.Sprintf(
+		"The orchestrator restarted while you were working on this story. Your workspace and plan were restored and you are continuing in %s. "+
+			"Check the current state of the workspace before continuing.", resumeState))
+
+	return resumeState, false, nil
+}
+
+// validateResumeWorkspace checks that a checkpointed workspace still exists and is a
+// usable git checkout of the story branch.
+func (c *Coder) validateResumeWorkspace(ctx context.Context, workspacePath, branch string) error {
+	if workspacePath == "" || branch == "" {
+		return fmt.Errorf("checkpoint has no workspace path or branch")
+	}
+
+	info, err := os.Stat(workspacePath)
+	if err != nil {
+		return fmt.Errorf("workspace %s is not accessible: %w", workspacePath, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("workspace %s is not a directory", workspacePath)
+	}
+
+	if c.cloneManager == nil || c.cloneManager.gitRunner == nil {
+		return nil
+	}
+
+	head, err := c.cloneManager.gitRunner.RunQuiet(ctx, workspacePath, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return fmt.Errorf("workspace %s is not a git checkout: %w", workspacePath, err)
+	}
+	if current := strings.TrimSpace(string(head)); current != branch {
+		return fmt.Errorf("workspace %s is on branch %q, expected %q", workspacePath, current, branch)
+	}
+
+	if _, err := c.cloneManager.gitRunner.RunQuiet(ctx, workspacePath, "status", "--porcelain"); err != nil {
+		return fmt.Errorf("workspace %s git status failed: %w", workspacePath, err)
+	}
+	return nil
+}
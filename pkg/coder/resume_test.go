@@ -0,0 +1,107 @@
+package coder
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"orchestrator/pkg/agent"
+	"orchestrator/pkg/contextmgr"
+	"orchestrator/pkg/logx"
+	"orchestrator/pkg/proto"
+	"orchestrator/pkg/state"
+)
+
+func newResumeTestCoder(t *testing.T, store *state.Store) *Coder {
+	t.Helper()
+	return &Coder{
+		BaseStateMachine: agent.NewBaseStateMachine("coder-001", proto.StateWaiting, nil, CoderTransitions),
+		agentID:          "coder-001",
+		contextManager:   contextmgr.NewContextManager(),
+		logger:           logx.NewLogger("coder-001"),
+		stateStore:       store,
+	}
+}
+
+func TestCheckpointRoundTrip(t *testing.T) {
+	store, err := state.NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+
+	workspace := t.TempDir()
+	c := newResumeTestCoder(t, store)
+	c.SetStateData(KeyStoryID, "story-1")
+	c.SetStateData(string(stateDataKeyTaskContent), "Build the thing")
+	c.SetStateData(KeyWorkspacePath, workspace)
+	c.SetStateData(KeyLocalBranchName, "maestro-story-story-1")
+	c.SetStateData(string(stateDataKeyPlan), "1. Do it")
+	c.SetStateData(KeyErrorMessage, "not checkpointed")
+
+	c.checkpoint(StateCoding)
+	// Review states keep the last resumable checkpoint.
+	c.checkpoint(StateCodeReview)
+
+	restarted := newResumeTestCoder(t, store)
+	storyID, err := restarted.RestoreCheckpoint()
+	if err != nil {
+		t.Fatalf("RestoreCheckpoint failed: %v", err)
+	}
+	if storyID != "story-1" {
+		t.Fatalf("Expected story-1 to be resumed, got %q", storyID)
+	}
+	if got, _ := restarted.GetStateValue(KeyResumeState); got != string(StateCoding) {
+		t.Errorf("Expected resume state CODING, got %v", got)
+	}
+	if got, _ := restarted.GetStateValue(string(stateDataKeyPlan)); got != "1. Do it" {
+		t.Errorf("Expected plan to be restored, got %v", got)
+	}
+	if _, exists := restarted.GetStateValue(KeyErrorMessage); exists {
+		t.Error("Expected only whitelisted keys to be checkpointed")
+	}
+
+	// SETUP reattaches the workspace and continues in CODING.
+	nextState, _, err := restarted.handleSetup(context.Background(), restarted.BaseStateMachine)
+	if err != nil {
+		t.Fatalf("handleSetup failed: %v", err)
+	}
+	if nextState != StateCoding {
+		t.Errorf("Expected SETUP to resume CODING, got %s", nextState)
+	}
+	if restarted.workDir != workspace {
+		t.Errorf("Expected work dir %s, got %s", workspace, restarted.workDir)
+	}
+
+	restarted.checkpoint(proto.StateDone)
+	if savedState, _, _ := store.LoadState("coder-001"); savedState != "" {
+		t.Errorf("Expected DONE to clear the checkpoint, found %s", savedState)
+	}
+}
+
+func TestResumeWithMissingWorkspaceFails(t *testing.T) {
+	store, err := state.NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	if err := store.SaveState("coder-001", string(StateTesting), map[string]any{
+		KeyStoryID:                      "story-1",
+		string(stateDataKeyTaskContent): "Build the thing",
+		KeyWorkspacePath:                filepath.Join(t.TempDir(), "gone"),
+		KeyLocalBranchName:              "maestro-story-story-1",
+	}); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	c := newResumeTestCoder(t, store)
+	if _, err := c.RestoreCheckpoint(); err != nil {
+		t.Fatalf("RestoreCheckpoint failed: %v", err)
+	}
+
+	nextState, _, err := c.handleSetup(context.Background(), c.BaseStateMachine)
+	if err == nil || nextState != proto.StateError {
+		t.Fatalf("Expected ERROR for a missing workspace, got %s (err: %v)", nextState, err)
+	}
+	if savedState, _, _ := store.LoadState("coder-001"); savedState != "" {
+		t.Errorf("Expected the failed checkpoint to be cleared, found %s", savedState)
+	}
+}
@@ -0,0 +1,67 @@
+package coder
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"orchestrator/pkg/config"
+	"orchestrator/pkg/contextmgr"
+)
+
+// scratchDirName is the per-workspace directory for agent scratch files. It lives inside
+// the workspace so the container can read it, and is excluded from git so it never ends
+// up in a commit.
+const scratchDirName = ".maestro-scratch"
+
+// setupScratchSpace prepares the story's scratch directory and routes oversized context
+// output into it, so truncated tool results can still be paged through by the model.
+func (c *Coder) setupScratchSpace(storyID string) error {
+	hostDir := filepath.Join(c.workDir, scratchDirName, storyID)
+	if err := os.MkdirAll(hostDir, 0755); err != nil {
+		return fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+	if err := excludeFromGit(c.workDir, scratchDirName); err != nil {
+		return err
+	}
+
+	workspacePath, err := config.GetContainerWorkspacePath()
+	if err != nil {
+		workspacePath = "/workspace"
+	}
+	displayDir := path.Join(workspacePath, scratchDirName, storyID)
+
+	c.contextManager.SetSpiller(contextmgr.NewFileSpiller(hostDir, displayDir))
+	return nil
+}
+
+// excludeFromGit adds a pattern to the repository's local exclude file if not already present.
+func excludeFromGit(repoDir, pattern string) error {
+	excludePath := filepath.Join(repoDir, ".git", "info", "exclude")
+	existing, err := os.ReadFile(excludePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read git exclude file: %w", err)
+	}
+
+	entry := "/" + pattern + "/"
+	for _, line := range strings.Split(string(existing), "\n") {
+		if strings.TrimSpace(line) == entry {
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(excludePath), 0755); err != nil {
+		return fmt.Errorf("failed to create git info directory: %w", err)
+	}
+	content := string(existing)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += entry + "\n"
+	if err := os.WriteFile(excludePath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to update git exclude file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package coder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"orchestrator/pkg/agent"
+	"orchestrator/pkg/config"
+	"orchestrator/pkg/logx"
+	"orchestrator/pkg/proto"
+	"orchestrator/pkg/templates"
+	"orchestrator/pkg/tools"
+	"orchestrator/pkg/utils"
+)
+
+// Self-review state data keys.
+const (
+	// KeySelfReviewRounds counts the SELF_REVIEW passes made for the story.
+	KeySelfReviewRounds = "self_review_rounds"
+	// KeySelfReviewNote holds the note from a clean self-review, attached to the code review request.
+	KeySelfReviewNote = "self_review_note"
+)
+
+// maxSelfReviewRounds bounds how often a story goes through SELF_REVIEW, so a coder that
+// keeps finding issues in its own work still reaches the architect.
+const maxSelfReviewRounds = 2
+
+// selfReviewEnabled reports whether coders review their own diff before CODE_REVIEW.
+func selfReviewEnabled() bool {
+	cfg, err := config.GetConfig()
+	if err != nil || cfg.Agents == nil {
+		return false
+	}
+	return cfg.Agents.SelfReview
+}
+
+// shouldSelfReview reports whether passing tests go to SELF_REVIEW rather than CODE_REVIEW.
+func shouldSelfReview(enabled bool, rounds int) bool {
+	return enabled && rounds < maxSelfReviewRounds
+}
+
+// handleSelfReview has the coder critique its own branch diff against the story, the
+// approved plan and the project review checklist. Issues send it back to CODING; a clean
+// review attaches the coder's note to the code review request.
+func (c *Coder) handleSelfReview(ctx context.Context, sm *agent.BaseStateMachine) (proto.State, bool, error) {
+	rounds := utils.GetStateValueOr[int](sm, KeySelfReviewRounds, 0)
+	sm.SetStateData(KeySelfReviewRounds, rounds+1)
+
+	baseBranch, err := c.getTargetBranch()
+	if err != nil {
+		c.logger.Warn("Failed to get target branch, using 'main': %v", err)
+		baseBranch = "main"
+	}
+	diff := c.getBranchDiff(ctx, baseBranch)
+
+	checklist, err := utils.LoadReviewChecklist(c.workDir)
+	if err != nil {
+		c.logger.Warn("Using the default review checklist: %v", err)
+	}
+
+	if c.renderer == nil {
+		return proto.StateError, false, logx.Errorf("template renderer not available")
+	}
+	prompt, err := c.renderer.RenderWithUserInstructions(templates.SelfReviewTemplate, &templates.TemplateData{
+		TaskContent: utils.GetStateValueOr[string](sm, string(stateDataKeyTaskContent), ""),
+		Plan:        utils.GetStateValueOr[string](sm, KeyPlan, ""),
+		WorkDir:     c.workDir,
+		Extra: map[string]any{
+			"diff":      diff,
+			"checklist": checklist,
+		},
+	}, c.workDir, "CODER")
+	if err != nil {
+		return proto.StateError, false, logx.Wrap(err, "failed to render self-review template")
+	}
+
+	c.logger.Info("🧑‍💻 Reviewing own diff before code review (round %d of %d)", rounds+1, maxSelfReviewRounds)
+
+	// The review is a single standalone call so the coding conversation is not polluted
+	reviewTool := tools.NewSubmitSelfReviewTool()
+	resp, err := c.llmClient.Complete(ctx, agent.CompletionRequest{
+		Messages:  []agent.CompletionMessage{{Role: agent.RoleUser, Content: prompt}},
+		MaxTokens: 4096,
+		Tools:     []tools.ToolDefinition{reviewTool.Definition()},
+	})
+	if err != nil {
+		return proto.StateError, false, logx.Wrap(err, "failed to get self-review response")
+	}
+
+	for i := range resp.ToolCalls {
+		if resp.ToolCalls[i].Name != tools.ToolSubmitSelfReview {
+			continue
+		}
+		result, execErr := reviewTool.Exec(ctx, resp.ToolCalls[i].Parameters)
+		if execErr != nil {
+			c.logger.Warn("Invalid self-review submission, continuing to code review: %v", execErr)
+			break
+		}
+		if resultMap, ok := result.(map[string]any); ok {
+			return c.applySelfReview(sm, resultMap), false, nil
+		}
+	}
+
+	c.logger.Warn("Self-review produced no submit_self_review call, continuing to code review")
+	return StateCodeReview, false, nil
+}
+
+// applySelfReview routes a validated self-review: issues go back to CODING as context for
+// the next coding pass, a clean review stores the note for the code review request.
+func (c *Coder) applySelfReview(sm *agent.BaseStateMachine, result map[string]any) proto.State {
+	verdict := utils.GetMapFieldOr[string](result, "verdict", tools.SelfReviewClean)
+	note := utils.GetMapFieldOr[string](result, "note", "")
+	issues := utils.GetMapFieldOr[[]string](result, "issues", nil)
+
+	if verdict == tools.SelfReviewFix {
+		var sb strings.Builder
+		sb.WriteString("Your self-review of the diff found issues to fix before code review:\n")
+		for _, issue := range issues {
+			sb.WriteString(fmt.Sprintf("- %s\n", issue))
+		}
+		sb.WriteString("\nFix them, make sure tests still pass, then call done.")
+		c.contextManager.AddMessage("self-review", sb.String())
+
+		c.logger.Info("🧑‍💻 Self-review found %d issues, returning to CODING", len(issues))
+		return StateCoding
+	}
+
+	sm.SetStateData(KeySelfReviewNote, note)
+	c.logger.Info("🧑‍💻 Self-review clean, transitioning to CODE_REVIEW")
+	return StateCodeReview
+}
+
+// resetSelfReview starts self-review afresh when architect feedback sends the coder back to
+// CODING, so the reworked diff gets its own rounds and an earlier note is not resubmitted.
+func resetSelfReview(sm *agent.BaseStateMachine) {
+	sm.SetStateData(KeySelfReviewRounds, 0)
+	sm.SetStateData(KeySelfReviewNote, "")
+}
+
+// buildSelfReviewSection formats the coder's self-review note for the code review request.
+func (c *Coder) buildSelfReviewSection() string {
+	note := utils.GetStateValueOr[string](c.BaseStateMachine, KeySelfReviewNote, "")
+	if note == "" {
+		return ""
+	}
+	return "\n\n## Self-Review\n" + note
+}
@@ -0,0 +1,98 @@
+package coder
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"orchestrator/pkg/agent"
+	"orchestrator/pkg/contextmgr"
+	"orchestrator/pkg/effect"
+	"orchestrator/pkg/logx"
+	"orchestrator/pkg/proto"
+	"orchestrator/pkg/tools"
+	"orchestrator/pkg/utils"
+)
+
+func TestShouldSelfReview(t *testing.T) {
+	if shouldSelfReview(false, 0) {
+		t.Error("Expected no self-review when disabled")
+	}
+	if !shouldSelfReview(true, 0) || !shouldSelfReview(true, maxSelfReviewRounds-1) {
+		t.Error("Expected self-review while rounds remain")
+	}
+	if shouldSelfReview(true, maxSelfReviewRounds) {
+		t.Error("Expected self-review to stop after the round limit")
+	}
+}
+
+func TestApplySelfReview(t *testing.T) {
+	sm := agent.NewBaseStateMachine("test-coder", proto.StateWaiting, nil, CoderTransitions)
+	c := &Coder{
+		BaseStateMachine: sm,
+		agentID:          "test-coder",
+		contextManager:   contextmgr.NewContextManager(),
+		logger:           logx.NewLogger("test-coder"),
+	}
+
+	next := c.applySelfReview(sm, map[string]any{
+		"verdict": tools.SelfReviewFix,
+		"issues":  []string{"main.go: remove fmt.Println debugging"},
+		"note":    "Found debug output",
+	})
+	if next != StateCoding {
+		t.Fatalf("Expected issues to return to CODING, got %s", next)
+	}
+	if err := c.contextManager.FlushUserBuffer(); err != nil {
+		t.Fatalf("FlushUserBuffer failed: %v", err)
+	}
+	var found bool
+	for _, m := range c.contextManager.GetMessages() {
+		if strings.Contains(m.Content, "- main.go: remove fmt.Println debugging") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected the self-review issues in the context")
+	}
+	if c.buildSelfReviewSection() != "" {
+		t.Error("Expected no self-review note while issues are outstanding")
+	}
+
+	next = c.applySelfReview(sm, map[string]any{
+		"verdict": tools.SelfReviewClean,
+		"note":    "Checked for debug code, tests and scope",
+	})
+	if next != StateCodeReview {
+		t.Fatalf("Expected a clean review to go to CODE_REVIEW, got %s", next)
+	}
+	if section := c.buildSelfReviewSection(); !strings.Contains(section, "## Self-Review\nChecked for debug code, tests and scope") {
+		t.Errorf("Expected the note in the code review section, got %q", section)
+	}
+}
+
+func TestCodeReviewFeedbackResetsSelfReview(t *testing.T) {
+	sm := agent.NewBaseStateMachine("test-coder", proto.StateWaiting, nil, CoderTransitions)
+	c := &Coder{
+		BaseStateMachine: sm,
+		agentID:          "test-coder",
+		contextManager:   contextmgr.NewContextManager(),
+		logger:           logx.NewLogger("test-coder"),
+	}
+	sm.SetStateData(KeySelfReviewRounds, maxSelfReviewRounds)
+	sm.SetStateData(KeySelfReviewNote, "Checked for debug code")
+
+	next, _, err := c.processApprovalResult(context.Background(), sm, &effect.ApprovalResult{
+		Status:   proto.ApprovalStatusNeedsChanges,
+		Feedback: "Handle the empty input case",
+	})
+	if err != nil || next != StateCoding {
+		t.Fatalf("Expected NEEDS_CHANGES to return to CODING, got %s (%v)", next, err)
+	}
+	if rounds := utils.GetStateValueOr[int](sm, KeySelfReviewRounds, -1); !shouldSelfReview(true, rounds) {
+		t.Errorf("Expected self-review to run again after architect feedback, rounds = %d", rounds)
+	}
+	if section := c.buildSelfReviewSection(); section != "" {
+		t.Errorf("Expected the earlier self-review note to be cleared, got %q", section)
+	}
+}
@@ -20,6 +20,11 @@ import (
 //
 //nolint:unparam // bool return required by state machine interface, always false for non-terminal states
 func (c *Coder) handleSetup(ctx context.Context, sm *agent.BaseStateMachine) (proto.State, bool, error) {
+	// A story restored from a checkpoint keeps its workspace
+	if resumeState := utils.GetStateValueOr[string](sm, KeyResumeState, ""); resumeState != "" {
+		return c.resumeSetup(ctx, sm, proto.State(resumeState))
+	}
+
 	// Clean and recreate work directory for fresh workspace
 	c.logger.Info("🧹 Cleaning work directory for fresh workspace: %s", c.workDir)
 	if err := os.RemoveAll(c.workDir); err != nil {
@@ -68,6 +73,17 @@ func (c *Coder) handleSetup(ctx context.Context, sm *agent.BaseStateMachine) (pr
 	c.logger.Debug("Updated coder working directory to: %s", c.workDir)
 	c.logger.Debug("Coder instance pointer: %p, workDir: %s", c, c.workDir)
 
+	// Oversized tool output is saved to the story's scratch directory instead of being lost
+	if err := c.setupScratchSpace(storyIDStr); err != nil {
+		c.logger.Warn("Failed to set up scratch space, large outputs will be truncated: %v", err)
+	}
+
+	// Notes from a previous attempt at this story are pinned into the context
+	c.loadStoryNotes(ctx, storyIDStr)
+
+	// Handoffs from abandoned attempts are shown in planning, and may seed the workspace with their work
+	c.loadStoryHandoffs(ctx, sm, storyIDStr)
+
 	// Git user identity is now configured during CloneManager.SetupWorkspace() on the host
 	// This avoids read-only filesystem issues with container mounts
 
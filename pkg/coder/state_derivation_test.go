@@ -14,8 +14,8 @@ func TestGetAllCoderStates(t *testing.T) {
 
 	// Expected states based on current CoderTransitions map.
 	expectedStates := []proto.State{
-		StateSetup, StatePlanning, StateCoding, StateTesting,
-		StatePlanReview, StateCodeReview, StatePrepareMerge, StateBudgetReview, StateAwaitMerge,
+		StateSetup, StatePlanning, StateCoding, StateTesting, StateSelfReview,
+		StatePlanReview, StateTestDesign, StateCodeReview, StatePrepareMerge, StateBudgetReview, StateAwaitMerge,
 	}
 
 	// Sort expected states for comparison.
@@ -0,0 +1,266 @@
+package coder
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"orchestrator/pkg/config"
+	execpkg "orchestrator/pkg/exec"
+	"orchestrator/pkg/proto"
+	"orchestrator/pkg/templates"
+	"orchestrator/pkg/utils"
+)
+
+// Conflict report limits keep the resolution prompt within the context budget.
+const (
+	maxConflictFiles     = 20
+	maxConflictHunkChars = 2000
+)
+
+// ConflictHunk is one conflicted region of a file, with the story branch's and the
+// target branch's version of it.
+type ConflictHunk struct {
+	Ours   string // Story branch side
+	Theirs string // Target branch side
+	Line   int    // 1-based line of the conflict marker
+}
+
+// ConflictFile lists the conflicted regions of one file.
+type ConflictFile struct {
+	Path  string
+	Hunks []ConflictHunk
+}
+
+// syncWithTarget brings the story branch up to date with the latest target branch before
+// it is pushed. Merging (rather than rebasing) keeps an already pushed story branch
+// fast-forwardable. A clean merge of an app story re-runs the test suite. It returns
+// feedback for the coder when conflicts need resolving or tests fail on the merged tree,
+// or an empty string when the branch is ready to push. Fetch and merge failures are
+// logged and left for the architect's merge to report.
+func (c *Coder) syncWithTarget(ctx context.Context, targetBranch string) string {
+	if c.longRunningExecutor == nil {
+		return ""
+	}
+
+	if err := c.fetchTarget(ctx, targetBranch); err != nil {
+		c.logger.Warn("🔀 Could not fetch %s, pushing without syncing: %v", targetBranch, err)
+		return ""
+	}
+
+	targetRef := "origin/" + targetBranch
+	merged, conflicts, err := mergeTargetRef(ctx, c.longRunningExecutor, c.workDir, targetRef)
+	if err != nil {
+		c.logger.Warn("🔀 Could not merge %s into the story branch, pushing without syncing: %v", targetRef, err)
+		return ""
+	}
+
+	if len(conflicts) > 0 {
+		c.logger.Info("🔀 Merging %s conflicts in %d files, returning to CODING", targetRef, len(conflicts))
+		return c.renderConflictReport(targetBranch, conflicts)
+	}
+	if !merged {
+		c.logger.Info("🔀 Story branch is up to date with %s", targetRef)
+		return ""
+	}
+
+	c.logger.Info("🔀 Merged latest %s into the story branch", targetRef)
+	storyType := utils.GetStateValueOr[string](c.BaseStateMachine, proto.KeyStoryType, string(proto.StoryTypeApp))
+	if storyType != string(proto.StoryTypeApp) {
+		return ""
+	}
+
+	passed, output, err := c.runTestSuite(ctx)
+	if err != nil {
+		output = err.Error()
+	}
+	if err != nil || !passed {
+		c.logger.Info("🔀 Tests fail after merging %s, returning to CODING", targetRef)
+		message := fmt.Sprintf("The latest %s was merged into your branch and the tests no longer pass. "+
+			"Your changes conflict in behavior with work merged since you started.\n\n%s", targetBranch, truncateOutput(output))
+		if c.renderer != nil {
+			if rendered, renderErr := c.renderer.RenderSimple(templates.TestFailureInstructionsTemplate, message); renderErr == nil {
+				return rendered
+			}
+		}
+		return message
+	}
+
+	c.logger.Info("🔀 Tests pass on the merged tree")
+	return ""
+}
+
+// fetchTarget fetches the latest target branch from origin.
+func (c *Coder) fetchTarget(ctx context.Context, targetBranch string) error {
+	opts := &execpkg.Opts{
+		WorkDir: c.workDir,
+		Timeout: 2 * time.Minute,
+		Env:     []string{},
+	}
+	if config.HasGitHubToken() {
+		opts.Env = append(opts.Env, "GITHUB_TOKEN")
+	}
+
+	result, err := c.longRunningExecutor.Run(ctx, []string{"git", "fetch", "origin", targetBranch}, opts)
+	if err != nil {
+		return fmt.Errorf("git fetch failed: %w", err)
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("git fetch failed (exit %d): %s", result.ExitCode, strings.TrimSpace(result.Stderr))
+	}
+	return nil
+}
+
+// runTestSuite runs the project's tests the way TESTING does.
+func (c *Coder) runTestSuite(ctx context.Context) (bool, string, error) {
+	if c.buildService != nil {
+		return c.runTestWithBuildService(ctx, c.workDir)
+	}
+	return c.runMakeTest(ctx, c.workDir)
+}
+
+// renderConflictReport formats the conflicts for the coder using the resolution template.
+func (c *Coder) renderConflictReport(targetBranch string, conflicts []ConflictFile) string {
+	omitted := 0
+	if len(conflicts) > maxConflictFiles {
+		omitted = len(conflicts) - maxConflictFiles
+		conflicts = conflicts[:maxConflictFiles]
+	}
+
+	if c.renderer != nil {
+		rendered, err := c.renderer.Render(templates.MergeConflictResolutionTemplate, &templates.TemplateData{
+			Extra: map[string]any{
+				"target_branch": targetBranch,
+				"files":         conflicts,
+				"omitted":       omitted,
+			},
+		})
+		if err == nil {
+			return rendered
+		}
+		c.logger.Error("Failed to render merge conflict report: %v", err)
+	}
+
+	paths := make([]string, 0, len(conflicts))
+	for i := range conflicts {
+		paths = append(paths, conflicts[i].Path)
+	}
+	return fmt.Sprintf("Merging the latest %s into your branch conflicts in: %s. Resolve the conflict markers, make sure tests pass, then call done.",
+		targetBranch, strings.Join(paths, ", "))
+}
+
+// mergeTargetRef merges targetRef into the branch checked out in workDir. It reports
+// whether a merge commit was made and, when the merge stops on conflicts, the conflicted
+// files. Conflicts leave the merge in progress with markers in the files for the coder to
+// resolve; the story commit concludes it.
+func mergeTargetRef(ctx context.Context, executor execpkg.Executor, workDir, targetRef string) (bool, []ConflictFile, error) {
+	if upToDate, err := isAncestor(ctx, executor, workDir, targetRef, "HEAD"); err != nil {
+		return false, nil, err
+	} else if upToDate {
+		return false, nil, nil
+	}
+
+	opts := &execpkg.Opts{
+		WorkDir: workDir,
+		Timeout: time.Minute,
+	}
+	// Some executors also return an error for a non-zero exit, so the outcome is read from
+	// the exit code and the index rather than from err alone
+	result, err := executor.Run(ctx, []string{"git", "merge", "--no-edit", "-m", "Merge " + targetRef + " into story branch", targetRef}, opts)
+	if err == nil && result.ExitCode == 0 {
+		return true, nil, nil
+	}
+
+	unmerged, listErr := runCheckpointGit(ctx, executor, workDir, "diff", "--name-only", "--diff-filter=U")
+	if listErr != nil || strings.TrimSpace(unmerged) == "" {
+		// Not a content conflict (e.g. untracked files in the way): leave the branch as it was
+		_, _ = runCheckpointGit(ctx, executor, workDir, "merge", "--abort")
+		return false, nil, fmt.Errorf("git merge failed (exit %d): %s", result.ExitCode, strings.TrimSpace(result.Stdout+result.Stderr))
+	}
+
+	var conflicts []ConflictFile
+	for _, path := range strings.Split(strings.TrimSpace(unmerged), "\n") {
+		file := ConflictFile{Path: path}
+		if content, readErr := os.ReadFile(filepath.Join(workDir, path)); readErr == nil {
+			file.Hunks = parseConflictHunks(string(content))
+		}
+		conflicts = append(conflicts, file)
+	}
+	return false, conflicts, nil
+}
+
+// isAncestor reports whether commit is an ancestor of (or equal to) descendant.
+func isAncestor(ctx context.Context, executor execpkg.Executor, workDir, commit, descendant string) (bool, error) {
+	result, err := executor.Run(ctx, []string{"git", "merge-base", "--is-ancestor", commit, descendant}, &execpkg.Opts{
+		WorkDir: workDir,
+		Timeout: 30 * time.Second,
+	})
+	switch {
+	case err == nil && result.ExitCode == 0:
+		return true, nil
+	case result.ExitCode == 1:
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("git merge-base failed: %w", err)
+	default:
+		return false, fmt.Errorf("git merge-base failed (exit %d): %s", result.ExitCode, strings.TrimSpace(result.Stderr))
+	}
+}
+
+// mergeInProgress reports whether the worktree has an unfinished merge.
+func mergeInProgress(ctx context.Context, executor execpkg.Executor, workDir string) bool {
+	_, err := runCheckpointGit(ctx, executor, workDir, "rev-parse", "-q", "--verify", "MERGE_HEAD")
+	return err == nil
+}
+
+// parseConflictHunks extracts the conflicted regions between git's conflict markers.
+// The common-ancestor section of diff3-style conflicts is skipped.
+func parseConflictHunks(content string) []ConflictHunk {
+	const (
+		outside = iota
+		inOurs
+		inBase
+		inTheirs
+	)
+
+	var (
+		hunks  []ConflictHunk
+		ours   []string
+		theirs []string
+		start  int
+		state  = outside
+	)
+	for i, line := range strings.Split(content, "\n") {
+		switch {
+		case state == outside && strings.HasPrefix(line, "<<<<<<<"):
+			state, start, ours, theirs = inOurs, i+1, nil, nil
+		case state == inOurs && strings.HasPrefix(line, "|||||||"):
+			state = inBase
+		case (state == inOurs || state == inBase) && strings.HasPrefix(line, "======="):
+			state = inTheirs
+		case state == inTheirs && strings.HasPrefix(line, ">>>>>>>"):
+			hunks = append(hunks, ConflictHunk{
+				Ours:   truncateConflictSide(strings.Join(ours, "\n")),
+				Theirs: truncateConflictSide(strings.Join(theirs, "\n")),
+				Line:   start,
+			})
+			state = outside
+		case state == inOurs:
+			ours = append(ours, line)
+		case state == inTheirs:
+			theirs = append(theirs, line)
+		}
+	}
+	return hunks
+}
+
+// truncateConflictSide bounds one side of a conflict hunk.
+func truncateConflictSide(side string) string {
+	if len(side) <= maxConflictHunkChars {
+		return side
+	}
+	return side[:maxConflictHunkChars] + "\n... (truncated)"
+}
@@ -0,0 +1,95 @@
+package coder
+
+import (
+	"context"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"testing"
+
+	execpkg "orchestrator/pkg/exec"
+)
+
+func TestMergeTargetRef(t *testing.T) {
+	if _, err := osexec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	gitOutput(t, dir, "init", "-q", "-b", "main")
+	gitOutput(t, dir, "config", "user.email", "test@example.com")
+	gitOutput(t, dir, "config", "user.name", "Test")
+	writeFile := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	commitAll := func(msg string) {
+		gitOutput(t, dir, "add", "-A")
+		gitOutput(t, dir, "commit", "-q", "-m", msg)
+	}
+	writeFile("main.go", "package main\n\nconst name = \"base\"\n")
+	commitAll("initial commit")
+	gitOutput(t, dir, "checkout", "-q", "-b", "story")
+
+	ctx := context.Background()
+	executor := execpkg.NewLocalExec()
+
+	merged, conflicts, err := mergeTargetRef(ctx, executor, dir, "main")
+	if err != nil || merged || len(conflicts) != 0 {
+		t.Fatalf("Expected an up-to-date branch to be left alone, got merged=%v conflicts=%v err=%v", merged, conflicts, err)
+	}
+
+	// A change to another file on the target merges cleanly.
+	gitOutput(t, dir, "checkout", "-q", "main")
+	writeFile("other.go", "package main\n")
+	commitAll("other story")
+	gitOutput(t, dir, "checkout", "-q", "story")
+	merged, conflicts, err = mergeTargetRef(ctx, executor, dir, "main")
+	if err != nil || !merged || len(conflicts) != 0 {
+		t.Fatalf("Expected a clean merge, got merged=%v conflicts=%v err=%v", merged, conflicts, err)
+	}
+
+	// Both sides changing the same line conflicts.
+	writeFile("main.go", "package main\n\nconst name = \"story\"\n")
+	commitAll("story change")
+	gitOutput(t, dir, "checkout", "-q", "main")
+	writeFile("main.go", "package main\n\nconst name = \"target\"\n")
+	commitAll("conflicting story")
+	gitOutput(t, dir, "checkout", "-q", "story")
+
+	merged, conflicts, err = mergeTargetRef(ctx, executor, dir, "main")
+	if err != nil || merged {
+		t.Fatalf("Expected conflicts, got merged=%v err=%v", merged, err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Path != "main.go" || len(conflicts[0].Hunks) != 1 {
+		t.Fatalf("Expected one conflict hunk in main.go, got %+v", conflicts)
+	}
+	hunk := conflicts[0].Hunks[0]
+	if hunk.Ours != "const name = \"story\"" || hunk.Theirs != "const name = \"target\"" || hunk.Line != 3 {
+		t.Errorf("Unexpected conflict hunk: %+v", hunk)
+	}
+	if !mergeInProgress(ctx, executor, dir) {
+		t.Error("Expected the merge to be left in progress for resolution")
+	}
+
+	// Checkpoints wait until the story commit concludes the merge.
+	if _, created, err := commitCheckpoint(ctx, executor, dir, "tests passed", ""); err != nil || created {
+		t.Errorf("Expected no checkpoint during a merge, got created=%v err=%v", created, err)
+	}
+}
+
+func TestParseConflictHunks(t *testing.T) {
+	content := "a\n<<<<<<< HEAD\nours 1\nours 2\n||||||| base\nbase\n=======\ntheirs\n>>>>>>> main\nb\n<<<<<<< HEAD\n=======\nadded\n>>>>>>> main\n"
+
+	hunks := parseConflictHunks(content)
+	if len(hunks) != 2 {
+		t.Fatalf("Expected 2 hunks, got %+v", hunks)
+	}
+	if hunks[0].Ours != "ours 1\nours 2" || hunks[0].Theirs != "theirs" || hunks[0].Line != 2 {
+		t.Errorf("Unexpected first hunk (base section must be skipped): %+v", hunks[0])
+	}
+	if hunks[1].Ours != "" || hunks[1].Theirs != "added" || hunks[1].Line != 11 {
+		t.Errorf("Unexpected second hunk: %+v", hunks[1])
+	}
+}
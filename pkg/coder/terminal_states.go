@@ -2,6 +2,7 @@ package coder
 
 import (
 	"context"
+	"fmt"
 
 	"orchestrator/pkg/agent"
 	"orchestrator/pkg/proto"
@@ -25,13 +26,16 @@ func (c *Coder) handleDone(_ /* ctx */ context.Context, sm *agent.BaseStateMachi
 // handleError processes the ERROR state - terminal state indicating failure.
 //
 //nolint:unparam // error return required by state machine interface, always nil for terminal states
-func (c *Coder) handleError(_ context.Context, sm *agent.BaseStateMachine) (proto.State, bool, error) {
+func (c *Coder) handleError(ctx context.Context, sm *agent.BaseStateMachine) (proto.State, bool, error) {
 	// ERROR is truly terminal - orchestrator handles all cleanup and story requeue.
 	// Only log once when entering ERROR state to avoid spam.
 	if val, exists := sm.GetStateValue(KeyDoneLogged); !exists || val != true {
 		errorMsg, _ := sm.GetStateValue(KeyErrorMessage)
 		c.logger.Error("🧑‍💻 Agent in ERROR state: %v - orchestrator will handle cleanup and story requeue", errorMsg)
 		sm.SetStateData(KeyDoneLogged, true)
+
+		// Leave a record of this attempt for the coder that picks up the requeued story
+		c.recordHandoff(ctx, sm, fmt.Sprintf("Coder error: %v", errorMsg))
 	}
 
 	// Return done=true to stop the run loop - orchestrator handles everything else.
@@ -0,0 +1,463 @@
+package coder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"orchestrator/pkg/agent"
+	"orchestrator/pkg/build"
+	"orchestrator/pkg/config"
+	"orchestrator/pkg/effect"
+	execpkg "orchestrator/pkg/exec"
+	"orchestrator/pkg/logx"
+	"orchestrator/pkg/proto"
+	"orchestrator/pkg/templates"
+	"orchestrator/pkg/tools"
+	"orchestrator/pkg/utils"
+)
+
+// maxTestFileContent bounds the test file content sent to the architect for review.
+const maxTestFileContent = 50000
+
+// approvedTests records the tests the architect approved in TEST_DESIGN. Stored in state
+// data as a JSON string so it survives checkpoints.
+type approvedTests struct {
+	Files  map[string]string `json:"files"`            // Test file path to git blob hash at approval
+	Commit string            `json:"commit,omitempty"` // Checkpoint commit containing the approved tests
+	Tests  []string          `json:"tests"`
+}
+
+// testFirstEnabled reports whether every app story is test-first.
+func testFirstEnabled() bool {
+	cfg, err := config.GetConfig()
+	if err != nil || cfg.Agents == nil {
+		return false
+	}
+	return cfg.Agents.TestFirst
+}
+
+// isTestFirst reports whether the current story gets approved failing tests before CODING,
+// either because the project enables it or because the architect marked the story.
+// Only app stories are test-first.
+func (c *Coder) isTestFirst(sm *agent.BaseStateMachine) bool {
+	storyType := utils.GetStateValueOr[string](sm, proto.KeyStoryType, string(proto.StoryTypeApp))
+	if storyType != string(proto.StoryTypeApp) {
+		return false
+	}
+	return testFirstEnabled() || utils.GetStateValueOr[bool](sm, KeyTestFirst, false)
+}
+
+// handleTestDesign has the coder write failing tests for the story's acceptance criteria
+// and submit them for architect approval before any implementation. Approved tests are
+// frozen: TESTING verifies they are unchanged and pass.
+func (c *Coder) handleTestDesign(ctx context.Context, sm *agent.BaseStateMachine) (proto.State, bool, error) {
+	const maxTestDesignIterations = 8
+	if budgetReviewEff, budgetExceeded := c.checkLoopBudget(sm, string(stateDataKeyTestDesignIterations), maxTestDesignIterations, StateTestDesign); budgetExceeded {
+		c.logger.Info("Test design budget exceeded, triggering BUDGET_REVIEW")
+		sm.SetStateData("budget_review_effect", budgetReviewEff)
+		return StateBudgetReview, false, nil
+	}
+
+	if c.testDesignToolProvider == nil {
+		c.testDesignToolProvider = tools.NewProvider(tools.AgentContext{
+			Executor:        c.longRunningExecutor,
+			ReadOnly:        false, // Test design writes test files
+			NetworkDisabled: false,
+			WorkDir:         c.workDir,
+			Policy:          c.newPolicyEnforcer(string(proto.StoryTypeApp)),
+			Notes:           c.notesStore(),
+			BuildService:    c.buildService,
+		}, withExternalTools(tools.AppTestDesignTools))
+	}
+
+	if c.renderer == nil {
+		return proto.StateError, false, logx.Errorf("template renderer not available")
+	}
+	templateData := &templates.TemplateData{
+		TaskContent:       utils.GetStateValueOr[string](sm, string(stateDataKeyTaskContent), ""),
+		Plan:              utils.GetStateValueOr[string](sm, KeyPlan, ""),
+		WorkDir:           c.workDir,
+		ToolDocumentation: c.testDesignToolProvider.GenerateToolDocumentation(),
+	}
+	if cfg, err := config.GetConfig(); err == nil && cfg.Build != nil {
+		templateData.TestCommand = cfg.Build.Test
+	}
+	prompt, err := c.renderer.RenderWithUserInstructions(templates.TestDesignTemplate, templateData, c.workDir, "CODER")
+	if err != nil {
+		return proto.StateError, false, logx.Wrap(err, "failed to render test design template")
+	}
+
+	c.contextManager.ResetForNewTemplate("test-design", prompt)
+	c.logger.Info("🧪 Writing failing tests for test-first story")
+
+	c.deliverQuestionAnswers()
+	if err := c.contextManager.FlushUserBuffer(); err != nil {
+		return proto.StateError, false, fmt.Errorf("failed to flush user buffer: %w", err)
+	}
+
+	toolMetas := c.testDesignToolProvider.List()
+	definitions := make([]tools.ToolDefinition, 0, len(toolMetas))
+	//nolint:gocritic // rangeValCopy: Direct access is clearer than pointer dereferencing
+	for _, meta := range toolMetas {
+		definitions = append(definitions, tools.ToolDefinition(meta))
+	}
+
+	req := agent.CompletionRequest{
+		Messages:  c.buildMessagesWithContext(prompt),
+		MaxTokens: 8192,
+		Tools:     definitions,
+	}
+	resp, err := c.llmClient.Complete(ctx, req)
+	if err != nil {
+		if c.isEmptyResponseError(err) {
+			return c.handleEmptyResponseError(sm, prompt, req, StateTestDesign)
+		}
+		return proto.StateError, false, logx.Wrap(err, "failed to get LLM test design response")
+	}
+
+	var submission map[string]any
+	for i := range resp.ToolCalls {
+		toolCall := &resp.ToolCalls[i]
+
+		if toolCall.Name == tools.ToolAskQuestion {
+			if err := c.askQuestion(ctx, sm, toolCall.Parameters, StateTestDesign); err != nil {
+				c.logger.Error("🧑‍💻 Failed to get answer: %v", err)
+				c.addComprehensiveToolFailureToContext(*toolCall, err)
+				continue
+			}
+		}
+
+		tool, err := c.testDesignToolProvider.Get(toolCall.Name)
+		if err != nil {
+			c.addComprehensiveToolFailureToContext(*toolCall, err)
+			continue
+		}
+		result, err := tool.Exec(ctx, toolCall.Parameters)
+		if err != nil {
+			c.logger.Info("Tool execution failed for %s: %v", toolCall.Name, err)
+			c.addComprehensiveToolFailureToContext(*toolCall, err)
+			continue
+		}
+		c.addToolResultToContext(*toolCall, result)
+
+		if toolCall.Name == tools.ToolSubmitTests {
+			if resultMap, ok := result.(map[string]any); ok {
+				submission = resultMap
+			}
+		}
+	}
+
+	if err := c.handleLLMResponse(resp); err != nil {
+		return proto.StateError, false, err
+	}
+
+	if submission != nil {
+		return c.submitTestsForApproval(ctx, sm, submission)
+	}
+	return StateTestDesign, false, nil
+}
+
+// submitTestsForApproval checks that every submitted test fails, checkpoints the test files
+// and asks the architect to approve them. Approval freezes the tests and starts CODING;
+// anything else returns the feedback to TEST_DESIGN.
+func (c *Coder) submitTestsForApproval(ctx context.Context, sm *agent.BaseStateMachine, submission map[string]any) (proto.State, bool, error) {
+	summary := utils.GetMapFieldOr[string](submission, "summary", "")
+	testNames := utils.GetMapFieldOr[[]string](submission, "tests", nil)
+	files := utils.GetMapFieldOr[[]string](submission, "files", nil)
+	workspacePath := utils.GetStateValueOr[string](sm, KeyWorkspacePath, c.workDir)
+
+	passing, missing := c.classifySubmittedTests(ctx, sm, workspacePath, testNames)
+	if len(passing) > 0 || len(missing) > 0 {
+		var sb strings.Builder
+		sb.WriteString("The submitted tests were not sent for review:\n")
+		if len(passing) > 0 {
+			sb.WriteString(fmt.Sprintf("- Already passing, so they do not capture the missing behavior: %s\n", strings.Join(passing, ", ")))
+		}
+		if len(missing) > 0 {
+			sb.WriteString(fmt.Sprintf("- Not found by run_tests (check the names): %s\n", strings.Join(missing, ", ")))
+		}
+		sb.WriteString("\nEvery submitted test must fail until the story is implemented. Fix the tests, then call submit_tests again.")
+		c.contextManager.AddMessage("test-design", sb.String())
+		c.logger.Info("🧪 %d submitted tests already pass and %d were not found, staying in TEST_DESIGN", len(passing), len(missing))
+		return StateTestDesign, false, nil
+	}
+
+	hashes, err := c.hashTestFiles(ctx, files)
+	if err != nil {
+		c.contextManager.AddMessage("test-design", fmt.Sprintf("Could not read the submitted test files: %v\n\nList the test files relative to the workspace and call submit_tests again.", err))
+		return StateTestDesign, false, nil
+	}
+
+	commit, err := c.checkpointSubmittedTests(ctx)
+	if err != nil {
+		c.logger.Warn("Tests not submitted: %v", err)
+		c.contextManager.AddMessage("test-design", fmt.Sprintf("The submitted tests could not be committed for review: %v\n\nMake sure the test files are saved in the workspace, then call submit_tests again.", err))
+		return StateTestDesign, false, nil
+	}
+
+	content := c.buildTestsReviewContent(ctx, summary, testNames, files)
+	eff := effect.NewApprovalEffect(content, "Failing tests ready for test-first review", proto.ApprovalTypeTests)
+	eff.StoryID = c.GetStoryID()
+
+	c.logger.Info("🧪 Requesting tests approval from architect for %d tests", len(testNames))
+	result, err := c.ExecuteEffect(ctx, eff)
+	if err != nil {
+		return proto.StateError, false, logx.Wrap(err, "failed to get tests approval")
+	}
+	approvalResult, ok := result.(*effect.ApprovalResult)
+	if !ok {
+		return proto.StateError, false, logx.Errorf("unexpected result type from approval effect: %T", result)
+	}
+
+	if approvalResult.Status != proto.ApprovalStatusApproved {
+		c.logger.Info("🧪 Tests %s, staying in TEST_DESIGN with feedback", approvalResult.Status)
+		if approvalResult.Feedback != "" {
+			c.contextManager.AddMessage("architect", fmt.Sprintf("Feedback on your tests: %s", approvalResult.Feedback))
+		}
+		return StateTestDesign, false, nil
+	}
+
+	approved := approvedTests{
+		Tests:  testNames,
+		Files:  hashes,
+		Commit: commit,
+	}
+	encoded, err := json.Marshal(approved)
+	if err != nil {
+		return proto.StateError, false, logx.Wrap(err, "failed to encode approved tests")
+	}
+	sm.SetStateData(KeyApprovedTests, string(encoded))
+	c.contextManager.AddMessage("architect", fmt.Sprintf("Your tests are approved and frozen. Implement the story so they pass without changing these files: %s", strings.Join(files, ", ")))
+
+	c.logger.Info("🧪 Tests approved, transitioning to CODING")
+	return StateCoding, false, nil
+}
+
+// checkpointSubmittedTests commits the submitted tests as a checkpoint and returns that commit,
+// from which the approved tests are restored if the coder changes them later. Fails when no
+// checkpoint commit was made.
+func (c *Coder) checkpointSubmittedTests(ctx context.Context) (string, error) {
+	if c.longRunningExecutor == nil {
+		return "", fmt.Errorf("no executor to commit the tests with")
+	}
+
+	base := utils.GetStateValueOr[string](c.BaseStateMachine, KeyCheckpointBase, "")
+	newBase, commit, err := commitTestsCheckpoint(ctx, c.longRunningExecutor, c.workDir, base)
+	if err != nil {
+		return "", err
+	}
+	c.BaseStateMachine.SetStateData(KeyCheckpointBase, newBase)
+	c.logger.Info("📍 Created checkpoint for the submitted tests: %s", commit)
+	return commit, nil
+}
+
+// commitTestsCheckpoint commits the workspace as a checkpoint and returns the checkpoint base
+// and the new commit. Fails when there is nothing to commit.
+func commitTestsCheckpoint(ctx context.Context, executor execpkg.Executor, workDir, base string) (newBase, commit string, err error) {
+	newBase, created, err := commitCheckpoint(ctx, executor, workDir, "Failing tests for test-first story", base)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to commit the tests: %w", err)
+	}
+	if !created {
+		return "", "", fmt.Errorf("no changes to commit since the last checkpoint")
+	}
+
+	head, err := runCheckpointGit(ctx, executor, workDir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve the tests commit: %w", err)
+	}
+	return newBase, strings.TrimSpace(head), nil
+}
+
+// classifySubmittedTests runs each submitted test in isolation and returns the tests that
+// already pass and those that did not run at all. A failing run, including one that does
+// not compile yet, is what a test-first test should produce.
+func (c *Coder) classifySubmittedTests(ctx context.Context, sm *agent.BaseStateMachine, workspacePath string, testNames []string) (passing, missing []string) {
+	if c.buildService == nil {
+		return nil, nil
+	}
+	runTests := tools.NewRunTestsTool(c.longRunningExecutor, c.buildService, workspacePath)
+	backend := c.testBackend(sm, workspacePath)
+
+	for _, name := range testNames {
+		result, err := runTests.Exec(ctx, isolatedTestArgs(backend, &build.TestCase{Name: name}))
+		if err != nil {
+			continue
+		}
+		resultMap, ok := result.(map[string]any)
+		if !ok {
+			continue
+		}
+		success, _ := resultMap["success"].(bool)
+		passed, _ := resultMap["passed"].(int)
+		switch {
+		case success && passed > 0:
+			passing = append(passing, name)
+		case success:
+			missing = append(missing, name)
+		}
+	}
+	return passing, missing
+}
+
+// verifyApprovedTests checks that the tests approved in TEST_DESIGN are unchanged and pass.
+// Returns feedback for the coder, or an empty string when the story has no approved tests
+// or they hold.
+func (c *Coder) verifyApprovedTests(ctx context.Context, sm *agent.BaseStateMachine, workspacePath string) string {
+	approved, ok := c.loadApprovedTests(sm)
+	if !ok {
+		return ""
+	}
+
+	var changed []string
+	files := make([]string, 0, len(approved.Files))
+	for file := range approved.Files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		current, err := c.hashTestFiles(ctx, []string{file})
+		if err != nil || current[file] != approved.Files[file] {
+			changed = append(changed, file)
+		}
+	}
+
+	var failing []string
+	if c.buildService != nil {
+		runTests := tools.NewRunTestsTool(c.longRunningExecutor, c.buildService, workspacePath)
+		backend := c.testBackend(sm, workspacePath)
+		for _, name := range approved.Tests {
+			if !c.passesInIsolation(ctx, runTests, backend, &build.TestCase{Name: name}, 1) {
+				failing = append(failing, name)
+			}
+		}
+	}
+
+	if len(changed) == 0 && len(failing) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("The tests approved before coding do not hold:\n")
+	if len(changed) > 0 {
+		sb.WriteString(fmt.Sprintf("- These approved test files were modified or removed: %s. Approved tests may not be weakened or changed.", strings.Join(changed, ", ")))
+		if approved.Commit != "" {
+			sb.WriteString(fmt.Sprintf(" Restore them with `git checkout %s -- <file>`.", approved.Commit))
+		}
+		sb.WriteString("\n")
+	}
+	if len(failing) > 0 {
+		sb.WriteString(fmt.Sprintf("- These approved tests do not pass yet: %s\n", strings.Join(failing, ", ")))
+	}
+	sb.WriteString("\nChange the implementation, not the tests, until every approved test passes.")
+	return sb.String()
+}
+
+// loadApprovedTests decodes the tests approved in TEST_DESIGN from state data.
+func (c *Coder) loadApprovedTests(sm *agent.BaseStateMachine) (*approvedTests, bool) {
+	encoded := utils.GetStateValueOr[string](sm, KeyApprovedTests, "")
+	if encoded == "" {
+		return nil, false
+	}
+	var approved approvedTests
+	if err := json.Unmarshal([]byte(encoded), &approved); err != nil {
+		c.logger.Warn("Ignoring unreadable approved tests: %v", err)
+		return nil, false
+	}
+	return &approved, true
+}
+
+// testBackend returns the build backend of the workspace, detecting it when TESTING has
+// not run yet.
+func (c *Coder) testBackend(sm *agent.BaseStateMachine, workspacePath string) string {
+	if backend := utils.GetStateValueOr[string](sm, KeyBuildBackend, ""); backend != "" {
+		return backend
+	}
+	info, err := c.buildService.GetBackendInfo(workspacePath)
+	if err != nil {
+		c.logger.Debug("Failed to detect build backend: %v", err)
+		return ""
+	}
+	sm.SetStateData(KeyBuildBackend, info.Name)
+	return info.Name
+}
+
+// hashTestFiles returns the git blob hash of each workspace file, keyed by path.
+func (c *Coder) hashTestFiles(ctx context.Context, files []string) (map[string]string, error) {
+	if c.longRunningExecutor == nil {
+		return nil, fmt.Errorf("no executor available")
+	}
+	out, err := runCheckpointGit(ctx, c.longRunningExecutor, c.workDir, append([]string{"hash-object", "--"}, files...)...)
+	if err != nil {
+		return nil, err
+	}
+	hashes := strings.Fields(out)
+	if len(hashes) != len(files) {
+		return nil, fmt.Errorf("expected %d hashes, got %d", len(files), len(hashes))
+	}
+	result := make(map[string]string, len(files))
+	for i, file := range files {
+		result[file] = hashes[i]
+	}
+	return result, nil
+}
+
+// buildTestsReviewContent formats a tests submission with the test file contents for the
+// architect.
+func (c *Coder) buildTestsReviewContent(ctx context.Context, summary string, testNames, files []string) string {
+	var sb strings.Builder
+	sb.WriteString("## Summary\n")
+	sb.WriteString(summary)
+	sb.WriteString("\n\n## Tests (all currently failing)\n")
+	for _, name := range testNames {
+		sb.WriteString(fmt.Sprintf("- %s\n", name))
+	}
+
+	sb.WriteString("\n## Test Files\n")
+	remaining := maxTestFileContent
+	for _, file := range files {
+		content := c.readWorkspaceFile(ctx, file)
+		if len(content) > remaining {
+			content = content[:remaining] + "\n... (truncated)"
+		}
+		remaining -= len(content)
+		sb.WriteString(fmt.Sprintf("\n### %s\n```\n%s\n```\n", file, content))
+		if remaining <= 0 {
+			break
+		}
+	}
+	return sb.String()
+}
+
+// readWorkspaceFile returns the content of a workspace file, or a note when it cannot be read.
+func (c *Coder) readWorkspaceFile(ctx context.Context, file string) string {
+	if c.longRunningExecutor == nil {
+		return "(file could not be read)"
+	}
+	result, err := c.longRunningExecutor.Run(ctx, []string{"cat", "--", file}, &execpkg.Opts{
+		WorkDir: c.workDir,
+		Timeout: 30 * time.Second,
+	})
+	if err != nil || result.ExitCode != 0 {
+		return "(file could not be read)"
+	}
+	return result.Stdout
+}
+
+// buildApprovedTestsSection lists the tests approved before coding for code review.
+func (c *Coder) buildApprovedTestsSection() string {
+	approved, ok := c.loadApprovedTests(c.BaseStateMachine)
+	if !ok {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n\n## Approved Tests\nThese tests were approved before coding and verified unchanged and passing:\n")
+	for _, name := range approved.Tests {
+		sb.WriteString(fmt.Sprintf("- %s\n", name))
+	}
+	return sb.String()
+}
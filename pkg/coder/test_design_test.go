@@ -0,0 +1,100 @@
+package coder
+
+import (
+	"context"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"orchestrator/pkg/agent"
+	execpkg "orchestrator/pkg/exec"
+	"orchestrator/pkg/logx"
+	"orchestrator/pkg/proto"
+)
+
+func TestIsTestFirst(t *testing.T) {
+	sm := agent.NewBaseStateMachine("test-coder", proto.StateWaiting, nil, CoderTransitions)
+	c := &Coder{BaseStateMachine: sm, logger: logx.NewLogger("test-coder")}
+
+	sm.SetStateData(proto.KeyStoryType, string(proto.StoryTypeApp))
+	if c.isTestFirst(sm) {
+		t.Error("Expected an unmarked app story not to be test-first")
+	}
+
+	sm.SetStateData(KeyTestFirst, true)
+	if !c.isTestFirst(sm) {
+		t.Error("Expected a marked app story to be test-first")
+	}
+
+	sm.SetStateData(proto.KeyStoryType, string(proto.StoryTypeDevOps))
+	if c.isTestFirst(sm) {
+		t.Error("Expected devops stories never to be test-first")
+	}
+}
+
+func TestApprovedTestsSection(t *testing.T) {
+	sm := agent.NewBaseStateMachine("test-coder", proto.StateWaiting, nil, CoderTransitions)
+	c := &Coder{BaseStateMachine: sm, logger: logx.NewLogger("test-coder")}
+
+	if c.buildApprovedTestsSection() != "" {
+		t.Error("Expected no section without approved tests")
+	}
+	if feedback := c.verifyApprovedTests(context.Background(), sm, "/tmp"); feedback != "" {
+		t.Errorf("Expected nothing to verify without approved tests, got %q", feedback)
+	}
+
+	sm.SetStateData(KeyApprovedTests, `{"files":{"parser_test.go":"abc123"},"tests":["TestParseValid","TestParseMalformed"]}`)
+	section := c.buildApprovedTestsSection()
+	if !strings.Contains(section, "## Approved Tests") || !strings.Contains(section, "- TestParseMalformed") {
+		t.Errorf("Expected the approved tests in the section, got %q", section)
+	}
+
+	// Without an executor the approved files cannot be read, which must not pass silently
+	feedback := c.verifyApprovedTests(context.Background(), sm, "/tmp")
+	if !strings.Contains(feedback, "parser_test.go") {
+		t.Errorf("Expected unreadable approved files to be reported, got %q", feedback)
+	}
+}
+
+func TestCommitTestsCheckpoint(t *testing.T) {
+	if _, err := osexec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	gitOutput(t, dir, "init", "-q", "-b", "main")
+	gitOutput(t, dir, "config", "user.email", "test@example.com")
+	gitOutput(t, dir, "config", "user.name", "Test")
+	if err := os.WriteFile(filepath.Join(dir, "parser.go"), []byte("package parser\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitOutput(t, dir, "add", ".")
+	gitOutput(t, dir, "commit", "-q", "-m", "initial commit")
+	initial := gitOutput(t, dir, "rev-parse", "HEAD")
+
+	ctx := context.Background()
+	executor := execpkg.NewLocalExec()
+	if err := os.WriteFile(filepath.Join(dir, "parser_test.go"), []byte("package parser\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	base, commit, err := commitTestsCheckpoint(ctx, executor, dir, "")
+	if err != nil {
+		t.Fatalf("commitTestsCheckpoint failed: %v", err)
+	}
+	if base != initial {
+		t.Errorf("Expected the checkpoint base to be the initial commit, got %s", base)
+	}
+	if commit == initial || commit != gitOutput(t, dir, "rev-parse", "HEAD") {
+		t.Errorf("Expected the new checkpoint commit, got %s (initial %s)", commit, initial)
+	}
+	if files := gitOutput(t, dir, "show", "--name-only", "--format=", commit); files != "parser_test.go" {
+		t.Errorf("Expected the commit to contain the tests, got %q", files)
+	}
+
+	// Nothing new to commit means the submission cannot name its tests commit
+	if _, _, err := commitTestsCheckpoint(ctx, executor, dir, base); err == nil {
+		t.Error("Expected an error when no checkpoint commit is made")
+	}
+}
@@ -84,16 +84,40 @@ func (c *Coder) handleAppStoryTesting(ctx context.Context, sm *agent.BaseStateMa
 		sm.SetStateData(KeyTestingCompletedAt, time.Now().UTC())
 
 		if !testsPassed {
-			c.logger.Info("App story tests failed, transitioning to CODING state for fixes")
-			// Create test failure effect with truncated test output
-			truncatedOutput := truncateOutput(testOutput)
+			// Prefer a per-test failure report; fall back to truncated raw output
+			failureMessage := truncateOutput(testOutput)
+			if triage := c.triageTestFailures(ctx, sm, workspacePathStr, backendInfo.Name, testOutput); triage != nil {
+				sm.SetStateData(KeyFlakyTests, triage.flakySummary())
+				failureMessage = triage.failureMessage()
+				sm.SetStateData(KeyTestOutput, failureMessage+"\n\n"+testOutput)
+				testsPassed = !triage.hasBlocking()
+			}
+
+			if !testsPassed {
+				c.logger.Info("App story tests failed, transitioning to CODING state for fixes")
+				testFailureEff := effect.NewGenericTestFailureEffect(failureMessage)
+				return c.executeTestFailureAndTransition(ctx, sm, testFailureEff)
+			}
+
+			c.logger.Info("App story test failures are all flaky tests outside the story's changes, continuing")
+			sm.SetStateData(KeyTestsPassed, true)
+		}
+
+		c.logger.Info("App story tests passed successfully")
 
-			testFailureEff := effect.NewGenericTestFailureEffect(truncatedOutput)
+		// Test-first stories must keep their approved tests unchanged and passing
+		if approvedFeedback := c.verifyApprovedTests(ctx, sm, workspacePathStr); approvedFeedback != "" {
+			c.logger.Info("Approved tests do not hold, transitioning to CODING state for fixes")
+			testFailureEff := effect.NewGenericTestFailureEffect(approvedFeedback)
 			return c.executeTestFailureAndTransition(ctx, sm, testFailureEff)
 		}
 
-		c.logger.Info("App story tests passed successfully")
-		return c.proceedToCodeReview()
+		// Measure coverage of the story's changes as evidence for code review
+		if coverageFeedback := c.checkChangedCoverage(ctx, sm, workspacePathStr); coverageFeedback != "" {
+			testFailureEff := effect.NewGenericTestFailureEffect(coverageFeedback)
+			return c.executeTestFailureAndTransition(ctx, sm, testFailureEff)
+		}
+		return c.proceedToCodeReview(ctx)
 	}
 
 	// Use general testing approach for other story types
@@ -133,7 +157,7 @@ func (c *Coder) handleDevOpsStoryTesting(ctx context.Context, sm *agent.BaseStat
 	sm.SetStateData(KeyTestingCompletedAt, time.Now().UTC())
 
 	c.logger.Info("DevOps story testing completed successfully")
-	return c.proceedToCodeReview()
+	return c.proceedToCodeReview(ctx)
 }
 
 // handleContainerTesting performs actual container infrastructure testing for DevOps stories.
@@ -211,7 +235,7 @@ func (c *Coder) handleContainerTesting(ctx context.Context, sm *agent.BaseStateM
 	sm.SetStateData(KeyTestOutput, fmt.Sprintf("Container infrastructure validation completed successfully:\n- Container '%s' built successfully\n- Container boot test passed\n- Infrastructure is ready for deployment", containerConfig.Name))
 	sm.SetStateData(KeyTestingCompletedAt, time.Now().UTC())
 
-	return c.proceedToCodeReview()
+	return c.proceedToCodeReview(ctx)
 }
 
 // executeTestFailureAndTransition executes a test failure effect and transitions to CODING state.
@@ -365,7 +389,72 @@ func (c *Coder) handleLegacyTesting(ctx context.Context, sm *agent.BaseStateMach
 	}
 
 	c.logger.Info("Tests passed successfully")
-	return c.proceedToCodeReview()
+	return c.proceedToCodeReview(ctx)
+}
+
+// structuredTestReport re-runs the packages or files that failed in testOutput through the
+// run_tests tool to obtain per-test results. Returns nil when the failing targets cannot be
+// identified or the backend has no structured results.
+func (c *Coder) structuredTestReport(ctx context.Context, workspacePath, backend, testOutput string) *build.TestReport {
+	if c.longRunningExecutor == nil || c.buildService == nil {
+		return nil
+	}
+	targets := build.FailedTestTargets(backend, testOutput)
+	if len(targets) == 0 {
+		return nil
+	}
+
+	runTests := tools.NewRunTestsTool(c.longRunningExecutor, c.buildService, workspacePath)
+	result, err := runTests.RunTargets(ctx, targets)
+	if err != nil {
+		c.logger.Debug("Structured test results unavailable: %v", err)
+		return nil
+	}
+
+	resultMap, ok := result.(map[string]any)
+	if !ok {
+		return nil
+	}
+	report, ok := resultMap["report"].(*build.TestReport)
+	if !ok {
+		return nil
+	}
+	return report
+}
+
+// checkChangedCoverage measures coverage of lines changed on the story branch and stores the
+// summary for code review. Returns feedback for the coder when the project's minimum coverage
+// for changed lines is set and not met, or an empty string otherwise. Coverage is best effort:
+// backends without coverage support never block the story.
+func (c *Coder) checkChangedCoverage(ctx context.Context, sm *agent.BaseStateMachine, workspacePath string) string {
+	if c.longRunningExecutor == nil || c.buildService == nil {
+		return ""
+	}
+
+	coverageTool := tools.NewCoverageTool(c.longRunningExecutor, c.buildService, workspacePath)
+	result, err := coverageTool.Exec(ctx, map[string]any{})
+	if err != nil {
+		c.logger.Warn("Failed to measure changed-line coverage: %v", err)
+		return ""
+	}
+
+	resultMap, ok := result.(map[string]any)
+	if !ok {
+		return ""
+	}
+	summary, _ := resultMap["output"].(string)
+	if success, _ := resultMap["success"].(bool); !success {
+		errMsg, _ := resultMap["error"].(string)
+		c.logger.Info("Coverage not measured: %s", errMsg)
+		return ""
+	}
+
+	sm.SetStateData(KeyCoverageSummary, summary)
+	if meets, _ := resultMap["meets_threshold"].(bool); !meets {
+		c.logger.Info("Changed-line coverage below project minimum, returning to CODING")
+		return "Tests pass, but coverage of your changes is below the project minimum.\n\n" + summary
+	}
+	return ""
 }
 
 // validateMakefileTargets validates that Makefile has reasonable targets for DevOps.
@@ -499,8 +588,17 @@ func (c *Coder) runTestWithBuildService(ctx context.Context, workspacePath strin
 	return true, response.Output, nil
 }
 
-// proceedToCodeReview transitions to CODE_REVIEW state after successful testing.
-func (c *Coder) proceedToCodeReview() (proto.State, bool, error) {
+// proceedToCodeReview checkpoints the passing tree and transitions to CODE_REVIEW state,
+// or to SELF_REVIEW first when self-review is enabled.
+func (c *Coder) proceedToCodeReview(ctx context.Context) (proto.State, bool, error) {
+	c.createWIPCheckpoint(ctx, "tests passed")
+
+	rounds := utils.GetStateValueOr[int](c.BaseStateMachine, KeySelfReviewRounds, 0)
+	if shouldSelfReview(selfReviewEnabled(), rounds) {
+		c.logger.Info("🧑‍💻 Tests completed successfully, transitioning to SELF_REVIEW")
+		return StateSelfReview, false, nil
+	}
+
 	// Tests passed, transition to CODE_REVIEW state
 	// Approval request will be sent when entering CODE_REVIEW state
 	c.logger.Info("🧑‍💻 Tests completed successfully, transitioning to CODE_REVIEW")
@@ -0,0 +1,159 @@
+package coder
+
+import (
+	"context"
+	"fmt"
+
+	"orchestrator/pkg/agent"
+	"orchestrator/pkg/proto"
+	"orchestrator/pkg/tools"
+	"orchestrator/pkg/utils"
+)
+
+// todosProvenance identifies the pinned plan-progress section in the context manager.
+const todosProvenance = "plan-todos"
+
+// todoTracker is the coder's tools.TodoTracker. Todos live in the state machine with the
+// rest of the plan; every update is pinned into the context and reported to the architect,
+// which persists it with the story.
+type todoTracker struct {
+	coder *Coder
+}
+
+// UpdateTodo sets the status of one approved-plan todo.
+func (t *todoTracker) UpdateTodo(ctx context.Context, id string, status proto.TodoStatus, note string) ([]proto.StoryTodo, error) {
+	c := t.coder
+	todos := getPlanTodos(c.BaseStateMachine)
+
+	found := false
+	completed := ""
+	for i := range todos {
+		if todos[i].ID != id {
+			continue
+		}
+		if status == proto.TodoDone && todos[i].Status != proto.TodoDone {
+			completed = todos[i].Description
+		}
+		todos[i].Status = status
+		todos[i].Completed = status == proto.TodoDone
+		todos[i].Note = note
+		found = true
+		break
+	}
+	if !found {
+		ids := make([]string, 0, len(todos))
+		for i := range todos {
+			ids = append(ids, todos[i].ID)
+		}
+		return nil, fmt.Errorf("unknown todo %q (plan todos: %v)", id, ids)
+	}
+
+	c.SetStateData(string(stateDataKeyPlanTodos), todos)
+	c.publishTodoProgress(todos)
+	if completed != "" {
+		c.createWIPCheckpoint(ctx, fmt.Sprintf("%s done: %s", id, completed))
+	}
+	return toStoryTodos(todos), nil
+}
+
+// publishTodoProgress pins the current plan progress into the context and sends it to
+// the architect as a status update.
+func (c *Coder) publishTodoProgress(todos []PlanTodo) {
+	storyTodos := toStoryTodos(todos)
+	if c.contextManager != nil && len(storyTodos) > 0 {
+		c.contextManager.Pin(todosProvenance, "## Plan Progress\n"+tools.FormatTodoProgress(storyTodos))
+	}
+
+	if c.dispatcher == nil || len(storyTodos) == 0 {
+		return
+	}
+	if err := c.dispatcher.UpdateStoryTodos(c.GetStoryID(), c.agentID, storyTodos); err != nil {
+		c.logger.Warn("Failed to send todo progress: %v", err)
+	}
+}
+
+// todoTrackerForTools returns the todo tracker for tool providers.
+func (c *Coder) todoTrackerForTools() tools.TodoTracker {
+	return &todoTracker{coder: c}
+}
+
+// getPlanTodos returns the approved plan's todos from state data.
+func getPlanTodos(sm *agent.BaseStateMachine) []PlanTodo {
+	value, exists := sm.GetStateValue(string(stateDataKeyPlanTodos))
+	if !exists {
+		return nil
+	}
+	return parsePlanTodos(value)
+}
+
+// parsePlanTodos converts the todo representations found in tool results and state data
+// (typed slices, or generic maps after serialization) into PlanTodos.
+func parsePlanTodos(value any) []PlanTodo {
+	switch todos := value.(type) {
+	case []PlanTodo:
+		result := make([]PlanTodo, len(todos))
+		copy(result, todos)
+		return result
+	case []map[string]any:
+		result := make([]PlanTodo, 0, len(todos))
+		for _, todoMap := range todos {
+			result = append(result, planTodoFromMap(todoMap))
+		}
+		return result
+	case []any:
+		result := make([]PlanTodo, 0, len(todos))
+		for _, item := range todos {
+			if todoMap, ok := utils.SafeAssert[map[string]any](item); ok {
+				result = append(result, planTodoFromMap(todoMap))
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// planTodoFromMap builds a PlanTodo from its map form.
+func planTodoFromMap(todoMap map[string]any) PlanTodo {
+	todo := PlanTodo{
+		ID:          utils.GetMapFieldOr[string](todoMap, "id", ""),
+		Description: utils.GetMapFieldOr[string](todoMap, "description", ""),
+		Completed:   utils.GetMapFieldOr[bool](todoMap, "completed", false),
+		Status:      proto.TodoStatus(utils.GetMapFieldOr[string](todoMap, "status", "")),
+		Note:        utils.GetMapFieldOr[string](todoMap, "note", ""),
+	}
+	if todo.Status == "" {
+		todo.Status = proto.TodoPending
+		if todo.Completed {
+			todo.Status = proto.TodoDone
+		}
+	}
+	return todo
+}
+
+// toStoryTodos converts plan todos to their wire form.
+func toStoryTodos(todos []PlanTodo) []proto.StoryTodo {
+	result := make([]proto.StoryTodo, 0, len(todos))
+	for i := range todos {
+		status := todos[i].Status
+		if status == "" {
+			status = proto.TodoPending
+		}
+		result = append(result, proto.StoryTodo{
+			ID:          todos[i].ID,
+			Description: todos[i].Description,
+			Status:      status,
+			Note:        todos[i].Note,
+		})
+	}
+	return result
+}
+
+// buildTodoReviewSection lists the plan todos and their status for code review.
+func (c *Coder) buildTodoReviewSection() string {
+	todos := getPlanTodos(c.BaseStateMachine)
+	if len(todos) == 0 {
+		return ""
+	}
+	return "\n\n## Plan Todos\n" + tools.FormatTodoProgress(toStoryTodos(todos))
+}
@@ -0,0 +1,69 @@
+package coder
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"orchestrator/pkg/agent"
+	"orchestrator/pkg/contextmgr"
+	"orchestrator/pkg/logx"
+	"orchestrator/pkg/proto"
+	"orchestrator/pkg/tools"
+)
+
+func TestUpdateTodoTracksPlanProgress(t *testing.T) {
+	sm := agent.NewBaseStateMachine("test-coder", proto.StateWaiting, nil, nil)
+	c := &Coder{
+		BaseStateMachine: sm,
+		agentID:          "test-coder",
+		contextManager:   contextmgr.NewContextManager(),
+		logger:           logx.NewLogger("test-coder"),
+	}
+	c.contextManager.ResetSystemPrompt("You are a coder.")
+
+	// Todos as produced by submit_plan
+	planResult, err := tools.NewSubmitPlanTool().Exec(context.Background(), map[string]any{
+		"plan":       "Add a calculator",
+		"confidence": "HIGH",
+		"todos":      []any{"Add Add function", "Write tests", "Update docs"},
+	})
+	if err != nil {
+		t.Fatalf("submit_plan failed: %v", err)
+	}
+	todos := parsePlanTodos(planResult.(map[string]any)["todos"])
+	if len(todos) != 3 || todos[0].ID != "todo_001" || todos[0].Status != proto.TodoPending {
+		t.Fatalf("Unexpected parsed todos: %+v", todos)
+	}
+	sm.SetStateData(string(stateDataKeyPlanTodos), todos)
+
+	tool := tools.NewUpdateTodoTool(c.todoTrackerForTools())
+	ctx := context.Background()
+	if _, err := tool.Exec(ctx, map[string]any{"id": "todo_001", "status": "done"}); err != nil {
+		t.Fatalf("update_todo failed: %v", err)
+	}
+	if _, err := tool.Exec(ctx, map[string]any{"id": "todo_003", "status": "blocked", "note": "No docs directory exists"}); err != nil {
+		t.Fatalf("update_todo failed: %v", err)
+	}
+	if _, err := tool.Exec(ctx, map[string]any{"id": "todo_009", "status": "done"}); err == nil {
+		t.Error("Expected unknown todo to be rejected")
+	}
+	if _, err := tool.Exec(ctx, map[string]any{"id": "todo_002", "status": "blocked"}); err == nil {
+		t.Error("Expected blocked without a note to be rejected")
+	}
+
+	updated := getPlanTodos(sm)
+	if updated[0].Status != proto.TodoDone || !updated[0].Completed || updated[2].Status != proto.TodoBlocked {
+		t.Errorf("Unexpected todo state: %+v", updated)
+	}
+
+	pinned := c.contextManager.GetMessages()[0].Content
+	if !strings.Contains(pinned, "Plan progress: 1/3 done") || !strings.Contains(pinned, "No docs directory exists") {
+		t.Errorf("Expected plan progress pinned into context, got %q", pinned)
+	}
+
+	review := c.buildTodoReviewSection()
+	if !strings.Contains(review, "## Plan Todos") || !strings.Contains(review, "- [ ] todo_002: Write tests") {
+		t.Errorf("Unexpected review section: %q", review)
+	}
+}
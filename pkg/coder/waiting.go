@@ -96,6 +96,11 @@ func (c *Coder) handleWaiting(ctx context.Context, sm *agent.BaseStateMachine) (
 		sm.SetStateData(proto.KeyStoryType, storyType) // Store story type for testing decisions
 		sm.SetStateData(string(stateDataKeyStartedAt), time.Now().UTC())
 
+		// The architect marks stories whose requirements call for tests before implementation
+		if testFirst, exists := proto.GetTypedPayload[bool](storyMsg, proto.KeyTestFirst); exists {
+			sm.SetStateData(KeyTestFirst, testFirst)
+		}
+
 		logx.DebugState(ctx, "coder", "transition", "WAITING -> SETUP", "received story message")
 		return StateSetup, false, nil
 	}
@@ -0,0 +1,139 @@
+package coder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	execpkg "orchestrator/pkg/exec"
+	"orchestrator/pkg/tools"
+	"orchestrator/pkg/utils"
+)
+
+// KeyCheckpointBase holds the commit the story's unpushed checkpoint commits start from.
+// PREPARE_MERGE squashes everything after it into the story commit.
+const KeyCheckpointBase = "checkpoint_base"
+
+// maxCheckpointLabel bounds the label in a checkpoint commit subject.
+const maxCheckpointLabel = 72
+
+// createWIPCheckpoint commits the current worktree as a checkpoint on the story branch so
+// the rollback tool can return to it. Failures are logged; checkpoints never block the story.
+func (c *Coder) createWIPCheckpoint(ctx context.Context, label string) {
+	if c.longRunningExecutor == nil || c.containerName == "" {
+		return
+	}
+
+	// Keep the label to a single short subject line
+	label, _, _ = strings.Cut(label, "\n")
+	if len(label) > maxCheckpointLabel {
+		label = label[:maxCheckpointLabel] + "..."
+	}
+
+	base := utils.GetStateValueOr[string](c.BaseStateMachine, KeyCheckpointBase, "")
+	newBase, created, err := commitCheckpoint(ctx, c.longRunningExecutor, c.workDir, label, base)
+	if err != nil {
+		c.logger.Warn("Failed to create checkpoint %q: %v", label, err)
+		return
+	}
+	if !created {
+		c.logger.Debug("No changes since the last checkpoint, skipping %q", label)
+		return
+	}
+
+	c.BaseStateMachine.SetStateData(KeyCheckpointBase, newBase)
+	c.logger.Info("📍 Created checkpoint: %s", label)
+}
+
+// squashWIPCheckpoints folds the story's checkpoint commits back into the index so the
+// story commit replaces them.
+func (c *Coder) squashWIPCheckpoints(ctx context.Context) {
+	base := utils.GetStateValueOr[string](c.BaseStateMachine, KeyCheckpointBase, "")
+	if base == "" {
+		return
+	}
+
+	squashed, err := squashCheckpoints(ctx, c.longRunningExecutor, c.workDir, base)
+	if err != nil {
+		c.logger.Warn("Keeping checkpoint commits in the branch history: %v", err)
+		return
+	}
+	if squashed > 0 {
+		c.logger.Info("🔀 Squashed %d checkpoint commits into the story commit", squashed)
+	}
+}
+
+// commitCheckpoint stages all changes and commits them as a checkpoint. It returns the
+// checkpoint base (the given base, or HEAD before the first checkpoint) and whether a
+// commit was made; a clean worktree or a merge still being resolved creates no checkpoint.
+func commitCheckpoint(ctx context.Context, executor execpkg.Executor, workDir, label, base string) (string, bool, error) {
+	if mergeInProgress(ctx, executor, workDir) {
+		return base, false, nil
+	}
+	if _, err := runCheckpointGit(ctx, executor, workDir, "add", "-A"); err != nil {
+		return base, false, err
+	}
+	if _, err := runCheckpointGit(ctx, executor, workDir, "diff", "--cached", "--quiet"); err == nil {
+		return base, false, nil
+	}
+
+	if base == "" {
+		head, err := runCheckpointGit(ctx, executor, workDir, "rev-parse", "HEAD")
+		if err != nil {
+			return base, false, err
+		}
+		base = strings.TrimSpace(head)
+	}
+
+	if _, err := runCheckpointGit(ctx, executor, workDir, "commit", "-q", "--no-verify", "-m", tools.CheckpointCommitPrefix+label); err != nil {
+		return base, false, err
+	}
+	return base, true, nil
+}
+
+// squashCheckpoints soft-resets the branch to base when every commit after it is a
+// checkpoint, leaving their changes staged. It returns the number of commits squashed.
+// Other commits after base (such as a merge made while resolving conflicts) are never
+// rewritten; the checkpoints are then left in place.
+func squashCheckpoints(ctx context.Context, executor execpkg.Executor, workDir, base string) (int, error) {
+	subjects, err := runCheckpointGit(ctx, executor, workDir, "log", "--format=%s", base+"..HEAD")
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, subject := range strings.Split(strings.TrimSpace(subjects), "\n") {
+		if subject == "" {
+			continue
+		}
+		if !strings.HasPrefix(subject, tools.CheckpointCommitPrefix) {
+			return 0, fmt.Errorf("branch has non-checkpoint commit %q after %s", subject, base)
+		}
+		count++
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	if _, err := runCheckpointGit(ctx, executor, workDir, "reset", "--soft", base); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// runCheckpointGit runs a git command in workDir and returns stdout, failing on non-zero exit.
+func runCheckpointGit(ctx context.Context, executor execpkg.Executor, workDir string, args ...string) (string, error) {
+	opts := &execpkg.Opts{
+		WorkDir: workDir,
+		Timeout: 30 * time.Second,
+	}
+	result, err := executor.Run(ctx, append([]string{"git"}, args...), opts)
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("git %s failed (exit %d): %s", args[0], result.ExitCode, strings.TrimSpace(result.Stderr))
+	}
+	return result.Stdout, nil
+}
@@ -0,0 +1,101 @@
+package coder
+
+import (
+	"context"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	execpkg "orchestrator/pkg/exec"
+)
+
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := osexec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v: %s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestCheckpointCommitsAreSquashed(t *testing.T) {
+	if _, err := osexec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	gitOutput(t, dir, "init", "-q", "-b", "main")
+	gitOutput(t, dir, "config", "user.email", "test@example.com")
+	gitOutput(t, dir, "config", "user.name", "Test")
+	writeFile := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	writeFile("main.go", "package main\n")
+	gitOutput(t, dir, "add", ".")
+	gitOutput(t, dir, "commit", "-q", "-m", "initial commit")
+	initial := gitOutput(t, dir, "rev-parse", "HEAD")
+
+	ctx := context.Background()
+	executor := execpkg.NewLocalExec()
+
+	// A clean tree creates no checkpoint.
+	base, created, err := commitCheckpoint(ctx, executor, dir, "tests passed", "")
+	if err != nil || created || base != "" {
+		t.Fatalf("Expected no checkpoint for a clean tree, got base=%q created=%v err=%v", base, created, err)
+	}
+
+	writeFile("a.go", "package main\n")
+	base, created, err = commitCheckpoint(ctx, executor, dir, "tests passed", "")
+	if err != nil || !created {
+		t.Fatalf("Expected a checkpoint, got created=%v err=%v", created, err)
+	}
+	if base != initial {
+		t.Errorf("Expected the checkpoint base to be the initial commit, got %s", base)
+	}
+
+	writeFile("b.go", "package main\n")
+	if base, _, err = commitCheckpoint(ctx, executor, dir, "todo_001 done: add b", base); err != nil || base != initial {
+		t.Fatalf("Expected the base to be kept, got %s (err: %v)", base, err)
+	}
+
+	squashed, err := squashCheckpoints(ctx, executor, dir, base)
+	if err != nil {
+		t.Fatalf("squashCheckpoints failed: %v", err)
+	}
+	if squashed != 2 {
+		t.Errorf("Expected 2 checkpoints squashed, got %d", squashed)
+	}
+	if head := gitOutput(t, dir, "rev-parse", "HEAD"); head != initial {
+		t.Errorf("Expected HEAD back at the base, got %s", head)
+	}
+	if staged := gitOutput(t, dir, "diff", "--cached", "--name-only"); staged != "a.go\nb.go" {
+		t.Errorf("Expected checkpoint changes to stay staged, got %q", staged)
+	}
+}
+
+func TestSquashCheckpointsKeepsOtherCommits(t *testing.T) {
+	if _, err := osexec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	gitOutput(t, dir, "init", "-q", "-b", "main")
+	gitOutput(t, dir, "config", "user.email", "test@example.com")
+	gitOutput(t, dir, "config", "user.name", "Test")
+	gitOutput(t, dir, "commit", "-q", "--allow-empty", "-m", "initial commit")
+	base := gitOutput(t, dir, "rev-parse", "HEAD")
+	gitOutput(t, dir, "commit", "-q", "--allow-empty", "-m", "Merge branch 'main'")
+
+	if _, err := squashCheckpoints(context.Background(), execpkg.NewLocalExec(), dir, base); err == nil {
+		t.Fatal("Expected squash to refuse rewriting a non-checkpoint commit")
+	}
+	if head := gitOutput(t, dir, "rev-parse", "HEAD"); head == base {
+		t.Error("Expected HEAD to be left alone")
+	}
+}
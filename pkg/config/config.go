@@ -198,12 +198,17 @@ type MetricsConfig struct {
 
 // AgentConfig defines which models to use and concurrency limits.
 type AgentConfig struct {
-	MaxCoders      int              `json:"max_coders"`      // must be <= CoderModel.MaxConnections
-	CoderModel     string           `json:"coder_model"`     // must match a Model.Name
-	ArchitectModel string           `json:"architect_model"` // must match a Model.Name
-	Metrics        MetricsConfig    `json:"metrics"`         // Metrics collection configuration
-	Resilience     ResilienceConfig `json:"resilience"`      // Resilience middleware configuration
-	StateTimeout   time.Duration    `json:"state_timeout"`   // Global timeout for any state processing
+	MaxCoders            int              `json:"max_coders"`            // must be <= CoderModel.MaxConnections
+	CoderModel           string           `json:"coder_model"`           // must match a Model.Name
+	ArchitectModel       string           `json:"architect_model"`       // must match a Model.Name
+	Metrics              MetricsConfig    `json:"metrics"`               // Metrics collection configuration
+	Resilience           ResilienceConfig `json:"resilience"`            // Resilience middleware configuration
+	StateTimeout         time.Duration    `json:"state_timeout"`         // Global timeout for any state processing
+	SelfReview           bool             `json:"self_review"`           // Coders review their own diff (SELF_REVIEW) before CODE_REVIEW
+	TestFirst            bool             `json:"test_first"`            // App stories get approved failing tests (TEST_DESIGN) before CODING
+	HandoffBranch        bool             `json:"handoff_branch"`        // Abandoned attempts push their branch; the next coder continues from it
+	SpecClarification    bool             `json:"spec_clarification"`    // Architect asks a human about spec ambiguities before generating stories
+	ClarificationTimeout time.Duration    `json:"clarification_timeout"` // Wait for answers before proceeding with assumptions (default 30m)
 }
 
 // All constants bundled together for easy maintenance.
@@ -277,6 +282,10 @@ const (
 	ProviderOpenAI         = "openai"
 	ProviderOpenAIOfficial = "openai_official"
 
+	// Agent types that may be granted MCP server tools.
+	MCPAgentCoder     = "coder"
+	MCPAgentArchitect = "architect"
+
 	// API key environment variable names.
 	EnvAnthropicAPIKey = "ANTHROPIC_API_KEY"
 	EnvOpenAIAPIKey    = "OPENAI_API_KEY"
@@ -319,6 +328,7 @@ type Config struct {
 	Build     *BuildConfig     `json:"build"`     // Build commands and targets
 	Agents    *AgentConfig     `json:"agents"`    // Which models to use for this project
 	Git       *GitConfig       `json:"git"`       // Git repository and branching settings
+	MCP       *MCPConfig       `json:"mcp"`       // External MCP servers exposed as agent tools
 
 	// === SYSTEM-WIDE ORCHESTRATOR SETTINGS ===
 	Orchestrator *OrchestratorConfig `json:"orchestrator"` // LLM models, rate limits, budgets
@@ -349,6 +359,16 @@ type ContainerConfig struct {
 	PIDs      int64  `json:"pids,omitempty"`       // Docker --pids-limit setting
 }
 
+// DefaultClarificationTimeout is how long the architect waits for answers to its spec
+// questions when clarification_timeout is unset.
+const DefaultClarificationTimeout = 30 * time.Minute
+
+// Flaky test re-run bounds.
+const (
+	DefaultFlakyRetries = 2  // Re-runs per failing test when flaky_retries is unset
+	MaxFlakyRetries     = 10 // Upper bound on flaky_retries
+)
+
 // BuildConfig defines build targets and commands.
 type BuildConfig struct {
 	// Required targets (must exist)
@@ -360,6 +380,29 @@ type BuildConfig struct {
 	// Optional targets
 	Clean   string `json:"clean,omitempty"`   // Clean command
 	Install string `json:"install,omitempty"` // Install command
+
+	// Minimum line coverage (percent) required for lines changed by a story; 0 disables the gate
+	MinChangedCoverage float64 `json:"min_changed_coverage,omitempty"`
+
+	// Isolated re-runs of each failing test used to tell flaky tests from real failures
+	// (0 uses the default of 2, -1 disables flaky test detection)
+	FlakyRetries int `json:"flaky_retries,omitempty"`
+}
+
+// MCPConfig lists external Model Context Protocol servers whose tools are made available to agents.
+type MCPConfig struct {
+	Servers []MCPServerConfig `json:"servers,omitempty"` // Stdio-launched MCP servers
+}
+
+// MCPServerConfig describes a single stdio-launched MCP server.
+// Discovered tools are registered as "<name>__<tool>" to avoid collisions with built-in tools.
+type MCPServerConfig struct {
+	Name         string            `json:"name"`                    // Namespace prefix for discovered tools (letters, digits, '-' and '_')
+	Command      string            `json:"command"`                 // Executable to launch
+	Args         []string          `json:"args,omitempty"`          // Command-line arguments
+	Env          map[string]string `json:"env,omitempty"`           // Extra environment variables for the server process
+	AllowedTools []string          `json:"allowed_tools,omitempty"` // Remote tool names to expose (empty = all)
+	Agents       []string          `json:"agents,omitempty"`        // Agent types that may use the tools (default: coder, architect)
 }
 
 // GetProjectMaestroDir returns the path to the .maestro directory containing all maestro files.
@@ -657,6 +700,7 @@ func createDefaultConfig() *Config {
 			GitUserName:   DefaultGitUserName,
 			GitUserEmail:  DefaultGitUserEmail,
 		},
+		MCP: &MCPConfig{},
 
 		// Orchestrator settings
 		Orchestrator: &OrchestratorConfig{
@@ -760,6 +804,9 @@ func applyDefaults(config *Config) {
 	if config.Git == nil {
 		config.Git = &GitConfig{}
 	}
+	if config.MCP == nil {
+		config.MCP = &MCPConfig{}
+	}
 	if config.Orchestrator == nil {
 		config.Orchestrator = &OrchestratorConfig{}
 	}
@@ -821,6 +868,13 @@ func applyDefaults(config *Config) {
 		}
 	}
 
+	// Apply MCP server defaults
+	for i := range config.MCP.Servers {
+		if len(config.MCP.Servers[i].Agents) == 0 {
+			config.MCP.Servers[i].Agents = []string{MCPAgentCoder, MCPAgentArchitect}
+		}
+	}
+
 	// Apply agent defaults
 	if config.Agents.MaxCoders == 0 {
 		config.Agents.MaxCoders = 2
@@ -975,6 +1029,21 @@ func validateConfig(config *Config) error {
 		}
 	}
 
+	// Validate MCP servers
+	if config.MCP != nil {
+		if err := validateMCPConfig(config.MCP); err != nil {
+			return fmt.Errorf("mcp config validation failed: %w", err)
+		}
+	}
+
+	// Validate build settings
+	if config.Build != nil && (config.Build.MinChangedCoverage < 0 || config.Build.MinChangedCoverage > 100) {
+		return fmt.Errorf("build min_changed_coverage must be between 0 and 100, got %v", config.Build.MinChangedCoverage)
+	}
+	if config.Build != nil && (config.Build.FlakyRetries < -1 || config.Build.FlakyRetries > MaxFlakyRetries) {
+		return fmt.Errorf("build flaky_retries must be between -1 and %d, got %d", MaxFlakyRetries, config.Build.FlakyRetries)
+	}
+
 	// Validate Git settings (RepoURL is optional - may not be using Git worktrees yet)
 	if config.Git != nil && config.Git.RepoURL != "" {
 		if !strings.HasPrefix(config.Git.RepoURL, "git@") && !strings.HasPrefix(config.Git.RepoURL, "https://") {
@@ -985,6 +1054,35 @@ func validateConfig(config *Config) error {
 	return nil
 }
 
+// validateMCPConfig checks that MCP server entries are launchable and uniquely named.
+func validateMCPConfig(mcp *MCPConfig) error {
+	seen := make(map[string]bool, len(mcp.Servers))
+	for i := range mcp.Servers {
+		server := &mcp.Servers[i]
+		if server.Name == "" {
+			return fmt.Errorf("server[%d]: name is required", i)
+		}
+		for _, r := range server.Name {
+			if !(r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
+				return fmt.Errorf("server %s: name may only contain letters, digits, '-' and '_'", server.Name)
+			}
+		}
+		if seen[server.Name] {
+			return fmt.Errorf("server %s: duplicate name", server.Name)
+		}
+		seen[server.Name] = true
+		if server.Command == "" {
+			return fmt.Errorf("server %s: command is required", server.Name)
+		}
+		for _, agentType := range server.Agents {
+			if agentType != MCPAgentCoder && agentType != MCPAgentArchitect {
+				return fmt.Errorf("server %s: unknown agent type '%s'", server.Name, agentType)
+			}
+		}
+	}
+	return nil
+}
+
 // validateRequiredAPIKeys checks that all required API keys are present for the configured models.
 func validateRequiredAPIKeys(cfg *Config) error {
 	if cfg.Agents == nil {
@@ -61,6 +61,8 @@ type ContextManager struct {
 	userBuffer      []Fragment    // Buffer for user content with provenance
 	modelConfig     *config.Model // Model configuration for limits
 	currentTemplate string        // Current template name for change detection
+	spiller         Spiller       // Optional destination for oversized output
+	pinned          []Fragment    // Content re-attached to the system prompt on every turn
 }
 
 // NewContextManager creates a new context manager instance.
@@ -162,6 +164,11 @@ func (cm *ContextManager) CountTokens() int {
 		totalLength += len(fragment.Content)
 	}
 
+	// Pinned content is sent with every request
+	for i := range cm.pinned {
+		totalLength += len(cm.pinned[i].Content)
+	}
+
 	return totalLength
 }
 
@@ -359,11 +366,55 @@ func deduplicateStrings(slice []string) []string {
 }
 
 // GetMessages returns a copy of all messages in the context.
+// Pinned content is appended to the system prompt so it is present on every turn.
 func (cm *ContextManager) GetMessages() []Message {
 	// Return a copy to prevent external modification.
 	result := make([]Message, len(cm.messages))
 	copy(result, cm.messages)
-	return result
+
+	pinned := cm.pinnedContent()
+	if pinned == "" {
+		return result
+	}
+	if len(result) > 0 && result[0].Role == "system" {
+		result[0].Content = strings.TrimSpace(result[0].Content + "\n\n" + pinned)
+		return result
+	}
+	return append([]Message{{Role: "system", Content: pinned}}, result...)
+}
+
+// Pin attaches content under the given provenance to every request, surviving compaction
+// and template resets. Pinning again with the same provenance replaces the content;
+// pinning empty content removes it.
+func (cm *ContextManager) Pin(provenance, content string) {
+	content = strings.TrimSpace(content)
+	for i := range cm.pinned {
+		if cm.pinned[i].Provenance != provenance {
+			continue
+		}
+		if content == "" {
+			cm.pinned = append(cm.pinned[:i], cm.pinned[i+1:]...)
+		} else {
+			cm.pinned[i].Content = content
+			cm.pinned[i].Timestamp = time.Now()
+		}
+		return
+	}
+	if content != "" {
+		cm.pinned = append(cm.pinned, Fragment{Provenance: provenance, Content: content, Timestamp: time.Now()})
+	}
+}
+
+// pinnedContent returns all pinned fragments joined for inclusion in the system prompt.
+func (cm *ContextManager) pinnedContent() string {
+	if len(cm.pinned) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(cm.pinned))
+	for i := range cm.pinned {
+		parts = append(parts, cm.pinned[i].Content)
+	}
+	return strings.Join(parts, "\n\n")
 }
 
 // GetModelConfig returns the model configuration.
@@ -502,6 +553,11 @@ func (cm *ContextManager) truncateOutputIfNeeded(content string) string {
 		return content
 	}
 
+	// Keep the full output on disk when possible so nothing is lost
+	if summary, ok := cm.spillOutput(content); ok {
+		return summary
+	}
+
 	// Truncate and add clear indicator
 	truncated := content[:maxOutputLength]
 	return truncated + "\n\n[... output truncated after " + fmt.Sprintf("%d", maxOutputLength) + " characters for context management ...]"
@@ -1,6 +1,9 @@
 package contextmgr
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -580,3 +583,75 @@ func TestCreateConversationSummary(t *testing.T) {
 		t.Errorf("Summary should be concise, got %d characters", len(summary))
 	}
 }
+
+func TestOversizedOutputSpillsToFile(t *testing.T) {
+	hostDir := t.TempDir()
+	cm := NewContextManager()
+	cm.SetSpiller(NewFileSpiller(hostDir, "/workspace/.scratch"))
+
+	var output strings.Builder
+	for i := 0; i < 500; i++ {
+		output.WriteString(fmt.Sprintf("line %d\n", i))
+	}
+	output.WriteString("FAIL: TestImportant")
+	cm.AddMessage("tool", output.String())
+
+	content := cm.userBuffer[0].Content
+	if !strings.Contains(content, "line 0") || !strings.Contains(content, "FAIL: TestImportant") {
+		t.Errorf("Expected head and tail to be kept, got %q", content)
+	}
+	if !strings.Contains(content, "/workspace/.scratch/output-001.log") || !strings.Contains(content, "501 lines") {
+		t.Errorf("Expected spill path and line count, got %q", content)
+	}
+
+	saved, err := os.ReadFile(filepath.Join(hostDir, "output-001.log"))
+	if err != nil {
+		t.Fatalf("Expected spill file: %v", err)
+	}
+	if string(saved) != output.String() {
+		t.Error("Expected spill file to contain the full output")
+	}
+
+	// Short output is left alone
+	cm.AddMessage("tool", "ok")
+	if cm.userBuffer[1].Content != "ok" {
+		t.Errorf("Expected short output unchanged, got %q", cm.userBuffer[1].Content)
+	}
+}
+
+func TestPinnedContentSurvivesCompactionAndReset(t *testing.T) {
+	cm := NewContextManager()
+	cm.ResetSystemPrompt("You are a coder.")
+	cm.Pin("notes", "## Story Notes\nTestFoo is flaky")
+
+	for i := 0; i < 20; i++ {
+		cm.AddMessage("tool", strings.Repeat("x", 500))
+		if err := cm.FlushUserBuffer(); err != nil {
+			t.Fatalf("Flush failed: %v", err)
+		}
+		cm.AddAssistantMessage("ok")
+	}
+	if err := cm.Compact(1500); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+
+	messages := cm.GetMessages()
+	if !strings.Contains(messages[0].Content, "You are a coder.") || !strings.Contains(messages[0].Content, "TestFoo is flaky") {
+		t.Errorf("Expected pinned notes in system prompt after compaction, got %q", messages[0].Content)
+	}
+
+	cm.ResetForNewTemplate("coding", "Now write code.")
+	cm.Pin("notes", "## Story Notes\nDon't touch pkg/legacy")
+	messages = cm.GetMessages()
+	if !strings.Contains(messages[0].Content, "Don't touch pkg/legacy") || strings.Contains(messages[0].Content, "TestFoo") {
+		t.Errorf("Expected replaced notes after template reset, got %q", messages[0].Content)
+	}
+	if cm.messages[0].Content != "Now write code." {
+		t.Errorf("Pinned content must not be stored in the conversation, got %q", cm.messages[0].Content)
+	}
+
+	cm.Pin("notes", "")
+	if strings.Contains(cm.GetMessages()[0].Content, "Story Notes") {
+		t.Error("Expected empty pin to remove notes")
+	}
+}
@@ -0,0 +1,93 @@
+package contextmgr
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	// spillHeadLength is the number of characters kept from the start of spilled output.
+	spillHeadLength = 1000
+	// spillTailLength is the number of characters kept from the end of spilled output.
+	spillTailLength = 800
+)
+
+// Spiller persists tool output that is too large to keep in the context window,
+// returning the path the model should use to page through it.
+type Spiller interface {
+	Spill(content string) (string, error)
+}
+
+// FileSpiller writes oversized output to numbered files in a scratch directory.
+// The directory is addressed by two paths: hostDir is where the files are written,
+// displayDir is how the agent sees the same directory (e.g. inside its container).
+//
+//nolint:govet // fieldalignment: Logical grouping preferred over memory optimization
+type FileSpiller struct {
+	hostDir    string
+	displayDir string
+	mu         sync.Mutex
+	seq        int
+}
+
+// NewFileSpiller creates a spiller writing to hostDir and reporting paths under displayDir.
+// If displayDir is empty, hostDir is reported.
+func NewFileSpiller(hostDir, displayDir string) *FileSpiller {
+	if displayDir == "" {
+		displayDir = hostDir
+	}
+	return &FileSpiller{hostDir: hostDir, displayDir: displayDir}
+}
+
+// Spill writes content to the next output file and returns its display path.
+func (s *FileSpiller) Spill(content string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.hostDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create spill directory: %w", err)
+	}
+
+	s.seq++
+	name := fmt.Sprintf("output-%03d.log", s.seq)
+	if err := os.WriteFile(filepath.Join(s.hostDir, name), []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("failed to write spill file: %w", err)
+	}
+	return path.Join(s.displayDir, name), nil
+}
+
+// SetSpiller configures where oversized content is saved before truncation.
+// With no spiller, oversized content is truncated and the remainder discarded.
+func (cm *ContextManager) SetSpiller(spiller Spiller) {
+	cm.spiller = spiller
+}
+
+// spillOutput saves the full content and returns its head and tail with a pointer to the file.
+// Returns false if the content could not be spilled.
+func (cm *ContextManager) spillOutput(content string) (string, bool) {
+	if cm.spiller == nil {
+		return "", false
+	}
+
+	spillPath, err := cm.spiller.Spill(content)
+	if err != nil {
+		return "", false
+	}
+
+	lineCount := strings.Count(content, "\n") + 1
+	head := content[:spillHeadLength]
+	tail := content[len(content)-spillTailLength:]
+
+	var summary strings.Builder
+	summary.WriteString(head)
+	summary.WriteString(fmt.Sprintf("\n\n[... %d characters omitted ...]\n\n", len(content)-spillHeadLength-spillTailLength))
+	summary.WriteString(tail)
+	summary.WriteString(fmt.Sprintf("\n\n[Output too large for context: full output (%d lines, %d characters) saved to %s. "+
+		"Page through it with shell commands such as `sed -n '1,200p' %s` or `grep -n -i error %s`.]",
+		lineCount, len(content), spillPath, spillPath, spillPath))
+	return summary.String(), true
+}
@@ -1078,6 +1078,48 @@ func (d *Dispatcher) UpdateStoryStatus(storyID, status string) error {
 	}
 }
 
+// UpdateStoryTodos sends a story's plan-todo progress to the architect via the status channel.
+// The story status itself is left unchanged.
+func (d *Dispatcher) UpdateStoryTodos(storyID, agentID string, todos []proto.StoryTodo) error {
+	statusUpdate := &proto.StoryStatusUpdate{
+		StoryID:   storyID,
+		Timestamp: time.Now().UTC(),
+		AgentID:   agentID,
+		Todos:     todos,
+	}
+
+	// Send to status updates channel (non-blocking)
+	select {
+	case d.statusUpdatesCh <- statusUpdate:
+		d.logger.Debug("Story %s todo progress sent to architect", storyID)
+		return nil
+	default:
+		d.logger.Warn("❌ Status updates channel full, dropping todo progress for story %s", storyID)
+		return fmt.Errorf("status updates channel full")
+	}
+}
+
+// ReportFlakyTests sends the flaky tests seen while testing a story to the architect via the
+// status channel, so it can schedule a fix-up story. The story status itself is left unchanged.
+func (d *Dispatcher) ReportFlakyTests(storyID, agentID string, flakyTests []proto.FlakyTest) error {
+	statusUpdate := &proto.StoryStatusUpdate{
+		StoryID:    storyID,
+		Timestamp:  time.Now().UTC(),
+		AgentID:    agentID,
+		FlakyTests: flakyTests,
+	}
+
+	// Send to status updates channel (non-blocking)
+	select {
+	case d.statusUpdatesCh <- statusUpdate:
+		d.logger.Debug("Story %s flaky test report sent to architect", storyID)
+		return nil
+	default:
+		d.logger.Warn("❌ Status updates channel full, dropping flaky test report for story %s", storyID)
+		return fmt.Errorf("status updates channel full")
+	}
+}
+
 // GetContainerRegistry returns the container registry for orchestrator access.
 func (d *Dispatcher) GetContainerRegistry() *exec.ContainerRegistry {
 	return d.containerRegistry
@@ -3,6 +3,7 @@ package effect
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"orchestrator/pkg/proto"
@@ -16,6 +17,7 @@ type ApprovalEffect struct {
 	StoryID      string             // Story ID for this approval request (required by architect)
 	TargetAgent  string             // Target agent (typically "architect")
 	Timeout      time.Duration      // Timeout for waiting for response
+	ExtraPayload map[string]any     // Additional payload fields for type-specific data (e.g. a proposed split)
 }
 
 // Execute sends an approval request and blocks waiting for the architect's response.
@@ -31,6 +33,9 @@ func (e *ApprovalEffect) Execute(ctx context.Context, runtime Runtime) (any, err
 	approvalMsg.SetPayload("reason", e.Reason)
 	approvalMsg.SetPayload("approval_id", approvalID)
 	approvalMsg.SetPayload("story_id", e.StoryID) // Include story_id that architect expects
+	for key, value := range e.ExtraPayload {
+		approvalMsg.SetPayload(key, value)
+	}
 
 	runtime.Info("📤 Sending %s approval request %s to %s", e.ApprovalType.String(), approvalID, e.TargetAgent)
 
@@ -137,3 +142,27 @@ func NewCompletionApprovalEffectWithStoryID(summary, filesCreated, storyID strin
 	effect.StoryID = storyID // Set the story ID for the message payload
 	return effect
 }
+
+// NewSplitApprovalEffectWithStoryID creates an approval effect proposing to split a story into sub-stories.
+func NewSplitApprovalEffectWithStoryID(split *proto.StorySplitPayload, storyID string) *ApprovalEffect {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Proposed split of Story %s:\n\nReason:\n%s\n", storyID, split.Reason))
+	for i := range split.Stories {
+		sub := &split.Stories[i]
+		sb.WriteString(fmt.Sprintf("\n### Sub-story %d: %s\n", i+1, sub.Title))
+		if len(sub.DependsOn) > 0 {
+			deps := make([]string, 0, len(sub.DependsOn))
+			for _, dep := range sub.DependsOn {
+				deps = append(deps, fmt.Sprintf("%d", dep+1))
+			}
+			sb.WriteString(fmt.Sprintf("Depends on sub-stories: %s\n", strings.Join(deps, ", ")))
+		}
+		sb.WriteString(sub.Content + "\n")
+	}
+
+	reason := fmt.Sprintf("Story split requires architect approval (Story %s, %d sub-stories)", storyID, len(split.Stories))
+	effect := NewApprovalEffect(sb.String(), reason, proto.ApprovalTypeSplit)
+	effect.StoryID = storyID
+	effect.ExtraPayload = map[string]any{proto.KeyStorySplit: split}
+	return effect
+}
@@ -151,3 +151,21 @@ func NewEmptyResponseBudgetReviewEffect(originState string, consecutiveCount int
 
 	return effect
 }
+
+// NewLoopDetectedBudgetReviewEffect creates a budget review effect for a failure loop that
+// persisted after the agent was warned about it.
+func NewLoopDetectedBudgetReviewEffect(originState, evidence string, repeats int) *BudgetReviewEffect {
+	content := fmt.Sprintf("Repeated failure loop detected in %s state: the same failure occurred %d times, "+
+		"and the agent kept repeating it after being warned.\n\nLoop evidence:\n%s\n\n"+
+		"Please provide guidance: CONTINUE (same approach), PIVOT (change approach), or ABANDON (stop task).",
+		originState, repeats, evidence)
+
+	reason := "BUDGET_REVIEW: Repeated failure loop detected, requesting guidance"
+
+	effect := NewBudgetReviewEffect(content, reason, originState)
+	effect.ExtraPayload["issue_type"] = "failure_loop"
+	effect.ExtraPayload["issue_pattern"] = evidence
+	effect.ExtraPayload["loop_repeats"] = repeats
+
+	return effect
+}
@@ -9,17 +9,25 @@ import (
 )
 
 // AwaitQuestionEffect represents an async question request effect.
+//
+//nolint:govet // fieldalignment: Logical grouping preferred over memory optimization
 type AwaitQuestionEffect struct {
-	Question    string
-	Context     string
-	Urgency     string
-	OriginState string
-	StoryID     string // Story ID for message payload (required by dispatcher)
-	TargetAgent string
-	Timeout     time.Duration
+	Question          string
+	Context           string
+	Urgency           string
+	OriginState       string
+	StoryID           string // Story ID for message payload (required by dispatcher)
+	TargetAgent       string
+	Timeout           time.Duration
+	Options           []string               // Candidate answers for a multiple-choice question
+	RecommendedOption string                 // The asker's preferred option
+	Category          proto.QuestionCategory // technical, business or environment
+	NonBlocking       bool                   // Return as soon as the question is sent
 }
 
-// Execute sends a question request and blocks waiting for the answer.
+// Execute sends a question request and blocks waiting for the answer. Non-blocking
+// questions return a pending result carrying the correlation ID as soon as they are sent;
+// the answer arrives later on the agent's reply channel.
 func (e *AwaitQuestionEffect) Execute(ctx context.Context, runtime Runtime) (any, error) {
 	agentID := runtime.GetAgentID()
 
@@ -27,11 +35,16 @@ func (e *AwaitQuestionEffect) Execute(ctx context.Context, runtime Runtime) (any
 	questionMsg := proto.NewAgentMsg(proto.MsgTypeREQUEST, agentID, e.TargetAgent)
 	questionMsg.SetPayload(proto.KeyKind, string(proto.RequestKindQuestion))
 	questionMsg.SetPayload(proto.KeyQuestion, proto.QuestionRequestPayload{
-		Text:    e.Question,
-		Context: fmt.Sprintf("%s clarification (%s urgency)", e.OriginState, e.Urgency),
-		Urgency: e.Urgency,
+		Text:              e.Question,
+		Context:           fmt.Sprintf("%s clarification (%s urgency)", e.OriginState, e.Urgency),
+		Urgency:           e.Urgency,
+		Options:           e.Options,
+		RecommendedOption: e.RecommendedOption,
+		Category:          e.Category,
+		NonBlocking:       e.NonBlocking,
 	})
-	questionMsg.SetPayload(proto.KeyCorrelationID, proto.GenerateCorrelationID())
+	correlationID := proto.GenerateCorrelationID()
+	questionMsg.SetPayload(proto.KeyCorrelationID, correlationID)
 	questionMsg.SetPayload(proto.KeyStoryID, e.StoryID) // Include story_id that dispatcher requires
 
 	if e.Context != "" {
@@ -49,6 +62,11 @@ func (e *AwaitQuestionEffect) Execute(ctx context.Context, runtime Runtime) (any
 		return nil, fmt.Errorf("failed to send question: %w", err)
 	}
 
+	if e.NonBlocking {
+		runtime.Info("📤 Non-blocking question %s sent, continuing without waiting", correlationID)
+		return &QuestionResult{CorrelationID: correlationID, Pending: true}, nil
+	}
+
 	// Create timeout context
 	timeoutCtx := ctx
 	if e.Timeout > 0 {
@@ -78,8 +96,10 @@ func (e *AwaitQuestionEffect) Execute(ctx context.Context, runtime Runtime) (any
 	}
 
 	result := &QuestionResult{
-		Answer: answerContent,
-		Data:   answerMsg.Payload,
+		Answer:         answerContent,
+		SelectedOption: SelectedOptionFromPayload(answerMsg.Payload),
+		CorrelationID:  correlationID,
+		Data:           answerMsg.Payload,
 	}
 
 	runtime.Info("✅ Received answer from %s", e.TargetAgent)
@@ -92,9 +112,22 @@ func (e *AwaitQuestionEffect) Type() string {
 }
 
 // QuestionResult represents the result of a question request.
+//
+//nolint:govet // fieldalignment: JSON serialization struct, logical order preferred
 type QuestionResult struct {
-	Data   map[string]any `json:"data,omitempty"`
-	Answer string         `json:"answer"`
+	Data           map[string]any `json:"data,omitempty"`
+	Answer         string         `json:"answer"`
+	SelectedOption string         `json:"selected_option,omitempty"` // Option chosen for a multiple-choice question
+	CorrelationID  string         `json:"correlation_id,omitempty"`
+	Pending        bool           `json:"pending,omitempty"` // Non-blocking question sent, answer not yet received
+}
+
+// SelectedOptionFromPayload returns the option the architect chose, if any.
+func SelectedOptionFromPayload(payload map[string]any) string {
+	if selected, ok := payload[proto.KeySelectedOption].(string); ok {
+		return selected
+	}
+	return ""
 }
 
 // NewQuestionEffect creates an effect for question requests.
@@ -0,0 +1,108 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	execpkg "orchestrator/pkg/exec"
+)
+
+// maxUntrackedFiles bounds how many untracked files are diffed when collecting changed lines.
+const maxUntrackedFiles = 200
+
+// ChangedLines returns, per repository-relative file, the line numbers in the working tree
+// that differ from the merge base with baseBranch. Uncommitted and untracked files are
+// included, since coverage is measured before the story's changes are committed.
+func ChangedLines(ctx context.Context, baseBranch, workDir string, executor GitExecutor) (map[string][]int, error) {
+	run := func(args ...string) (execpkg.Result, error) {
+		opts := &execpkg.Opts{WorkDir: workDir, Timeout: 30 * time.Second}
+		return executor.Run(ctx, append([]string{"git"}, args...), opts)
+	}
+
+	base := baseBranch
+	if result, err := run("merge-base", baseBranch, "HEAD"); err == nil && result.ExitCode == 0 && strings.TrimSpace(result.Stdout) != "" {
+		base = strings.TrimSpace(result.Stdout)
+	}
+
+	result, err := run("diff", "--unified=0", "--no-color", "--no-ext-diff", base)
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed: %w", err)
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("git diff failed: %s", strings.TrimSpace(result.Stderr))
+	}
+	changed := ParseChangedLines(result.Stdout)
+
+	untracked, err := run("ls-files", "--others", "--exclude-standard")
+	if err != nil || untracked.ExitCode != 0 {
+		return changed, nil //nolint:nilerr // Untracked files are best effort
+	}
+	files := strings.Fields(untracked.Stdout)
+	if len(files) > maxUntrackedFiles {
+		files = files[:maxUntrackedFiles]
+	}
+	for _, file := range files {
+		// --no-index exits 1 when files differ, which is always the case here.
+		diff, diffErr := run("diff", "--no-index", "--unified=0", "--no-color", "/dev/null", file)
+		if diffErr != nil {
+			continue
+		}
+		for path, lines := range ParseChangedLines(diff.Stdout) {
+			changed[path] = append(changed[path], lines...)
+		}
+	}
+	return changed, nil
+}
+
+// ParseChangedLines extracts added or modified line numbers (in the new version) from a
+// unified diff produced with --unified=0. Deleted files are omitted.
+func ParseChangedLines(diff string) map[string][]int {
+	changed := make(map[string][]int)
+	current := ""
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			target := strings.TrimPrefix(line, "+++ ")
+			if target == "/dev/null" {
+				current = ""
+				continue
+			}
+			current = strings.TrimPrefix(target, "b/")
+		case strings.HasPrefix(line, "@@ ") && current != "":
+			start, count, ok := parseHunkTarget(line)
+			if !ok {
+				continue
+			}
+			for l := start; l < start+count; l++ {
+				changed[current] = append(changed[current], l)
+			}
+		}
+	}
+	return changed
+}
+
+// parseHunkTarget extracts the new-file range from a hunk header "@@ -a,b +c,d @@".
+func parseHunkTarget(header string) (start, count int, ok bool) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, false
+	}
+	spec := strings.TrimPrefix(fields[2], "+")
+	startStr, countStr, hasCount := strings.Cut(spec, ",")
+
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return 0, 0, false
+	}
+	count = 1
+	if hasCount {
+		if count, err = strconv.Atoi(countStr); err != nil {
+			return 0, 0, false
+		}
+	}
+	return start, count, true
+}
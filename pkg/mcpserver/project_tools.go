@@ -0,0 +1,574 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"orchestrator/pkg/agent"
+	"orchestrator/pkg/architect"
+	"orchestrator/pkg/dispatch"
+	"orchestrator/pkg/proto"
+	"orchestrator/pkg/tools"
+)
+
+// Project operation tool names.
+const (
+	ToolListStories      = "maestro_list_stories"
+	ToolStoryStatus      = "maestro_story_status"
+	ToolSubmitSpec       = "maestro_submit_spec"
+	ToolListEscalations  = "maestro_list_escalations"
+	ToolAnswerEscalation = "maestro_answer_escalation"
+	ToolAgentState       = "maestro_agent_state"
+
+	// mcpOperator is recorded as the human operator for escalations answered over MCP.
+	mcpOperator = "mcp-client"
+)
+
+// Orchestrator is the subset of the dispatcher the project tools need.
+type Orchestrator interface {
+	GetRegisteredAgents() []dispatch.AgentInfo
+	DispatchMessage(msg *proto.AgentMsg) error
+}
+
+// storyProvider is implemented by the architect driver.
+type storyProvider interface {
+	GetStoryList() []*architect.QueuedStory
+}
+
+// specRevisionProvider is implemented by the architect driver.
+type specRevisionProvider interface {
+	SubmitSpecRevision(specID, content string) error
+}
+
+// escalationProvider is implemented by the architect driver.
+type escalationProvider interface {
+	GetEscalationHandler() *architect.EscalationHandler
+}
+
+// RegisterProjectTools registers all project operation tools on the server.
+func RegisterProjectTools(s *Server, orch Orchestrator) {
+	s.Register(&ListStoriesTool{orch: orch})
+	s.Register(&StoryStatusTool{orch: orch})
+	s.Register(&SubmitSpecTool{orch: orch})
+	s.Register(&ListEscalationsTool{orch: orch})
+	s.Register(&AnswerEscalationTool{orch: orch})
+	s.Register(&AgentStateTool{orch: orch})
+}
+
+// exposableTools are the registry tools that only read project information. Everything else
+// runs commands on the host or changes state on behalf of any client, so it is never exposed;
+// new tools stay hidden until they are added here.
+//
+//nolint:gochecknoglobals // Fixed set of tool names
+var exposableTools = map[string]bool{
+	tools.ToolBackendInfo: true,
+}
+
+// RegisterRegistryTools exposes read-only tools from the global Maestro tool registry,
+// instantiated with the given agent context, alongside the project operations. Tools that
+// are not known to be read-only are refused.
+func RegisterRegistryTools(s *Server, agentCtx tools.AgentContext, names []string) error {
+	for _, name := range names {
+		if !exposableTools[name] {
+			return fmt.Errorf("tool %s is not read-only and cannot be exposed over MCP", name)
+		}
+	}
+
+	provider := tools.NewProvider(agentCtx, names)
+	for _, name := range names {
+		tool, err := provider.Get(name)
+		if err != nil {
+			return fmt.Errorf("failed to expose tool %s: %w", name, err)
+		}
+		s.Register(tool)
+	}
+	return nil
+}
+
+// findArchitect returns the registered architect driver.
+func findArchitect(orch Orchestrator) (agent.Driver, error) {
+	agents := orch.GetRegisteredAgents()
+	for i := range agents {
+		if agents[i].Type == agent.TypeArchitect && agents[i].Driver != nil {
+			return agents[i].Driver, nil
+		}
+	}
+	return nil, fmt.Errorf("no architect agent registered")
+}
+
+// getStories returns the architect's story list.
+func getStories(orch Orchestrator) ([]*architect.QueuedStory, error) {
+	driver, err := findArchitect(orch)
+	if err != nil {
+		return nil, err
+	}
+	provider, ok := driver.(storyProvider)
+	if !ok {
+		return nil, fmt.Errorf("architect does not provide stories")
+	}
+	return provider.GetStoryList(), nil
+}
+
+// getEscalationHandler returns the architect's escalation handler.
+func getEscalationHandler(orch Orchestrator) (*architect.EscalationHandler, error) {
+	driver, err := findArchitect(orch)
+	if err != nil {
+		return nil, err
+	}
+	provider, ok := driver.(escalationProvider)
+	if !ok || provider.GetEscalationHandler() == nil {
+		return nil, fmt.Errorf("architect does not provide escalations")
+	}
+	return provider.GetEscalationHandler(), nil
+}
+
+// stringArg extracts an optional string argument.
+func stringArg(args map[string]any, key string) string {
+	if value, ok := args[key].(string); ok {
+		return value
+	}
+	return ""
+}
+
+// ListStoriesTool lists stories known to the architect.
+type ListStoriesTool struct {
+	orch Orchestrator
+}
+
+// Definition returns the tool's definition in Claude API format.
+func (t *ListStoriesTool) Definition() tools.ToolDefinition {
+	return tools.ToolDefinition{
+		Name:        ToolListStories,
+		Description: "List all stories with their status and assigned agent",
+		InputSchema: tools.InputSchema{
+			Type: "object",
+			Properties: map[string]tools.Property{
+				"status": {
+					Type:        "string",
+					Description: "Only return stories with this status (e.g. pending, coding, done)",
+				},
+			},
+		},
+	}
+}
+
+// Name returns the tool identifier.
+func (t *ListStoriesTool) Name() string {
+	return ToolListStories
+}
+
+// PromptDocumentation returns markdown documentation for LLM prompts.
+func (t *ListStoriesTool) PromptDocumentation() string {
+	return `- **maestro_list_stories** - List stories with status and assigned agent
+  - Parameters: status (optional filter)`
+}
+
+// Exec executes the list operation.
+func (t *ListStoriesTool) Exec(_ context.Context, args map[string]any) (any, error) {
+	stories, err := getStories(t.orch)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := stringArg(args, "status")
+	summaries := make([]map[string]any, 0, len(stories))
+	for _, story := range stories {
+		if filter != "" && story.Status != filter {
+			continue
+		}
+		summaries = append(summaries, map[string]any{
+			"id":             story.ID,
+			"title":          story.Title,
+			"status":         story.Status,
+			"story_type":     story.StoryType,
+			"assigned_agent": story.AssignedAgent,
+			"depends_on":     story.DependsOn,
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i]["id"].(string) < summaries[j]["id"].(string)
+	})
+
+	return map[string]any{
+		"success": true,
+		"count":   len(summaries),
+		"stories": summaries,
+	}, nil
+}
+
+// StoryStatusTool returns the full record for a single story.
+type StoryStatusTool struct {
+	orch Orchestrator
+}
+
+// Definition returns the tool's definition in Claude API format.
+func (t *StoryStatusTool) Definition() tools.ToolDefinition {
+	return tools.ToolDefinition{
+		Name:        ToolStoryStatus,
+		Description: "Show the status, content and progress details of a single story",
+		InputSchema: tools.InputSchema{
+			Type: "object",
+			Properties: map[string]tools.Property{
+				"story_id": {
+					Type:        "string",
+					Description: "ID of the story to show",
+				},
+			},
+			Required: []string{"story_id"},
+		},
+	}
+}
+
+// Name returns the tool identifier.
+func (t *StoryStatusTool) Name() string {
+	return ToolStoryStatus
+}
+
+// PromptDocumentation returns markdown documentation for LLM prompts.
+func (t *StoryStatusTool) PromptDocumentation() string {
+	return `- **maestro_story_status** - Show details of a single story
+  - Parameters: story_id (required)`
+}
+
+// Exec executes the story lookup.
+func (t *StoryStatusTool) Exec(_ context.Context, args map[string]any) (any, error) {
+	storyID := stringArg(args, "story_id")
+	if storyID == "" {
+		return nil, fmt.Errorf("story_id parameter is required")
+	}
+
+	stories, err := getStories(t.orch)
+	if err != nil {
+		return nil, err
+	}
+	for _, story := range stories {
+		if story.ID == storyID {
+			return map[string]any{
+				"success": true,
+				"story":   story.Story,
+			}, nil
+		}
+	}
+
+	return map[string]any{
+		"success": false,
+		"error":   fmt.Sprintf("story %s not found", storyID),
+	}, nil
+}
+
+// SubmitSpecTool sends a specification to the architect.
+type SubmitSpecTool struct {
+	orch Orchestrator
+}
+
+// Definition returns the tool's definition in Claude API format.
+func (t *SubmitSpecTool) Definition() tools.ToolDefinition {
+	return tools.ToolDefinition{
+		Name:        ToolSubmitSpec,
+		Description: "Submit a markdown specification to the architect for story generation",
+		InputSchema: tools.InputSchema{
+			Type: "object",
+			Properties: map[string]tools.Property{
+				"content": {
+					Type:        "string",
+					Description: "Full markdown content of the specification",
+				},
+				"spec_id": {
+					Type:        "string",
+					Description: "ID of an existing spec this content revises; the proposed story changes need approval in the web UI",
+				},
+			},
+			Required: []string{"content"},
+		},
+	}
+}
+
+// Name returns the tool identifier.
+func (t *SubmitSpecTool) Name() string {
+	return ToolSubmitSpec
+}
+
+// PromptDocumentation returns markdown documentation for LLM prompts.
+func (t *SubmitSpecTool) PromptDocumentation() string {
+	return `- **maestro_submit_spec** - Submit a specification to the architect
+  - Parameters: content (required), spec_id (optional, revises an existing spec)`
+}
+
+// Exec dispatches the spec to the architect.
+func (t *SubmitSpecTool) Exec(_ context.Context, args map[string]any) (any, error) {
+	content := stringArg(args, "content")
+	if content == "" {
+		return nil, fmt.Errorf("content parameter is required")
+	}
+
+	if specID := stringArg(args, "spec_id"); specID != "" {
+		return t.submitRevision(specID, content)
+	}
+
+	// Same message shape as InjectSpec in the CLI flows.
+	msg := proto.NewAgentMsg(proto.MsgTypeSPEC, "mcp", string(agent.TypeArchitect))
+	msg.SetPayload("spec_content", content)
+	msg.SetPayload("type", "spec_content")
+	msg.SetMetadata("source", "mcp")
+
+	if err := t.orch.DispatchMessage(msg); err != nil {
+		return nil, fmt.Errorf("failed to dispatch spec: %w", err)
+	}
+
+	return map[string]any{
+		"success":    true,
+		"message":    "Specification submitted to architect",
+		"message_id": msg.ID,
+	}, nil
+}
+
+// submitRevision hands a revised spec to the architect, which proposes a change set for approval.
+func (t *SubmitSpecTool) submitRevision(specID, content string) (any, error) {
+	driver, err := findArchitect(t.orch)
+	if err != nil {
+		return nil, err
+	}
+	provider, ok := driver.(specRevisionProvider)
+	if !ok {
+		return nil, fmt.Errorf("architect does not accept spec revisions")
+	}
+	if err := provider.SubmitSpecRevision(specID, content); err != nil {
+		return nil, fmt.Errorf("failed to submit spec revision: %w", err)
+	}
+
+	return map[string]any{
+		"success": true,
+		"message": "Spec revision submitted; approve the proposed change set in the web UI",
+		"spec_id": specID,
+	}, nil
+}
+
+// ListEscalationsTool lists escalations awaiting a human.
+type ListEscalationsTool struct {
+	orch Orchestrator
+}
+
+// Definition returns the tool's definition in Claude API format.
+func (t *ListEscalationsTool) Definition() tools.ToolDefinition {
+	return tools.ToolDefinition{
+		Name:        ToolListEscalations,
+		Description: "List escalations raised by the architect that need human input",
+		InputSchema: tools.InputSchema{
+			Type: "object",
+			Properties: map[string]tools.Property{
+				"status": {
+					Type:        "string",
+					Description: "Escalation status filter (default: pending)",
+					Enum:        []string{"pending", "acknowledged", "resolved", "all"},
+				},
+			},
+		},
+	}
+}
+
+// Name returns the tool identifier.
+func (t *ListEscalationsTool) Name() string {
+	return ToolListEscalations
+}
+
+// PromptDocumentation returns markdown documentation for LLM prompts.
+func (t *ListEscalationsTool) PromptDocumentation() string {
+	return `- **maestro_list_escalations** - List escalations needing human input
+  - Parameters: status (pending, acknowledged, resolved, all; default pending)`
+}
+
+// Exec lists escalations.
+func (t *ListEscalationsTool) Exec(_ context.Context, args map[string]any) (any, error) {
+	handler, err := getEscalationHandler(t.orch)
+	if err != nil {
+		return nil, err
+	}
+
+	status := stringArg(args, "status")
+	switch status {
+	case "":
+		status = "pending"
+	case "all":
+		status = ""
+	}
+
+	escalations := handler.GetEscalations(status)
+	return map[string]any{
+		"success":     true,
+		"count":       len(escalations),
+		"escalations": escalations,
+	}, nil
+}
+
+// AnswerEscalationTool resolves an escalation with a human answer.
+type AnswerEscalationTool struct {
+	orch Orchestrator
+}
+
+// Definition returns the tool's definition in Claude API format.
+func (t *AnswerEscalationTool) Definition() tools.ToolDefinition {
+	return tools.ToolDefinition{
+		Name:        ToolAnswerEscalation,
+		Description: "Answer a pending escalation so the architect can continue",
+		InputSchema: tools.InputSchema{
+			Type: "object",
+			Properties: map[string]tools.Property{
+				"escalation_id": {
+					Type:        "string",
+					Description: "ID of the escalation to answer",
+				},
+				"answer": {
+					Type:        "string",
+					Description: "The human decision or answer",
+				},
+				"option": {
+					Type:        "string",
+					Description: "Answer by choosing one of the escalation's options (option text or 1-based number) instead of writing an answer",
+				},
+				"operator": {
+					Type:        "string",
+					Description: "Name of the person answering (recorded in the escalation log)",
+				},
+			},
+			Required: []string{"escalation_id"},
+		},
+	}
+}
+
+// Name returns the tool identifier.
+func (t *AnswerEscalationTool) Name() string {
+	return ToolAnswerEscalation
+}
+
+// PromptDocumentation returns markdown documentation for LLM prompts.
+func (t *AnswerEscalationTool) PromptDocumentation() string {
+	return `- **maestro_answer_escalation** - Resolve a pending escalation
+  - Parameters: escalation_id (required), answer or option (one required), operator
+  - Use option to pick one of the choices listed with the escalation`
+}
+
+// Exec resolves the escalation.
+func (t *AnswerEscalationTool) Exec(_ context.Context, args map[string]any) (any, error) {
+	escalationID := stringArg(args, "escalation_id")
+	answer := stringArg(args, "answer")
+	option := stringArg(args, "option")
+	if escalationID == "" || (answer == "" && option == "") {
+		return nil, fmt.Errorf("escalation_id and either answer or option parameters are required")
+	}
+	operator := stringArg(args, "operator")
+	if operator == "" {
+		operator = mcpOperator
+	}
+
+	handler, err := getEscalationHandler(t.orch)
+	if err != nil {
+		return nil, err
+	}
+	if option != "" {
+		answer, err = handler.ResolveEscalationWithOption(escalationID, option, operator)
+	} else {
+		err = handler.ResolveEscalation(escalationID, answer, operator)
+	}
+	if err != nil {
+		return map[string]any{
+			"success": false,
+			"error":   err.Error(),
+		}, nil
+	}
+
+	return map[string]any{
+		"success": true,
+		"message": fmt.Sprintf("Escalation %s resolved: %s", escalationID, answer),
+	}, nil
+}
+
+// AgentStateTool reports agent states.
+type AgentStateTool struct {
+	orch Orchestrator
+}
+
+// Definition returns the tool's definition in Claude API format.
+func (t *AgentStateTool) Definition() tools.ToolDefinition {
+	return tools.ToolDefinition{
+		Name:        ToolAgentState,
+		Description: "Show the current state of all agents, or the state data of one agent",
+		InputSchema: tools.InputSchema{
+			Type: "object",
+			Properties: map[string]tools.Property{
+				"agent_id": {
+					Type:        "string",
+					Description: "Agent to inspect in detail (omit to list all agents)",
+				},
+			},
+		},
+	}
+}
+
+// Name returns the tool identifier.
+func (t *AgentStateTool) Name() string {
+	return ToolAgentState
+}
+
+// PromptDocumentation returns markdown documentation for LLM prompts.
+func (t *AgentStateTool) PromptDocumentation() string {
+	return `- **maestro_agent_state** - Show agent states
+  - Parameters: agent_id (optional, returns state data for that agent)`
+}
+
+// Exec reports agent state.
+func (t *AgentStateTool) Exec(_ context.Context, args map[string]any) (any, error) {
+	agentID := stringArg(args, "agent_id")
+	agents := t.orch.GetRegisteredAgents()
+	sort.Slice(agents, func(i, j int) bool { return agents[i].ID < agents[j].ID })
+
+	if agentID == "" {
+		summaries := make([]map[string]any, 0, len(agents))
+		for i := range agents {
+			summaries = append(summaries, map[string]any{
+				"id":    agents[i].ID,
+				"type":  agents[i].Type.String(),
+				"state": agents[i].State,
+			})
+		}
+		return map[string]any{
+			"success": true,
+			"agents":  summaries,
+		}, nil
+	}
+
+	for i := range agents {
+		if agents[i].ID != agentID {
+			continue
+		}
+		result := map[string]any{
+			"success": true,
+			"id":      agents[i].ID,
+			"type":    agents[i].Type.String(),
+			"state":   agents[i].State,
+		}
+		if agents[i].Driver != nil {
+			result["state_data"] = summarizeStateData(agents[i].Driver.GetStateData())
+		}
+		return result, nil
+	}
+
+	return map[string]any{
+		"success": false,
+		"error":   fmt.Sprintf("agent %s not found", agentID),
+	}, nil
+}
+
+// summarizeStateData renders state data values as strings so arbitrary internal types
+// (channels, structs with unexported fields) never break JSON encoding.
+func summarizeStateData(data map[string]any) map[string]string {
+	summary := make(map[string]string, len(data))
+	for key, value := range data {
+		text := fmt.Sprintf("%v", value)
+		if len(text) > 2000 {
+			text = text[:2000] + "... (truncated)"
+		}
+		summary[key] = text
+	}
+	return summary
+}
@@ -0,0 +1,347 @@
+// Package mcpserver exposes Maestro over the Model Context Protocol so that IDE assistants
+// can query and steer a running orchestrator. Operations are implemented as tools.Tool
+// values and served over stdio or HTTP using JSON-RPC 2.0.
+package mcpserver
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"orchestrator/pkg/logx"
+	"orchestrator/pkg/tools"
+)
+
+const (
+	// ProtocolVersion is the MCP revision implemented by the server.
+	ProtocolVersion = "2024-11-05"
+
+	// JSON-RPC error codes.
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// rpcRequest is an incoming JSON-RPC 2.0 request or notification.
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcResponse is an outgoing JSON-RPC 2.0 response.
+//
+//nolint:govet // fieldalignment: JSON serialization order requirements
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is a JSON-RPC 2.0 error object.
+type rpcError struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+// Server dispatches MCP requests to registered tools.
+//
+//nolint:govet // fieldalignment: Logical grouping preferred over memory optimization
+type Server struct {
+	name    string
+	version string
+	logger  *logx.Logger
+	mu      sync.RWMutex
+	tools   map[string]tools.Tool
+}
+
+// NewServer creates an MCP server with no tools registered.
+func NewServer(name, version string) *Server {
+	return &Server{
+		name:    name,
+		version: version,
+		logger:  logx.NewLogger("mcp-server"),
+		tools:   make(map[string]tools.Tool),
+	}
+}
+
+// Register exposes a tool to MCP clients. A tool with the same name replaces the previous one.
+func (s *Server) Register(tool tools.Tool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tools[tool.Name()] = tool
+}
+
+// ToolNames returns the sorted names of all registered tools.
+func (s *Server) ToolNames() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.tools))
+	for name := range s.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HandleMessage processes a single JSON-RPC message and returns the encoded response.
+// Returns nil for notifications, which receive no response.
+func (s *Server) HandleMessage(ctx context.Context, data []byte) []byte {
+	var req rpcRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return s.encode(&rpcResponse{
+			JSONRPC: "2.0",
+			ID:      json.RawMessage("null"),
+			Error:   &rpcError{Code: codeParseError, Message: fmt.Sprintf("parse error: %v", err)},
+		})
+	}
+
+	// Notifications carry no ID and must not be answered.
+	if len(req.ID) == 0 {
+		s.logger.Debug("Received MCP notification: %s", req.Method)
+		return nil
+	}
+
+	result, rpcErr := s.dispatch(ctx, &req)
+	return s.encode(&rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rpcErr})
+}
+
+// dispatch routes a request to the matching MCP method handler.
+func (s *Server) dispatch(ctx context.Context, req *rpcRequest) (any, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		return map[string]any{
+			"protocolVersion": ProtocolVersion,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": s.name, "version": s.version},
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		return map[string]any{"tools": s.listTools()}, nil
+	case "tools/call":
+		return s.callTool(ctx, req.Params)
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+	}
+}
+
+// listTools converts registered tools to MCP tool descriptors.
+func (s *Server) listTools() []map[string]any {
+	names := s.ToolNames()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		def := s.tools[name].Definition()
+		result = append(result, map[string]any{
+			"name":        def.Name,
+			"description": def.Description,
+			"inputSchema": def.InputSchema,
+		})
+	}
+	return result
+}
+
+// callTool executes a tool and wraps its result as MCP text content.
+func (s *Server) callTool(ctx context.Context, rawParams json.RawMessage) (any, *rpcError) {
+	var params struct {
+		Arguments map[string]any `json:"arguments"`
+		Name      string         `json:"name"`
+	}
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("invalid params: %v", err)}
+	}
+
+	s.mu.RLock()
+	tool, exists := s.tools[params.Name]
+	s.mu.RUnlock()
+	if !exists {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown tool: %s", params.Name)}
+	}
+	if params.Arguments == nil {
+		params.Arguments = map[string]any{}
+	}
+
+	s.logger.Info("MCP client called tool %s", params.Name)
+	result, err := tool.Exec(ctx, params.Arguments)
+	if err != nil {
+		return toolResult(err.Error(), true), nil
+	}
+
+	isError := false
+	if resultMap, ok := result.(map[string]any); ok {
+		if success, ok := resultMap["success"].(bool); ok && !success {
+			isError = true
+		}
+	}
+	text, marshalErr := json.MarshalIndent(result, "", "  ")
+	if marshalErr != nil {
+		return toolResult(fmt.Sprintf("%v", result), isError), nil
+	}
+	return toolResult(string(text), isError), nil
+}
+
+// toolResult builds an MCP tools/call result with a single text block.
+func toolResult(text string, isError bool) map[string]any {
+	return map[string]any{
+		"content": []map[string]any{{"type": "text", "text": text}},
+		"isError": isError,
+	}
+}
+
+// encode marshals a response, logging instead of failing on the (unexpected) error path.
+func (s *Server) encode(resp *rpcResponse) []byte {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		s.logger.Error("Failed to encode MCP response: %v", err)
+		data, _ = json.Marshal(&rpcResponse{
+			JSONRPC: "2.0",
+			ID:      resp.ID,
+			Error:   &rpcError{Code: codeParseError, Message: "failed to encode response"},
+		})
+	}
+	return data
+}
+
+// ServeStdio reads newline-delimited JSON-RPC messages from in and writes responses to out
+// until in reaches EOF or the context is cancelled.
+func (s *Server) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	lines := make(chan []byte)
+	scanErr := make(chan error, 1)
+
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			line := append([]byte(nil), scanner.Bytes()...)
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		scanErr <- scanner.Err()
+	}()
+
+	s.logger.Info("Serving MCP over stdio with %d tools", len(s.ToolNames()))
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case line, ok := <-lines:
+			if !ok {
+				select {
+				case err := <-scanErr:
+					if err != nil {
+						return fmt.Errorf("failed to read MCP input: %w", err)
+					}
+				default:
+				}
+				return nil
+			}
+			if len(line) == 0 {
+				continue
+			}
+			if resp := s.HandleMessage(ctx, line); resp != nil {
+				if _, err := out.Write(append(resp, '\n')); err != nil {
+					return fmt.Errorf("failed to write MCP response: %w", err)
+				}
+			}
+		}
+	}
+}
+
+// ServeHTTP implements http.Handler. Each POST body carries one JSON-RPC message.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 16*1024*1024))
+	if err != nil {
+		http.Error(w, "Failed to read request", http.StatusBadRequest)
+		return
+	}
+
+	resp := s.HandleMessage(r.Context(), body)
+	if resp == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(resp); err != nil {
+		s.logger.Error("Failed to write MCP HTTP response: %v", err)
+	}
+}
+
+// RequireBearerToken wraps a handler so that every request must carry "Authorization: Bearer <token>".
+func RequireBearerToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// StartHTTP serves MCP at /mcp on addr (host:port) until the context is cancelled. Every request
+// must carry the bearer token, so a token is required.
+func (s *Server) StartHTTP(ctx context.Context, addr, token string) error {
+	if token == "" {
+		return fmt.Errorf("a bearer token is required to serve MCP over HTTP")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", RequireBearerToken(token, s))
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	s.logger.Info("Serving MCP over HTTP on %s with %d tools", addr, len(s.ToolNames()))
+	serverDone := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serverDone <- err
+		} else {
+			serverDone <- nil
+		}
+	}()
+
+	select {
+	case err := <-serverDone:
+		if err != nil {
+			return fmt.Errorf("MCP HTTP server failed: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("MCP HTTP server shutdown failed: %w", err)
+		}
+		return nil
+	}
+}
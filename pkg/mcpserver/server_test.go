@@ -0,0 +1,378 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"orchestrator/pkg/agent"
+	"orchestrator/pkg/architect"
+	"orchestrator/pkg/dispatch"
+	"orchestrator/pkg/persistence"
+	"orchestrator/pkg/proto"
+	"orchestrator/pkg/tools"
+)
+
+// fakeArchitect implements the parts of the architect driver used by the project tools.
+type fakeArchitect struct {
+	agent.Driver
+	queue       *architect.Queue
+	escalations *architect.EscalationHandler
+	revisions   map[string]string
+}
+
+func (f *fakeArchitect) GetStoryList() []*architect.QueuedStory {
+	return f.queue.GetAllStories()
+}
+
+func (f *fakeArchitect) GetEscalationHandler() *architect.EscalationHandler {
+	return f.escalations
+}
+
+func (f *fakeArchitect) SubmitSpecRevision(specID, content string) error {
+	if f.revisions == nil {
+		f.revisions = make(map[string]string)
+	}
+	f.revisions[specID] = content
+	return nil
+}
+
+func (f *fakeArchitect) GetStateData() map[string]any {
+	return map[string]any{"current_spec": "spec-1"}
+}
+
+// fakeOrchestrator records dispatched messages.
+type fakeOrchestrator struct {
+	agents     []dispatch.AgentInfo
+	dispatched []*proto.AgentMsg
+}
+
+func (f *fakeOrchestrator) GetRegisteredAgents() []dispatch.AgentInfo {
+	return f.agents
+}
+
+func (f *fakeOrchestrator) DispatchMessage(msg *proto.AgentMsg) error {
+	f.dispatched = append(f.dispatched, msg)
+	return nil
+}
+
+func newTestServer(t *testing.T) (*Server, *fakeOrchestrator, *architect.EscalationHandler) {
+	t.Helper()
+
+	queue := architect.NewQueue(make(chan *persistence.Request, 100))
+	queue.AddStory("002", "spec-1", "Add login", "Login story", "app", []string{"001"}, 2)
+	queue.AddStory("001", "spec-1", "Set up project", "Setup story", "devops", nil, 1)
+
+	escalations := architect.NewEscalationHandler(t.TempDir(), queue)
+	arch := &fakeArchitect{queue: queue, escalations: escalations}
+
+	orch := &fakeOrchestrator{agents: []dispatch.AgentInfo{
+		{ID: "coder-001", Type: agent.TypeCoder, State: "CODING"},
+		{ID: "architect-001", Type: agent.TypeArchitect, State: "MONITORING", Driver: arch},
+	}}
+
+	server := NewServer("maestro", "test")
+	RegisterProjectTools(server, orch)
+	return server, orch, escalations
+}
+
+// call sends a JSON-RPC request and decodes the response.
+func call(t *testing.T, s *Server, method string, params any) map[string]any {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(s.HandleMessage(context.Background(), data), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return resp
+}
+
+// callTool invokes a tool and returns its decoded JSON payload and error flag.
+func callTool(t *testing.T, s *Server, name string, args map[string]any) (map[string]any, bool) {
+	t.Helper()
+	resp := call(t, s, "tools/call", map[string]any{"name": name, "arguments": args})
+	result, ok := resp["result"].(map[string]any)
+	if !ok {
+		t.Fatalf("Expected result for %s, got %v", name, resp)
+	}
+	content := result["content"].([]any)[0].(map[string]any)
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(content["text"].(string)), &payload); err != nil {
+		t.Fatalf("Expected JSON tool output, got %q", content["text"])
+	}
+	return payload, result["isError"].(bool)
+}
+
+func TestServer_InitializeAndListTools(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	resp := call(t, server, "initialize", map[string]any{"protocolVersion": ProtocolVersion})
+	result := resp["result"].(map[string]any)
+	if result["protocolVersion"] != ProtocolVersion {
+		t.Errorf("Expected protocol version %s, got %v", ProtocolVersion, result["protocolVersion"])
+	}
+
+	resp = call(t, server, "tools/list", map[string]any{})
+	listed := resp["result"].(map[string]any)["tools"].([]any)
+	if len(listed) != 6 {
+		t.Fatalf("Expected 6 project tools, got %d", len(listed))
+	}
+	first := listed[0].(map[string]any)
+	if first["name"] != ToolAgentState || first["inputSchema"] == nil {
+		t.Errorf("Expected sorted tools with input schemas, got %v", first)
+	}
+
+	resp = call(t, server, "resources/list", nil)
+	if resp["error"] == nil {
+		t.Error("Expected method-not-found error for unsupported method")
+	}
+}
+
+func TestServer_NotificationsGetNoResponse(t *testing.T) {
+	server, _, _ := newTestServer(t)
+	resp := server.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	if resp != nil {
+		t.Errorf("Expected no response to notification, got %s", resp)
+	}
+}
+
+func TestProjectTools_Stories(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	payload, isError := callTool(t, server, ToolListStories, nil)
+	if isError || payload["count"].(float64) != 2 {
+		t.Fatalf("Expected 2 stories, got %v", payload)
+	}
+	stories := payload["stories"].([]any)
+	if stories[0].(map[string]any)["id"] != "001" {
+		t.Errorf("Expected stories sorted by ID, got %v", stories)
+	}
+
+	payload, _ = callTool(t, server, ToolListStories, map[string]any{"status": "done"})
+	if payload["count"].(float64) != 0 {
+		t.Errorf("Expected status filter to exclude all stories, got %v", payload)
+	}
+
+	payload, isError = callTool(t, server, ToolStoryStatus, map[string]any{"story_id": "002"})
+	if isError || payload["story"].(map[string]any)["title"] != "Add login" {
+		t.Errorf("Expected story 002 details, got %v", payload)
+	}
+
+	_, isError = callTool(t, server, ToolStoryStatus, map[string]any{"story_id": "missing"})
+	if !isError {
+		t.Error("Expected missing story to be reported as an error")
+	}
+}
+
+func TestProjectTools_SubmitSpec(t *testing.T) {
+	server, orch, _ := newTestServer(t)
+
+	_, isError := callTool(t, server, ToolSubmitSpec, map[string]any{"content": "# Spec"})
+	if isError {
+		t.Fatal("Expected spec submission to succeed")
+	}
+	if len(orch.dispatched) != 1 {
+		t.Fatalf("Expected one dispatched message, got %d", len(orch.dispatched))
+	}
+	msg := orch.dispatched[0]
+	if content, _ := msg.GetPayload("spec_content"); msg.Type != proto.MsgTypeSPEC || content != "# Spec" {
+		t.Errorf("Expected SPEC message with content, got %v", msg)
+	}
+}
+
+func TestProjectTools_SubmitSpecRevision(t *testing.T) {
+	server, orch, _ := newTestServer(t)
+
+	_, isError := callTool(t, server, ToolSubmitSpec, map[string]any{"content": "# Revised", "spec_id": "spec-1"})
+	if isError {
+		t.Fatal("Expected spec revision to succeed")
+	}
+	if len(orch.dispatched) != 0 {
+		t.Errorf("Expected no SPEC message for a revision, got %d", len(orch.dispatched))
+	}
+	arch := orch.agents[1].Driver.(*fakeArchitect)
+	if arch.revisions["spec-1"] != "# Revised" {
+		t.Errorf("Expected revision to reach the architect, got %v", arch.revisions)
+	}
+}
+
+func TestProjectTools_Escalations(t *testing.T) {
+	server, _, escalations := newTestServer(t)
+	if err := escalations.EscalateReviewFailure(context.Background(), "001", "coder-001", 3, "still failing"); err != nil {
+		t.Fatalf("Failed to create escalation: %v", err)
+	}
+
+	payload, _ := callTool(t, server, ToolListEscalations, nil)
+	if payload["count"].(float64) != 1 {
+		t.Fatalf("Expected one pending escalation, got %v", payload)
+	}
+	escalationID := payload["escalations"].([]any)[0].(map[string]any)["id"].(string)
+
+	_, isError := callTool(t, server, ToolAnswerEscalation, map[string]any{
+		"escalation_id": escalationID,
+		"answer":        "Skip the flaky check",
+	})
+	if isError {
+		t.Fatal("Expected escalation answer to succeed")
+	}
+
+	resolved := escalations.GetEscalations("resolved")
+	if len(resolved) != 1 || resolved[0].Resolution != "Skip the flaky check" || resolved[0].HumanOperator != mcpOperator {
+		t.Errorf("Expected escalation resolved by MCP client, got %+v", resolved)
+	}
+}
+
+func TestProjectTools_AnswerEscalationWithOption(t *testing.T) {
+	server, _, escalations := newTestServer(t)
+	err := escalations.EscalateBusinessQuestion(context.Background(), &architect.PendingQuestion{
+		ID:                "q-1",
+		StoryID:           "002",
+		AgentID:           "coder-001",
+		Question:          "Should login accept email or username?",
+		Options:           []string{"email", "username"},
+		RecommendedOption: "email",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create escalation: %v", err)
+	}
+	escalationID := escalations.GetEscalations("pending")[0].ID
+
+	payload, isError := callTool(t, server, ToolAnswerEscalation, map[string]any{
+		"escalation_id": escalationID,
+		"option":        "3",
+	})
+	if !isError && payload["success"] != false {
+		t.Fatal("Expected an out-of-range option to be rejected")
+	}
+
+	_, isError = callTool(t, server, ToolAnswerEscalation, map[string]any{
+		"escalation_id": escalationID,
+		"option":        "2",
+	})
+	if isError {
+		t.Fatal("Expected escalation option answer to succeed")
+	}
+
+	resolved := escalations.GetEscalations("resolved")
+	if len(resolved) != 1 || resolved[0].Resolution != "username" {
+		t.Errorf("Expected escalation resolved with the chosen option, got %+v", resolved)
+	}
+}
+
+func TestProjectTools_AgentState(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	payload, _ := callTool(t, server, ToolAgentState, nil)
+	agents := payload["agents"].([]any)
+	if len(agents) != 2 || agents[0].(map[string]any)["id"] != "architect-001" {
+		t.Fatalf("Expected sorted agent list, got %v", agents)
+	}
+
+	payload, _ = callTool(t, server, ToolAgentState, map[string]any{"agent_id": "architect-001"})
+	stateData := payload["state_data"].(map[string]any)
+	if stateData["current_spec"] != "spec-1" {
+		t.Errorf("Expected architect state data, got %v", payload)
+	}
+}
+
+func TestServer_ServeStdio(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+	}, "\n") + "\n"
+	var out bytes.Buffer
+
+	if err := server.ServeStdio(context.Background(), strings.NewReader(input), &out); err != nil {
+		t.Fatalf("ServeStdio failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 responses (notification unanswered), got %d: %s", len(lines), out.String())
+	}
+	if !strings.Contains(lines[1], ToolListStories) {
+		t.Errorf("Expected tools/list response, got %s", lines[1])
+	}
+}
+
+func TestServer_ServeHTTP(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":"a","method":"ping"}`))
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"id":"a"`) {
+		t.Errorf("Expected ping response, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/mcp", nil)
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET, got %d", rec.Code)
+	}
+}
+
+func TestRequireBearerToken(t *testing.T) {
+	server, _, _ := newTestServer(t)
+	handler := RequireBearerToken("secret", server)
+
+	for _, tc := range []struct {
+		header string
+		code   int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"secret", http.StatusUnauthorized},
+		{"Bearer secret", http.StatusOK},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":"a","method":"ping"}`))
+		if tc.header != "" {
+			req.Header.Set("Authorization", tc.header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tc.code {
+			t.Errorf("Authorization %q: expected %d, got %d", tc.header, tc.code, rec.Code)
+		}
+	}
+
+	if err := server.StartHTTP(context.Background(), "127.0.0.1:0", ""); err == nil {
+		t.Error("Expected StartHTTP to refuse an empty token")
+	}
+}
+
+func TestRegisterRegistryTools_RefusesHostCommands(t *testing.T) {
+	server, _, _ := newTestServer(t)
+	for _, name := range []string{tools.ToolShell, tools.ToolBuild, tools.ToolContainerTest, tools.ToolNotes, tools.ToolSubmitPlan, "unknown"} {
+		if err := RegisterRegistryTools(server, tools.AgentContext{}, []string{name}); err == nil {
+			t.Errorf("Expected %s to be refused", name)
+		}
+	}
+	for _, name := range server.ToolNames() {
+		if name == tools.ToolShell {
+			t.Error("Expected shell not to be registered")
+		}
+	}
+}
+
+func TestRegisterRegistryTools_ExposesReadOnlyTools(t *testing.T) {
+	server, _, _ := newTestServer(t)
+	if err := RegisterRegistryTools(server, tools.AgentContext{ReadOnly: true}, []string{tools.ToolBackendInfo}); err != nil {
+		t.Fatalf("Expected backend_info to be exposed: %v", err)
+	}
+	if !slices.Contains(server.ToolNames(), tools.ToolBackendInfo) {
+		t.Errorf("Expected backend_info to be registered, got %v", server.ToolNames())
+	}
+}
@@ -2,16 +2,21 @@ package persistence
 
 import (
 	"crypto/rand"
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+
+	"orchestrator/pkg/proto"
 )
 
 // Spec represents a specification document.
 type Spec struct {
 	CreatedAt   time.Time  `json:"created_at"`
 	ProcessedAt *time.Time `json:"processed_at,omitempty"`
+	RevisedAt   *time.Time `json:"revised_at,omitempty"` // When an approved revision last changed the spec
 	ID          string     `json:"id"`
 	Content     string     `json:"content"`
 }
@@ -42,18 +47,74 @@ type Story struct {
 	// Metrics and costs
 	TokensUsed int64   `json:"tokens_used"`
 	CostUSD    float64 `json:"cost_usd"`
+	Iterations int     `json:"iterations"` // LLM round trips spent on the story
 
 	// Completion tracking
 	PRID              string `json:"pr_id,omitempty"`              // Pull request ID
 	CommitHash        string `json:"commit_hash,omitempty"`        // Commit hash from merge
 	CompletionSummary string `json:"completion_summary,omitempty"` // Summary of what was completed
 
+	// Plan progress (stored as JSON)
+	Todos []proto.StoryTodo `json:"todos,omitempty"` // Approved-plan todos with coder-reported status
+
 	// Extensibility
 	Metadata string `json:"metadata,omitempty"` // JSON blob for extensibility
 
+	// Estimates made during story generation
+	EstimatedPoints  int     `json:"estimated_points"` // Estimation points
+	EstimatedTokens  int64   `json:"estimated_tokens"`
+	EstimatedCostUSD float64 `json:"estimated_cost_usd"`
+	Risk             string  `json:"risk,omitempty"` // "low", "medium" or "high"
+
 	// Queue-specific fields (not persisted to database)
-	DependsOn       []string `json:"depends_on" db:"-"`       // Story dependencies
-	EstimatedPoints int      `json:"estimated_points" db:"-"` // Estimation points
+	DependsOn []string `json:"depends_on" db:"-"` // Story dependencies
+}
+
+// encodeTodos serializes the story's todos for the todos column (NULL when none).
+func (s *Story) encodeTodos() (sql.NullString, error) {
+	if len(s.Todos) == 0 {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(s.Todos)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("failed to encode todos for story %s: %w", s.ID, err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// decodeTodos restores the story's todos from the todos column.
+func (s *Story) decodeTodos(raw sql.NullString) error {
+	if !raw.Valid || raw.String == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(raw.String), &s.Todos); err != nil {
+		return fmt.Errorf("failed to decode todos for story %s: %w", s.ID, err)
+	}
+	return nil
+}
+
+// StoryMetadata holds optional per-story settings kept in the story's metadata column.
+type StoryMetadata struct {
+	TestFirst bool `json:"test_first,omitempty"` // Coder gets failing tests approved before coding
+}
+
+// GetMetadata decodes the story's metadata. Empty or malformed metadata reads as no settings.
+func (s *Story) GetMetadata() StoryMetadata {
+	var metadata StoryMetadata
+	if s.Metadata != "" {
+		_ = json.Unmarshal([]byte(s.Metadata), &metadata)
+	}
+	return metadata
+}
+
+// SetMetadata stores the given settings in the story's metadata column.
+func (s *Story) SetMetadata(metadata StoryMetadata) error {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata for story %s: %w", s.ID, err)
+	}
+	s.Metadata = string(data)
+	return nil
 }
 
 // StoryDependency represents a dependency relationship between stories.
@@ -64,12 +125,13 @@ type StoryDependency struct {
 
 // Story status constants (mirrored from canonical in architect for database operations).
 const (
-	StatusNew      = "new"
-	StatusPending  = "pending"
-	StatusAssigned = "assigned"
-	StatusPlanning = "planning"
-	StatusCoding   = "coding"
-	StatusDone     = "done"
+	StatusNew       = "new"
+	StatusPending   = "pending"
+	StatusAssigned  = "assigned"
+	StatusPlanning  = "planning"
+	StatusCoding    = "coding"
+	StatusDone      = "done"
+	StatusCancelled = "cancelled"
 )
 
 // GenerateSpecID generates a new UUID for a spec.
@@ -116,11 +178,40 @@ type AgentRequest struct {
 	ToAgent       string    `json:"to_agent"`
 	Content       string    `json:"content"`
 	StoryID       *string   `json:"story_id,omitempty"`
-	ApprovalType  *string   `json:"approval_type,omitempty"` // "plan", "code", "budget_review", "completion"
+	ApprovalType  *string   `json:"approval_type,omitempty"` // "plan", "code", "budget_review", "completion", "split", "tests"
 	Context       *string   `json:"context,omitempty"`
 	Reason        *string   `json:"reason,omitempty"`
 	CorrelationID *string   `json:"correlation_id,omitempty"`
 	ParentMsgID   *string   `json:"parent_msg_id,omitempty"`
+
+	// Structured question fields (questions only).
+	Options           []string `json:"options,omitempty"`            // Candidate answers, stored as JSON
+	RecommendedOption *string  `json:"recommended_option,omitempty"` // Option the asker would pick
+	Category          *string  `json:"category,omitempty"`           // "technical", "business", "environment"
+	Blocking          *bool    `json:"blocking,omitempty"`           // Whether the asker waits for the answer
+}
+
+// encodeOptions serializes the request's options for the options column.
+func (r *AgentRequest) encodeOptions() (sql.NullString, error) {
+	if len(r.Options) == 0 {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(r.Options)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("failed to encode options for request %s: %w", r.ID, err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// decodeOptions restores the request's options from the options column.
+func (r *AgentRequest) decodeOptions(raw sql.NullString) error {
+	if !raw.Valid || raw.String == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(raw.String), &r.Options); err != nil {
+		return fmt.Errorf("failed to decode options for request %s: %w", r.ID, err)
+	}
+	return nil
 }
 
 // AgentResponse represents a response (answer or result) to an agent request.
@@ -156,6 +247,62 @@ type AgentPlan struct {
 	Feedback   *string    `json:"feedback,omitempty"`
 }
 
+// StoryNotes holds the scratchpad notes coders keep for a story.
+type StoryNotes struct {
+	UpdatedAt time.Time `json:"updated_at"`
+	StoryID   string    `json:"story_id"`
+	Content   string    `json:"content"`
+	UpdatedBy string    `json:"updated_by,omitempty"` // Agent that last wrote the notes
+}
+
+// FlakyTest records a test that failed during TESTING and then passed when re-run in isolation.
+type FlakyTest struct {
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+	Suite       string    `json:"suite,omitempty"` // Package, class or file the test belongs to
+	Name        string    `json:"name"`
+	LastStoryID string    `json:"last_story_id,omitempty"` // Story whose test run last hit the flake
+	LastOutput  string    `json:"last_output,omitempty"`   // Failure output of the last flaky run
+	Occurrences int       `json:"occurrences"`
+}
+
+// StoryHandoff records what an abandoned attempt at a story tried, so the coder that
+// picks the story up next does not start from zero.
+type StoryHandoff struct {
+	CreatedAt      time.Time `json:"created_at"`
+	StoryID        string    `json:"story_id"`
+	AgentID        string    `json:"agent_id,omitempty"`         // Agent whose attempt was abandoned
+	Reason         string    `json:"reason"`                     // Why the attempt was abandoned
+	Attempted      string    `json:"attempted,omitempty"`        // Approach and progress of the attempt
+	LastTestOutput string    `json:"last_test_output,omitempty"` // Output of the attempt's last test run
+	BranchName     string    `json:"branch_name,omitempty"`
+	Files          []string  `json:"files,omitempty"` // Files the attempt changed
+	ID             int64     `json:"id"`
+	BranchPushed   bool      `json:"branch_pushed"` // Whether the attempt's work was pushed to BranchName
+}
+
+// Risk levels of a story estimate.
+const (
+	RiskLow    = "low"
+	RiskMedium = "medium"
+	RiskHigh   = "high"
+)
+
+// EstimateOutcome compares a completed story's estimates with what the story actually took.
+type EstimateOutcome struct {
+	CompletedAt      time.Time `json:"completed_at"`
+	StoryID          string    `json:"story_id"`
+	StoryType        string    `json:"story_type"`
+	Risk             string    `json:"risk,omitempty"`
+	ID               int64     `json:"id"`
+	EstimatedTokens  int64     `json:"estimated_tokens"`
+	ActualTokens     int64     `json:"actual_tokens"`
+	EstimatedCostUSD float64   `json:"estimated_cost_usd"`
+	ActualCostUSD    float64   `json:"actual_cost_usd"`
+	EstimatedPoints  int       `json:"estimated_points"`
+	Iterations       int       `json:"iterations"`
+}
+
 // Request type constants.
 const (
 	RequestTypeQuestion = "question"
@@ -2,6 +2,7 @@ package persistence
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -25,10 +26,14 @@ const (
 	OpRemoveStoryDependency = "remove_story_dependency"
 
 	// Agent interaction operations.
-	OpUpsertAgentRequest  = "upsert_agent_request"
-	OpUpsertAgentResponse = "upsert_agent_response"
-	OpUpsertAgentPlan     = "upsert_agent_plan"
-	OpUpdateAgentPlan     = "update_agent_plan"
+	OpUpsertAgentRequest    = "upsert_agent_request"
+	OpUpsertAgentResponse   = "upsert_agent_response"
+	OpUpsertAgentPlan       = "upsert_agent_plan"
+	OpUpdateAgentPlan       = "update_agent_plan"
+	OpUpsertStoryNotes      = "upsert_story_notes"
+	OpRecordFlakyTest       = "record_flaky_test"
+	OpRecordStoryHandoff    = "record_story_handoff"
+	OpRecordEstimateOutcome = "record_estimate_outcome"
 
 	// Query operations (with response).
 	OpQueryStoriesByStatus               = "query_stories_by_status"
@@ -38,9 +43,14 @@ const (
 	OpGetStoryByID                       = "get_story_by_id"
 	OpGetSpecByID                        = "get_spec_by_id"
 	OpGetAllStories                      = "get_all_stories"
+	OpGetStoriesBySpec                   = "get_stories_by_spec"
 	OpGetAgentRequestsByStory            = "get_agent_requests_by_story"
 	OpGetAgentResponsesByStory           = "get_agent_responses_by_story"
 	OpGetAgentPlansByStory               = "get_agent_plans_by_story"
+	OpGetStoryNotes                      = "get_story_notes"
+	OpGetFlakyTests                      = "get_flaky_tests"
+	OpGetStoryHandoffs                   = "get_story_handoffs"
+	OpGetEstimateOutcomes                = "get_estimate_outcomes"
 	OpBatchUpsertStoriesWithDependencies = "batch_upsert_stories_with_dependencies"
 )
 
@@ -77,14 +87,15 @@ func NewDatabaseOperations(db *sql.DB) *DatabaseOperations {
 // UpsertSpec inserts or updates a spec record.
 func (ops *DatabaseOperations) UpsertSpec(spec *Spec) error {
 	query := `
-		INSERT INTO specs (id, content, created_at, processed_at)
-		VALUES (?, ?, ?, ?)
+		INSERT INTO specs (id, content, created_at, processed_at, revised_at)
+		VALUES (?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			content = excluded.content,
-			processed_at = excluded.processed_at
+			processed_at = excluded.processed_at,
+			revised_at = COALESCE(excluded.revised_at, specs.revised_at)
 	`
 
-	_, err := ops.db.Exec(query, spec.ID, spec.Content, spec.CreatedAt, spec.ProcessedAt)
+	_, err := ops.db.Exec(query, spec.ID, spec.Content, spec.CreatedAt, spec.ProcessedAt, spec.RevisedAt)
 	if err != nil {
 		return fmt.Errorf("failed to upsert spec %s: %w", spec.ID, err)
 	}
@@ -97,8 +108,9 @@ func (ops *DatabaseOperations) UpsertStory(story *Story) error {
 		INSERT INTO stories (
 			id, spec_id, title, content, status, priority, approved_plan,
 			created_at, started_at, completed_at, assigned_agent,
-			tokens_used, cost_usd, metadata, story_type, pr_id, commit_hash, completion_summary
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			tokens_used, cost_usd, metadata, story_type, pr_id, commit_hash, completion_summary, todos,
+			estimated_points, estimated_tokens, estimated_cost_usd, risk, iterations
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			spec_id = excluded.spec_id,
 			title = excluded.title,
@@ -115,14 +127,26 @@ func (ops *DatabaseOperations) UpsertStory(story *Story) error {
 			story_type = excluded.story_type,
 			pr_id = excluded.pr_id,
 			commit_hash = excluded.commit_hash,
-			completion_summary = excluded.completion_summary
+			completion_summary = excluded.completion_summary,
+			todos = excluded.todos,
+			estimated_points = excluded.estimated_points,
+			estimated_tokens = excluded.estimated_tokens,
+			estimated_cost_usd = excluded.estimated_cost_usd,
+			risk = excluded.risk,
+			iterations = excluded.iterations
 	`
 
-	_, err := ops.db.Exec(query,
+	todos, err := story.encodeTodos()
+	if err != nil {
+		return err
+	}
+
+	_, err = ops.db.Exec(query,
 		story.ID, story.SpecID, story.Title, story.Content, story.Status,
 		story.Priority, story.ApprovedPlan, story.CreatedAt, story.StartedAt,
 		story.CompletedAt, story.AssignedAgent, story.TokensUsed,
 		story.CostUSD, story.Metadata, story.StoryType, story.PRID, story.CommitHash, story.CompletionSummary,
+		todos, story.EstimatedPoints, story.EstimatedTokens, story.EstimatedCostUSD, story.Risk, story.Iterations,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to upsert story %s: %w", story.ID, err)
@@ -137,7 +161,7 @@ func (ops *DatabaseOperations) UpdateStoryStatus(req *UpdateStoryStatusRequest)
 	switch req.Status {
 	case StatusPlanning, StatusCoding:
 		timestampField = "started_at"
-	case StatusDone:
+	case StatusDone, StatusCancelled:
 		timestampField = "completed_at"
 	}
 
@@ -235,7 +259,7 @@ func (ops *DatabaseOperations) RemoveStoryDependency(storyID, dependsOn string)
 
 // QueryStoriesByFilter returns stories matching the given filter criteria.
 func (ops *DatabaseOperations) QueryStoriesByFilter(filter *StoryFilter) ([]*Story, error) {
-	query := "SELECT id, spec_id, title, content, status, priority, approved_plan, created_at, started_at, completed_at, assigned_agent, tokens_used, cost_usd, metadata FROM stories WHERE 1=1"
+	query := "SELECT id, spec_id, title, content, status, priority, approved_plan, created_at, started_at, completed_at, assigned_agent, tokens_used, cost_usd, metadata, todos, story_type, estimated_points, estimated_tokens, estimated_cost_usd, risk, iterations FROM stories WHERE 1=1"
 	var args []interface{}
 
 	// Build WHERE conditions
@@ -276,16 +300,21 @@ func (ops *DatabaseOperations) QueryStoriesByFilter(filter *StoryFilter) ([]*Sto
 	var stories []*Story
 	for rows.Next() {
 		story := &Story{}
+		var todos sql.NullString
 		err := rows.Scan(
 			&story.ID, &story.SpecID, &story.Title, &story.Content,
 			&story.Status, &story.Priority, &story.ApprovedPlan,
 			&story.CreatedAt, &story.StartedAt, &story.CompletedAt,
 			&story.AssignedAgent, &story.TokensUsed, &story.CostUSD,
-			&story.Metadata,
+			&story.Metadata, &todos, &story.StoryType,
+			&story.EstimatedPoints, &story.EstimatedTokens, &story.EstimatedCostUSD, &story.Risk, &story.Iterations,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan story: %w", err)
 		}
+		if err := story.decodeTodos(todos); err != nil {
+			return nil, err
+		}
 		stories = append(stories, story)
 	}
 
@@ -301,11 +330,12 @@ func (ops *DatabaseOperations) QueryPendingStories() ([]*Story, error) {
 	return ops.queryStoriesBySQL(`
 		SELECT DISTINCT s.id, s.spec_id, s.title, s.content, s.status, s.priority, 
 		       s.approved_plan, s.created_at, s.started_at, s.completed_at, 
-		       s.assigned_agent, s.tokens_used, s.cost_usd, s.metadata
+		       s.assigned_agent, s.tokens_used, s.cost_usd, s.metadata, s.todos, s.story_type,
+		       s.estimated_points, s.estimated_tokens, s.estimated_cost_usd, s.risk, s.iterations
 		FROM stories s
 		LEFT JOIN story_dependencies d ON s.id = d.story_id
 		LEFT JOIN stories dep ON d.depends_on = dep.id 
-		    AND dep.status NOT IN ('`+StatusDone+`')
+		    AND dep.status NOT IN ('`+StatusDone+`', '`+StatusCancelled+`')
 		WHERE s.status = '`+StatusNew+`' AND dep.id IS NULL
 		ORDER BY s.priority DESC, s.created_at ASC
 	`, "pending stories")
@@ -383,17 +413,20 @@ func (ops *DatabaseOperations) GetStoryByID(storyID string) (*Story, error) {
 	query := `
 		SELECT id, spec_id, title, content, status, priority, approved_plan, 
 		       created_at, started_at, completed_at, assigned_agent, 
-		       tokens_used, cost_usd, metadata
+		       tokens_used, cost_usd, metadata, todos,
+		       estimated_points, estimated_tokens, estimated_cost_usd, risk, iterations
 		FROM stories WHERE id = ?
 	`
 
 	story := &Story{}
+	var todos sql.NullString
 	err := ops.db.QueryRow(query, storyID).Scan(
 		&story.ID, &story.SpecID, &story.Title, &story.Content,
 		&story.Status, &story.Priority, &story.ApprovedPlan,
 		&story.CreatedAt, &story.StartedAt, &story.CompletedAt,
 		&story.AssignedAgent, &story.TokensUsed, &story.CostUSD,
-		&story.Metadata,
+		&story.Metadata, &todos,
+		&story.EstimatedPoints, &story.EstimatedTokens, &story.EstimatedCostUSD, &story.Risk, &story.Iterations,
 	)
 
 	if err == sql.ErrNoRows {
@@ -402,17 +435,20 @@ func (ops *DatabaseOperations) GetStoryByID(storyID string) (*Story, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get story %s: %w", storyID, err)
 	}
+	if err := story.decodeTodos(todos); err != nil {
+		return nil, err
+	}
 
 	return story, nil
 }
 
 // GetSpecByID returns a spec by its ID.
 func (ops *DatabaseOperations) GetSpecByID(specID string) (*Spec, error) {
-	query := `SELECT id, content, created_at, processed_at FROM specs WHERE id = ?`
+	query := `SELECT id, content, created_at, processed_at, revised_at FROM specs WHERE id = ?`
 
 	spec := &Spec{}
 	err := ops.db.QueryRow(query, specID).Scan(
-		&spec.ID, &spec.Content, &spec.CreatedAt, &spec.ProcessedAt,
+		&spec.ID, &spec.Content, &spec.CreatedAt, &spec.ProcessedAt, &spec.RevisedAt,
 	)
 
 	if err == sql.ErrNoRows {
@@ -441,16 +477,21 @@ func (ops *DatabaseOperations) queryStoriesBySQL(query, queryType string) ([]*St
 	var stories []*Story
 	for rows.Next() {
 		story := &Story{}
+		var todos sql.NullString
 		err := rows.Scan(
 			&story.ID, &story.SpecID, &story.Title, &story.Content,
 			&story.Status, &story.Priority, &story.ApprovedPlan,
 			&story.CreatedAt, &story.StartedAt, &story.CompletedAt,
 			&story.AssignedAgent, &story.TokensUsed, &story.CostUSD,
-			&story.Metadata,
+			&story.Metadata, &todos, &story.StoryType,
+			&story.EstimatedPoints, &story.EstimatedTokens, &story.EstimatedCostUSD, &story.Risk, &story.Iterations,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan story: %w", err)
 		}
+		if err := story.decodeTodos(todos); err != nil {
+			return nil, err
+		}
 		stories = append(stories, story)
 	}
 
@@ -466,18 +507,42 @@ func (ops *DatabaseOperations) GetAllStories() ([]*Story, error) {
 	return ops.queryStoriesBySQL(`
 		SELECT id, spec_id, title, content, status, priority, approved_plan, 
 		       created_at, started_at, completed_at, assigned_agent, 
-		       tokens_used, cost_usd, metadata
+		       tokens_used, cost_usd, metadata, todos, story_type,
+		       estimated_points, estimated_tokens, estimated_cost_usd, risk, iterations
 		FROM stories ORDER BY priority DESC, created_at ASC
 	`, "all stories")
 }
 
+// GetStoriesBySpec returns the stories generated from a spec, with their dependencies.
+func (ops *DatabaseOperations) GetStoriesBySpec(specID string) ([]*Story, error) {
+	stories, err := ops.QueryStoriesByFilter(&StoryFilter{SpecID: &specID})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, story := range stories {
+		dependencies, err := ops.GetStoryDependencies(story.ID)
+		if err != nil {
+			return nil, err
+		}
+		story.DependsOn = dependencies
+	}
+	return stories, nil
+}
+
 // UpsertAgentRequest inserts or updates an agent request record.
 func (ops *DatabaseOperations) UpsertAgentRequest(request *AgentRequest) error {
+	options, err := request.encodeOptions()
+	if err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO agent_requests (
 			id, story_id, request_type, approval_type, from_agent, to_agent, 
-			content, context, reason, created_at, correlation_id, parent_msg_id
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			content, context, reason, created_at, correlation_id, parent_msg_id,
+			options, recommended_option, category, blocking
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			story_id = excluded.story_id,
 			request_type = excluded.request_type,
@@ -488,13 +553,18 @@ func (ops *DatabaseOperations) UpsertAgentRequest(request *AgentRequest) error {
 			context = excluded.context,
 			reason = excluded.reason,
 			correlation_id = excluded.correlation_id,
-			parent_msg_id = excluded.parent_msg_id
+			parent_msg_id = excluded.parent_msg_id,
+			options = excluded.options,
+			recommended_option = excluded.recommended_option,
+			category = excluded.category,
+			blocking = excluded.blocking
 	`
 
-	_, err := ops.db.Exec(query,
+	_, err = ops.db.Exec(query,
 		request.ID, request.StoryID, request.RequestType, request.ApprovalType,
 		request.FromAgent, request.ToAgent, request.Content, request.Context,
-		request.Reason, request.CreatedAt, request.CorrelationID, request.ParentMsgID)
+		request.Reason, request.CreatedAt, request.CorrelationID, request.ParentMsgID,
+		options, request.RecommendedOption, request.Category, request.Blocking)
 	if err != nil {
 		return fmt.Errorf("failed to upsert agent request %s: %w", request.ID, err)
 	}
@@ -565,7 +635,8 @@ func (ops *DatabaseOperations) UpsertAgentPlan(plan *AgentPlan) error {
 func (ops *DatabaseOperations) GetAgentRequestsByStory(storyID string) ([]*AgentRequest, error) {
 	query := `
 		SELECT id, story_id, request_type, approval_type, from_agent, to_agent,
-		       content, context, reason, created_at, correlation_id, parent_msg_id
+		       content, context, reason, created_at, correlation_id, parent_msg_id,
+		       options, recommended_option, category, blocking
 		FROM agent_requests
 		WHERE story_id = ?
 		ORDER BY created_at ASC
@@ -582,13 +653,18 @@ func (ops *DatabaseOperations) GetAgentRequestsByStory(storyID string) ([]*Agent
 	var requests []*AgentRequest
 	for rows.Next() {
 		var request AgentRequest
+		var options sql.NullString
 		err := rows.Scan(
 			&request.ID, &request.StoryID, &request.RequestType, &request.ApprovalType,
 			&request.FromAgent, &request.ToAgent, &request.Content, &request.Context,
-			&request.Reason, &request.CreatedAt, &request.CorrelationID, &request.ParentMsgID)
+			&request.Reason, &request.CreatedAt, &request.CorrelationID, &request.ParentMsgID,
+			&options, &request.RecommendedOption, &request.Category, &request.Blocking)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan agent request: %w", err)
 		}
+		if err := request.decodeOptions(options); err != nil {
+			return nil, err
+		}
 		requests = append(requests, &request)
 	}
 
@@ -674,6 +750,264 @@ func (ops *DatabaseOperations) GetAgentPlansByStory(storyID string) ([]*AgentPla
 	return plans, nil
 }
 
+// UpsertStoryNotes replaces the notes recorded for a story.
+func (ops *DatabaseOperations) UpsertStoryNotes(notes *StoryNotes) error {
+	if notes.StoryID == "" {
+		return fmt.Errorf("cannot upsert story notes: story_id is empty")
+	}
+	query := `
+		INSERT INTO story_notes (story_id, content, updated_by, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(story_id) DO UPDATE SET
+			content = excluded.content,
+			updated_by = excluded.updated_by,
+			updated_at = excluded.updated_at
+	`
+
+	updatedAt := notes.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = time.Now()
+	}
+	_, err := ops.db.Exec(query, notes.StoryID, notes.Content, notes.UpdatedBy, updatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert notes for story %s: %w", notes.StoryID, err)
+	}
+	return nil
+}
+
+// GetStoryNotes returns the notes recorded for a story, or nil if none have been written.
+func (ops *DatabaseOperations) GetStoryNotes(storyID string) (*StoryNotes, error) {
+	query := `
+		SELECT story_id, content, updated_by, updated_at
+		FROM story_notes WHERE story_id = ?
+	`
+
+	notes := &StoryNotes{}
+	var updatedBy sql.NullString
+	err := ops.db.QueryRow(query, storyID).Scan(&notes.StoryID, &notes.Content, &updatedBy, &notes.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notes for story %s: %w", storyID, err)
+	}
+	notes.UpdatedBy = updatedBy.String
+
+	return notes, nil
+}
+
+// RecordFlakyTest records a sighting of a flaky test, incrementing its occurrence count
+// when the test has been seen before.
+func (ops *DatabaseOperations) RecordFlakyTest(test *FlakyTest) error {
+	if test.Name == "" {
+		return fmt.Errorf("cannot record flaky test: name is empty")
+	}
+	query := `
+		INSERT INTO flaky_tests (suite, name, occurrences, first_seen, last_seen, last_story_id, last_output)
+		VALUES (?, ?, 1, ?, ?, ?, ?)
+		ON CONFLICT(suite, name) DO UPDATE SET
+			occurrences = occurrences + 1,
+			last_seen = excluded.last_seen,
+			last_story_id = excluded.last_story_id,
+			last_output = excluded.last_output
+	`
+
+	seenAt := test.LastSeen
+	if seenAt.IsZero() {
+		seenAt = time.Now()
+	}
+	_, err := ops.db.Exec(query, test.Suite, test.Name, seenAt, seenAt, test.LastStoryID, test.LastOutput)
+	if err != nil {
+		return fmt.Errorf("failed to record flaky test %s: %w", test.Name, err)
+	}
+	return nil
+}
+
+// GetFlakyTests returns all recorded flaky tests, most frequently seen first.
+func (ops *DatabaseOperations) GetFlakyTests() ([]*FlakyTest, error) {
+	query := `
+		SELECT suite, name, occurrences, first_seen, last_seen, last_story_id, last_output
+		FROM flaky_tests
+		ORDER BY occurrences DESC, last_seen DESC
+	`
+
+	rows, err := ops.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query flaky tests: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			_ = closeErr // Ignore close error in defer
+		}
+	}()
+
+	var tests []*FlakyTest
+	for rows.Next() {
+		test := &FlakyTest{}
+		var lastStoryID, lastOutput sql.NullString
+		if err := rows.Scan(&test.Suite, &test.Name, &test.Occurrences, &test.FirstSeen, &test.LastSeen, &lastStoryID, &lastOutput); err != nil {
+			return nil, fmt.Errorf("failed to scan flaky test: %w", err)
+		}
+		test.LastStoryID = lastStoryID.String
+		test.LastOutput = lastOutput.String
+		tests = append(tests, test)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return tests, nil
+}
+
+// RecordStoryHandoff stores the handoff record of an abandoned attempt at a story.
+func (ops *DatabaseOperations) RecordStoryHandoff(handoff *StoryHandoff) error {
+	if handoff.StoryID == "" {
+		return fmt.Errorf("cannot record story handoff: story_id is empty")
+	}
+	files, err := json.Marshal(handoff.Files)
+	if err != nil {
+		return fmt.Errorf("failed to encode handoff files: %w", err)
+	}
+	query := `
+		INSERT INTO story_handoffs (story_id, agent_id, reason, attempted, last_test_output, files, branch_name, branch_pushed, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	createdAt := handoff.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	result, err := ops.db.Exec(query, handoff.StoryID, handoff.AgentID, handoff.Reason, handoff.Attempted,
+		handoff.LastTestOutput, string(files), handoff.BranchName, handoff.BranchPushed, createdAt)
+	if err != nil {
+		return fmt.Errorf("failed to record handoff for story %s: %w", handoff.StoryID, err)
+	}
+	if id, idErr := result.LastInsertId(); idErr == nil {
+		handoff.ID = id
+	}
+	return nil
+}
+
+// GetStoryHandoffs returns the handoff records of a story, most recent first.
+func (ops *DatabaseOperations) GetStoryHandoffs(storyID string) ([]*StoryHandoff, error) {
+	query := `
+		SELECT id, story_id, agent_id, reason, attempted, last_test_output, files, branch_name, branch_pushed, created_at
+		FROM story_handoffs WHERE story_id = ?
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := ops.db.Query(query, storyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query handoffs for story %s: %w", storyID, err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			_ = closeErr // Ignore close error in defer
+		}
+	}()
+
+	var handoffs []*StoryHandoff
+	for rows.Next() {
+		handoff := &StoryHandoff{}
+		var agentID, attempted, lastTestOutput, files, branchName sql.NullString
+		if err := rows.Scan(&handoff.ID, &handoff.StoryID, &agentID, &handoff.Reason, &attempted, &lastTestOutput,
+			&files, &branchName, &handoff.BranchPushed, &handoff.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan story handoff: %w", err)
+		}
+		handoff.AgentID = agentID.String
+		handoff.Attempted = attempted.String
+		handoff.LastTestOutput = lastTestOutput.String
+		handoff.BranchName = branchName.String
+		if files.String != "" {
+			if err := json.Unmarshal([]byte(files.String), &handoff.Files); err != nil {
+				return nil, fmt.Errorf("failed to decode handoff files: %w", err)
+			}
+		}
+		handoffs = append(handoffs, handoff)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return handoffs, nil
+}
+
+// RecordEstimateOutcome stores the comparison of a completed story's estimates with its actuals.
+func (ops *DatabaseOperations) RecordEstimateOutcome(outcome *EstimateOutcome) error {
+	if outcome.StoryID == "" {
+		return fmt.Errorf("cannot record estimate outcome: story_id is empty")
+	}
+	query := `
+		INSERT INTO estimate_outcomes (
+			story_id, story_type, risk, estimated_points, estimated_tokens, estimated_cost_usd,
+			actual_tokens, actual_cost_usd, iterations, completed_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	completedAt := outcome.CompletedAt
+	if completedAt.IsZero() {
+		completedAt = time.Now()
+	}
+	result, err := ops.db.Exec(query, outcome.StoryID, outcome.StoryType, outcome.Risk, outcome.EstimatedPoints,
+		outcome.EstimatedTokens, outcome.EstimatedCostUSD, outcome.ActualTokens, outcome.ActualCostUSD,
+		outcome.Iterations, completedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record estimate outcome for story %s: %w", outcome.StoryID, err)
+	}
+	if id, idErr := result.LastInsertId(); idErr == nil {
+		outcome.ID = id
+	}
+	return nil
+}
+
+// GetEstimateOutcomes returns up to limit estimate outcomes, most recent first. A limit of zero or
+// less returns all of them.
+func (ops *DatabaseOperations) GetEstimateOutcomes(limit int) ([]*EstimateOutcome, error) {
+	query := `
+		SELECT id, story_id, story_type, risk, estimated_points, estimated_tokens, estimated_cost_usd,
+		       actual_tokens, actual_cost_usd, iterations, completed_at
+		FROM estimate_outcomes
+		ORDER BY completed_at DESC, id DESC
+	`
+	var args []interface{}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := ops.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query estimate outcomes: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			_ = closeErr // Ignore close error in defer
+		}
+	}()
+
+	var outcomes []*EstimateOutcome
+	for rows.Next() {
+		outcome := &EstimateOutcome{}
+		var storyType, risk sql.NullString
+		if err := rows.Scan(&outcome.ID, &outcome.StoryID, &storyType, &risk, &outcome.EstimatedPoints,
+			&outcome.EstimatedTokens, &outcome.EstimatedCostUSD, &outcome.ActualTokens, &outcome.ActualCostUSD,
+			&outcome.Iterations, &outcome.CompletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan estimate outcome: %w", err)
+		}
+		outcome.StoryType = storyType.String
+		outcome.Risk = risk.String
+		outcomes = append(outcomes, outcome)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return outcomes, nil
+}
+
 // BatchUpsertStoriesWithDependencies atomically inserts stories and their dependencies.
 // This ensures all stories exist before any dependencies are created, preventing foreign key constraint errors.
 func (ops *DatabaseOperations) BatchUpsertStoriesWithDependencies(req *BatchUpsertStoriesWithDependenciesRequest) error {
@@ -693,8 +1027,9 @@ func (ops *DatabaseOperations) BatchUpsertStoriesWithDependencies(req *BatchUpse
 		INSERT INTO stories (
 			id, spec_id, title, content, status, priority, approved_plan,
 			created_at, started_at, completed_at, assigned_agent,
-			tokens_used, cost_usd, metadata, story_type
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			tokens_used, cost_usd, metadata, story_type,
+			estimated_points, estimated_tokens, estimated_cost_usd, risk
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			spec_id = excluded.spec_id,
 			title = excluded.title,
@@ -708,7 +1043,11 @@ func (ops *DatabaseOperations) BatchUpsertStoriesWithDependencies(req *BatchUpse
 			tokens_used = excluded.tokens_used,
 			cost_usd = excluded.cost_usd,
 			metadata = excluded.metadata,
-			story_type = excluded.story_type
+			story_type = excluded.story_type,
+			estimated_points = excluded.estimated_points,
+			estimated_tokens = excluded.estimated_tokens,
+			estimated_cost_usd = excluded.estimated_cost_usd,
+			risk = excluded.risk
 	`
 
 	for _, story := range req.Stories {
@@ -717,6 +1056,7 @@ func (ops *DatabaseOperations) BatchUpsertStoriesWithDependencies(req *BatchUpse
 			story.Priority, story.ApprovedPlan, story.CreatedAt, story.StartedAt,
 			story.CompletedAt, story.AssignedAgent, story.TokensUsed,
 			story.CostUSD, story.Metadata, story.StoryType,
+			story.EstimatedPoints, story.EstimatedTokens, story.EstimatedCostUSD, story.Risk,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to upsert story %s: %w", story.ID, err)
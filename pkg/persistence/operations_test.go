@@ -4,6 +4,9 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"orchestrator/pkg/proto"
 )
 
 // Helper function to create a new database for each test.
@@ -57,6 +60,50 @@ func TestDatabaseOperations(t *testing.T) {
 		}
 	})
 
+	// Test that a revision keeps the spec's creation time
+	t.Run("SpecRevision", func(t *testing.T) {
+		ops, cleanup := createTestDB(t)
+		defer cleanup()
+
+		specID := GenerateSpecID()
+		createdAt := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+		if err := ops.UpsertSpec(&Spec{ID: specID, Content: "Original", CreatedAt: createdAt}); err != nil {
+			t.Fatalf("Failed to upsert spec: %v", err)
+		}
+
+		revisedAt := time.Now().UTC().Truncate(time.Second)
+		revision := &Spec{ID: specID, Content: "Revised", CreatedAt: createdAt, RevisedAt: &revisedAt}
+		if err := ops.UpsertSpec(revision); err != nil {
+			t.Fatalf("Failed to upsert revised spec: %v", err)
+		}
+
+		retrievedSpec, err := ops.GetSpecByID(specID)
+		if err != nil {
+			t.Fatalf("Failed to get spec: %v", err)
+		}
+		if retrievedSpec.Content != "Revised" {
+			t.Errorf("Expected revised content, got %q", retrievedSpec.Content)
+		}
+		if !retrievedSpec.CreatedAt.Equal(createdAt) {
+			t.Errorf("Expected created_at %v to be kept, got %v", createdAt, retrievedSpec.CreatedAt)
+		}
+		if retrievedSpec.RevisedAt == nil || !retrievedSpec.RevisedAt.Equal(revisedAt) {
+			t.Errorf("Expected revised_at %v, got %v", revisedAt, retrievedSpec.RevisedAt)
+		}
+
+		// A later upsert without a revision time keeps the recorded one
+		if err := ops.UpsertSpec(&Spec{ID: specID, Content: "Revised", CreatedAt: createdAt}); err != nil {
+			t.Fatalf("Failed to upsert spec: %v", err)
+		}
+		retrievedSpec, err = ops.GetSpecByID(specID)
+		if err != nil {
+			t.Fatalf("Failed to get spec: %v", err)
+		}
+		if retrievedSpec.RevisedAt == nil || !retrievedSpec.RevisedAt.Equal(revisedAt) {
+			t.Errorf("Expected revised_at %v to be kept, got %v", revisedAt, retrievedSpec.RevisedAt)
+		}
+	})
+
 	// Test story operations
 	t.Run("StoryOperations", func(t *testing.T) {
 		ops, cleanup := createTestDB(t)
@@ -355,7 +402,7 @@ func TestIDGeneration(t *testing.T) {
 }
 
 func TestValidStatus(t *testing.T) {
-	validStatuses := []string{StatusNew, StatusPending, StatusAssigned, StatusPlanning, StatusCoding, StatusDone}
+	validStatuses := []string{StatusNew, StatusPending, StatusAssigned, StatusPlanning, StatusCoding, StatusDone, StatusCancelled}
 
 	// Simple validation test - just check that the constants are defined correctly
 	for _, status := range validStatuses {
@@ -489,3 +536,307 @@ func mustGenerateStoryID() string {
 	}
 	return id
 }
+
+func TestStoryNotes(t *testing.T) {
+	ops, cleanup := createTestDB(t)
+	defer cleanup()
+
+	specID := GenerateSpecID()
+	if err := ops.UpsertSpec(&Spec{ID: specID, Content: "Parent spec"}); err != nil {
+		t.Fatalf("Failed to create parent spec: %v", err)
+	}
+	storyID, _ := GenerateStoryID()
+	story := &Story{ID: storyID, SpecID: specID, Title: "Notes story", Content: "Content", Status: StatusNew, StoryType: "app", Priority: 1}
+	if err := ops.UpsertStory(story); err != nil {
+		t.Fatalf("Failed to upsert story: %v", err)
+	}
+
+	// No notes yet
+	notes, err := ops.GetStoryNotes(storyID)
+	if err != nil || notes != nil {
+		t.Fatalf("Expected no notes, got %+v (err %v)", notes, err)
+	}
+
+	if err := ops.UpsertStoryNotes(&StoryNotes{StoryID: storyID, Content: "TestFoo is flaky", UpdatedBy: "coder-001"}); err != nil {
+		t.Fatalf("Failed to upsert notes: %v", err)
+	}
+	if err := ops.UpsertStoryNotes(&StoryNotes{StoryID: storyID, Content: "TestFoo is flaky\nDon't touch pkg/legacy", UpdatedBy: "coder-002"}); err != nil {
+		t.Fatalf("Failed to replace notes: %v", err)
+	}
+
+	notes, err = ops.GetStoryNotes(storyID)
+	if err != nil {
+		t.Fatalf("Failed to get notes: %v", err)
+	}
+	if notes.Content != "TestFoo is flaky\nDon't touch pkg/legacy" || notes.UpdatedBy != "coder-002" {
+		t.Errorf("Unexpected notes: %+v", notes)
+	}
+}
+
+func TestRecordFlakyTest(t *testing.T) {
+	ops, cleanup := createTestDB(t)
+	defer cleanup()
+
+	tests, err := ops.GetFlakyTests()
+	if err != nil || len(tests) != 0 {
+		t.Fatalf("Expected no flaky tests, got %v (err %v)", tests, err)
+	}
+
+	sightings := []*FlakyTest{
+		{Suite: "example/pkg/cache", Name: "TestEviction", LastStoryID: "story-1", LastOutput: "timeout"},
+		{Suite: "example/pkg/api", Name: "TestServer", LastStoryID: "story-1"},
+		{Suite: "example/pkg/cache", Name: "TestEviction", LastStoryID: "story-2", LastOutput: "race"},
+	}
+	for _, sighting := range sightings {
+		if err := ops.RecordFlakyTest(sighting); err != nil {
+			t.Fatalf("Failed to record flaky test: %v", err)
+		}
+	}
+	if err := ops.RecordFlakyTest(&FlakyTest{Suite: "example/pkg/api"}); err == nil {
+		t.Error("Expected a flaky test without a name to be rejected")
+	}
+
+	tests, err = ops.GetFlakyTests()
+	if err != nil {
+		t.Fatalf("Failed to get flaky tests: %v", err)
+	}
+	if len(tests) != 2 {
+		t.Fatalf("Expected 2 flaky tests, got %d", len(tests))
+	}
+	eviction := tests[0]
+	if eviction.Name != "TestEviction" || eviction.Occurrences != 2 {
+		t.Errorf("Expected TestEviction seen twice to be listed first, got %+v", eviction)
+	}
+	if eviction.LastStoryID != "story-2" || eviction.LastOutput != "race" {
+		t.Errorf("Expected the latest sighting to be recorded, got %+v", eviction)
+	}
+}
+
+func TestRecordStoryHandoff(t *testing.T) {
+	ops, cleanup := createTestDB(t)
+	defer cleanup()
+
+	specID := GenerateSpecID()
+	if err := ops.UpsertSpec(&Spec{ID: specID, Content: "Parent spec"}); err != nil {
+		t.Fatalf("Failed to create parent spec: %v", err)
+	}
+	storyID, _ := GenerateStoryID()
+	if err := ops.UpsertStory(&Story{ID: storyID, SpecID: specID, Title: "Handoff story", Content: "Content", Status: StatusPending, StoryType: "app"}); err != nil {
+		t.Fatalf("Failed to upsert story: %v", err)
+	}
+
+	handoffs, err := ops.GetStoryHandoffs(storyID)
+	if err != nil || len(handoffs) != 0 {
+		t.Fatalf("Expected no handoffs, got %v (err %v)", handoffs, err)
+	}
+
+	first := &StoryHandoff{StoryID: storyID, AgentID: "coder-001", Reason: "budget review abandoned", CreatedAt: time.Now().Add(-time.Hour)}
+	second := &StoryHandoff{
+		StoryID:        storyID,
+		AgentID:        "coder-002",
+		Reason:         "tests kept failing",
+		Attempted:      "Rewrote the cache eviction",
+		LastTestOutput: "FAIL TestEviction",
+		Files:          []string{"cache/lru.go", "cache/lru_test.go"},
+		BranchName:     "maestro/story-" + storyID,
+		BranchPushed:   true,
+	}
+	for _, handoff := range []*StoryHandoff{first, second} {
+		if err := ops.RecordStoryHandoff(handoff); err != nil {
+			t.Fatalf("Failed to record handoff: %v", err)
+		}
+	}
+	if err := ops.RecordStoryHandoff(&StoryHandoff{Reason: "no story"}); err == nil {
+		t.Error("Expected a handoff without a story to be rejected")
+	}
+
+	handoffs, err = ops.GetStoryHandoffs(storyID)
+	if err != nil {
+		t.Fatalf("Failed to get handoffs: %v", err)
+	}
+	if len(handoffs) != 2 {
+		t.Fatalf("Expected 2 handoffs, got %d", len(handoffs))
+	}
+	latest := handoffs[0]
+	if latest.AgentID != "coder-002" || latest.LastTestOutput != "FAIL TestEviction" || !latest.BranchPushed {
+		t.Errorf("Expected the latest handoff first with its details, got %+v", latest)
+	}
+	if len(latest.Files) != 2 || latest.Files[1] != "cache/lru_test.go" {
+		t.Errorf("Expected the handoff files to round-trip, got %v", latest.Files)
+	}
+}
+
+func TestGetStoriesBySpec(t *testing.T) {
+	ops, cleanup := createTestDB(t)
+	defer cleanup()
+
+	specID := GenerateSpecID()
+	otherSpecID := GenerateSpecID()
+	for _, id := range []string{specID, otherSpecID} {
+		if err := ops.UpsertSpec(&Spec{ID: id, Content: "spec"}); err != nil {
+			t.Fatalf("Failed to create spec: %v", err)
+		}
+	}
+
+	base := &Story{ID: "base0001", SpecID: specID, Title: "Base", Content: "base", Status: StatusDone, StoryType: "devops", CreatedAt: time.Now().Add(-time.Minute)}
+	feature := &Story{ID: "feat0001", SpecID: specID, Title: "Feature", Content: "feature", Status: StatusCancelled, StoryType: "app", CreatedAt: time.Now()}
+	other := &Story{ID: "other001", SpecID: otherSpecID, Title: "Other", Content: "other", Status: StatusNew, StoryType: "app"}
+	for _, story := range []*Story{base, feature, other} {
+		if err := ops.UpsertStory(story); err != nil {
+			t.Fatalf("Failed to upsert story %s: %v", story.ID, err)
+		}
+	}
+	if err := ops.AddStoryDependency(feature.ID, base.ID); err != nil {
+		t.Fatalf("Failed to add dependency: %v", err)
+	}
+
+	stories, err := ops.GetStoriesBySpec(specID)
+	if err != nil {
+		t.Fatalf("GetStoriesBySpec failed: %v", err)
+	}
+	if len(stories) != 2 || stories[0].ID != base.ID || stories[1].ID != feature.ID {
+		t.Fatalf("Expected the spec's two stories in creation order, got %v", stories)
+	}
+	if stories[0].StoryType != "devops" || stories[1].Status != StatusCancelled {
+		t.Errorf("Expected story type and status to round-trip, got %+v", stories)
+	}
+	if len(stories[1].DependsOn) != 1 || stories[1].DependsOn[0] != base.ID {
+		t.Errorf("Expected the feature's dependency on base, got %v", stories[1].DependsOn)
+	}
+}
+
+func TestStoryTodosRoundTrip(t *testing.T) {
+	ops, cleanup := createTestDB(t)
+	defer cleanup()
+
+	specID := GenerateSpecID()
+	if err := ops.UpsertSpec(&Spec{ID: specID, Content: "Parent spec"}); err != nil {
+		t.Fatalf("Failed to create parent spec: %v", err)
+	}
+	storyID, _ := GenerateStoryID()
+	story := &Story{ID: storyID, SpecID: specID, Title: "Todo story", Content: "Content", Status: StatusCoding, StoryType: "app"}
+	story.Todos = []proto.StoryTodo{
+		{ID: "todo_001", Description: "Add handler", Status: proto.TodoDone},
+		{ID: "todo_002", Description: "Update docs", Status: proto.TodoBlocked, Note: "No docs directory"},
+	}
+	if err := ops.UpsertStory(story); err != nil {
+		t.Fatalf("Failed to upsert story: %v", err)
+	}
+
+	retrieved, err := ops.GetStoryByID(storyID)
+	if err != nil {
+		t.Fatalf("Failed to get story: %v", err)
+	}
+	if len(retrieved.Todos) != 2 || retrieved.Todos[1].Status != proto.TodoBlocked || retrieved.Todos[1].Note != "No docs directory" {
+		t.Errorf("Unexpected todos: %+v", retrieved.Todos)
+	}
+
+	all, err := ops.GetAllStories()
+	if err != nil || len(all) != 1 || len(all[0].Todos) != 2 {
+		t.Errorf("Expected todos from GetAllStories, got %+v (err %v)", all, err)
+	}
+}
+
+func TestAgentRequestQuestionFieldsRoundTrip(t *testing.T) {
+	ops, cleanup := createTestDB(t)
+	defer cleanup()
+
+	specID := GenerateSpecID()
+	if err := ops.UpsertSpec(&Spec{ID: specID, Content: "Parent spec"}); err != nil {
+		t.Fatalf("Failed to create parent spec: %v", err)
+	}
+	storyID, _ := GenerateStoryID()
+	if err := ops.UpsertStory(&Story{ID: storyID, SpecID: specID, Title: "Question story", Content: "Content", Status: StatusCoding, StoryType: "app"}); err != nil {
+		t.Fatalf("Failed to upsert story: %v", err)
+	}
+
+	recommended := "postgres"
+	category := "technical"
+	blocking := false
+	request := &AgentRequest{
+		ID:                "req-1",
+		StoryID:           &storyID,
+		RequestType:       RequestTypeQuestion,
+		FromAgent:         "coder-001",
+		ToAgent:           "architect",
+		Content:           "Which database should the service use?",
+		CreatedAt:         time.Now(),
+		Options:           []string{"postgres", "sqlite"},
+		RecommendedOption: &recommended,
+		Category:          &category,
+		Blocking:          &blocking,
+	}
+	if err := ops.UpsertAgentRequest(request); err != nil {
+		t.Fatalf("Failed to upsert agent request: %v", err)
+	}
+
+	requests, err := ops.GetAgentRequestsByStory(storyID)
+	if err != nil {
+		t.Fatalf("Failed to get agent requests: %v", err)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(requests))
+	}
+	got := requests[0]
+	if len(got.Options) != 2 || got.Options[1] != "sqlite" {
+		t.Errorf("Unexpected options: %v", got.Options)
+	}
+	if got.RecommendedOption == nil || *got.RecommendedOption != recommended {
+		t.Errorf("Unexpected recommended option: %v", got.RecommendedOption)
+	}
+	if got.Category == nil || *got.Category != category {
+		t.Errorf("Unexpected category: %v", got.Category)
+	}
+	if got.Blocking == nil || *got.Blocking {
+		t.Errorf("Expected non-blocking question, got %v", got.Blocking)
+	}
+}
+
+func TestRecordEstimateOutcome(t *testing.T) {
+	ops, cleanup := createTestDB(t)
+	defer cleanup()
+
+	specID := GenerateSpecID()
+	if err := ops.UpsertSpec(&Spec{ID: specID, Content: "Parent spec"}); err != nil {
+		t.Fatalf("Failed to create parent spec: %v", err)
+	}
+	storyID, _ := GenerateStoryID()
+	story := &Story{
+		ID: storyID, SpecID: specID, Title: "Estimated story", Content: "Content", Status: StatusDone, StoryType: "app",
+		EstimatedPoints: 3, EstimatedTokens: 150000, EstimatedCostUSD: 0.45, Risk: RiskHigh,
+		TokensUsed: 210000, CostUSD: 0.63, Iterations: 14,
+	}
+	if err := ops.UpsertStory(story); err != nil {
+		t.Fatalf("Failed to upsert story: %v", err)
+	}
+	all, err := ops.GetAllStories()
+	if err != nil || len(all) != 1 {
+		t.Fatalf("Failed to get stories: %v", err)
+	}
+	if got := all[0]; got.EstimatedTokens != 150000 || got.EstimatedCostUSD != 0.45 || got.Risk != RiskHigh || got.Iterations != 14 {
+		t.Errorf("Expected estimates and iterations to round-trip, got %+v", got)
+	}
+
+	if err := ops.RecordEstimateOutcome(&EstimateOutcome{}); err == nil {
+		t.Error("Expected an error for an outcome without a story")
+	}
+	older := &EstimateOutcome{StoryID: storyID, EstimatedPoints: 3, EstimatedTokens: 150000, ActualTokens: 90000, CompletedAt: time.Now().Add(-time.Hour)}
+	newer := &EstimateOutcome{StoryID: storyID, StoryType: "app", Risk: RiskHigh, EstimatedPoints: 3, EstimatedTokens: 150000,
+		EstimatedCostUSD: 0.45, ActualTokens: 210000, ActualCostUSD: 0.63, Iterations: 14}
+	for _, outcome := range []*EstimateOutcome{older, newer} {
+		if err := ops.RecordEstimateOutcome(outcome); err != nil {
+			t.Fatalf("Failed to record estimate outcome: %v", err)
+		}
+	}
+
+	outcomes, err := ops.GetEstimateOutcomes(0)
+	if err != nil || len(outcomes) != 2 {
+		t.Fatalf("Expected two outcomes, got %d (err %v)", len(outcomes), err)
+	}
+	if outcomes[0].ID != newer.ID || outcomes[0].ActualTokens != 210000 || outcomes[0].Risk != RiskHigh || outcomes[0].Iterations != 14 {
+		t.Errorf("Expected the most recent outcome first, got %+v", outcomes[0])
+	}
+	if limited, err := ops.GetEstimateOutcomes(1); err != nil || len(limited) != 1 {
+		t.Errorf("Expected the limit to apply, got %d (err %v)", len(limited), err)
+	}
+}
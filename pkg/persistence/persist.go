@@ -180,3 +180,73 @@ func PersistDependency(storyID, dependsOnID string, persistenceChannel chan<- *R
 		Response:  nil, // Fire-and-forget
 	}
 }
+
+// PersistDependencyRemoval removes a single story dependency from the database.
+func PersistDependencyRemoval(storyID, dependsOnID string, persistenceChannel chan<- *Request) {
+	if persistenceChannel == nil || storyID == "" || dependsOnID == "" {
+		return
+	}
+
+	dependency := &StoryDependency{
+		StoryID:   storyID,
+		DependsOn: dependsOnID,
+	}
+
+	persistenceChannel <- &Request{
+		Operation: OpRemoveStoryDependency,
+		Data:      dependency,
+		Response:  nil, // Fire-and-forget
+	}
+}
+
+// PersistStoryNotes persists a story's notes to the database.
+func PersistStoryNotes(notes *StoryNotes, persistenceChannel chan<- *Request) {
+	if persistenceChannel == nil || notes == nil || notes.StoryID == "" {
+		return
+	}
+
+	persistenceChannel <- &Request{
+		Operation: OpUpsertStoryNotes,
+		Data:      notes,
+		Response:  nil, // Fire-and-forget
+	}
+}
+
+// PersistFlakyTest records a flaky test sighting in the database.
+func PersistFlakyTest(test *FlakyTest, persistenceChannel chan<- *Request) {
+	if persistenceChannel == nil || test == nil || test.Name == "" {
+		return
+	}
+
+	persistenceChannel <- &Request{
+		Operation: OpRecordFlakyTest,
+		Data:      test,
+		Response:  nil, // Fire-and-forget
+	}
+}
+
+// PersistStoryHandoff records the handoff of an abandoned story attempt in the database.
+func PersistStoryHandoff(handoff *StoryHandoff, persistenceChannel chan<- *Request) {
+	if persistenceChannel == nil || handoff == nil || handoff.StoryID == "" {
+		return
+	}
+
+	persistenceChannel <- &Request{
+		Operation: OpRecordStoryHandoff,
+		Data:      handoff,
+		Response:  nil, // Fire-and-forget
+	}
+}
+
+// PersistEstimateOutcome records how a completed story's estimates compared with its actuals.
+func PersistEstimateOutcome(outcome *EstimateOutcome, persistenceChannel chan<- *Request) {
+	if persistenceChannel == nil || outcome == nil || outcome.StoryID == "" {
+		return
+	}
+
+	persistenceChannel <- &Request{
+		Operation: OpRecordEstimateOutcome,
+		Data:      outcome,
+		Response:  nil, // Fire-and-forget
+	}
+}
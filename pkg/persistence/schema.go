@@ -2,9 +2,11 @@
 package persistence
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 
 	_ "github.com/mattn/go-sqlite3" // SQLite driver
 
@@ -12,7 +14,7 @@ import (
 )
 
 // CurrentSchemaVersion defines the current schema version for migration support.
-const CurrentSchemaVersion = 1
+const CurrentSchemaVersion = 12
 
 // InitializeDatabase creates and initializes the SQLite database with the required schema.
 // This function is idempotent and safe to call multiple times.
@@ -44,6 +46,27 @@ func InitializeDatabase(dbPath string) (*sql.DB, error) {
 	return db, nil
 }
 
+// OpenDatabaseReadOnly opens an existing database for reading without creating or migrating its
+// schema, so it is safe to use while another maestro instance has the database open.
+func OpenDatabaseReadOnly(dbPath string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=ro&_foreign_keys=ON", dbPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	var version int
+	if err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&version); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version < CurrentSchemaVersion {
+		_ = db.Close()
+		return nil, fmt.Errorf("database schema version %d is older than %d - run maestro to migrate it", version, CurrentSchemaVersion)
+	}
+
+	return db, nil
+}
+
 // initializeSchemaWithMigrations ensures the database schema is at the current version.
 func initializeSchemaWithMigrations(db *sql.DB) error {
 	// Get current schema version
@@ -96,17 +119,46 @@ func runMigration(db *sql.DB, version int) error {
 		return migrateToVersion5(db)
 	case 6:
 		return migrateToVersion6(db)
+	case 7:
+		return migrateToVersion7(db)
+	case 8:
+		return migrateToVersion8(db)
+	case 9:
+		return migrateToVersion9(db)
+	case 10:
+		return migrateToVersion10(db)
+	case 11:
+		return migrateToVersion11(db)
+	case 12:
+		return migrateToVersion12(db)
 	default:
 		return fmt.Errorf("unknown migration version: %d", version)
 	}
 }
 
-// migrateToVersion6 adds the new completion fields to the stories table.
-func migrateToVersion6(db *sql.DB) error {
+// migrateToVersion2 adds the story_notes table holding per-story coder scratchpads.
+func migrateToVersion2(db *sql.DB) error {
+	if _, err := db.Exec(storyNotesTableDDL); err != nil {
+		return fmt.Errorf("failed to create story_notes table: %w", err)
+	}
+	return nil
+}
+
+// migrateToVersion3 adds plan-todo progress to the stories table.
+func migrateToVersion3(db *sql.DB) error {
+	if _, err := db.Exec("ALTER TABLE stories ADD COLUMN todos TEXT"); err != nil {
+		return fmt.Errorf("failed to add todos column: %w", err)
+	}
+	return nil
+}
+
+// migrateToVersion4 adds the structured question fields to the agent_requests table.
+func migrateToVersion4(db *sql.DB) error {
 	migrations := []string{
-		"ALTER TABLE stories ADD COLUMN pr_id TEXT",
-		"ALTER TABLE stories ADD COLUMN commit_hash TEXT",
-		"ALTER TABLE stories ADD COLUMN completion_summary TEXT",
+		"ALTER TABLE agent_requests ADD COLUMN options TEXT",
+		"ALTER TABLE agent_requests ADD COLUMN recommended_option TEXT",
+		"ALTER TABLE agent_requests ADD COLUMN category TEXT",
+		"ALTER TABLE agent_requests ADD COLUMN blocking BOOLEAN",
 	}
 
 	for _, migration := range migrations {
@@ -118,12 +170,277 @@ func migrateToVersion6(db *sql.DB) error {
 	return nil
 }
 
-// Placeholder migrations for future versions 1-5 (these would be implemented when needed).
+// migrateToVersion5 rebuilds the agent_requests table so its approval_type check accepts
+// split requests.
+func migrateToVersion5(db *sql.DB) error {
+	return rebuildAgentRequestsTable(db)
+}
+
+// rebuildAgentRequestsTable recreates the agent_requests table from agentRequestsTableDDL,
+// keeping its rows. SQLite cannot change a CHECK constraint in place.
+func rebuildAgentRequestsTable(db *sql.DB) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Foreign keys are off while the table is swapped so agent_responses keeps its references.
+	// The pragma is per connection, hence the dedicated connection.
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return fmt.Errorf("failed to disable foreign keys: %w", err)
+	}
+	defer func() { _, _ = conn.ExecContext(ctx, "PRAGMA foreign_keys = ON") }()
+
+	columns := "id, story_id, request_type, approval_type, from_agent, to_agent, content, context, reason, " +
+		"created_at, correlation_id, parent_msg_id, options, recommended_option, category, blocking"
+	migrations := []string{
+		strings.Replace(agentRequestsTableDDL, "agent_requests", "agent_requests_new", 1),
+		"INSERT INTO agent_requests_new (" + columns + ") SELECT " + columns + " FROM agent_requests",
+		"DROP TABLE agent_requests",
+		"ALTER TABLE agent_requests_new RENAME TO agent_requests",
+		"CREATE INDEX IF NOT EXISTS idx_agent_requests_story ON agent_requests(story_id)",
+		"CREATE INDEX IF NOT EXISTS idx_agent_requests_type ON agent_requests(request_type)",
+		"CREATE INDEX IF NOT EXISTS idx_agent_requests_correlation ON agent_requests(correlation_id)",
+	}
+
+	for _, migration := range migrations {
+		if _, err := conn.ExecContext(ctx, migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %s: %w", migration, err)
+		}
+	}
+
+	return nil
+}
+
+// migrateToVersion6 adds the flaky_tests table recording tests that passed on re-run.
+func migrateToVersion6(db *sql.DB) error {
+	if _, err := db.Exec(flakyTestsTableDDL); err != nil {
+		return fmt.Errorf("failed to create flaky_tests table: %w", err)
+	}
+	return nil
+}
+
+// migrateToVersion7 rebuilds the agent_requests table so its approval_type check accepts
+// test-first tests approvals.
+func migrateToVersion7(db *sql.DB) error {
+	return rebuildAgentRequestsTable(db)
+}
+
+// migrateToVersion8 adds the story_handoffs table recording abandoned attempts at a story.
+func migrateToVersion8(db *sql.DB) error {
+	if _, err := db.Exec(storyHandoffsTableDDL); err != nil {
+		return fmt.Errorf("failed to create story_handoffs table: %w", err)
+	}
+	if _, err := db.Exec(storyHandoffsIndexDDL); err != nil {
+		return fmt.Errorf("failed to create story_handoffs index: %w", err)
+	}
+	return nil
+}
+
+// migrateToVersion9 rebuilds the stories table so its status check accepts stories cancelled
+// by a spec revision.
+func migrateToVersion9(db *sql.DB) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Foreign keys are off while the table is swapped so the tables referencing stories keep
+	// their references. The pragma is per connection, hence the dedicated connection.
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return fmt.Errorf("failed to disable foreign keys: %w", err)
+	}
+	defer func() { _, _ = conn.ExecContext(ctx, "PRAGMA foreign_keys = ON") }()
+
+	columns := "id, spec_id, title, content, status, priority, approved_plan, created_at, started_at, completed_at, " +
+		"assigned_agent, tokens_used, cost_usd, metadata, story_type, pr_id, commit_hash, completion_summary, todos"
+	migrations := []string{
+		strings.Replace(storiesTableDDL, "stories", "stories_new", 1),
+		"INSERT INTO stories_new (" + columns + ") SELECT " + columns + " FROM stories",
+		"DROP TABLE stories",
+		"ALTER TABLE stories_new RENAME TO stories",
+		"CREATE INDEX IF NOT EXISTS idx_stories_status ON stories(status)",
+		"CREATE INDEX IF NOT EXISTS idx_stories_agent ON stories(assigned_agent)",
+		"CREATE INDEX IF NOT EXISTS idx_stories_type ON stories(story_type)",
+		"CREATE INDEX IF NOT EXISTS idx_stories_spec ON stories(spec_id)",
+	}
+
+	for _, migration := range migrations {
+		if _, err := conn.ExecContext(ctx, migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %s: %w", migration, err)
+		}
+	}
+
+	return nil
+}
+
+// migrateToVersion10 adds the estimated points to the stories table so the scheduler can project
+// schedules from the database. Databases rebuilt by migrateToVersion9 already have the column.
+func migrateToVersion10(db *sql.DB) error {
+	return addColumnIfMissing(db, "stories", "estimated_points", "INTEGER DEFAULT 0")
+}
+
+// migrateToVersion11 adds the token, cost and risk estimates and the actual iterations to the
+// stories table, and the estimate_outcomes table comparing completed stories' estimates with actuals.
+func migrateToVersion11(db *sql.DB) error {
+	columns := []struct{ name, definition string }{
+		{"estimated_tokens", "BIGINT DEFAULT 0"},
+		{"estimated_cost_usd", "DECIMAL(10,4) DEFAULT 0.0"},
+		{"risk", "TEXT DEFAULT ''"},
+		{"iterations", "INTEGER DEFAULT 0"},
+	}
+	for _, column := range columns {
+		if err := addColumnIfMissing(db, "stories", column.name, column.definition); err != nil {
+			return err
+		}
+	}
+	if _, err := db.Exec(estimateOutcomesTableDDL); err != nil {
+		return fmt.Errorf("failed to create estimate_outcomes table: %w", err)
+	}
+	return nil
+}
+
+// migrateToVersion12 adds the time a spec was last revised to the specs table, so revisions no
+// longer overwrite when the spec was created or first processed.
+func migrateToVersion12(db *sql.DB) error {
+	return addColumnIfMissing(db, "specs", "revised_at", "DATETIME")
+}
+
+// addColumnIfMissing adds a column to a table unless a rebuild of the table already created it.
+func addColumnIfMissing(db *sql.DB, table, column, definition string) error {
+	exists, err := columnExists(db, table, column)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add %s column: %w", column, err)
+	}
+	return nil
+}
+
+// columnExists reports whether a table has the named column.
+func columnExists(db *sql.DB, table, column string) (bool, error) {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect %s columns: %w", table, err)
+	}
+	return count > 0, nil
+}
+
+// Placeholder migrations for future versions (these would be implemented when needed).
 func migrateToVersion1(_ *sql.DB) error { return nil }
-func migrateToVersion2(_ *sql.DB) error { return nil }
-func migrateToVersion3(_ *sql.DB) error { return nil }
-func migrateToVersion4(_ *sql.DB) error { return nil }
-func migrateToVersion5(_ *sql.DB) error { return nil }
+
+// storyNotesTableDDL creates the table holding each story's coder notes.
+// Notes are keyed by story so they survive requeues and are handed to the next coder.
+const storyNotesTableDDL = `CREATE TABLE IF NOT EXISTS story_notes (
+			story_id TEXT PRIMARY KEY REFERENCES stories(id),
+			content TEXT NOT NULL,
+			updated_by TEXT,
+			updated_at DATETIME DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ','now'))
+		)`
+
+// flakyTestsTableDDL creates the table of tests that failed and then passed on an isolated re-run.
+// Tests are keyed by suite and name so repeated sightings across stories accumulate.
+const flakyTestsTableDDL = `CREATE TABLE IF NOT EXISTS flaky_tests (
+			suite TEXT NOT NULL DEFAULT '',
+			name TEXT NOT NULL,
+			occurrences INTEGER NOT NULL DEFAULT 1,
+			first_seen DATETIME DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ','now')),
+			last_seen DATETIME DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ','now')),
+			last_story_id TEXT,
+			last_output TEXT,
+			PRIMARY KEY (suite, name)
+		)`
+
+// storyHandoffsTableDDL creates the table of handoff records left when an attempt at a story
+// is abandoned, so the next coder knows what was tried. Files is a JSON array of paths.
+const storyHandoffsTableDDL = `CREATE TABLE IF NOT EXISTS story_handoffs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			story_id TEXT NOT NULL REFERENCES stories(id),
+			agent_id TEXT,
+			reason TEXT NOT NULL,
+			attempted TEXT,
+			last_test_output TEXT,
+			files TEXT,
+			branch_name TEXT,
+			branch_pushed BOOLEAN NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ','now'))
+		)`
+
+// storyHandoffsIndexDDL indexes handoffs by story.
+const storyHandoffsIndexDDL = "CREATE INDEX IF NOT EXISTS idx_story_handoffs_story ON story_handoffs(story_id)"
+
+// estimateOutcomesTableDDL creates the table comparing completed stories' estimates with what they
+// actually took. Rows are kept when a story is reworked, so the history calibrates later estimates.
+const estimateOutcomesTableDDL = `CREATE TABLE IF NOT EXISTS estimate_outcomes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			story_id TEXT NOT NULL REFERENCES stories(id),
+			story_type TEXT,
+			risk TEXT,
+			estimated_points INTEGER DEFAULT 0,
+			estimated_tokens BIGINT DEFAULT 0,
+			estimated_cost_usd DECIMAL(10,4) DEFAULT 0.0,
+			actual_tokens BIGINT DEFAULT 0,
+			actual_cost_usd DECIMAL(10,4) DEFAULT 0.0,
+			iterations INTEGER DEFAULT 0,
+			completed_at DATETIME DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ','now'))
+		)`
+
+// storiesTableDDL creates the table of stories generated from specs.
+const storiesTableDDL = `CREATE TABLE IF NOT EXISTS stories (
+			id TEXT PRIMARY KEY,
+			spec_id TEXT REFERENCES specs(id),
+			title TEXT NOT NULL,
+			content TEXT NOT NULL,
+			status TEXT DEFAULT 'new' CHECK (status IN ('new','pending','assigned','planning','coding','done','cancelled')),
+			priority INTEGER DEFAULT 0,
+			approved_plan TEXT,
+			created_at DATETIME DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ','now')),
+			started_at DATETIME,
+			completed_at DATETIME,
+			assigned_agent TEXT,
+			tokens_used BIGINT DEFAULT 0,
+			cost_usd DECIMAL(10,4) DEFAULT 0.0,
+			metadata TEXT,
+			story_type TEXT DEFAULT 'app' CHECK (story_type IN ('devops', 'app')),
+			pr_id TEXT,
+			commit_hash TEXT,
+			completion_summary TEXT,
+			todos TEXT,
+			estimated_points INTEGER DEFAULT 0,
+			estimated_tokens BIGINT DEFAULT 0,
+			estimated_cost_usd DECIMAL(10,4) DEFAULT 0.0,
+			risk TEXT DEFAULT '',
+			iterations INTEGER DEFAULT 0
+		)`
+
+// agentRequestsTableDDL creates the table holding questions and approval requests.
+const agentRequestsTableDDL = `CREATE TABLE IF NOT EXISTS agent_requests (
+			id TEXT PRIMARY KEY,
+			story_id TEXT REFERENCES stories(id),
+			request_type TEXT NOT NULL CHECK (request_type IN ('question', 'approval')),
+			approval_type TEXT CHECK (approval_type IN ('plan', 'code', 'budget_review', 'completion', 'split', 'tests')),
+			from_agent TEXT NOT NULL,
+			to_agent TEXT NOT NULL,
+			content TEXT NOT NULL,
+			context TEXT,
+			reason TEXT,
+			created_at DATETIME DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ','now')),
+			correlation_id TEXT,
+			parent_msg_id TEXT,
+			options TEXT,
+			recommended_option TEXT,
+			category TEXT,
+			blocking BOOLEAN
+		)`
 
 // createSchema creates all required tables and indices.
 func createSchema(db *sql.DB) error {
@@ -152,30 +469,12 @@ func createSchema(db *sql.DB) error {
 			id TEXT PRIMARY KEY,
 			content TEXT NOT NULL,
 			created_at DATETIME DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ','now')),
-			processed_at DATETIME
+			processed_at DATETIME,
+			revised_at DATETIME
 		)`,
 
 		// Stories table
-		`CREATE TABLE IF NOT EXISTS stories (
-			id TEXT PRIMARY KEY,
-			spec_id TEXT REFERENCES specs(id),
-			title TEXT NOT NULL,
-			content TEXT NOT NULL,
-			status TEXT DEFAULT 'new' CHECK (status IN ('new','pending','assigned','planning','coding','done')),
-			priority INTEGER DEFAULT 0,
-			approved_plan TEXT,
-			created_at DATETIME DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ','now')),
-			started_at DATETIME,
-			completed_at DATETIME,
-			assigned_agent TEXT,
-			tokens_used BIGINT DEFAULT 0,
-			cost_usd DECIMAL(10,4) DEFAULT 0.0,
-			metadata TEXT,
-			story_type TEXT DEFAULT 'app' CHECK (story_type IN ('devops', 'app')),
-			pr_id TEXT,
-			commit_hash TEXT,
-			completion_summary TEXT
-		)`,
+		storiesTableDDL,
 
 		// Story dependencies junction table
 		`CREATE TABLE IF NOT EXISTS story_dependencies (
@@ -197,20 +496,7 @@ func createSchema(db *sql.DB) error {
 		)`,
 
 		// Agent requests table (unified questions and approval requests)
-		`CREATE TABLE IF NOT EXISTS agent_requests (
-			id TEXT PRIMARY KEY,
-			story_id TEXT REFERENCES stories(id),
-			request_type TEXT NOT NULL CHECK (request_type IN ('question', 'approval')),
-			approval_type TEXT CHECK (approval_type IN ('plan', 'code', 'budget_review', 'completion')),
-			from_agent TEXT NOT NULL,
-			to_agent TEXT NOT NULL,
-			content TEXT NOT NULL,
-			context TEXT,
-			reason TEXT,
-			created_at DATETIME DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ','now')),
-			correlation_id TEXT,
-			parent_msg_id TEXT
-		)`,
+		agentRequestsTableDDL,
 
 		// Agent responses table (unified answers and approval results)
 		`CREATE TABLE IF NOT EXISTS agent_responses (
@@ -240,6 +526,18 @@ func createSchema(db *sql.DB) error {
 			reviewed_by TEXT,
 			feedback TEXT
 		)`,
+
+		// Story notes table (coder scratchpad, pinned into context)
+		storyNotesTableDDL,
+
+		// Flaky tests table (tests that failed and then passed in isolation)
+		flakyTestsTableDDL,
+
+		// Story handoffs table (what abandoned attempts tried)
+		storyHandoffsTableDDL,
+
+		// Estimate outcomes table (estimates versus actuals of completed stories)
+		estimateOutcomesTableDDL,
 	}
 
 	// Create indices
@@ -257,6 +555,7 @@ func createSchema(db *sql.DB) error {
 		"CREATE INDEX IF NOT EXISTS idx_agent_responses_request ON agent_responses(request_id)",
 		"CREATE INDEX IF NOT EXISTS idx_agent_responses_story ON agent_responses(story_id)",
 		"CREATE INDEX IF NOT EXISTS idx_agent_responses_correlation ON agent_responses(correlation_id)",
+		storyHandoffsIndexDDL,
 		"CREATE INDEX IF NOT EXISTS idx_agent_plans_story ON agent_plans(story_id)",
 		"CREATE INDEX IF NOT EXISTS idx_agent_plans_status ON agent_plans(status)",
 	}
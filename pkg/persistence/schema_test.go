@@ -0,0 +1,347 @@
+package persistence
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrateToVersion5AllowsSplitApprovals(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "migrate.db")
+	db, err := InitializeDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Recreate the version 4 agent_requests table, which did not accept split approvals
+	oldDDL := strings.Replace(agentRequestsTableDDL, ", 'split', 'tests'", "", 1)
+	setup := []string{
+		"DROP TABLE agent_responses",
+		"DROP TABLE agent_requests",
+		oldDDL,
+		`CREATE TABLE agent_responses (
+			id TEXT PRIMARY KEY,
+			request_id TEXT REFERENCES agent_requests(id),
+			response_type TEXT NOT NULL,
+			from_agent TEXT NOT NULL,
+			to_agent TEXT NOT NULL,
+			content TEXT NOT NULL
+		)`,
+		"INSERT INTO agent_requests (id, request_type, approval_type, from_agent, to_agent, content) VALUES ('req-1', 'approval', 'plan', 'coder-001', 'architect', 'plan')",
+		"INSERT INTO agent_responses (id, request_id, response_type, from_agent, to_agent, content) VALUES ('resp-1', 'req-1', 'result', 'architect', 'coder-001', 'ok')",
+		"DELETE FROM schema_version",
+	}
+	for _, stmt := range setup {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Setup failed on %q: %v", stmt, err)
+		}
+	}
+	if err := setSchemaVersion(db, 4); err != nil {
+		t.Fatalf("Failed to set schema version: %v", err)
+	}
+	db.Close()
+
+	db, err = InitializeDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Migration failed: %v", err)
+	}
+	defer db.Close()
+
+	if version, _ := GetSchemaVersion(db); version != CurrentSchemaVersion {
+		t.Errorf("Expected schema version %d, got %d", CurrentSchemaVersion, version)
+	}
+
+	var content string
+	if err := db.QueryRow("SELECT content FROM agent_requests WHERE id = 'req-1'").Scan(&content); err != nil || content != "plan" {
+		t.Errorf("Expected existing request to survive the migration, got %q (%v)", content, err)
+	}
+	if _, err := db.Exec("INSERT INTO agent_requests (id, request_type, approval_type, from_agent, to_agent, content) VALUES ('req-2', 'approval', 'split', 'coder-001', 'architect', 'split')"); err != nil {
+		t.Errorf("Expected split approvals to be accepted after migration: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO agent_responses (id, request_id, response_type, from_agent, to_agent, content) VALUES ('resp-2', 'req-2', 'result', 'architect', 'coder-001', 'ok')"); err != nil {
+		t.Errorf("Expected responses to reference the rebuilt table: %v", err)
+	}
+}
+
+func TestMigrateToVersion7AllowsTestsApprovals(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "migrate.db")
+	db, err := InitializeDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Recreate the version 6 agent_requests table, which did not accept tests approvals
+	setup := []string{
+		"DROP TABLE agent_responses",
+		"DROP TABLE agent_requests",
+		strings.Replace(agentRequestsTableDDL, ", 'tests'", "", 1),
+		"INSERT INTO agent_requests (id, request_type, approval_type, from_agent, to_agent, content) VALUES ('req-1', 'approval', 'split', 'coder-001', 'architect', 'split')",
+		"DELETE FROM schema_version",
+	}
+	for _, stmt := range setup {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Setup failed on %q: %v", stmt, err)
+		}
+	}
+	if err := setSchemaVersion(db, 6); err != nil {
+		t.Fatalf("Failed to set schema version: %v", err)
+	}
+	db.Close()
+
+	db, err = InitializeDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Migration failed: %v", err)
+	}
+	defer db.Close()
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM agent_requests").Scan(&count); err != nil || count != 1 {
+		t.Errorf("Expected the existing request to survive the migration, got %d (%v)", count, err)
+	}
+	if _, err := db.Exec("INSERT INTO agent_requests (id, request_type, approval_type, from_agent, to_agent, content) VALUES ('req-2', 'approval', 'tests', 'coder-001', 'architect', 'tests')"); err != nil {
+		t.Errorf("Expected tests approvals to be accepted after migration: %v", err)
+	}
+}
+
+func TestMigrateToVersion8AddsStoryHandoffs(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "migrate.db")
+	db, err := InitializeDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Recreate a version 7 database, which had no story_handoffs table
+	for _, stmt := range []string{"DROP TABLE story_handoffs", "DELETE FROM schema_version"} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Setup failed on %q: %v", stmt, err)
+		}
+	}
+	if err := setSchemaVersion(db, 7); err != nil {
+		t.Fatalf("Failed to set schema version: %v", err)
+	}
+	db.Close()
+
+	db, err = InitializeDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Migration failed: %v", err)
+	}
+	defer db.Close()
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM story_handoffs").Scan(&count); err != nil {
+		t.Errorf("Expected the story_handoffs table after migration: %v", err)
+	}
+}
+
+func TestMigrateToVersion9AllowsCancelledStories(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "migrate.db")
+	db, err := InitializeDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Recreate the version 8 stories table, which did not accept cancelled stories
+	setup := []string{
+		"INSERT INTO specs (id, content) VALUES ('spec-1', 'spec')",
+		"DROP TABLE stories",
+		strings.Replace(storiesTableDDL, ",'cancelled'", "", 1),
+		"INSERT INTO stories (id, spec_id, title, content, status) VALUES ('story-1', 'spec-1', 'First', 'content', 'done')",
+		"DELETE FROM schema_version",
+	}
+	for _, stmt := range setup {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Setup failed on %q: %v", stmt, err)
+		}
+	}
+	if err := setSchemaVersion(db, 8); err != nil {
+		t.Fatalf("Failed to set schema version: %v", err)
+	}
+	db.Close()
+
+	db, err = InitializeDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Migration failed: %v", err)
+	}
+	defer db.Close()
+
+	var status string
+	if err := db.QueryRow("SELECT status FROM stories WHERE id = 'story-1'").Scan(&status); err != nil || status != "done" {
+		t.Errorf("Expected existing story to survive the migration, got %q (%v)", status, err)
+	}
+	if _, err := db.Exec("INSERT INTO stories (id, spec_id, title, content, status) VALUES ('story-2', 'spec-1', 'Second', 'content', 'cancelled')"); err != nil {
+		t.Errorf("Expected cancelled stories to be accepted after migration: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO story_notes (story_id, content) VALUES ('story-2', 'notes')"); err != nil {
+		t.Errorf("Expected notes to reference the rebuilt table: %v", err)
+	}
+}
+
+func TestMigrateToVersion10AddsEstimatedPoints(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "migrate.db")
+	db, err := InitializeDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Recreate the version 9 stories table, which had no estimate column
+	setup := []string{
+		"INSERT INTO specs (id, content) VALUES ('spec-1', 'spec')",
+		"DROP TABLE stories",
+		strings.Replace(storiesTableDDL, ",\n\t\t\testimated_points INTEGER DEFAULT 0", "", 1),
+		"INSERT INTO stories (id, spec_id, title, content, status, approved_plan, assigned_agent, metadata) " +
+			"VALUES ('story-1', 'spec-1', 'First', 'content', 'new', '', '', '')",
+		"DELETE FROM schema_version",
+	}
+	for _, stmt := range setup {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Setup failed on %q: %v", stmt, err)
+		}
+	}
+	if exists, err := columnExists(db, "stories", "estimated_points"); err != nil || exists {
+		t.Fatalf("Expected the version 9 table without estimated_points (%v)", err)
+	}
+	if err := setSchemaVersion(db, 9); err != nil {
+		t.Fatalf("Failed to set schema version: %v", err)
+	}
+	db.Close()
+
+	db, err = InitializeDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Migration failed: %v", err)
+	}
+	defer db.Close()
+
+	ops := NewDatabaseOperations(db)
+	story, err := ops.GetStoryByID("story-1")
+	if err != nil || story.EstimatedPoints != 0 {
+		t.Fatalf("Expected existing story to default to 0 points, got %+v (%v)", story, err)
+	}
+	story.StoryType = "app" // GetStoryByID does not load the story type
+	story.EstimatedPoints = 5
+	if err := ops.UpsertStory(story); err != nil {
+		t.Fatalf("Failed to upsert story: %v", err)
+	}
+	if story, err = ops.GetStoryByID("story-1"); err != nil || story.EstimatedPoints != 5 {
+		t.Errorf("Expected estimated points to round-trip, got %+v (%v)", story, err)
+	}
+}
+
+func TestMigrateToVersion11AddsEstimates(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "migrate.db")
+	db, err := InitializeDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Recreate the version 10 schema, which had no token, cost or risk estimates
+	setup := []string{
+		"DROP TABLE estimate_outcomes",
+		"ALTER TABLE stories DROP COLUMN estimated_tokens",
+		"ALTER TABLE stories DROP COLUMN estimated_cost_usd",
+		"ALTER TABLE stories DROP COLUMN risk",
+		"ALTER TABLE stories DROP COLUMN iterations",
+		"DELETE FROM schema_version",
+	}
+	for _, stmt := range setup {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Setup failed on %q: %v", stmt, err)
+		}
+	}
+	if err := setSchemaVersion(db, 10); err != nil {
+		t.Fatalf("Failed to set schema version: %v", err)
+	}
+	db.Close()
+
+	db, err = InitializeDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Migration failed: %v", err)
+	}
+	defer db.Close()
+
+	for _, column := range []string{"estimated_tokens", "estimated_cost_usd", "risk", "iterations"} {
+		if exists, err := columnExists(db, "stories", column); err != nil || !exists {
+			t.Errorf("Expected stories.%s after migration (%v)", column, err)
+		}
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM estimate_outcomes").Scan(&count); err != nil {
+		t.Errorf("Expected the estimate_outcomes table after migration: %v", err)
+	}
+}
+
+func TestMigrateToVersion12AddsSpecRevisedAt(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "migrate.db")
+	db, err := InitializeDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Recreate the version 11 specs table, which did not record revisions
+	setup := []string{
+		"ALTER TABLE specs DROP COLUMN revised_at",
+		"DELETE FROM schema_version",
+	}
+	for _, stmt := range setup {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Setup failed on %q: %v", stmt, err)
+		}
+	}
+	if err := setSchemaVersion(db, 11); err != nil {
+		t.Fatalf("Failed to set schema version: %v", err)
+	}
+	db.Close()
+
+	db, err = InitializeDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Migration failed: %v", err)
+	}
+	defer db.Close()
+
+	if exists, err := columnExists(db, "specs", "revised_at"); err != nil || !exists {
+		t.Errorf("Expected specs.revised_at after migration (%v)", err)
+	}
+}
+
+func TestOpenDatabaseReadOnly(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "readonly.db")
+	db, err := InitializeDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	if err := NewDatabaseOperations(db).UpsertSpec(&Spec{ID: "spec-1", Content: "# Spec"}); err != nil {
+		t.Fatalf("Failed to upsert spec: %v", err)
+	}
+
+	readOnly, err := OpenDatabaseReadOnly(dbPath)
+	if err != nil {
+		t.Fatalf("OpenDatabaseReadOnly failed: %v", err)
+	}
+	if spec, err := NewDatabaseOperations(readOnly).GetSpecByID("spec-1"); err != nil || spec.Content != "# Spec" {
+		t.Errorf("Expected to read the spec, got %+v (%v)", spec, err)
+	}
+	if err := NewDatabaseOperations(readOnly).UpsertSpec(&Spec{ID: "spec-2", Content: "# Other"}); err == nil {
+		t.Error("Expected writes to a read-only database to fail")
+	}
+	readOnly.Close()
+
+	// An outdated schema is reported instead of migrated
+	if _, err := db.Exec("DELETE FROM schema_version"); err != nil {
+		t.Fatalf("Failed to clear schema version: %v", err)
+	}
+	if err := setSchemaVersion(db, CurrentSchemaVersion-1); err != nil {
+		t.Fatalf("Failed to set schema version: %v", err)
+	}
+	db.Close()
+
+	if readOnly, err := OpenDatabaseReadOnly(dbPath); err == nil {
+		readOnly.Close()
+		t.Fatal("Expected an outdated schema to be rejected")
+	}
+}
+
+func TestOpenDatabaseReadOnlyMissing(t *testing.T) {
+	if db, err := OpenDatabaseReadOnly(filepath.Join(t.TempDir(), "missing.db")); err == nil {
+		db.Close()
+		t.Error("Expected a missing database to be an error rather than created")
+	}
+}
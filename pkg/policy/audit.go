@@ -0,0 +1,147 @@
+package policy
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// AuditFilename is the audit trail file name inside the .maestro directory.
+const AuditFilename = "policy_audit.jsonl"
+
+// AuditEntry records a blocked command.
+//
+//nolint:govet // fieldalignment: JSON serialization order requirements
+type AuditEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	AgentID   string    `json:"agent_id"`
+	StoryID   string    `json:"story_id"`
+	StoryType string    `json:"story_type"`
+	Tool      string    `json:"tool"`
+	Command   string    `json:"command"`
+	RuleID    string    `json:"rule_id,omitempty"`
+	Reason    string    `json:"reason"`
+}
+
+// auditMu serializes appends from concurrent agents within this process.
+//
+//nolint:gochecknoglobals // Shared lock for the single audit file per project
+var auditMu sync.Mutex
+
+// AppendAudit appends an entry to the audit trail in the given .maestro directory.
+func AppendAudit(maestroDir string, entry *AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	auditMu.Lock()
+	defer auditMu.Unlock()
+
+	if err := os.MkdirAll(maestroDir, 0755); err != nil {
+		return fmt.Errorf("failed to create audit directory: %w", err)
+	}
+	file, err := os.OpenFile(filepath.Join(maestroDir, AuditFilename), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	return nil
+}
+
+// ReadAudit returns audit entries for a story (all entries when storyID is empty).
+func ReadAudit(maestroDir, storyID string) ([]AuditEntry, error) {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+
+	file, err := os.Open(filepath.Join(maestroDir, AuditFilename))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue // Skip corrupt lines rather than hiding the rest of the trail
+		}
+		if storyID == "" || entry.StoryID == storyID {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("failed to read audit file: %w", err)
+	}
+	return entries, nil
+}
+
+// Enforcer binds a policy to the agent and story it is enforced for.
+//
+//nolint:govet // fieldalignment: Logical grouping preferred over memory optimization
+type Enforcer struct {
+	policy     *Policy
+	maestroDir string
+	AgentID    string
+	StoryID    string
+	StoryType  string
+
+	// DefaultWorkDir resolves relative paths when a command has no explicit working directory.
+	DefaultWorkDir string
+}
+
+// NewEnforcer creates an enforcer that audits violations into maestroDir.
+func NewEnforcer(p *Policy, maestroDir, agentID, storyID, storyType string) *Enforcer {
+	return &Enforcer{
+		policy:     p,
+		maestroDir: maestroDir,
+		AgentID:    agentID,
+		StoryID:    storyID,
+		StoryType:  storyType,
+	}
+}
+
+// Check evaluates a command. Violations are appended to the audit trail and returned as an error.
+func (e *Enforcer) Check(tool, command, workDir string) error {
+	if e == nil {
+		return nil
+	}
+
+	if workDir == "" {
+		workDir = e.DefaultWorkDir
+	}
+	decision := e.policy.Evaluate(&Request{Command: command, WorkDir: workDir, StoryType: e.StoryType})
+	if decision.Allowed {
+		return nil
+	}
+
+	entry := &AuditEntry{
+		Timestamp: time.Now().UTC(),
+		AgentID:   e.AgentID,
+		StoryID:   e.StoryID,
+		StoryType: e.StoryType,
+		Tool:      tool,
+		Command:   command,
+		RuleID:    decision.RuleID,
+		Reason:    decision.Reason,
+	}
+	if err := AppendAudit(e.maestroDir, entry); err != nil {
+		// Still block the command even if the audit write fails.
+		return fmt.Errorf("policy violation: %s (audit failed: %w)", decision.Reason, err)
+	}
+	return fmt.Errorf("policy violation: %s", decision.Reason)
+}
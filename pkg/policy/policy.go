@@ -0,0 +1,258 @@
+// Package policy evaluates per-project allow/deny rules for agent shell commands and
+// records violations in an audit trail.
+//
+// Rules are read from <projectDir>/.maestro/policy.json:
+//
+//	{
+//	  "rules": [
+//	    {"id": "no-git-rm", "action": "deny", "commands": ["^rm\\s+-[a-z]*r"], "paths": ["/workspace/.git/**"]},
+//	    {"id": "no-push", "action": "deny", "commands": ["\\bgit\\s+push\\b"], "reason": "merges go through the architect"},
+//	    {"id": "no-curl-app", "action": "deny", "commands": ["\\bcurl\\b"], "story_types": ["app"]}
+//	  ]
+//	}
+//
+// Deny rules always win. If any allow rule applies to the story type, commands must also
+// match at least one allow rule.
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// Filename is the policy file name inside the .maestro directory.
+	Filename = "policy.json"
+
+	// ActionAllow marks a rule that permits matching commands.
+	ActionAllow = "allow"
+	// ActionDeny marks a rule that forbids matching commands.
+	ActionDeny = "deny"
+)
+
+// Rule is a single allow or deny rule. All non-empty conditions must match.
+//
+//nolint:govet // fieldalignment: JSON serialization order requirements
+type Rule struct {
+	ID         string   `json:"id"`
+	Action     string   `json:"action"`                // "allow" or "deny"
+	Commands   []string `json:"commands,omitempty"`    // Regular expressions matched against the full command
+	Paths      []string `json:"paths,omitempty"`       // Path globs; "dir/**" matches everything below dir
+	StoryTypes []string `json:"story_types,omitempty"` // Story types the rule applies to (empty = all)
+	Reason     string   `json:"reason,omitempty"`      // Shown to the model when the rule blocks a command
+
+	commandPatterns []*regexp.Regexp
+}
+
+// Policy is a compiled set of rules.
+type Policy struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Request describes a command to be checked.
+type Request struct {
+	Command   string
+	WorkDir   string
+	StoryType string
+}
+
+// Decision is the outcome of evaluating a request.
+type Decision struct {
+	RuleID  string
+	Reason  string
+	Allowed bool
+}
+
+// Load reads and compiles the policy file from the given .maestro directory.
+// A missing file yields an empty policy that allows everything.
+func Load(maestroDir string) (*Policy, error) {
+	data, err := os.ReadFile(filepath.Join(maestroDir, Filename))
+	if errors.Is(err, os.ErrNotExist) {
+		return &Policy{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+	return Parse(data)
+}
+
+// DenyAll returns a policy that blocks every command with the given reason.
+func DenyAll(reason string) *Policy {
+	return &Policy{Rules: []Rule{{
+		ID:              "deny-all",
+		Action:          ActionDeny,
+		Commands:        []string{".*"},
+		Reason:          reason,
+		commandPatterns: []*regexp.Regexp{regexp.MustCompile(".*")},
+	}}}
+}
+
+// Parse decodes and compiles a policy document.
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file: %w", err)
+	}
+	if err := p.compile(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// compile validates rules and pre-compiles command patterns.
+func (p *Policy) compile() error {
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.ID == "" {
+			rule.ID = fmt.Sprintf("rule-%d", i+1)
+		}
+		if rule.Action != ActionAllow && rule.Action != ActionDeny {
+			return fmt.Errorf("policy rule %s: action must be %q or %q", rule.ID, ActionAllow, ActionDeny)
+		}
+		if len(rule.Commands) == 0 && len(rule.Paths) == 0 {
+			return fmt.Errorf("policy rule %s: at least one of commands or paths is required", rule.ID)
+		}
+		rule.commandPatterns = make([]*regexp.Regexp, 0, len(rule.Commands))
+		for _, pattern := range rule.Commands {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("policy rule %s: invalid command pattern %q: %w", rule.ID, pattern, err)
+			}
+			rule.commandPatterns = append(rule.commandPatterns, re)
+		}
+		for _, pattern := range rule.Paths {
+			if _, err := path.Match(strings.TrimSuffix(pattern, "/**"), "/"); err != nil {
+				return fmt.Errorf("policy rule %s: invalid path pattern %q: %w", rule.ID, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Evaluate checks a request against the policy.
+func (p *Policy) Evaluate(req *Request) Decision {
+	if p == nil || len(p.Rules) == 0 {
+		return Decision{Allowed: true}
+	}
+
+	hasAllowRules := false
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if !rule.appliesTo(req.StoryType) {
+			continue
+		}
+		if rule.Action == ActionAllow {
+			hasAllowRules = true
+			continue
+		}
+		if rule.matches(req) {
+			return Decision{Allowed: false, RuleID: rule.ID, Reason: rule.reason()}
+		}
+	}
+
+	if !hasAllowRules {
+		return Decision{Allowed: true}
+	}
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.Action == ActionAllow && rule.appliesTo(req.StoryType) && rule.matches(req) {
+			return Decision{Allowed: true, RuleID: rule.ID}
+		}
+	}
+	return Decision{Allowed: false, Reason: "command does not match any allow rule"}
+}
+
+// appliesTo reports whether the rule is active for the story type.
+func (r *Rule) appliesTo(storyType string) bool {
+	if len(r.StoryTypes) == 0 {
+		return true
+	}
+	for _, t := range r.StoryTypes {
+		if t == storyType {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether every configured condition matches the request.
+func (r *Rule) matches(req *Request) bool {
+	if len(r.commandPatterns) > 0 {
+		matched := false
+		for _, re := range r.commandPatterns {
+			if re.MatchString(req.Command) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(r.Paths) > 0 {
+		for _, p := range commandPaths(req.Command, req.WorkDir) {
+			for _, pattern := range r.Paths {
+				if matchPath(pattern, p) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// reason returns the message shown for a deny decision.
+func (r *Rule) reason() string {
+	if r.Reason != "" {
+		return r.Reason
+	}
+	return fmt.Sprintf("blocked by policy rule %s", r.ID)
+}
+
+// commandPaths extracts path-like arguments from a command and resolves them against workDir.
+func commandPaths(command, workDir string) []string {
+	var paths []string
+	for _, field := range strings.Fields(command) {
+		token := strings.Trim(field, `"'`)
+		if eq := strings.Index(token, "="); eq >= 0 && strings.HasPrefix(token, "-") {
+			token = token[eq+1:]
+		}
+		if token == "" || strings.HasPrefix(token, "-") {
+			continue
+		}
+		if !strings.Contains(token, "/") && !strings.HasPrefix(token, ".") {
+			continue
+		}
+		if !path.IsAbs(token) && workDir != "" {
+			token = path.Join(workDir, token)
+		}
+		paths = append(paths, path.Clean(token))
+	}
+	return paths
+}
+
+// matchPath matches a cleaned path against a glob, treating a trailing "/**" as "this
+// directory and everything below it".
+func matchPath(pattern, p string) bool {
+	if base, ok := strings.CutSuffix(pattern, "/**"); ok {
+		if matched, _ := path.Match(base, p); matched {
+			return true
+		}
+		for dir := path.Dir(p); dir != "/" && dir != "."; dir = path.Dir(dir) {
+			if matched, _ := path.Match(base, dir); matched {
+				return true
+			}
+		}
+		return false
+	}
+	matched, _ := path.Match(pattern, p)
+	return matched
+}
@@ -0,0 +1,144 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const examplePolicy = `{
+  "rules": [
+    {"id": "no-git-rm", "action": "deny", "commands": ["^rm\\s+-[a-z]*r"], "paths": ["/workspace/.git/**"]},
+    {"id": "no-push", "action": "deny", "commands": ["\\bgit\\s+push\\b"], "reason": "merges go through the architect"},
+    {"id": "no-curl-app", "action": "deny", "commands": ["\\bcurl\\b"], "story_types": ["app"]}
+  ]
+}`
+
+func TestEvaluate_DenyRules(t *testing.T) {
+	p, err := Parse([]byte(examplePolicy))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		command   string
+		workDir   string
+		storyType string
+		allowed   bool
+		ruleID    string
+	}{
+		{"rm git dir absolute", "rm -rf /workspace/.git", "/workspace", "app", false, "no-git-rm"},
+		{"rm git dir relative", "rm -rf .git/objects", "/workspace", "app", false, "no-git-rm"},
+		{"rm other dir", "rm -rf build/", "/workspace", "app", true, ""},
+		{"git push", "git push origin main", "/workspace", "devops", false, "no-push"},
+		{"git status", "git status", "/workspace", "devops", true, ""},
+		{"curl in app story", "curl https://example.com", "/workspace", "app", false, "no-curl-app"},
+		{"curl in devops story", "curl https://example.com", "/workspace", "devops", true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := p.Evaluate(&Request{Command: tt.command, WorkDir: tt.workDir, StoryType: tt.storyType})
+			if decision.Allowed != tt.allowed || decision.RuleID != tt.ruleID {
+				t.Errorf("Evaluate(%q) = %+v, want allowed=%v rule=%q", tt.command, decision, tt.allowed, tt.ruleID)
+			}
+		})
+	}
+}
+
+func TestEvaluate_AllowList(t *testing.T) {
+	p, err := Parse([]byte(`{"rules": [
+		{"id": "read-only", "action": "allow", "commands": ["^(ls|cat|grep|find)\\b"], "story_types": ["app"]},
+		{"id": "no-secrets", "action": "deny", "paths": ["/workspace/secrets/**"]}
+	]}`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if d := p.Evaluate(&Request{Command: "ls -la", StoryType: "app"}); !d.Allowed {
+		t.Errorf("Expected allow-listed command to pass, got %+v", d)
+	}
+	if d := p.Evaluate(&Request{Command: "make build", StoryType: "app"}); d.Allowed {
+		t.Error("Expected command outside allow list to be denied")
+	}
+	if d := p.Evaluate(&Request{Command: "make build", StoryType: "devops"}); !d.Allowed {
+		t.Errorf("Expected allow list not to apply to devops stories, got %+v", d)
+	}
+	if d := p.Evaluate(&Request{Command: "cat secrets/key.pem", WorkDir: "/workspace", StoryType: "app"}); d.Allowed || d.RuleID != "no-secrets" {
+		t.Errorf("Expected deny rule to win over allow rule, got %+v", d)
+	}
+}
+
+func TestParse_InvalidRules(t *testing.T) {
+	invalid := []string{
+		`{"rules": [{"action": "maybe", "commands": ["x"]}]}`,
+		`{"rules": [{"action": "deny"}]}`,
+		`{"rules": [{"action": "deny", "commands": ["("]}]}`,
+		`not json`,
+	}
+	for _, doc := range invalid {
+		if _, err := Parse([]byte(doc)); err == nil {
+			t.Errorf("Expected error for policy %s", doc)
+		}
+	}
+}
+
+func TestLoad_MissingFileAllowsEverything(t *testing.T) {
+	p, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if d := p.Evaluate(&Request{Command: "rm -rf /"}); !d.Allowed {
+		t.Errorf("Expected empty policy to allow, got %+v", d)
+	}
+}
+
+func TestEnforcer_AuditsViolations(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, Filename), []byte(examplePolicy), 0644); err != nil {
+		t.Fatalf("Failed to write policy: %v", err)
+	}
+	p, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	enforcer := NewEnforcer(p, dir, "coder-001", "story-1", "app")
+	enforcer.DefaultWorkDir = "/workspace"
+
+	if err := enforcer.Check("shell", "ls", ""); err != nil {
+		t.Errorf("Expected ls to be allowed, got %v", err)
+	}
+	err = enforcer.Check("shell", "git push", "")
+	if err == nil || !strings.Contains(err.Error(), "merges go through the architect") {
+		t.Fatalf("Expected policy violation with rule reason, got %v", err)
+	}
+	if err := enforcer.Check("shell", "rm -rf .git", ""); err == nil {
+		t.Error("Expected relative path to resolve against default work dir")
+	}
+
+	other := NewEnforcer(p, dir, "coder-002", "story-2", "app")
+	_ = other.Check("shell", "curl example.com", "")
+
+	entries, err := ReadAudit(dir, "story-1")
+	if err != nil {
+		t.Fatalf("ReadAudit failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].RuleID != "no-push" || entries[0].AgentID != "coder-001" {
+		t.Errorf("Expected two audited violations for story-1, got %+v", entries)
+	}
+
+	all, _ := ReadAudit(dir, "")
+	if len(all) != 3 {
+		t.Errorf("Expected three audit entries overall, got %d", len(all))
+	}
+}
+
+func TestEnforcer_NilIsPermissive(t *testing.T) {
+	var enforcer *Enforcer
+	if err := enforcer.Check("shell", "rm -rf /", ""); err != nil {
+		t.Errorf("Expected nil enforcer to allow, got %v", err)
+	}
+}
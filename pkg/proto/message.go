@@ -88,11 +88,12 @@ const (
 	KeyRequeue  = "requeue"  // Requeue request payload
 
 	// Response-specific keys.
-	KeyAnswer       = "answer"        // Question response payload
-	KeyDecision     = "decision"      // Approval decision
-	KeyFeedback     = "feedback"      // General feedback/comments
-	KeySuccess      = "success"       // Whether operation was successful
-	KeyErrorMessage = "error_message" // Error details for failed operations
+	KeyAnswer         = "answer"          // Question response payload
+	KeySelectedOption = "selected_option" // Option chosen for a multiple-choice question
+	KeyDecision       = "decision"        // Approval decision
+	KeyFeedback       = "feedback"        // General feedback/comments
+	KeySuccess        = "success"         // Whether operation was successful
+	KeyErrorMessage   = "error_message"   // Error details for failed operations
 
 	// Story-related keys.
 	KeyStoryType       = "story_type"
@@ -103,6 +104,8 @@ const (
 	KeyEstimatedPoints = "estimated_points"
 	KeyFilePath        = "file_path"
 	KeyBackend         = "backend"
+	KeyStorySplit      = "story_split"
+	KeyTestFirst       = "test_first"
 
 	// Resource request keys.
 	KeyRequestedTokens     = "requestedTokens"
@@ -142,6 +145,12 @@ const (
 
 	// ApprovalTypeCompletion indicates a story completion request.
 	ApprovalTypeCompletion ApprovalType = "completion"
+
+	// ApprovalTypeSplit indicates a request to split a story into sub-stories.
+	ApprovalTypeSplit ApprovalType = "split"
+
+	// ApprovalTypeTests indicates a request to approve test-first tests before coding.
+	ApprovalTypeTests ApprovalType = "tests"
 )
 
 // ApprovalRequest represents a request for approval (plan or code).
@@ -464,7 +473,7 @@ func ValidateApprovalStatus(status string) (ApprovalStatus, bool) {
 // ValidateApprovalType validates if a string is a valid approval type.
 func ValidateApprovalType(approvalType string) (ApprovalType, bool) {
 	switch ApprovalType(approvalType) {
-	case ApprovalTypePlan, ApprovalTypeCode, ApprovalTypeBudgetReview, ApprovalTypeCompletion:
+	case ApprovalTypePlan, ApprovalTypeCode, ApprovalTypeBudgetReview, ApprovalTypeCompletion, ApprovalTypeSplit, ApprovalTypeTests:
 		return ApprovalType(approvalType), true
 	default:
 		return "", false
@@ -606,6 +615,10 @@ func ParseApprovalType(s string) (ApprovalType, error) {
 		return ApprovalTypeBudgetReview, nil
 	case "completion":
 		return ApprovalTypeCompletion, nil
+	case "split":
+		return ApprovalTypeSplit, nil
+	case "tests":
+		return ApprovalTypeTests, nil
 	default:
 		// Check if it's already in the correct format.
 		if approvalType, valid := ValidateApprovalType(s); valid {
@@ -686,9 +699,52 @@ type StateChangeNotification struct {
 }
 
 // StoryStatusUpdate represents a simple story status change notification.
+// An empty Status leaves the story status unchanged; Todos, when set, replaces the
+// story's plan-todo progress; FlakyTests reports flaky tests seen while testing the story.
 type StoryStatusUpdate struct {
-	StoryID   string    `json:"story_id"`
-	Status    string    `json:"status"`
-	Timestamp time.Time `json:"timestamp"`
-	AgentID   string    `json:"agent_id"`
+	StoryID    string      `json:"story_id"`
+	Status     string      `json:"status"`
+	Timestamp  time.Time   `json:"timestamp"`
+	AgentID    string      `json:"agent_id"`
+	Todos      []StoryTodo `json:"todos,omitempty"`
+	FlakyTests []FlakyTest `json:"flaky_tests,omitempty"`
+}
+
+// FlakyTest is a test that failed during TESTING and then passed when re-run in isolation.
+type FlakyTest struct {
+	Suite  string `json:"suite,omitempty"` // Package, class or file the test belongs to
+	Name   string `json:"name"`
+	Output string `json:"output,omitempty"` // Output of the original failure
+}
+
+// TodoStatus is the progress of a single approved-plan todo.
+type TodoStatus string
+
+const (
+	// TodoPending indicates work on the todo has not started.
+	TodoPending TodoStatus = "pending"
+	// TodoInProgress indicates the coder is working on the todo.
+	TodoInProgress TodoStatus = "in_progress"
+	// TodoDone indicates the todo is complete.
+	TodoDone TodoStatus = "done"
+	// TodoBlocked indicates the todo cannot be completed; Note explains why.
+	TodoBlocked TodoStatus = "blocked"
+)
+
+// ValidTodoStatus reports whether s is a known todo status.
+func ValidTodoStatus(s TodoStatus) bool {
+	switch s {
+	case TodoPending, TodoInProgress, TodoDone, TodoBlocked:
+		return true
+	default:
+		return false
+	}
+}
+
+// StoryTodo is an approved-plan todo with its current progress.
+type StoryTodo struct {
+	ID          string     `json:"id"`
+	Description string     `json:"description"`
+	Status      TodoStatus `json:"status"`
+	Note        string     `json:"note,omitempty"` // Why the todo is blocked or was skipped
 }
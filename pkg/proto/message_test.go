@@ -530,6 +530,8 @@ func TestEnumParsing(t *testing.T) {
 			{"code", ApprovalTypeCode, false},
 			{"budget_review", ApprovalTypeBudgetReview, false},
 			{"completion", ApprovalTypeCompletion, false},
+			{"split", ApprovalTypeSplit, false},
+			{"tests", ApprovalTypeTests, false},
 			{"invalid", "", true},
 		}
 
@@ -80,11 +80,37 @@ type UnifiedResponse struct {
 
 // QuestionRequestPayload represents the payload for question requests.
 type QuestionRequestPayload struct {
-	Text        string            `json:"text"`                  // The question text
-	Context     string            `json:"context,omitempty"`     // Additional context
-	Urgency     string            `json:"urgency,omitempty"`     // How urgent is the answer
-	Suggestions []string          `json:"suggestions,omitempty"` // Suggested answers
-	Metadata    map[string]string `json:"metadata,omitempty"`    // Question-specific metadata
+	Text              string            `json:"text"`                         // The question text
+	Context           string            `json:"context,omitempty"`            // Additional context
+	Urgency           string            `json:"urgency,omitempty"`            // How urgent is the answer
+	Suggestions       []string          `json:"suggestions,omitempty"`        // Suggested answers
+	Metadata          map[string]string `json:"metadata,omitempty"`           // Question-specific metadata
+	Options           []string          `json:"options,omitempty"`            // Candidate answers to choose from
+	RecommendedOption string            `json:"recommended_option,omitempty"` // Option the asker would pick
+	Category          QuestionCategory  `json:"category,omitempty"`           // technical, business or environment
+	NonBlocking       bool              `json:"non_blocking,omitempty"`       // Asker keeps working while waiting
+}
+
+// QuestionCategory classifies a question so the architect can route it.
+type QuestionCategory string
+
+const (
+	// QuestionCategoryTechnical covers design and implementation questions the architect answers.
+	QuestionCategoryTechnical QuestionCategory = "technical"
+	// QuestionCategoryBusiness covers product and requirement decisions that need a human.
+	QuestionCategoryBusiness QuestionCategory = "business"
+	// QuestionCategoryEnvironment covers tooling, credentials and infrastructure problems.
+	QuestionCategoryEnvironment QuestionCategory = "environment"
+)
+
+// ValidQuestionCategory reports whether c is a known question category.
+func ValidQuestionCategory(c QuestionCategory) bool {
+	switch c {
+	case QuestionCategoryTechnical, QuestionCategoryBusiness, QuestionCategoryEnvironment:
+		return true
+	default:
+		return false
+	}
 }
 
 // QuestionResponsePayload represents the payload for question responses.
@@ -117,6 +143,21 @@ type ApprovalResponsePayload struct {
 	Metadata   map[string]string `json:"metadata,omitempty"` // Response-specific metadata
 }
 
+// StorySplitPayload is the split proposed in a split approval request: the original
+// story is replaced by the sub-stories, in order.
+type StorySplitPayload struct {
+	Reason  string         `json:"reason"`  // Why the story should be split
+	Stories []SubStorySpec `json:"stories"` // Proposed sub-stories in implementation order
+}
+
+// SubStorySpec describes one proposed sub-story. DependsOn holds the 0-based indexes of
+// earlier sub-stories in the same split.
+type SubStorySpec struct {
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	DependsOn []int  `json:"depends_on,omitempty"`
+}
+
 // Merge-specific payload structures
 
 // MergeRequestPayload represents the payload for merge requests.
@@ -60,6 +60,8 @@ const (
 	BudgetReviewFeedbackTemplate StateTemplate = "budget_review_feedback.tpl.md"
 	// MergeFailureFeedbackTemplate is the mini-template for merge failure feedback.
 	MergeFailureFeedbackTemplate StateTemplate = "merge_failure_feedback.tpl.md"
+	// MergeConflictResolutionTemplate is the mini-template for resolving conflicts with the target branch.
+	MergeConflictResolutionTemplate StateTemplate = "merge_conflict_resolution.tpl.md"
 	// GitCommitFailureTemplate is the mini-template for git commit failures.
 	GitCommitFailureTemplate StateTemplate = "git_commit_failure.tpl.md"
 	// GitPushFailureTemplate is the mini-template for git push failures.
@@ -70,10 +72,18 @@ const (
 	GitConfigFailureTemplate StateTemplate = "git_config_failure.tpl.md"
 	// GitHubAuthFailureTemplate is the mini-template for GitHub authentication failures.
 	GitHubAuthFailureTemplate StateTemplate = "github_auth_failure.tpl.md"
+	// SelfReviewTemplate is the template for the coder's review of its own diff before code review.
+	SelfReviewTemplate StateTemplate = "self_review.tpl.md"
+	// TestDesignTemplate is the template for writing failing tests before coding a test-first story.
+	TestDesignTemplate StateTemplate = "test_design.tpl.md"
 	// AppCodeReviewTemplate is the template for app story code review approval.
 	AppCodeReviewTemplate StateTemplate = "app_code_review.tpl.md"
 	// DevOpsCodeReviewTemplate is the template for devops story code review approval.
 	DevOpsCodeReviewTemplate StateTemplate = "devops_code_review.tpl.md"
+	// SplitReviewTemplate is the template for reviewing a coder's proposal to split a story.
+	SplitReviewTemplate StateTemplate = "split_review.tpl.md"
+	// TestDesignReviewTemplate is the template for reviewing a coder's test-first tests.
+	TestDesignReviewTemplate StateTemplate = "test_design_review.tpl.md"
 
 	// BudgetReviewPlanningTemplate is the template for architect budget review in planning state.
 	BudgetReviewPlanningTemplate StateTemplate = "budget_review_planning.tpl.md"
@@ -82,6 +92,10 @@ const (
 
 	// SpecAnalysisTemplate is the template for architect spec analysis state.
 	SpecAnalysisTemplate StateTemplate = "spec_analysis.tpl.md"
+	// SpecClarificationTemplate is the template for the architect's questions about a spec before story generation.
+	SpecClarificationTemplate StateTemplate = "spec_clarification.tpl.md"
+	// SpecRevisionTemplate is the template for the architect's change set for a revised spec.
+	SpecRevisionTemplate StateTemplate = "spec_revision.tpl.md"
 	// StoryGenerationTemplate is the template for architect story generation state.
 	StoryGenerationTemplate StateTemplate = "story_generation.tpl.md"
 	// TechnicalQATemplate is the template for architect technical Q&A state.
@@ -114,20 +128,27 @@ func NewRenderer() (*Renderer, error) {
 		DevOpsTestFailureInstructionsTemplate,
 		BudgetReviewFeedbackTemplate,
 		MergeFailureFeedbackTemplate,
+		MergeConflictResolutionTemplate,
 		GitCommitFailureTemplate,
 		GitPushFailureTemplate,
 		PRCreationFailureTemplate,
 		GitConfigFailureTemplate,
 		GitHubAuthFailureTemplate,
+		SelfReviewTemplate,
+		TestDesignTemplate,
 		// Architect agent templates.
 		BudgetReviewPlanningTemplate,
 		BudgetReviewCodingTemplate,
 		SpecAnalysisTemplate,
+		SpecClarificationTemplate,
+		SpecRevisionTemplate,
 		StoryGenerationTemplate,
 		TechnicalQATemplate,
 		CodeReviewTemplate,
 		AppCodeReviewTemplate,
 		DevOpsCodeReviewTemplate,
+		SplitReviewTemplate,
+		TestDesignReviewTemplate,
 	}
 
 	for _, name := range templateNames {
@@ -28,20 +28,27 @@ func TestNewRenderer(t *testing.T) {
 		DevOpsTestFailureInstructionsTemplate,
 		BudgetReviewFeedbackTemplate,
 		MergeFailureFeedbackTemplate,
+		MergeConflictResolutionTemplate,
 		GitCommitFailureTemplate,
 		GitPushFailureTemplate,
 		PRCreationFailureTemplate,
 		GitConfigFailureTemplate,
 		GitHubAuthFailureTemplate,
+		SelfReviewTemplate,
+		TestDesignTemplate,
 		// Architect agent templates.
 		BudgetReviewPlanningTemplate,
 		BudgetReviewCodingTemplate,
 		SpecAnalysisTemplate,
+		SpecClarificationTemplate,
+		SpecRevisionTemplate,
 		StoryGenerationTemplate,
 		TechnicalQATemplate,
 		CodeReviewTemplate,
 		AppCodeReviewTemplate,
 		DevOpsCodeReviewTemplate,
+		SplitReviewTemplate,
+		TestDesignReviewTemplate,
 	}
 
 	for _, templateName := range expectedTemplates {
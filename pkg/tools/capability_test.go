@@ -32,9 +32,12 @@ func TestToolProviderDevOpsPlanningTools(t *testing.T) {
 	// Verify all expected tools are present
 	expectedTools := map[string]bool{
 		ToolShell:             false,
+		ToolGit:               false,
+		ToolNotes:             false,
 		ToolSubmitPlan:        false,
 		ToolAskQuestion:       false,
 		ToolMarkStoryComplete: false,
+		ToolProposeSplit:      false,
 		ToolContainerTest:     false,
 		ToolContainerList:     false,
 	}
@@ -79,9 +82,12 @@ func TestToolProviderAppPlanningTools(t *testing.T) {
 	// Verify all expected tools are present
 	expectedTools := map[string]bool{
 		ToolShell:             false,
+		ToolGit:               false,
+		ToolNotes:             false,
 		ToolSubmitPlan:        false,
 		ToolAskQuestion:       false,
 		ToolMarkStoryComplete: false,
+		ToolProposeSplit:      false,
 	}
 
 	for _, meta := range toolMetas {
@@ -124,9 +130,15 @@ func TestToolProviderAppCodingTools(t *testing.T) {
 	// Verify all expected tools are present
 	expectedTools := map[string]bool{
 		ToolShell:       false,
+		ToolGit:         false,
 		ToolBuild:       false,
 		ToolTest:        false,
+		ToolRunTests:    false,
+		ToolCoverage:    false,
 		ToolLint:        false,
+		ToolNotes:       false,
+		ToolUpdateTodo:  false,
+		ToolRollback:    false,
 		ToolAskQuestion: false,
 		ToolDone:        false,
 	}
@@ -147,6 +159,48 @@ func TestToolProviderAppCodingTools(t *testing.T) {
 	}
 }
 
+func TestToolProviderAppTestDesignTools(t *testing.T) {
+	// Create AgentContext for App test design
+	agentCtx := AgentContext{
+		Executor:        exec.NewLocalExec(),
+		ReadOnly:        false, // Test design writes test files
+		NetworkDisabled: true,
+		WorkDir:         "/tmp",
+	}
+
+	provider := NewProvider(agentCtx, AppTestDesignTools)
+	toolMetas := provider.List()
+
+	if len(toolMetas) != len(AppTestDesignTools) {
+		t.Errorf("Expected %d App test design tools, got %d", len(AppTestDesignTools), len(toolMetas))
+	}
+
+	// Test design writes and runs tests but cannot finish the story
+	expectedTools := map[string]bool{
+		ToolShell:       false,
+		ToolGit:         false,
+		ToolTest:        false,
+		ToolRunTests:    false,
+		ToolNotes:       false,
+		ToolAskQuestion: false,
+		ToolSubmitTests: false,
+	}
+
+	for _, meta := range toolMetas {
+		if _, exists := expectedTools[meta.Name]; exists {
+			expectedTools[meta.Name] = true
+		} else {
+			t.Errorf("Unexpected tool in App test design: %s", meta.Name)
+		}
+	}
+
+	for toolName, found := range expectedTools {
+		if !found {
+			t.Errorf("Missing expected App test design tool: %s", toolName)
+		}
+	}
+}
+
 func TestToolProviderGenerateDocumentation(t *testing.T) {
 	// Create AgentContext
 	agentCtx := AgentContext{
@@ -7,14 +7,25 @@ const (
 	ToolSubmitPlan        = "submit_plan"
 	ToolAskQuestion       = "ask_question"
 	ToolMarkStoryComplete = "mark_story_complete"
+	ToolProposeSplit      = "propose_split"
+
+	// Review tools.
+	ToolSubmitSelfReview = "submit_self_review"
+	ToolSubmitTests      = "submit_tests"
 
 	// Development tools.
 	ToolShell       = "shell"
 	ToolBuild       = "build"
 	ToolTest        = "test"
+	ToolRunTests    = "run_tests"
+	ToolCoverage    = "coverage"
 	ToolLint        = "lint"
 	ToolDone        = "done"
 	ToolBackendInfo = "backend_info"
+	ToolGit         = "git"
+	ToolNotes       = "notes"
+	ToolUpdateTodo  = "update_todo"
+	ToolRollback    = "rollback"
 
 	// Container tools.
 	ToolContainerBuild  = "container_build"
@@ -30,18 +41,24 @@ var (
 	// App planning tools - exploration and plan submission for application stories.
 	AppPlanningTools = []string{
 		ToolShell,
+		ToolGit,
+		ToolNotes,
 		ToolSubmitPlan,
 		ToolAskQuestion,
 		ToolMarkStoryComplete,
+		ToolProposeSplit,
 	}
 
 	// DevOps planning tools - exploration and plan submission for infrastructure stories.
 	// Includes container tools for verification of existing infrastructure.
 	DevOpsPlanningTools = []string{
 		ToolShell,
+		ToolGit,
+		ToolNotes,
 		ToolSubmitPlan,
 		ToolAskQuestion,
 		ToolMarkStoryComplete,
+		ToolProposeSplit,
 		ToolContainerTest,
 		ToolContainerList,
 	}
@@ -49,6 +66,10 @@ var (
 	// DevOps coding tools - infrastructure focus, container operations.
 	DevOpsCodingTools = []string{
 		ToolShell,
+		ToolGit,
+		ToolNotes,
+		ToolUpdateTodo,
+		ToolRollback,
 		ToolAskQuestion,
 		ToolDone,
 		ToolContainerBuild,
@@ -60,18 +81,37 @@ var (
 	// App coding tools - full development environment.
 	AppCodingTools = []string{
 		ToolShell,
+		ToolGit,
 		ToolBuild,
 		ToolTest,
+		ToolRunTests,
+		ToolCoverage,
 		ToolLint,
+		ToolNotes,
+		ToolUpdateTodo,
+		ToolRollback,
 		ToolAskQuestion,
 		ToolDone,
 	}
 
+	// App test design tools - writing failing tests before coding a test-first story.
+	AppTestDesignTools = []string{
+		ToolShell,
+		ToolGit,
+		ToolTest,
+		ToolRunTests,
+		ToolNotes,
+		ToolAskQuestion,
+		ToolSubmitTests,
+	}
+
 	// Testing tools - validation and verification.
 	TestingTools = []string{
 		ToolShell,
 		ToolBuild,
 		ToolTest,
+		ToolRunTests,
+		ToolCoverage,
 		ToolLint,
 		ToolBackendInfo,
 	}
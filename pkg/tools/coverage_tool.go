@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"orchestrator/pkg/build"
+	"orchestrator/pkg/config"
+	"orchestrator/pkg/exec"
+	"orchestrator/pkg/git"
+)
+
+// CoverageTool measures test coverage of the lines changed on the story branch.
+//
+//nolint:govet // fieldalignment: Logical grouping preferred over memory optimization
+type CoverageTool struct {
+	executor     exec.Executor
+	buildService *build.Service
+	workDir      string
+}
+
+// NewCoverageTool creates a coverage tool. The executor runs git to find changed lines;
+// the build service runs the test suite with coverage enabled.
+func NewCoverageTool(executor exec.Executor, buildService *build.Service, workDir string) *CoverageTool {
+	return &CoverageTool{
+		executor:     executor,
+		buildService: buildService,
+		workDir:      workDir,
+	}
+}
+
+// Definition returns the tool's definition in Claude API format.
+func (c *CoverageTool) Definition() ToolDefinition {
+	return ToolDefinition{
+		Name:        ToolCoverage,
+		Description: "Run the tests with coverage and report coverage of the lines changed versus the target branch",
+		InputSchema: InputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"timeout": {
+					Type:        "number",
+					Description: "Timeout in seconds (default: 300)",
+				},
+			},
+			Required: []string{},
+		},
+	}
+}
+
+// Name returns the tool identifier.
+func (c *CoverageTool) Name() string {
+	return ToolCoverage
+}
+
+// PromptDocumentation returns markdown documentation for LLM prompts.
+func (c *CoverageTool) PromptDocumentation() string {
+	return `- **coverage** - Measure test coverage of your changes
+  - Parameters: timeout (default 300s)
+  - Runs the test suite with coverage (Go, Python, JavaScript) and compares with the target branch
+  - Returns: changed-line coverage, per-file coverage and the uncovered changed lines
+  - The architect reviews this; add tests for uncovered lines before calling done`
+}
+
+// Exec measures coverage of changed lines.
+func (c *CoverageTool) Exec(ctx context.Context, args map[string]any) (any, error) {
+	if c.executor == nil || c.buildService == nil {
+		return nil, fmt.Errorf("coverage tool requires an executor and build service")
+	}
+
+	timeout := 300
+	if timeoutVal, ok := args["timeout"].(float64); ok && timeoutVal > 0 {
+		timeout = int(timeoutVal)
+	}
+
+	report, output, err := c.buildService.Coverage(ctx, c.workDir, time.Duration(timeout)*time.Second)
+	if err != nil {
+		return map[string]any{
+			"success": false,
+			"output":  truncateGitOutput(output),
+			"error":   err.Error(),
+		}, nil
+	}
+
+	baseRef := NewGitTool(c.executor, c.workDir, true).baseRef(ctx)
+	changed, err := git.ChangedLines(ctx, baseRef, c.workDir, c.executor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine changed lines: %w", err)
+	}
+	coverage := report.ChangedCoverage(changed)
+
+	result := map[string]any{
+		"success":          true,
+		"base":             baseRef,
+		"changed_percent":  coverage.Percent(),
+		"overall_percent":  coverage.Overall,
+		"coverage":         coverage,
+		"meets_threshold":  true,
+		"minimum_required": 0.0,
+	}
+
+	var summary strings.Builder
+	summary.WriteString(coverage.Summary())
+	if minimum := MinChangedCoverage(); minimum > 0 {
+		result["minimum_required"] = minimum
+		if coverage.Percent() < minimum {
+			result["meets_threshold"] = false
+			summary.WriteString(fmt.Sprintf("Below the project minimum of %.1f%% for changed lines - add tests for the uncovered lines.\n", minimum))
+		}
+	}
+	result["output"] = summary.String()
+	return result, nil
+}
+
+// MinChangedCoverage returns the configured minimum coverage for changed lines, or 0 if unset.
+func MinChangedCoverage() float64 {
+	cfg, err := config.GetConfig()
+	if err != nil || cfg.Build == nil {
+		return 0
+	}
+	return cfg.Build.MinChangedCoverage
+}
@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"context"
+	osexec "os/exec"
+	"strings"
+	"testing"
+
+	"orchestrator/pkg/build"
+	"orchestrator/pkg/exec"
+)
+
+func TestCoverageTool_ChangedLines(t *testing.T) {
+	if _, err := osexec.LookPath("go"); err != nil {
+		t.Skip("go not available")
+	}
+
+	dir := setupGitRepo(t)
+	writeRepoFile(t, dir, "go.mod", "module example.com/calc\n\ngo 1.21\n")
+	writeRepoFile(t, dir, "main.go", "package calc\n")
+	writeRepoFile(t, dir, "calc.go", "package calc\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n")
+	writeRepoFile(t, dir, "calc_test.go", "package calc\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {\n\tif Add(1, 2) != 3 {\n\t\tt.Fatal(\"bad\")\n\t}\n}\n")
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "calc")
+	runGit(t, dir, "checkout", "-q", "-b", "story")
+
+	// Story adds an untested function to a tracked file and an untested new file
+	writeRepoFile(t, dir, "calc.go", "package calc\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n\nfunc Sub(a, b int) int {\n\treturn a - b\n}\n")
+	writeRepoFile(t, dir, "mul.go", "package calc\n\nfunc Mul(a, b int) int {\n\treturn a * b\n}\n")
+
+	tool := NewCoverageTool(exec.NewLocalExec(), build.NewBuildService(), dir)
+	result, err := tool.Exec(context.Background(), map[string]any{})
+	if err != nil {
+		t.Fatalf("coverage failed: %v", err)
+	}
+	resultMap := result.(map[string]any)
+	if resultMap["success"] != true {
+		t.Fatalf("Expected coverage to succeed, got %+v", resultMap)
+	}
+
+	coverage := resultMap["coverage"].(*build.ChangedCoverage)
+	if coverage.CoveredLines != 0 || coverage.ChangedLines == 0 {
+		t.Errorf("Expected only uncovered changed lines, got %+v", coverage)
+	}
+	output := resultMap["output"].(string)
+	if !strings.Contains(output, "calc.go") || !strings.Contains(output, "mul.go") {
+		t.Errorf("Expected tracked and untracked files in summary, got %s", output)
+	}
+}
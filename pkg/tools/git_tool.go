@@ -0,0 +1,402 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"orchestrator/pkg/config"
+	"orchestrator/pkg/exec"
+	"orchestrator/pkg/policy"
+)
+
+// Git tool operations. Branch manipulation, commits and pushes are intentionally absent:
+// they are reserved to the coder FSM (commitChanges / pushBranch in PREPARE_MERGE).
+const (
+	GitOpStatus   = "status"
+	GitOpDiff     = "diff"
+	GitOpLog      = "log"
+	GitOpShowBase = "show_base"
+	GitOpRestore  = "restore"
+
+	// gitMaxOutput bounds diff and file output returned to the model.
+	gitMaxOutput = 50000
+
+	// gitDefaultLogLimit is the number of commits returned by log when no limit is given.
+	gitDefaultLogLimit = 10
+)
+
+// GitFileStatus is one entry of a structured git status.
+type GitFileStatus struct {
+	Path     string `json:"path"`
+	OrigPath string `json:"orig_path,omitempty"`
+	State    string `json:"state"`    // modified, added, deleted, renamed, copied, untracked, conflicted
+	Staged   bool   `json:"staged"`   // Change is in the index
+	Unstaged bool   `json:"unstaged"` // Change is in the working tree only
+}
+
+// GitCommit is one entry of a structured git log.
+type GitCommit struct {
+	Hash    string `json:"hash"`
+	Author  string `json:"author"`
+	Date    string `json:"date"`
+	Subject string `json:"subject"`
+}
+
+// GitTool provides structured, read-mostly git operations on the agent's workspace.
+//
+//nolint:govet // fieldalignment: Logical grouping preferred over memory optimization
+type GitTool struct {
+	executor exec.Executor
+	workDir  string
+	readOnly bool
+	policy   *policy.Enforcer
+}
+
+// NewGitTool creates a git tool operating in workDir. Read-only tools reject restore.
+func NewGitTool(executor exec.Executor, workDir string, readOnly bool) *GitTool {
+	return &GitTool{
+		executor: executor,
+		workDir:  workDir,
+		readOnly: readOnly,
+	}
+}
+
+// SetPolicy attaches a command policy that is checked against the equivalent git command.
+func (g *GitTool) SetPolicy(enforcer *policy.Enforcer) {
+	g.policy = enforcer
+}
+
+// Definition returns the tool's definition in Claude API format.
+func (g *GitTool) Definition() ToolDefinition {
+	return ToolDefinition{
+		Name:        ToolGit,
+		Description: "Structured git operations: status, diff, log, show a file at the target branch, restore a file",
+		InputSchema: InputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"operation": {
+					Type:        "string",
+					Description: "Git operation to perform",
+					Enum:        []string{GitOpStatus, GitOpDiff, GitOpLog, GitOpShowBase, GitOpRestore},
+				},
+				"path": {
+					Type:        "string",
+					Description: "Repository-relative file path (required for show_base and restore, optional filter for diff and log)",
+				},
+				"stat": {
+					Type:        "boolean",
+					Description: "diff: return a per-file change summary instead of the full patch",
+				},
+				"staged": {
+					Type:        "boolean",
+					Description: "diff: show staged changes only",
+				},
+				"against_base": {
+					Type:        "boolean",
+					Description: "diff: compare the working tree with the target branch instead of HEAD",
+				},
+				"limit": {
+					Type:        "integer",
+					Description: "log: maximum number of commits (default 10)",
+				},
+				"source": {
+					Type:        "string",
+					Description: "restore: where to restore the file from (head or base, default head)",
+					Enum:        []string{"head", "base"},
+				},
+			},
+			Required: []string{"operation"},
+		},
+	}
+}
+
+// Name returns the tool identifier.
+func (g *GitTool) Name() string {
+	return ToolGit
+}
+
+// PromptDocumentation returns markdown documentation for LLM prompts.
+func (g *GitTool) PromptDocumentation() string {
+	return `- **git** - Structured git operations on your workspace
+  - status: changed files with state (modified, added, deleted, renamed, untracked, conflicted)
+  - diff: patch or stat (stat=true) for all files or one path; staged=true or against_base=true to change the comparison
+  - log: recent commits (limit, optional path)
+  - show_base: contents of a file on the target branch (path required)
+  - restore: discard local changes to a file, from head (default) or base (path required)
+  - Commits, pushes and branch changes are handled automatically - do not attempt them`
+}
+
+// Exec executes the requested git operation.
+func (g *GitTool) Exec(ctx context.Context, args map[string]any) (any, error) {
+	operation, _ := args["operation"].(string)
+	filePath, _ := args["path"].(string)
+	if filePath != "" {
+		if err := validateGitPath(filePath); err != nil {
+			return nil, err
+		}
+	}
+
+	switch operation {
+	case GitOpStatus:
+		return g.status(ctx)
+	case GitOpDiff:
+		stat, _ := args["stat"].(bool)
+		staged, _ := args["staged"].(bool)
+		againstBase, _ := args["against_base"].(bool)
+		return g.diff(ctx, filePath, stat, staged, againstBase)
+	case GitOpLog:
+		limit := gitDefaultLogLimit
+		if limitVal, ok := args["limit"].(float64); ok && limitVal > 0 {
+			limit = int(limitVal)
+		}
+		return g.log(ctx, filePath, limit)
+	case GitOpShowBase:
+		if filePath == "" {
+			return nil, fmt.Errorf("path is required for show_base")
+		}
+		return g.showBase(ctx, filePath)
+	case GitOpRestore:
+		if filePath == "" {
+			return nil, fmt.Errorf("path is required for restore")
+		}
+		if g.readOnly {
+			return nil, fmt.Errorf("restore is not available in read-only mode")
+		}
+		source, _ := args["source"].(string)
+		return g.restore(ctx, filePath, source)
+	default:
+		return nil, fmt.Errorf("unknown git operation %q (expected status, diff, log, show_base or restore)", operation)
+	}
+}
+
+// status returns changed files parsed from NUL-separated porcelain output, which leaves
+// paths with spaces or non-ASCII characters unquoted.
+func (g *GitTool) status(ctx context.Context) (any, error) {
+	stdout, err := g.run(ctx, "status", "--porcelain=v1", "--branch", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	branch, files := parseGitStatus(stdout)
+	return gitResult(map[string]any{
+		"branch": branch,
+		"clean":  len(files) == 0,
+		"files":  files,
+	}), nil
+}
+
+// diff returns a patch or stat summary.
+func (g *GitTool) diff(ctx context.Context, filePath string, stat, staged, againstBase bool) (any, error) {
+	args := []string{"diff"}
+	if staged {
+		args = append(args, "--cached")
+	}
+	if stat {
+		args = append(args, "--stat")
+	}
+	if againstBase {
+		args = append(args, g.baseRef(ctx))
+	}
+	if filePath != "" {
+		args = append(args, "--", filePath)
+	}
+
+	stdout, err := g.run(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(stdout) == "" {
+		stdout = "No differences"
+	}
+	return gitResult(map[string]any{"diff": truncateGitOutput(stdout)}), nil
+}
+
+// log returns recent commits.
+func (g *GitTool) log(ctx context.Context, filePath string, limit int) (any, error) {
+	args := []string{"log", "-n", strconv.Itoa(limit), "--format=%H%x1f%an%x1f%aI%x1f%s"}
+	if filePath != "" {
+		args = append(args, "--", filePath)
+	}
+
+	stdout, err := g.run(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := make([]GitCommit, 0, limit)
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		fields := strings.SplitN(line, "\x1f", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		commits = append(commits, GitCommit{Hash: fields[0], Author: fields[1], Date: fields[2], Subject: fields[3]})
+	}
+	return gitResult(map[string]any{"commits": commits}), nil
+}
+
+// showBase returns a file's contents at the target branch.
+func (g *GitTool) showBase(ctx context.Context, filePath string) (any, error) {
+	ref := g.baseRef(ctx)
+	stdout, err := g.run(ctx, "show", ref+":"+filePath)
+	if err != nil {
+		return nil, err
+	}
+	return gitResult(map[string]any{
+		"ref":     ref,
+		"path":    filePath,
+		"content": truncateGitOutput(stdout),
+	}), nil
+}
+
+// restore discards working-tree changes to a file.
+func (g *GitTool) restore(ctx context.Context, filePath, source string) (any, error) {
+	ref := "HEAD"
+	if source == "base" {
+		ref = g.baseRef(ctx)
+	}
+	if _, err := g.run(ctx, "restore", "--source="+ref, "--staged", "--worktree", "--", filePath); err != nil {
+		return nil, err
+	}
+	return gitResult(map[string]any{
+		"message": fmt.Sprintf("Restored %s from %s", filePath, ref),
+	}), nil
+}
+
+// baseRef returns the ref for the target branch, preferring the remote-tracking branch.
+func (g *GitTool) baseRef(ctx context.Context) string {
+	branch := config.DefaultTargetBranch
+	if cfg, err := config.GetConfig(); err == nil && cfg.Git != nil && cfg.Git.TargetBranch != "" {
+		branch = cfg.Git.TargetBranch
+	}
+
+	remote := "origin/" + branch
+	if _, err := g.run(ctx, "rev-parse", "--verify", "--quiet", remote); err == nil {
+		return remote
+	}
+	return branch
+}
+
+// run executes git with the given arguments and returns stdout, failing on non-zero exit.
+func (g *GitTool) run(ctx context.Context, args ...string) (string, error) {
+	if g.executor == nil {
+		return "", fmt.Errorf("git tool requires an executor")
+	}
+	if err := g.policy.Check(ToolGit, "git "+strings.Join(args, " "), ""); err != nil {
+		return "", err
+	}
+
+	opts := &exec.Opts{
+		WorkDir: g.workDir,
+		Timeout: 30 * time.Second,
+	}
+	result, err := g.executor.Run(ctx, append([]string{"git"}, args...), opts)
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("git %s failed (exit %d): %s", args[0], result.ExitCode, strings.TrimSpace(result.Stderr))
+	}
+	return result.Stdout, nil
+}
+
+// parseGitStatus parses `git status --porcelain=v1 --branch -z` output. Entries are
+// NUL-terminated; a rename or copy is followed by a separate entry holding its original path.
+func parseGitStatus(output string) (string, []GitFileStatus) {
+	branch := ""
+	files := make([]GitFileStatus, 0)
+
+	entries := strings.Split(output, "\x00")
+	for i := 0; i < len(entries); i++ {
+		line := entries[i]
+		if strings.HasPrefix(line, "## ") {
+			branch = strings.TrimPrefix(line, "## ")
+			continue
+		}
+		if len(line) < 4 {
+			continue
+		}
+
+		index, worktree := line[0], line[1]
+		entry := GitFileStatus{Path: line[3:]}
+		if (index == 'R' || index == 'C' || worktree == 'R' || worktree == 'C') && i+1 < len(entries) {
+			i++
+			entry.OrigPath = entries[i]
+		}
+
+		switch {
+		case index == '?' && worktree == '?':
+			entry.State = "untracked"
+			entry.Unstaged = true
+		case index == 'U' || worktree == 'U' || (index == 'A' && worktree == 'A') || (index == 'D' && worktree == 'D'):
+			entry.State = "conflicted"
+		default:
+			entry.Staged = index != ' '
+			entry.Unstaged = worktree != ' '
+			code := index
+			if code == ' ' {
+				code = worktree
+			}
+			entry.State = gitStateName(code)
+		}
+		files = append(files, entry)
+	}
+	return branch, files
+}
+
+// gitStateName maps a porcelain status code to a readable state.
+func gitStateName(code byte) string {
+	switch code {
+	case 'M':
+		return "modified"
+	case 'A':
+		return "added"
+	case 'D':
+		return "deleted"
+	case 'R':
+		return "renamed"
+	case 'C':
+		return "copied"
+	case 'T':
+		return "type_changed"
+	default:
+		return "unknown"
+	}
+}
+
+// validateGitPath rejects paths that could be read as options or escape the repository.
+func validateGitPath(p string) error {
+	if strings.HasPrefix(p, "-") {
+		return fmt.Errorf("invalid path %q", p)
+	}
+	cleaned := path.Clean(p)
+	if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return fmt.Errorf("path must be relative to the repository root: %q", p)
+	}
+	return nil
+}
+
+// truncateGitOutput limits output size returned to the model.
+func truncateGitOutput(output string) string {
+	if len(output) > gitMaxOutput {
+		return output[:gitMaxOutput] + fmt.Sprintf("\n... (truncated, showing first %d chars)", gitMaxOutput)
+	}
+	return output
+}
+
+// gitResult wraps structured data in the common tool result shape. The JSON rendering in
+// "output" is what the model sees in its context.
+func gitResult(data map[string]any) map[string]any {
+	result := map[string]any{"success": true}
+	for key, value := range data {
+		result[key] = value
+	}
+	if encoded, err := json.MarshalIndent(data, "", "  "); err == nil {
+		result["output"] = string(encoded)
+	}
+	return result
+}
@@ -0,0 +1,206 @@
+package tools
+
+import (
+	"context"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"orchestrator/pkg/exec"
+)
+
+// setupGitRepo creates a repository with one commit on main and returns its path.
+func setupGitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := osexec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+	} {
+		runGit(t, dir, args...)
+	}
+	writeRepoFile(t, dir, "main.go", "package main\n")
+	writeRepoFile(t, dir, "README.md", "hello\n")
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "initial commit")
+	return dir
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := osexec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v: %s", args, err, out)
+	}
+}
+
+func writeRepoFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+}
+
+func TestGitTool_Status(t *testing.T) {
+	dir := setupGitRepo(t)
+	writeRepoFile(t, dir, "main.go", "package main\n\nfunc main() {}\n")
+	writeRepoFile(t, dir, "new.go", "package main\n")
+	if err := os.Remove(filepath.Join(dir, "README.md")); err != nil {
+		t.Fatalf("Failed to remove README.md: %v", err)
+	}
+
+	tool := NewGitTool(exec.NewLocalExec(), dir, true)
+	result, err := tool.Exec(context.Background(), map[string]any{"operation": GitOpStatus})
+	if err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+
+	resultMap := result.(map[string]any)
+	files := resultMap["files"].([]GitFileStatus)
+	states := make(map[string]string)
+	for _, f := range files {
+		states[f.Path] = f.State
+	}
+	expected := map[string]string{"main.go": "modified", "new.go": "untracked", "README.md": "deleted"}
+	for path, state := range expected {
+		if states[path] != state {
+			t.Errorf("Expected %s to be %s, got %q (all: %+v)", path, state, states[path], files)
+		}
+	}
+	if !strings.HasPrefix(resultMap["branch"].(string), "main") {
+		t.Errorf("Expected branch main, got %v", resultMap["branch"])
+	}
+	if !strings.Contains(resultMap["output"].(string), `"untracked"`) {
+		t.Error("Expected JSON output for the model context")
+	}
+}
+
+func TestGitTool_StatusQuotedPaths(t *testing.T) {
+	dir := setupGitRepo(t)
+	writeRepoFile(t, dir, "my notes.md", "notes\n")
+	writeRepoFile(t, dir, "héllo.go", "package main\n")
+	runGit(t, dir, "mv", "main.go", "main program.go")
+
+	tool := NewGitTool(exec.NewLocalExec(), dir, true)
+	result, err := tool.Exec(context.Background(), map[string]any{"operation": GitOpStatus})
+	if err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+
+	files := result.(map[string]any)["files"].([]GitFileStatus)
+	byPath := make(map[string]GitFileStatus)
+	for _, f := range files {
+		byPath[f.Path] = f
+	}
+	if byPath["my notes.md"].State != "untracked" || byPath["héllo.go"].State != "untracked" {
+		t.Errorf("Expected unquoted untracked paths, got %+v", files)
+	}
+	if renamed := byPath["main program.go"]; renamed.State != "renamed" || renamed.OrigPath != "main.go" {
+		t.Errorf("Expected main.go renamed to 'main program.go', got %+v", files)
+	}
+}
+
+func TestGitTool_DiffLogAndShowBase(t *testing.T) {
+	dir := setupGitRepo(t)
+	writeRepoFile(t, dir, "main.go", "package main\n\nfunc main() {}\n")
+	tool := NewGitTool(exec.NewLocalExec(), dir, true)
+	ctx := context.Background()
+
+	result, err := tool.Exec(ctx, map[string]any{"operation": GitOpDiff, "stat": true})
+	if err != nil {
+		t.Fatalf("diff failed: %v", err)
+	}
+	if diff := result.(map[string]any)["diff"].(string); !strings.Contains(diff, "main.go") {
+		t.Errorf("Expected stat to mention main.go, got %q", diff)
+	}
+
+	result, err = tool.Exec(ctx, map[string]any{"operation": GitOpLog, "limit": float64(5)})
+	if err != nil {
+		t.Fatalf("log failed: %v", err)
+	}
+	commits := result.(map[string]any)["commits"].([]GitCommit)
+	if len(commits) != 1 || commits[0].Subject != "initial commit" {
+		t.Errorf("Expected one commit, got %+v", commits)
+	}
+
+	result, err = tool.Exec(ctx, map[string]any{"operation": GitOpShowBase, "path": "main.go"})
+	if err != nil {
+		t.Fatalf("show_base failed: %v", err)
+	}
+	if content := result.(map[string]any)["content"].(string); content != "package main\n" {
+		t.Errorf("Expected base content, got %q", content)
+	}
+}
+
+func TestGitTool_Restore(t *testing.T) {
+	dir := setupGitRepo(t)
+	writeRepoFile(t, dir, "main.go", "broken\n")
+	ctx := context.Background()
+	args := map[string]any{"operation": GitOpRestore, "path": "main.go"}
+
+	if _, err := NewGitTool(exec.NewLocalExec(), dir, true).Exec(ctx, args); err == nil {
+		t.Error("Expected restore to be rejected in read-only mode")
+	}
+
+	if _, err := NewGitTool(exec.NewLocalExec(), dir, false).Exec(ctx, args); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	if err != nil {
+		t.Fatalf("Failed to read main.go: %v", err)
+	}
+	if string(data) != "package main\n" {
+		t.Errorf("Expected restored content, got %q", data)
+	}
+}
+
+func TestGitTool_RejectsUnsafeInput(t *testing.T) {
+	tool := NewGitTool(exec.NewLocalExec(), t.TempDir(), false)
+	ctx := context.Background()
+
+	invalid := []map[string]any{
+		{"operation": "push"},
+		{"operation": "commit"},
+		{"operation": GitOpShowBase},
+		{"operation": GitOpDiff, "path": "--output=/tmp/x"},
+		{"operation": GitOpRestore, "path": "../outside.go"},
+		{"operation": GitOpRestore, "path": "/etc/passwd"},
+	}
+	for _, args := range invalid {
+		if _, err := tool.Exec(ctx, args); err == nil {
+			t.Errorf("Expected error for %v", args)
+		}
+	}
+}
+
+func TestParseGitStatus(t *testing.T) {
+	output := "## feature...origin/feature [ahead 1]\x00M  staged.go\x00 M unstaged.go\x00R  new.go\x00old.go\x00UU conflict.go\x00"
+	branch, files := parseGitStatus(output)
+
+	if branch != "feature...origin/feature [ahead 1]" {
+		t.Errorf("Unexpected branch %q", branch)
+	}
+	if len(files) != 4 {
+		t.Fatalf("Expected 4 files, got %+v", files)
+	}
+	if !files[0].Staged || files[0].Unstaged || files[0].State != "modified" {
+		t.Errorf("Unexpected staged entry %+v", files[0])
+	}
+	if files[1].Staged || !files[1].Unstaged {
+		t.Errorf("Unexpected unstaged entry %+v", files[1])
+	}
+	if files[2].State != "renamed" || files[2].OrigPath != "old.go" || files[2].Path != "new.go" {
+		t.Errorf("Unexpected rename entry %+v", files[2])
+	}
+	if files[3].State != "conflicted" {
+		t.Errorf("Unexpected conflict entry %+v", files[3])
+	}
+}
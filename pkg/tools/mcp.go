@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"orchestrator/pkg/exec"
+	"orchestrator/pkg/policy"
 )
 
 // ToolDefinition represents an Anthropic Claude tool definition.
@@ -77,6 +78,7 @@ type ShellTool struct {
 	readOnly        bool
 	networkDisabled bool
 	resourceLimits  *exec.ResourceLimits
+	policy          *policy.Enforcer
 }
 
 // NewShellTool creates a new shell tool with the specified executor.
@@ -99,6 +101,11 @@ func NewShellToolWithConfig(executor exec.Executor, readOnly, networkDisabled bo
 	}
 }
 
+// SetPolicy attaches a command policy. Commands that violate it are rejected before execution.
+func (s *ShellTool) SetPolicy(enforcer *policy.Enforcer) {
+	s.policy = enforcer
+}
+
 // Definition returns the tool's definition in Claude API format.
 func (s *ShellTool) Definition() ToolDefinition {
 	return ToolDefinition{
@@ -160,6 +167,11 @@ func (s *ShellTool) Exec(ctx context.Context, args map[string]any) (any, error)
 		}
 	}
 
+	// Reject commands forbidden by the project policy before they reach the executor.
+	if err := s.policy.Check(ToolShell, cmdStr, cwd); err != nil {
+		return nil, err
+	}
+
 	// Execute shell command.
 	return s.executeShellCommand(ctx, cmdStr, cwd)
 }
@@ -0,0 +1,495 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"orchestrator/pkg/config"
+	"orchestrator/pkg/logx"
+)
+
+const (
+	// MCPProtocolVersion is the MCP revision Maestro speaks when acting as a client.
+	MCPProtocolVersion = "2024-11-05"
+
+	// MCPToolSeparator joins server name and remote tool name into a namespaced tool name.
+	MCPToolSeparator = "__"
+
+	// MaxToolNameLength is the longest tool name LLM providers and MCP clients accept.
+	MaxToolNameLength = 64
+
+	// mcpRequestTimeout bounds handshake and discovery requests.
+	mcpRequestTimeout = 30 * time.Second
+
+	// mcpCallTimeout bounds a single tools/call round trip.
+	mcpCallTimeout = 5 * time.Minute
+)
+
+// rpcMessage is a JSON-RPC 2.0 message as exchanged over the MCP stdio transport.
+//
+//nolint:govet // fieldalignment: JSON serialization order requirements
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  any             `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is a JSON-RPC 2.0 error object.
+type rpcError struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// mcpRemoteTool is a tool entry from a tools/list response.
+type mcpRemoteTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+}
+
+// mcpCallResult is the result of a tools/call request.
+type mcpCallResult struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	IsError bool `json:"isError"`
+}
+
+// MCPClient speaks the Model Context Protocol to a single stdio-launched server.
+// Requests may be issued concurrently; responses are matched to callers by ID.
+//
+//nolint:govet // fieldalignment: Logical grouping preferred over memory optimization
+type MCPClient struct {
+	name    string
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	logger  *logx.Logger
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan *rpcMessage
+	closed  bool
+	done    chan struct{}
+}
+
+// StartMCPClient launches the configured server process and performs the MCP handshake.
+func StartMCPClient(ctx context.Context, server *config.MCPServerConfig) (*MCPClient, error) {
+	//nolint:gosec // Command comes from the project's own configuration
+	cmd := exec.Command(server.Command, server.Args...)
+	cmd.Env = os.Environ()
+	for key, value := range server.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stdin for MCP server %s: %w", server.Name, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stdout for MCP server %s: %w", server.Name, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start MCP server %s: %w", server.Name, err)
+	}
+
+	client := &MCPClient{
+		name:    server.Name,
+		cmd:     cmd,
+		stdin:   stdin,
+		logger:  logx.NewLogger("mcp-" + server.Name),
+		pending: make(map[int64]chan *rpcMessage),
+		done:    make(chan struct{}),
+	}
+	go client.readLoop(stdout)
+
+	if err := client.initialize(ctx); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// Name returns the configured server name.
+func (c *MCPClient) Name() string {
+	return c.name
+}
+
+// initialize performs the initialize / notifications/initialized handshake.
+func (c *MCPClient) initialize(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, mcpRequestTimeout)
+	defer cancel()
+
+	params := map[string]any{
+		"protocolVersion": MCPProtocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo": map[string]any{
+			"name":    "maestro",
+			"version": config.SchemaVersion,
+		},
+	}
+	if _, err := c.request(ctx, "initialize", params); err != nil {
+		return fmt.Errorf("MCP initialize with %s failed: %w", c.name, err)
+	}
+	if err := c.notify("notifications/initialized", nil); err != nil {
+		return fmt.Errorf("MCP initialized notification to %s failed: %w", c.name, err)
+	}
+	return nil
+}
+
+// ListTools returns the tools advertised by the server.
+func (c *MCPClient) ListTools(ctx context.Context) ([]mcpRemoteTool, error) {
+	ctx, cancel := context.WithTimeout(ctx, mcpRequestTimeout)
+	defer cancel()
+
+	raw, err := c.request(ctx, "tools/list", map[string]any{})
+	if err != nil {
+		return nil, fmt.Errorf("MCP tools/list on %s failed: %w", c.name, err)
+	}
+	var result struct {
+		Tools []mcpRemoteTool `json:"tools"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse tools/list result from %s: %w", c.name, err)
+	}
+	return result.Tools, nil
+}
+
+// CallTool invokes a remote tool and returns its concatenated text content.
+func (c *MCPClient) CallTool(ctx context.Context, name string, args map[string]any) (string, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, mcpCallTimeout)
+	defer cancel()
+
+	if args == nil {
+		args = map[string]any{}
+	}
+	raw, err := c.request(ctx, "tools/call", map[string]any{"name": name, "arguments": args})
+	if err != nil {
+		return "", false, fmt.Errorf("MCP tools/call %s on %s failed: %w", name, c.name, err)
+	}
+	var result mcpCallResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return "", false, fmt.Errorf("failed to parse tools/call result from %s: %w", c.name, err)
+	}
+
+	var text strings.Builder
+	for _, block := range result.Content {
+		if block.Type != "text" {
+			text.WriteString(fmt.Sprintf("[%s content omitted]", block.Type))
+			continue
+		}
+		if text.Len() > 0 {
+			text.WriteString("\n")
+		}
+		text.WriteString(block.Text)
+	}
+	return text.String(), result.IsError, nil
+}
+
+// Close terminates the server process and fails any in-flight requests.
+func (c *MCPClient) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.mu.Unlock()
+
+	_ = c.stdin.Close()
+	select {
+	case <-c.done:
+	case <-time.After(2 * time.Second):
+		_ = c.cmd.Process.Kill()
+	}
+	_ = c.cmd.Wait()
+	return nil
+}
+
+// request sends a JSON-RPC request and waits for the matching response.
+func (c *MCPClient) request(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, fmt.Errorf("MCP client %s is closed", c.name)
+	}
+	c.nextID++
+	id := c.nextID
+	replyCh := make(chan *rpcMessage, 1)
+	c.pending[id] = replyCh
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.write(&rpcMessage{JSONRPC: "2.0", ID: &id, Method: method, Params: params}); err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for %s response: %w", method, ctx.Err())
+	case <-c.done:
+		return nil, fmt.Errorf("MCP server %s exited", c.name)
+	case reply := <-replyCh:
+		if reply.Error != nil {
+			return nil, reply.Error
+		}
+		return reply.Result, nil
+	}
+}
+
+// notify sends a JSON-RPC notification (no response expected).
+func (c *MCPClient) notify(method string, params any) error {
+	return c.write(&rpcMessage{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// write serializes a message as a single newline-delimited JSON line.
+func (c *MCPClient) write(msg *rpcMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal MCP message: %w", err)
+	}
+	data = append(data, '\n')
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err := c.stdin.Write(data); err != nil {
+		return fmt.Errorf("failed to write to MCP server %s: %w", c.name, err)
+	}
+	return nil
+}
+
+// readLoop routes responses from the server to waiting requests until stdout closes.
+func (c *MCPClient) readLoop(stdout io.Reader) {
+	defer close(c.done)
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var msg rpcMessage
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			c.logger.Warn("Ignoring malformed MCP message: %v", err)
+			continue
+		}
+		if msg.ID == nil || msg.Method != "" {
+			// Server notifications and server-initiated requests are not supported.
+			c.logger.Debug("Ignoring MCP %s from server", msg.Method)
+			continue
+		}
+
+		// Each request takes exactly one response: the entry is removed on delivery, so a
+		// duplicate or late response for the same ID is dropped instead of blocking the loop.
+		c.mu.Lock()
+		replyCh, ok := c.pending[*msg.ID]
+		delete(c.pending, *msg.ID)
+		c.mu.Unlock()
+		if !ok {
+			c.logger.Debug("Ignoring MCP response to unknown or answered request %d", *msg.ID)
+			continue
+		}
+		select {
+		case replyCh <- &msg:
+		default:
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		c.logger.Warn("MCP server stdout closed: %v", err)
+	}
+}
+
+// MCPTool proxies a single remote MCP tool through the Tool interface.
+type MCPTool struct {
+	client     *MCPClient
+	remoteName string
+	definition ToolDefinition
+}
+
+// newMCPTool converts a discovered remote tool into a namespaced proxy.
+func newMCPTool(client *MCPClient, remote *mcpRemoteTool) *MCPTool {
+	schema := InputSchema{Type: "object", Properties: map[string]Property{}}
+	if len(remote.InputSchema) > 0 {
+		if err := json.Unmarshal(remote.InputSchema, &schema); err != nil {
+			client.logger.Warn("Tool %s has an unsupported input schema, exposing without parameters: %v", remote.Name, err)
+			schema = InputSchema{Type: "object", Properties: map[string]Property{}}
+		}
+	}
+	if schema.Type == "" {
+		schema.Type = "object"
+	}
+	if schema.Properties == nil {
+		schema.Properties = map[string]Property{}
+	}
+
+	return &MCPTool{
+		client:     client,
+		remoteName: remote.Name,
+		definition: ToolDefinition{
+			Name:        client.Name() + MCPToolSeparator + sanitizeToolName(remote.Name),
+			Description: fmt.Sprintf("[%s] %s", client.Name(), remote.Description),
+			InputSchema: schema,
+		},
+	}
+}
+
+// sanitizeToolName replaces characters outside the tool-name charset with '_', so remote
+// names such as "search.docs" can still be offered to the LLM. Calls use the original name.
+func sanitizeToolName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if isToolNameRune(r) {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// isToolNameRune reports whether r may appear in a tool name.
+func isToolNameRune(r rune) bool {
+	return r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+// ValidateToolName checks a tool name against the charset and length LLM providers and MCP
+// clients accept: 1 to 64 letters, digits, '-' or '_'.
+func ValidateToolName(name string) error {
+	if name == "" || len(name) > MaxToolNameLength {
+		return fmt.Errorf("tool name '%s' must be 1 to %d characters long", name, MaxToolNameLength)
+	}
+	for _, r := range name {
+		if !isToolNameRune(r) {
+			return fmt.Errorf("tool name '%s' may only contain letters, digits, '-' and '_'", name)
+		}
+	}
+	return nil
+}
+
+// Definition returns the tool's definition in Claude API format.
+func (t *MCPTool) Definition() ToolDefinition {
+	return t.definition
+}
+
+// Name returns the namespaced tool identifier.
+func (t *MCPTool) Name() string {
+	return t.definition.Name
+}
+
+// PromptDocumentation returns markdown documentation for LLM prompts.
+func (t *MCPTool) PromptDocumentation() string {
+	return fmt.Sprintf("- **%s** - %s\n  - Provided by external MCP server '%s'", t.definition.Name, t.definition.Description, t.client.Name())
+}
+
+// Exec forwards the call to the MCP server.
+func (t *MCPTool) Exec(ctx context.Context, args map[string]any) (any, error) {
+	output, isError, err := t.client.CallTool(ctx, t.remoteName, args)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"success": !isError,
+		"output":  output,
+	}, nil
+}
+
+// MCPManager owns the MCP server connections configured for a project.
+type MCPManager struct {
+	logger  *logx.Logger
+	clients []*MCPClient
+	tools   []string
+}
+
+// StartMCPServers launches every configured server, discovers its tools and registers
+// them as external tools. A server that fails to start is logged and skipped so that a
+// broken integration does not prevent Maestro from running.
+func StartMCPServers(ctx context.Context, servers []config.MCPServerConfig) *MCPManager {
+	manager := &MCPManager{logger: logx.NewLogger("mcp")}
+
+	for i := range servers {
+		server := &servers[i]
+		client, err := StartMCPClient(ctx, server)
+		if err != nil {
+			manager.logger.Error("Skipping MCP server %s: %v", server.Name, err)
+			continue
+		}
+
+		remoteTools, err := client.ListTools(ctx)
+		if err != nil {
+			manager.logger.Error("Skipping MCP server %s: %v", server.Name, err)
+			_ = client.Close()
+			continue
+		}
+
+		allowed := make(map[string]bool, len(server.AllowedTools))
+		for _, name := range server.AllowedTools {
+			allowed[name] = true
+		}
+
+		registered := 0
+		seen := make(map[string]string, len(remoteTools))
+		for j := range remoteTools {
+			if len(allowed) > 0 && !allowed[remoteTools[j].Name] {
+				continue
+			}
+			tool := newMCPTool(client, &remoteTools[j])
+			if other, duplicate := seen[tool.Name()]; duplicate {
+				manager.logger.Error("Skipping tool %s of MCP server %s: its name %s is already used by tool %s",
+					remoteTools[j].Name, server.Name, tool.Name(), other)
+				continue
+			}
+			if err := RegisterExternal(tool, server.Agents); err != nil {
+				manager.logger.Error("Skipping tool %s of MCP server %s: %v", remoteTools[j].Name, server.Name, err)
+				continue
+			}
+			seen[tool.Name()] = remoteTools[j].Name
+			manager.tools = append(manager.tools, tool.Name())
+			registered++
+		}
+
+		manager.clients = append(manager.clients, client)
+		manager.logger.Info("Connected to MCP server %s: registered %d of %d tools", server.Name, registered, len(remoteTools))
+	}
+
+	return manager
+}
+
+// ToolNames returns the namespaced names of all tools registered by this manager.
+func (m *MCPManager) ToolNames() []string {
+	return append([]string(nil), m.tools...)
+}
+
+// Close unregisters the manager's tools and shuts down all server processes.
+func (m *MCPManager) Close() {
+	for _, name := range m.tools {
+		UnregisterExternal(name)
+	}
+	m.tools = nil
+	for _, client := range m.clients {
+		if err := client.Close(); err != nil {
+			m.logger.Warn("Failed to close MCP server %s: %v", client.Name(), err)
+		}
+	}
+	m.clients = nil
+}
@@ -0,0 +1,170 @@
+package tools
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"orchestrator/pkg/config"
+	"orchestrator/pkg/logx"
+)
+
+// buildFixtureServer compiles the stdio MCP server fixture into a temp directory.
+func buildFixtureServer(t *testing.T) string {
+	t.Helper()
+	binary := filepath.Join(t.TempDir(), "mcpserver")
+	cmd := exec.Command("go", "build", "-o", binary, "./testdata/mcpserver")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("Cannot build MCP fixture server: %v\n%s", err, output)
+	}
+	return binary
+}
+
+func TestStartMCPServers_RegistersNamespacedTools(t *testing.T) {
+	binary := buildFixtureServer(t)
+
+	manager := StartMCPServers(context.Background(), []config.MCPServerConfig{{
+		Name:    "fixture",
+		Command: binary,
+		Agents:  []string{config.MCPAgentCoder},
+	}})
+	defer manager.Close()
+
+	names := ExternalToolNames(config.MCPAgentCoder)
+	if len(names) != 2 || names[0] != "fixture__echo" || names[1] != "fixture__fail" {
+		t.Fatalf("Expected namespaced fixture tools, got %v", names)
+	}
+	if architectTools := ExternalToolNames(config.MCPAgentArchitect); len(architectTools) != 0 {
+		t.Errorf("Expected no architect tools, got %v", architectTools)
+	}
+
+	provider := NewProvider(AgentContext{}, names)
+	echo, err := provider.Get("fixture__echo")
+	if err != nil {
+		t.Fatalf("Expected echo tool from provider: %v", err)
+	}
+	if echo.Definition().InputSchema.Required[0] != "text" {
+		t.Errorf("Expected remote schema to be preserved, got %+v", echo.Definition().InputSchema)
+	}
+
+	result, err := echo.Exec(context.Background(), map[string]any{"text": "hi"})
+	if err != nil {
+		t.Fatalf("Echo call failed: %v", err)
+	}
+	resultMap := result.(map[string]any)
+	if resultMap["success"] != true || resultMap["output"] != "echo: hi" {
+		t.Errorf("Unexpected echo result: %v", resultMap)
+	}
+
+	fail, err := provider.Get("fixture__fail")
+	if err != nil {
+		t.Fatalf("Expected fail tool from provider: %v", err)
+	}
+	result, err = fail.Exec(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Fail call returned transport error: %v", err)
+	}
+	if result.(map[string]any)["success"] != false {
+		t.Errorf("Expected isError to map to success=false, got %v", result)
+	}
+
+	if len(provider.List()) != 2 {
+		t.Errorf("Expected provider to list both external tools, got %d", len(provider.List()))
+	}
+}
+
+func TestStartMCPServers_AllowedToolsAndCleanup(t *testing.T) {
+	binary := buildFixtureServer(t)
+
+	manager := StartMCPServers(context.Background(), []config.MCPServerConfig{
+		{
+			Name:         "filtered",
+			Command:      binary,
+			AllowedTools: []string{"echo"},
+			Agents:       []string{config.MCPAgentArchitect},
+		},
+		{
+			Name:    "broken",
+			Command: filepath.Join(t.TempDir(), "does-not-exist"),
+			Agents:  []string{config.MCPAgentArchitect},
+		},
+	})
+
+	names := ExternalToolNames(config.MCPAgentArchitect)
+	if len(names) != 1 || names[0] != "filtered__echo" {
+		t.Fatalf("Expected only filtered__echo, got %v", names)
+	}
+
+	manager.Close()
+	if names := ExternalToolNames(config.MCPAgentArchitect); len(names) != 0 {
+		t.Errorf("Expected tools to be unregistered after Close, got %v", names)
+	}
+}
+
+func TestMCPClientReadLoop_DropsDuplicateResponses(t *testing.T) {
+	client := &MCPClient{
+		name:    "fixture",
+		logger:  logx.NewLogger("mcp-test"),
+		pending: make(map[int64]chan *rpcMessage),
+		done:    make(chan struct{}),
+	}
+	replyCh := make(chan *rpcMessage, 1)
+	client.pending[1] = replyCh
+
+	// A duplicate and a late response must not block the loop before the next reply
+	stdout := strings.NewReader(`{"jsonrpc":"2.0","id":1,"result":{"n":1}}
+{"jsonrpc":"2.0","id":1,"result":{"n":2}}
+{"jsonrpc":"2.0","id":7,"result":{}}
+`)
+	go client.readLoop(stdout)
+
+	select {
+	case <-client.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Read loop blocked on a duplicate response")
+	}
+	if reply := <-replyCh; string(reply.Result) != `{"n":1}` {
+		t.Errorf("Expected the first response to be delivered, got %s", reply.Result)
+	}
+	if len(client.pending) != 0 {
+		t.Errorf("Expected the delivered request to be removed from pending, got %v", client.pending)
+	}
+}
+
+func TestRegisterExternal_RejectsBuiltinNames(t *testing.T) {
+	tool := &MCPTool{remoteName: "shell", definition: ToolDefinition{Name: ToolShell}}
+	if err := RegisterExternal(tool, []string{config.MCPAgentCoder}); err == nil {
+		UnregisterExternal(ToolShell)
+		t.Fatal("Expected a tool named like a built-in tool to be rejected")
+	}
+	if names := ExternalToolNames(config.MCPAgentCoder); len(names) != 0 {
+		t.Errorf("Expected no external tools after a rejected registration, got %v", names)
+	}
+}
+
+func TestRegisterExternal_ValidatesToolNames(t *testing.T) {
+	for _, name := range []string{"", "docs__search.docs", "docs__search docs", "docs__" + strings.Repeat("x", MaxToolNameLength)} {
+		tool := &MCPTool{definition: ToolDefinition{Name: name}}
+		if err := RegisterExternal(tool, []string{config.MCPAgentCoder}); err == nil {
+			UnregisterExternal(name)
+			t.Errorf("Expected tool name %q to be rejected", name)
+		}
+	}
+}
+
+func TestNewMCPTool_SanitizesRemoteNames(t *testing.T) {
+	client := &MCPClient{name: "docs", logger: logx.NewLogger("mcp-docs")}
+	tool := newMCPTool(client, &mcpRemoteTool{Name: "search.docs v2"})
+	if tool.Name() != "docs__search_docs_v2" {
+		t.Errorf("Expected a sanitized namespaced name, got %s", tool.Name())
+	}
+	if tool.remoteName != "search.docs v2" {
+		t.Errorf("Expected calls to use the remote name, got %s", tool.remoteName)
+	}
+	if err := ValidateToolName(tool.Name()); err != nil {
+		t.Errorf("Expected the sanitized name to be valid: %v", err)
+	}
+}
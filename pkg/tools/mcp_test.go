@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"orchestrator/pkg/exec"
+	"orchestrator/pkg/policy"
 )
 
 // Tests for MCP tool system
@@ -45,6 +46,29 @@ func TestShellTool_Definition(t *testing.T) {
 	}
 }
 
+func TestShellTool_PolicyViolation(t *testing.T) {
+	p, err := policy.Parse([]byte(`{"rules": [{"id": "no-push", "action": "deny", "commands": ["\\bgit\\s+push\\b"]}]}`))
+	if err != nil {
+		t.Fatalf("Failed to parse policy: %v", err)
+	}
+	auditDir := t.TempDir()
+
+	tool := NewShellTool(exec.NewLocalExec())
+	tool.SetPolicy(policy.NewEnforcer(p, auditDir, "coder-001", "story-1", "app"))
+
+	if _, err := tool.Exec(context.Background(), map[string]any{"cmd": "git push origin main"}); err == nil {
+		t.Fatal("Expected policy violation error")
+	}
+	if _, err := tool.Exec(context.Background(), map[string]any{"cmd": "echo ok"}); err != nil {
+		t.Errorf("Expected allowed command to run, got %v", err)
+	}
+
+	entries, err := policy.ReadAudit(auditDir, "story-1")
+	if err != nil || len(entries) != 1 || entries[0].Command != "git push origin main" {
+		t.Errorf("Expected violation in audit trail, got %+v (err %v)", entries, err)
+	}
+}
+
 func TestShellTool_Exec(t *testing.T) {
 	tool := NewShellTool(exec.NewLocalExec())
 	ctx := context.Background()
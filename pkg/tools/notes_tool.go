@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Notes tool operations.
+const (
+	NotesOpAppend  = "append"
+	NotesOpReplace = "replace"
+	NotesOpRead    = "read"
+
+	// MaxNotesLength bounds the notes so they can be pinned into every request.
+	MaxNotesLength = 8000
+)
+
+// NotesStore holds the scratchpad notes for the story an agent is working on.
+// Implementations persist the notes so they survive compaction, restarts and requeues.
+type NotesStore interface {
+	ReadNotes(ctx context.Context) (string, error)
+	WriteNotes(ctx context.Context, content string) error
+}
+
+// NotesTool lets an agent record findings it must not lose, such as known flaky tests
+// or packages to leave alone. The notes are pinned into the agent's context on every turn.
+type NotesTool struct {
+	store NotesStore
+}
+
+// NewNotesTool creates a notes tool backed by the given store.
+func NewNotesTool(store NotesStore) *NotesTool {
+	return &NotesTool{store: store}
+}
+
+// Definition returns the tool's definition in Claude API format.
+func (n *NotesTool) Definition() ToolDefinition {
+	return ToolDefinition{
+		Name:        ToolNotes,
+		Description: "Read or update your scratchpad notes for this story. Notes are shown to you on every turn and passed to any coder that retries the story",
+		InputSchema: InputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"operation": {
+					Type:        "string",
+					Description: "Operation to perform",
+					Enum:        []string{NotesOpAppend, NotesOpReplace, NotesOpRead},
+				},
+				"content": {
+					Type:        "string",
+					Description: "Text to append, or the full new notes for replace",
+				},
+			},
+			Required: []string{"operation"},
+		},
+	}
+}
+
+// Name returns the tool identifier.
+func (n *NotesTool) Name() string {
+	return ToolNotes
+}
+
+// PromptDocumentation returns markdown documentation for LLM prompts.
+func (n *NotesTool) PromptDocumentation() string {
+	return fmt.Sprintf(`- **notes** - Keep scratchpad notes for this story
+  - Parameters: operation (append, replace, read), content (for append/replace)
+  - Record hard-won findings: flaky tests, packages not to touch, commands that work, decisions made
+  - Notes are pinned into your context on every turn, survive context compaction and are handed to the next coder if the story is retried
+  - Keep them short (max %d characters); use replace to prune stale entries`, MaxNotesLength)
+}
+
+// Exec performs a notes operation.
+func (n *NotesTool) Exec(ctx context.Context, args map[string]any) (any, error) {
+	if n.store == nil {
+		return nil, fmt.Errorf("notes tool requires a notes store")
+	}
+
+	operation, _ := args["operation"].(string)
+	content, _ := args["content"].(string)
+	content = strings.TrimSpace(content)
+
+	current, err := n.store.ReadNotes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notes: %w", err)
+	}
+
+	var updated string
+	switch operation {
+	case NotesOpRead:
+		output := current
+		if output == "" {
+			output = "No notes recorded for this story yet."
+		}
+		return map[string]any{
+			"success": true,
+			"notes":   current,
+			"output":  output,
+		}, nil
+	case NotesOpAppend:
+		if content == "" {
+			return nil, fmt.Errorf("content is required for append")
+		}
+		updated = content
+		if current != "" {
+			updated = current + "\n" + content
+		}
+	case NotesOpReplace:
+		updated = content
+	default:
+		return nil, fmt.Errorf("unknown operation %q (expected %s, %s or %s)", operation, NotesOpAppend, NotesOpReplace, NotesOpRead)
+	}
+
+	if len(updated) > MaxNotesLength {
+		return map[string]any{
+			"success": false,
+			"error":   fmt.Sprintf("notes would be %d characters, over the %d limit - use replace to condense them", len(updated), MaxNotesLength),
+		}, nil
+	}
+
+	if err := n.store.WriteNotes(ctx, updated); err != nil {
+		return nil, fmt.Errorf("failed to save notes: %w", err)
+	}
+
+	return map[string]any{
+		"success": true,
+		"notes":   updated,
+		"output":  fmt.Sprintf("Notes saved (%d characters).", len(updated)),
+	}, nil
+}
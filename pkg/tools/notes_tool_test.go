@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// memoryNotesStore is an in-memory NotesStore for tests.
+type memoryNotesStore struct {
+	content string
+	writes  int
+}
+
+func (m *memoryNotesStore) ReadNotes(_ context.Context) (string, error) {
+	return m.content, nil
+}
+
+func (m *memoryNotesStore) WriteNotes(_ context.Context, content string) error {
+	m.content = content
+	m.writes++
+	return nil
+}
+
+func TestNotesTool(t *testing.T) {
+	store := &memoryNotesStore{}
+	tool := NewNotesTool(store)
+	ctx := context.Background()
+
+	result, err := tool.Exec(ctx, map[string]any{"operation": NotesOpRead})
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if result.(map[string]any)["notes"] != "" {
+		t.Errorf("Expected empty notes, got %+v", result)
+	}
+
+	for _, line := range []string{"TestFoo is flaky", "Don't touch pkg/legacy"} {
+		if _, err := tool.Exec(ctx, map[string]any{"operation": NotesOpAppend, "content": line}); err != nil {
+			t.Fatalf("append failed: %v", err)
+		}
+	}
+	if store.content != "TestFoo is flaky\nDon't touch pkg/legacy" {
+		t.Errorf("Unexpected notes after append: %q", store.content)
+	}
+
+	if _, err := tool.Exec(ctx, map[string]any{"operation": NotesOpReplace, "content": "Only this"}); err != nil {
+		t.Fatalf("replace failed: %v", err)
+	}
+	if store.content != "Only this" {
+		t.Errorf("Unexpected notes after replace: %q", store.content)
+	}
+
+	result, err = tool.Exec(ctx, map[string]any{"operation": NotesOpAppend, "content": strings.Repeat("x", MaxNotesLength)})
+	if err != nil {
+		t.Fatalf("oversized append returned error: %v", err)
+	}
+	if result.(map[string]any)["success"] != false || store.writes != 3 {
+		t.Errorf("Expected oversized notes to be rejected without writing, got %+v", result)
+	}
+
+	if _, err := tool.Exec(ctx, map[string]any{"operation": "delete"}); err == nil {
+		t.Error("Expected unknown operation to fail")
+	}
+}
@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"orchestrator/pkg/proto"
 )
@@ -36,6 +37,27 @@ func (a *AskQuestionTool) Definition() ToolDefinition {
 					Description: "How critical this question is for proceeding",
 					Enum:        []string{string(proto.PriorityLow), string(proto.PriorityMedium), string(proto.PriorityHigh)},
 				},
+				"options": {
+					Type:        "array",
+					Description: "Candidate answers when the question is a choice between alternatives",
+					Items:       &Property{Type: "string"},
+				},
+				"recommended": {
+					Type:        "string",
+					Description: "The option you would pick (must be one of options)",
+				},
+				"blocking": {
+					Type:        "boolean",
+					Description: "Wait for the answer before continuing (default true). Set false to keep working; the answer is delivered when it arrives",
+				},
+				"category": {
+					Type:        "string",
+					Description: "technical (design/implementation), business (product or requirement decision) or environment (tooling, credentials, infrastructure)",
+					Enum: []string{
+						string(proto.QuestionCategoryTechnical), string(proto.QuestionCategoryBusiness),
+						string(proto.QuestionCategoryEnvironment),
+					},
+				},
 			},
 			Required: []string{"question"},
 		},
@@ -50,9 +72,11 @@ func (a *AskQuestionTool) Name() string {
 // PromptDocumentation returns markdown documentation for LLM prompts.
 func (a *AskQuestionTool) PromptDocumentation() string {
 	return `- **ask_question** - Ask architect for clarification during planning
-  - Parameters: question (required), context, urgency
-  - Handled inline via Effects pattern, blocks until architect's answer received
-  - Use when you need guidance on requirements or technical decisions`
+  - Parameters: question (required), context, urgency, options, recommended, blocking, category
+  - Handled inline via Effects pattern, blocks until architect's answer received unless blocking is false
+  - Use when you need guidance on requirements or technical decisions
+  - For a choice between alternatives, list them in options and name your pick in recommended
+  - Use blocking: false when you can make progress on other work while waiting`
 }
 
 // Exec executes the ask question operation.
@@ -93,16 +117,85 @@ func (a *AskQuestionTool) Exec(_ context.Context, args map[string]any) (any, err
 		}
 	}
 
+	options, recommended, err := parseQuestionOptions(args)
+	if err != nil {
+		return nil, err
+	}
+
+	blocking := true
+	if blockVal, hasBlock := args["blocking"].(bool); hasBlock {
+		blocking = blockVal
+	}
+
+	category := ""
+	if catStr, hasCat := args["category"].(string); hasCat && catStr != "" {
+		if !proto.ValidQuestionCategory(proto.QuestionCategory(catStr)) {
+			return nil, fmt.Errorf("category must be %s, %s, or %s",
+				proto.QuestionCategoryTechnical, proto.QuestionCategoryBusiness, proto.QuestionCategoryEnvironment)
+		}
+		category = catStr
+	}
+
 	return map[string]any{
-		"success":    true,
-		"message":    "Question handled inline via Effects pattern",
-		"question":   questionStr,
-		"context":    context,
-		"urgency":    urgency,
-		"next_state": "INLINE_HANDLED",
+		"success":     true,
+		"message":     "Question handled inline via Effects pattern",
+		"question":    questionStr,
+		"context":     context,
+		"urgency":     urgency,
+		"options":     options,
+		"recommended": recommended,
+		"blocking":    blocking,
+		"category":    category,
+		"next_state":  "INLINE_HANDLED",
 	}, nil
 }
 
+// parseQuestionOptions extracts the candidate options and the recommended option,
+// checking that the recommendation is one of the options.
+func parseQuestionOptions(args map[string]any) ([]string, string, error) {
+	var options []string
+	switch raw := args["options"].(type) {
+	case nil:
+	case []string:
+		options = raw
+	case []any:
+		for _, item := range raw {
+			option, ok := item.(string)
+			if !ok {
+				return nil, "", fmt.Errorf("options must be strings")
+			}
+			options = append(options, option)
+		}
+	default:
+		return nil, "", fmt.Errorf("options must be an array of strings")
+	}
+
+	seen := make(map[string]bool, len(options))
+	cleaned := make([]string, 0, len(options))
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		if option == "" || seen[option] {
+			continue
+		}
+		seen[option] = true
+		cleaned = append(cleaned, option)
+	}
+	if len(cleaned) == 1 {
+		return nil, "", fmt.Errorf("options needs at least two distinct choices")
+	}
+
+	recommended, _ := args["recommended"].(string)
+	recommended = strings.TrimSpace(recommended)
+	if recommended != "" && !seen[recommended] {
+		return nil, "", fmt.Errorf("recommended option %q is not one of the options", recommended)
+	}
+
+	if len(cleaned) == 0 {
+		cleaned = nil
+	}
+	return cleaned, recommended, nil
+}
+
 // SubmitPlanTool finalizes planning and triggers review.
 type SubmitPlanTool struct{}
 
@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAskQuestionToolStructuredFields(t *testing.T) {
+	tool := NewAskQuestionTool()
+
+	result, err := tool.Exec(context.Background(), map[string]any{
+		"question":    "Which storage backend?",
+		"options":     []any{"postgres", " sqlite ", "postgres"},
+		"recommended": "sqlite",
+		"blocking":    false,
+		"category":    "technical",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resultMap := result.(map[string]any)
+	options := resultMap["options"].([]string)
+	if len(options) != 2 || options[1] != "sqlite" {
+		t.Errorf("Expected trimmed, de-duplicated options, got %v", options)
+	}
+	if resultMap["recommended"] != "sqlite" || resultMap["blocking"] != false || resultMap["category"] != "technical" {
+		t.Errorf("Unexpected structured fields: %v", resultMap)
+	}
+
+	result, err = tool.Exec(context.Background(), map[string]any{"question": "Plain question"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.(map[string]any)["blocking"] != true {
+		t.Error("Expected questions to block by default")
+	}
+}
+
+func TestAskQuestionToolValidation(t *testing.T) {
+	tool := NewAskQuestionTool()
+	for name, args := range map[string]map[string]any{
+		"recommended not an option":   {"question": "q", "options": []any{"a", "b"}, "recommended": "c"},
+		"recommended without options": {"question": "q", "recommended": "a"},
+		"single option":               {"question": "q", "options": []any{"a"}},
+		"non-string option":           {"question": "q", "options": []any{"a", 2}},
+		"unknown category":            {"question": "q", "category": "legal"},
+	} {
+		if _, err := tool.Exec(context.Background(), args); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
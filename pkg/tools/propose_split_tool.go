@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"orchestrator/pkg/proto"
+)
+
+// Bounds on the number of sub-stories in a split.
+const (
+	minSplitStories = 2
+	maxSplitStories = 8
+)
+
+// ProposeSplitTool proposes replacing the current story with smaller sub-stories.
+type ProposeSplitTool struct{}
+
+// NewProposeSplitTool creates a new propose split tool instance.
+func NewProposeSplitTool() *ProposeSplitTool {
+	return &ProposeSplitTool{}
+}
+
+// Definition returns the tool's definition in Claude API format.
+func (p *ProposeSplitTool) Definition() ToolDefinition {
+	return ToolDefinition{
+		Name:        ToolProposeSplit,
+		Description: "Propose splitting the story into smaller sub-stories for architect approval",
+		InputSchema: InputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"reason": {
+					Type:        "string",
+					Description: "Why the story is too large to deliver as one unit of work",
+				},
+				"stories": {
+					Type:        "array",
+					Description: "Sub-stories in implementation order; together they must cover the whole original story",
+					Items: &Property{
+						Type: "object",
+						Properties: map[string]*Property{
+							"title": {
+								Type:        "string",
+								Description: "Short title of the sub-story",
+							},
+							"content": {
+								Type:        "string",
+								Description: "Description and acceptance criteria of the sub-story",
+							},
+							"depends_on": {
+								Type:        "array",
+								Description: "Numbers (1-based) of earlier sub-stories in this list that must be completed first",
+								Items:       &Property{Type: "integer"},
+							},
+						},
+						Required: []string{"title", "content"},
+					},
+					MinItems: &[]int{minSplitStories}[0],
+					MaxItems: &[]int{maxSplitStories}[0],
+				},
+			},
+			Required: []string{"reason", "stories"},
+		},
+	}
+}
+
+// Name returns the tool identifier.
+func (p *ProposeSplitTool) Name() string {
+	return ToolProposeSplit
+}
+
+// PromptDocumentation returns markdown documentation for LLM prompts.
+func (p *ProposeSplitTool) PromptDocumentation() string {
+	return `- **propose_split** - Propose splitting the story into smaller sub-stories
+  - Parameters: reason (required), stories (required, 2-8 items of title, content, depends_on)
+  - depends_on lists the 1-based numbers of earlier sub-stories in the same proposal
+  - Use when exploration shows the story is really several independent pieces of work
+  - Advances to PLAN_REVIEW; once approved the sub-stories replace this story`
+}
+
+// Exec validates the proposed split.
+func (p *ProposeSplitTool) Exec(_ context.Context, args map[string]any) (any, error) {
+	reason, _ := args["reason"].(string)
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("reason parameter is required")
+	}
+
+	rawStories, ok := args["stories"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("stories must be an array")
+	}
+	if len(rawStories) < minSplitStories || len(rawStories) > maxSplitStories {
+		return nil, fmt.Errorf("stories must contain between %d and %d sub-stories, got %d", minSplitStories, maxSplitStories, len(rawStories))
+	}
+
+	stories := make([]proto.SubStorySpec, 0, len(rawStories))
+	for i, raw := range rawStories {
+		storyMap, ok := raw.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("sub-story %d must be an object", i+1)
+		}
+
+		title, _ := storyMap["title"].(string)
+		content, _ := storyMap["content"].(string)
+		title, content = strings.TrimSpace(title), strings.TrimSpace(content)
+		if title == "" || content == "" {
+			return nil, fmt.Errorf("sub-story %d needs a title and content", i+1)
+		}
+
+		dependsOn, err := parseSplitDependencies(storyMap["depends_on"], i)
+		if err != nil {
+			return nil, err
+		}
+
+		stories = append(stories, proto.SubStorySpec{
+			Title:     title,
+			Content:   content,
+			DependsOn: dependsOn,
+		})
+	}
+
+	return map[string]any{
+		"success":    true,
+		"message":    fmt.Sprintf("Split into %d sub-stories submitted, advancing to PLAN_REVIEW", len(stories)),
+		"reason":     reason,
+		"stories":    stories,
+		"next_state": "PLAN_REVIEW",
+	}, nil
+}
+
+// parseSplitDependencies converts the 1-based depends_on numbers of sub-story index into
+// 0-based indexes. Only earlier sub-stories may be depended on, which keeps the split acyclic.
+func parseSplitDependencies(raw any, index int) ([]int, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	rawDeps, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("depends_on of sub-story %d must be an array", index+1)
+	}
+
+	deps := make([]int, 0, len(rawDeps))
+	for _, rawDep := range rawDeps {
+		var number int
+		switch v := rawDep.(type) {
+		case float64:
+			number = int(v)
+		case int:
+			number = v
+		default:
+			return nil, fmt.Errorf("depends_on of sub-story %d must contain numbers", index+1)
+		}
+		if number < 1 || number > index {
+			return nil, fmt.Errorf("sub-story %d can only depend on earlier sub-stories, got %d", index+1, number)
+		}
+		deps = append(deps, number-1)
+	}
+	return deps, nil
+}
@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"orchestrator/pkg/proto"
+)
+
+func TestProposeSplitTool_Exec(t *testing.T) {
+	tool := NewProposeSplitTool()
+
+	result, err := tool.Exec(context.Background(), map[string]any{
+		"reason": "The story covers the API, the storage layer and the UI",
+		"stories": []any{
+			map[string]any{"title": "Storage layer", "content": "Add the tables"},
+			map[string]any{"title": "API", "content": "Add the endpoints", "depends_on": []any{float64(1)}},
+			map[string]any{"title": "UI", "content": "Add the page", "depends_on": []any{float64(1), float64(2)}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+
+	resultMap := result.(map[string]any)
+	if resultMap["next_state"] != "PLAN_REVIEW" {
+		t.Errorf("Expected next_state PLAN_REVIEW, got %v", resultMap["next_state"])
+	}
+	stories := resultMap["stories"].([]proto.SubStorySpec)
+	if len(stories) != 3 {
+		t.Fatalf("Expected 3 sub-stories, got %d", len(stories))
+	}
+	if len(stories[0].DependsOn) != 0 {
+		t.Errorf("Expected the first sub-story to have no dependencies, got %v", stories[0].DependsOn)
+	}
+	if deps := stories[2].DependsOn; len(deps) != 2 || deps[0] != 0 || deps[1] != 1 {
+		t.Errorf("Expected 1-based dependencies to become indexes [0 1], got %v", deps)
+	}
+}
+
+func TestProposeSplitTool_ExecInvalid(t *testing.T) {
+	tool := NewProposeSplitTool()
+	story := func(title string, deps ...any) map[string]any {
+		s := map[string]any{"title": title, "content": "content"}
+		if len(deps) > 0 {
+			s["depends_on"] = deps
+		}
+		return s
+	}
+
+	invalid := map[string]map[string]any{
+		"missing reason": {"stories": []any{story("a"), story("b")}},
+		"single story":   {"reason": "r", "stories": []any{story("a")}},
+		"missing title":  {"reason": "r", "stories": []any{story("a"), story("")}},
+		"forward dep":    {"reason": "r", "stories": []any{story("a", float64(2)), story("b")}},
+		"self dep":       {"reason": "r", "stories": []any{story("a"), story("b", float64(2))}},
+		"non-number dep": {"reason": "r", "stories": []any{story("a"), story("b", "1")}},
+	}
+	for name, args := range invalid {
+		if _, err := tool.Exec(context.Background(), args); err == nil {
+			t.Errorf("%s: expected the split to be rejected", name)
+		}
+	}
+}
@@ -3,11 +3,13 @@ package tools
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 
 	"orchestrator/pkg/build"
 	execpkg "orchestrator/pkg/exec"
+	"orchestrator/pkg/policy"
 )
 
 // AgentContext contains agent+state specific configuration for tool creation.
@@ -18,6 +20,10 @@ type AgentContext struct {
 	ReadOnly        bool
 	NetworkDisabled bool
 	WorkDir         string
+	Policy          *policy.Enforcer // Optional command policy for the shell tool
+	Notes           NotesStore       // Optional per-story notes store for the notes tool
+	Todos           TodoTracker      // Optional plan-todo tracker for the update_todo tool
+	BuildService    *build.Service   // Build service for the run_tests and coverage tools
 }
 
 // ToolFactory creates a tool instance configured for a specific agent context.
@@ -91,6 +97,84 @@ func ListTools() []ToolMeta {
 	return result
 }
 
+// externalToolEntry is a tool supplied at runtime by an external source such as an MCP server.
+type externalToolEntry struct {
+	tool       Tool
+	agentTypes map[string]struct{}
+}
+
+// externalRegistry holds runtime-discovered tools. Unlike the global registry it is not
+// sealed, since external servers are only known once the project configuration is loaded.
+//
+//nolint:govet // fieldalignment: Logical grouping preferred over memory optimization
+type externalRegistry struct {
+	mu    sync.RWMutex
+	tools map[string]externalToolEntry
+}
+
+// Global external tool registry.
+//
+//nolint:gochecknoglobals // Shared with all ToolProviders, mirrors globalRegistry
+var externalTools = &externalRegistry{
+	tools: make(map[string]externalToolEntry),
+}
+
+// RegisterExternal adds a ready-made tool instance for the given agent types (e.g. "coder").
+// Replaces any existing external tool with the same name. Built-in tool names cannot be shadowed,
+// and names must be valid tool names.
+func RegisterExternal(tool Tool, agentTypes []string) error {
+	if err := ValidateToolName(tool.Name()); err != nil {
+		return err
+	}
+
+	globalRegistry.mu.RLock()
+	_, builtin := globalRegistry.tools[tool.Name()]
+	globalRegistry.mu.RUnlock()
+	if builtin {
+		return fmt.Errorf("external tool '%s' conflicts with a built-in tool", tool.Name())
+	}
+
+	types := make(map[string]struct{}, len(agentTypes))
+	for _, agentType := range agentTypes {
+		types[agentType] = struct{}{}
+	}
+
+	externalTools.mu.Lock()
+	defer externalTools.mu.Unlock()
+	externalTools.tools[tool.Name()] = externalToolEntry{tool: tool, agentTypes: types}
+	return nil
+}
+
+// UnregisterExternal removes an external tool by name.
+func UnregisterExternal(name string) {
+	externalTools.mu.Lock()
+	defer externalTools.mu.Unlock()
+	delete(externalTools.tools, name)
+}
+
+// ExternalToolNames returns the sorted names of external tools available to the given agent type.
+func ExternalToolNames(agentType string) []string {
+	externalTools.mu.RLock()
+	defer externalTools.mu.RUnlock()
+
+	names := make([]string, 0, len(externalTools.tools))
+	for name, entry := range externalTools.tools {
+		if _, ok := entry.agentTypes[agentType]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getExternalTool looks up an external tool by name.
+func getExternalTool(name string) (Tool, bool) {
+	externalTools.mu.RLock()
+	defer externalTools.mu.RUnlock()
+	entry, ok := externalTools.tools[name]
+	return entry.tool, ok
+}
+
 // ToolProvider creates and manages tool instances for a specific agent+state context.
 //
 //nolint:govet // fieldalignment: Logical grouping preferred over memory optimization
@@ -139,6 +223,11 @@ func (p *ToolProvider) Get(name string) (Tool, error) {
 	globalRegistry.mu.RUnlock()
 
 	if !exists {
+		// Fall back to tools supplied by external MCP servers
+		if external, ok := getExternalTool(name); ok {
+			p.tools[name] = external
+			return external, nil
+		}
 		return nil, fmt.Errorf("tool '%s' not registered", name)
 	}
 
@@ -170,6 +259,13 @@ func (p *ToolProvider) List() []ToolMeta {
 	for name := range p.allowSet {
 		if desc, ok := globalRegistry.tools[name]; ok {
 			result = append(result, desc.meta)
+		} else if external, ok := getExternalTool(name); ok {
+			def := external.Definition()
+			result = append(result, ToolMeta{
+				Name:        def.Name,
+				Description: def.Description,
+				InputSchema: def.InputSchema,
+			})
 		}
 	}
 	return result
@@ -207,12 +303,55 @@ func createShellTool(ctx AgentContext) (Tool, error) {
 		return nil, fmt.Errorf("shell tool requires an executor")
 	}
 
-	return NewShellToolWithConfig(
+	tool := NewShellToolWithConfig(
 		ctx.Executor,
 		ctx.ReadOnly,
 		ctx.NetworkDisabled,
 		nil, // No resource limits by default
-	), nil
+	)
+	tool.SetPolicy(ctx.Policy)
+	return tool, nil
+}
+
+// createGitTool creates a git tool instance bound to the agent's workspace.
+func createGitTool(ctx AgentContext) (Tool, error) {
+	if ctx.Executor == nil {
+		return nil, fmt.Errorf("git tool requires an executor")
+	}
+
+	tool := NewGitTool(ctx.Executor, ctx.WorkDir, ctx.ReadOnly)
+	tool.SetPolicy(ctx.Policy)
+	return tool, nil
+}
+
+// createRollbackTool creates a rollback tool bound to the agent's workspace.
+func createRollbackTool(ctx AgentContext) (Tool, error) {
+	if ctx.Executor == nil {
+		return nil, fmt.Errorf("rollback tool requires an executor")
+	}
+	if ctx.ReadOnly {
+		return nil, fmt.Errorf("rollback tool is not available in read-only mode")
+	}
+
+	tool := NewRollbackTool(ctx.Executor, ctx.WorkDir)
+	tool.SetPolicy(ctx.Policy)
+	return tool, nil
+}
+
+// createNotesTool creates a notes tool bound to the agent's current story.
+func createNotesTool(ctx AgentContext) (Tool, error) {
+	if ctx.Notes == nil {
+		return nil, fmt.Errorf("notes tool requires a notes store")
+	}
+	return NewNotesTool(ctx.Notes), nil
+}
+
+// createUpdateTodoTool creates an update_todo tool bound to the agent's approved plan.
+func createUpdateTodoTool(ctx AgentContext) (Tool, error) {
+	if ctx.Todos == nil {
+		return nil, fmt.Errorf("update_todo tool requires a todo tracker")
+	}
+	return NewUpdateTodoTool(ctx.Todos), nil
 }
 
 // createSubmitPlanTool creates a submit plan tool instance.
@@ -230,6 +369,16 @@ func createMarkStoryCompleteTool(_ AgentContext) (Tool, error) {
 	return NewMarkStoryCompleteTool(), nil
 }
 
+// createSubmitTestsTool creates a submit tests tool instance.
+func createSubmitTestsTool(_ AgentContext) (Tool, error) {
+	return NewSubmitTestsTool(), nil
+}
+
+// createProposeSplitTool creates a propose split tool instance.
+func createProposeSplitTool(_ AgentContext) (Tool, error) {
+	return NewProposeSplitTool(), nil
+}
+
 // createBuildTool creates a build tool instance.
 func createBuildTool(_ AgentContext) (Tool, error) {
 	// TODO: Properly inject build.Service via AgentContext
@@ -246,6 +395,32 @@ func createTestTool(_ AgentContext) (Tool, error) {
 	return NewTestTool(buildSvc), nil
 }
 
+// createRunTestsTool creates a run_tests tool instance bound to the agent's workspace.
+func createRunTestsTool(ctx AgentContext) (Tool, error) {
+	if ctx.Executor == nil {
+		return nil, fmt.Errorf("run_tests tool requires an executor")
+	}
+	if ctx.BuildService == nil {
+		return nil, fmt.Errorf("run_tests tool requires a build service")
+	}
+
+	tool := NewRunTestsTool(ctx.Executor, ctx.BuildService, ctx.WorkDir)
+	tool.SetPolicy(ctx.Policy)
+	return tool, nil
+}
+
+// createCoverageTool creates a coverage tool instance bound to the agent's workspace.
+func createCoverageTool(ctx AgentContext) (Tool, error) {
+	if ctx.Executor == nil {
+		return nil, fmt.Errorf("coverage tool requires an executor")
+	}
+	if ctx.BuildService == nil {
+		return nil, fmt.Errorf("coverage tool requires a build service")
+	}
+
+	return NewCoverageTool(ctx.Executor, ctx.BuildService, ctx.WorkDir), nil
+}
+
 // createLintTool creates a lint tool instance.
 func createLintTool(_ AgentContext) (Tool, error) {
 	// TODO: Properly inject build.Service via AgentContext
@@ -302,6 +477,30 @@ func getShellSchema() InputSchema {
 	return NewShellTool(nil).Definition().InputSchema
 }
 
+func getGitSchema() InputSchema {
+	return NewGitTool(nil, "", false).Definition().InputSchema
+}
+
+func getRunTestsSchema() InputSchema {
+	return NewRunTestsTool(nil, nil, "").Definition().InputSchema
+}
+
+func getCoverageSchema() InputSchema {
+	return NewCoverageTool(nil, nil, "").Definition().InputSchema
+}
+
+func getRollbackSchema() InputSchema {
+	return NewRollbackTool(nil, "").Definition().InputSchema
+}
+
+func getNotesSchema() InputSchema {
+	return NewNotesTool(nil).Definition().InputSchema
+}
+
+func getUpdateTodoSchema() InputSchema {
+	return NewUpdateTodoTool(nil).Definition().InputSchema
+}
+
 func getSubmitPlanSchema() InputSchema {
 	return NewSubmitPlanTool().Definition().InputSchema
 }
@@ -314,6 +513,14 @@ func getMarkStoryCompleteSchema() InputSchema {
 	return NewMarkStoryCompleteTool().Definition().InputSchema
 }
 
+func getSubmitTestsSchema() InputSchema {
+	return NewSubmitTestsTool().Definition().InputSchema
+}
+
+func getProposeSplitSchema() InputSchema {
+	return NewProposeSplitTool().Definition().InputSchema
+}
+
 func getBuildSchema() InputSchema {
 	buildSvc := build.NewBuildService()
 	return NewBuildTool(buildSvc).Definition().InputSchema
@@ -378,6 +585,18 @@ func init() {
 		InputSchema: getMarkStoryCompleteSchema(),
 	})
 
+	Register(ToolProposeSplit, createProposeSplitTool, &ToolMeta{
+		Name:        ToolProposeSplit,
+		Description: "Propose splitting the story into smaller sub-stories for architect approval",
+		InputSchema: getProposeSplitSchema(),
+	})
+
+	Register(ToolSubmitTests, createSubmitTestsTool, &ToolMeta{
+		Name:        ToolSubmitTests,
+		Description: "Submit the failing tests written for a test-first story for architect approval",
+		InputSchema: getSubmitTestsSchema(),
+	})
+
 	// Register development tools
 	Register(ToolShell, createShellTool, &ToolMeta{
 		Name:        ToolShell,
@@ -385,6 +604,30 @@ func init() {
 		InputSchema: getShellSchema(),
 	})
 
+	Register(ToolGit, createGitTool, &ToolMeta{
+		Name:        ToolGit,
+		Description: "Structured git status, diff, log, show-at-base and restore operations",
+		InputSchema: getGitSchema(),
+	})
+
+	Register(ToolRollback, createRollbackTool, &ToolMeta{
+		Name:        ToolRollback,
+		Description: "List checkpoint commits and restore the workspace to one",
+		InputSchema: getRollbackSchema(),
+	})
+
+	Register(ToolNotes, createNotesTool, &ToolMeta{
+		Name:        ToolNotes,
+		Description: "Per-story scratchpad notes pinned into the agent's context",
+		InputSchema: getNotesSchema(),
+	})
+
+	Register(ToolUpdateTodo, createUpdateTodoTool, &ToolMeta{
+		Name:        ToolUpdateTodo,
+		Description: "Update the status of an approved-plan todo",
+		InputSchema: getUpdateTodoSchema(),
+	})
+
 	Register(ToolBuild, createBuildTool, &ToolMeta{
 		Name:        ToolBuild,
 		Description: "Build the project using the build system",
@@ -397,6 +640,18 @@ func init() {
 		InputSchema: getTestSchema(),
 	})
 
+	Register(ToolRunTests, createRunTestsTool, &ToolMeta{
+		Name:        ToolRunTests,
+		Description: "Run a filtered subset of tests and return structured results",
+		InputSchema: getRunTestsSchema(),
+	})
+
+	Register(ToolCoverage, createCoverageTool, &ToolMeta{
+		Name:        ToolCoverage,
+		Description: "Measure test coverage of lines changed versus the target branch",
+		InputSchema: getCoverageSchema(),
+	})
+
 	Register(ToolLint, createLintTool, &ToolMeta{
 		Name:        ToolLint,
 		Description: "Run linting on the project code",
@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"orchestrator/pkg/exec"
+	"orchestrator/pkg/policy"
+)
+
+const (
+	// CheckpointCommitPrefix starts the subject of the work-in-progress commits the coder
+	// creates on the story branch when tests pass or a todo is completed. They are squashed
+	// into the story commit in PREPARE_MERGE.
+	CheckpointCommitPrefix = "maestro-checkpoint: "
+
+	// Rollback tool actions.
+	RollbackActionList    = "list"
+	RollbackActionRestore = "restore"
+
+	// rollbackListLimit bounds the number of checkpoints listed.
+	rollbackListLimit = 20
+)
+
+// Checkpoint is one work-in-progress commit on the story branch.
+type Checkpoint struct {
+	Number int    `json:"number"` // 1 is the most recent
+	Hash   string `json:"hash"`
+	Date   string `json:"date"`
+	Label  string `json:"label"`
+}
+
+// RollbackTool lists the coder's checkpoint commits and resets the worktree to one of them.
+type RollbackTool struct {
+	git *GitTool
+}
+
+// NewRollbackTool creates a rollback tool operating on the repository in workDir.
+func NewRollbackTool(executor exec.Executor, workDir string) *RollbackTool {
+	return &RollbackTool{git: NewGitTool(executor, workDir, false)}
+}
+
+// SetPolicy attaches a command policy that is checked against each git command.
+func (r *RollbackTool) SetPolicy(enforcer *policy.Enforcer) {
+	r.git.SetPolicy(enforcer)
+}
+
+// Definition returns the tool's definition in Claude API format.
+func (r *RollbackTool) Definition() ToolDefinition {
+	return ToolDefinition{
+		Name:        ToolRollback,
+		Description: "List the checkpoints saved when tests passed or todos were completed, and restore the workspace to one",
+		InputSchema: InputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"action": {
+					Type:        "string",
+					Description: "list checkpoints, or restore the workspace to one",
+					Enum:        []string{RollbackActionList, RollbackActionRestore},
+				},
+				"checkpoint": {
+					Type:        "string",
+					Description: "restore: checkpoint number from list (1 is the most recent) or commit hash",
+				},
+			},
+			Required: []string{"action"},
+		},
+	}
+}
+
+// Name returns the tool identifier.
+func (r *RollbackTool) Name() string {
+	return ToolRollback
+}
+
+// PromptDocumentation returns markdown documentation for LLM prompts.
+func (r *RollbackTool) PromptDocumentation() string {
+	return `- **rollback** - Return to a known-good state of your work
+  - A checkpoint is saved automatically each time tests pass and each time you mark a todo done
+  - list: show checkpoints, most recent first
+  - restore: reset the workspace to a checkpoint (number from list or hash), discarding ALL changes made after it
+  - Use it when your changes have broken a previously passing tree and you cannot find the way back`
+}
+
+// Exec lists checkpoints or restores one.
+func (r *RollbackTool) Exec(ctx context.Context, args map[string]any) (any, error) {
+	action, _ := args["action"].(string)
+	switch action {
+	case RollbackActionList:
+		checkpoints, err := r.listCheckpoints(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return gitResult(map[string]any{"checkpoints": checkpoints}), nil
+	case RollbackActionRestore:
+		ref, _ := args["checkpoint"].(string)
+		ref = strings.TrimSpace(ref)
+		if ref == "" {
+			return nil, fmt.Errorf("checkpoint is required for restore")
+		}
+		return r.restore(ctx, ref)
+	default:
+		return nil, fmt.Errorf("unknown rollback action %q (expected list or restore)", action)
+	}
+}
+
+// listCheckpoints returns the checkpoint commits on the story branch, most recent first.
+func (r *RollbackTool) listCheckpoints(ctx context.Context) ([]Checkpoint, error) {
+	stdout, err := r.git.run(ctx, "log", "-n", strconv.Itoa(rollbackListLimit), "--format=%H%x1f%aI%x1f%s",
+		"--grep=^"+CheckpointCommitPrefix, "HEAD", "--not", r.git.baseRef(ctx), "--")
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoints := make([]Checkpoint, 0)
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		fields := strings.SplitN(line, "\x1f", 3)
+		if len(fields) != 3 || !strings.HasPrefix(fields[2], CheckpointCommitPrefix) {
+			continue
+		}
+		checkpoints = append(checkpoints, Checkpoint{
+			Number: len(checkpoints) + 1,
+			Hash:   fields[0],
+			Date:   fields[1],
+			Label:  strings.TrimPrefix(fields[2], CheckpointCommitPrefix),
+		})
+	}
+	return checkpoints, nil
+}
+
+// restore resets the worktree to a checkpoint, removing later commits and all uncommitted
+// and untracked changes.
+func (r *RollbackTool) restore(ctx context.Context, ref string) (any, error) {
+	checkpoints, err := r.listCheckpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	target := findCheckpoint(checkpoints, ref)
+	if target == nil {
+		return nil, fmt.Errorf("unknown checkpoint %q (use action=list to see the %d available checkpoints)", ref, len(checkpoints))
+	}
+
+	if _, err := r.git.run(ctx, "reset", "--hard", target.Hash); err != nil {
+		return nil, err
+	}
+	if _, err := r.git.run(ctx, "clean", "-fd"); err != nil {
+		return nil, err
+	}
+
+	return gitResult(map[string]any{
+		"restored": target,
+		"message":  fmt.Sprintf("Workspace restored to checkpoint %d (%s); later changes were discarded", target.Number, target.Label),
+	}), nil
+}
+
+// findCheckpoint resolves a checkpoint by list number or (abbreviated) hash.
+func findCheckpoint(checkpoints []Checkpoint, ref string) *Checkpoint {
+	if n, err := strconv.Atoi(ref); err == nil && n >= 1 && n <= len(checkpoints) {
+		return &checkpoints[n-1]
+	}
+	if len(ref) < 7 {
+		return nil
+	}
+	for i := range checkpoints {
+		if strings.HasPrefix(checkpoints[i].Hash, strings.ToLower(ref)) {
+			return &checkpoints[i]
+		}
+	}
+	return nil
+}
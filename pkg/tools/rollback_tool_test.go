@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"orchestrator/pkg/exec"
+)
+
+func TestRollbackTool_ListAndRestore(t *testing.T) {
+	dir := setupGitRepo(t)
+	runGit(t, dir, "checkout", "-q", "-b", "story-1")
+
+	writeRepoFile(t, dir, "main.go", "package main\n\nfunc main() {}\n")
+	runGit(t, dir, "commit", "-q", "-am", CheckpointCommitPrefix+"tests passed")
+	writeRepoFile(t, dir, "main.go", "package main\n\nfunc main() { broken }\n")
+	runGit(t, dir, "commit", "-q", "-am", CheckpointCommitPrefix+"todo_002 done: break things")
+	writeRepoFile(t, dir, "main.go", "garbage\n")
+	writeRepoFile(t, dir, "scratch.go", "package main\n")
+
+	tool := NewRollbackTool(exec.NewLocalExec(), dir)
+	result, err := tool.Exec(context.Background(), map[string]any{"action": RollbackActionList})
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	checkpoints := result.(map[string]any)["checkpoints"].([]Checkpoint)
+	if len(checkpoints) != 2 {
+		t.Fatalf("Expected 2 checkpoints, got %+v", checkpoints)
+	}
+	if checkpoints[0].Label != "todo_002 done: break things" || checkpoints[1].Label != "tests passed" {
+		t.Errorf("Expected most recent checkpoint first, got %+v", checkpoints)
+	}
+
+	if _, err := tool.Exec(context.Background(), map[string]any{"action": RollbackActionRestore, "checkpoint": "3"}); err == nil {
+		t.Error("Expected an unknown checkpoint to be rejected")
+	}
+
+	if _, err := tool.Exec(context.Background(), map[string]any{"action": RollbackActionRestore, "checkpoint": "2"}); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	if err != nil {
+		t.Fatalf("Failed to read main.go: %v", err)
+	}
+	if string(content) != "package main\n\nfunc main() {}\n" {
+		t.Errorf("Expected main.go from the first checkpoint, got %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "scratch.go")); !os.IsNotExist(err) {
+		t.Error("Expected untracked files to be removed")
+	}
+}
@@ -0,0 +1,265 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"orchestrator/pkg/build"
+	"orchestrator/pkg/exec"
+	"orchestrator/pkg/policy"
+)
+
+const (
+	// junitReportPath and jestReportPath are where report files are written inside the
+	// execution environment. They live outside the workspace so they are never committed.
+	junitReportPath = "/tmp/maestro-run-tests-junit.xml"
+	jestReportPath  = "/tmp/maestro-run-tests-jest.json"
+
+	// runTestsDefaultTimeout is the default test timeout in seconds.
+	runTestsDefaultTimeout = 300
+)
+
+// RunTestsTool runs a filtered subset of tests and returns structured results.
+//
+//nolint:govet // fieldalignment: Logical grouping preferred over memory optimization
+type RunTestsTool struct {
+	executor     exec.Executor
+	buildService *build.Service
+	workDir      string
+	policy       *policy.Enforcer
+}
+
+// NewRunTestsTool creates a run_tests tool. workDir is used both to detect the build
+// backend and as the working directory for the test command.
+func NewRunTestsTool(executor exec.Executor, buildService *build.Service, workDir string) *RunTestsTool {
+	return &RunTestsTool{
+		executor:     executor,
+		buildService: buildService,
+		workDir:      workDir,
+	}
+}
+
+// SetPolicy attaches a command policy that is checked before tests run.
+func (r *RunTestsTool) SetPolicy(enforcer *policy.Enforcer) {
+	r.policy = enforcer
+}
+
+// Definition returns the tool's definition in Claude API format.
+func (r *RunTestsTool) Definition() ToolDefinition {
+	return ToolDefinition{
+		Name:        ToolRunTests,
+		Description: "Run a subset of tests and get structured results: counts, failing tests with their output, durations",
+		InputSchema: InputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"package": {
+					Type:        "string",
+					Description: "Package or directory to test (e.g. ./pkg/foo/... for Go, tests/unit for Python)",
+				},
+				"file": {
+					Type:        "string",
+					Description: "Test file to run (Go runs the file's package)",
+				},
+				"test": {
+					Type:        "string",
+					Description: "Test name filter (go test -run, pytest -k, jest -t)",
+				},
+				"timeout": {
+					Type:        "number",
+					Description: "Timeout in seconds (default: 300)",
+				},
+			},
+			Required: []string{},
+		},
+	}
+}
+
+// Name returns the tool identifier.
+func (r *RunTestsTool) Name() string {
+	return ToolRunTests
+}
+
+// PromptDocumentation returns markdown documentation for LLM prompts.
+func (r *RunTestsTool) PromptDocumentation() string {
+	return `- **run_tests** - Run tests with structured results
+  - Parameters: package, file, test (name filter), timeout (default 300s) - all optional
+  - Supports Go (go test -json), Python (pytest) and JavaScript (jest) projects
+  - Returns: passed/failed/skipped counts, each failing test with its assertion output, durations
+  - Use a filter to iterate on a single failing test instead of re-running the whole suite`
+}
+
+// Exec runs the requested tests.
+func (r *RunTestsTool) Exec(ctx context.Context, args map[string]any) (any, error) {
+	if r.executor == nil {
+		return nil, fmt.Errorf("run_tests requires an executor")
+	}
+
+	target, _ := args["package"].(string)
+	file, _ := args["file"].(string)
+	filter, _ := args["test"].(string)
+	timeout := runTestsDefaultTimeout
+	if timeoutVal, ok := args["timeout"].(float64); ok && timeoutVal > 0 {
+		timeout = int(timeoutVal)
+	}
+	for _, value := range []string{target, file} {
+		if strings.HasPrefix(value, "-") {
+			return nil, fmt.Errorf("invalid test target %q", value)
+		}
+	}
+
+	info, err := r.buildService.GetBackendInfo(r.workDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect build backend: %w", err)
+	}
+
+	return r.runForBackend(ctx, info.Name, testTargets(info.Name, target, file), filter, time.Duration(timeout)*time.Second)
+}
+
+// RunTargets runs all tests of the given packages or files, such as those build.FailedTestTargets
+// found in the output of a full test run, and returns the same result as Exec.
+func (r *RunTestsTool) RunTargets(ctx context.Context, targets []string) (any, error) {
+	if r.executor == nil {
+		return nil, fmt.Errorf("run_tests requires an executor")
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no test targets given")
+	}
+	for _, target := range targets {
+		if strings.HasPrefix(target, "-") {
+			return nil, fmt.Errorf("invalid test target %q", target)
+		}
+	}
+
+	info, err := r.buildService.GetBackendInfo(r.workDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect build backend: %w", err)
+	}
+
+	return r.runForBackend(ctx, info.Name, targets, "", runTestsDefaultTimeout*time.Second)
+}
+
+// runForBackend builds the framework-specific command, runs it and parses the report.
+// Without targets the whole suite runs.
+func (r *RunTestsTool) runForBackend(ctx context.Context, backend string, targets []string, filter string, timeout time.Duration) (any, error) {
+	var cmd []string
+	var reportFile string
+	var parse func([]byte) (*build.TestReport, error)
+
+	switch backend {
+	case "go":
+		if len(targets) == 0 {
+			targets = []string{"./..."}
+		}
+		cmd = []string{"go", "test", "-json", "-count=1"}
+		if filter != "" {
+			cmd = append(cmd, "-run", filter)
+		}
+		cmd = append(cmd, targets...)
+		parse = build.ParseGoTestJSON
+	case "python":
+		cmd = []string{"python", "-m", "pytest", "-q", "--junitxml=" + junitReportPath}
+		if filter != "" {
+			cmd = append(cmd, "-k", filter)
+		}
+		cmd = append(cmd, targets...)
+		reportFile = junitReportPath
+		parse = build.ParseJUnitXML
+	case "node":
+		cmd = []string{"npx", "jest", "--json", "--outputFile=" + jestReportPath}
+		if filter != "" {
+			cmd = append(cmd, "-t", filter)
+		}
+		cmd = append(cmd, targets...)
+		reportFile = jestReportPath
+		parse = build.ParseJestJSON
+	default:
+		return nil, fmt.Errorf("structured test results are not supported for the %s backend - use the test tool instead", backend)
+	}
+
+	if err := r.policy.Check(ToolRunTests, strings.Join(cmd, " "), ""); err != nil {
+		return nil, err
+	}
+
+	opts := &exec.Opts{WorkDir: r.workDir, Timeout: timeout}
+	if reportFile != "" {
+		// Remove stale reports so a crashed run is not mistaken for the previous result
+		_, _ = r.executor.Run(ctx, []string{"rm", "-f", reportFile}, opts)
+	}
+
+	// Some executors report a non-zero exit as an error; failing tests still leave a report to parse
+	result, err := r.executor.Run(ctx, cmd, opts)
+	if err != nil && result.ExitCode == 0 {
+		return nil, fmt.Errorf("failed to run tests: %w", err)
+	}
+
+	reportData := []byte(result.Stdout)
+	if reportFile != "" {
+		catResult, catErr := r.executor.Run(ctx, []string{"cat", reportFile}, opts)
+		if catErr != nil || catResult.ExitCode != 0 {
+			return rawTestResult(backend, &result, "test report was not written"), nil
+		}
+		reportData = []byte(catResult.Stdout)
+	}
+
+	report, err := parse(reportData)
+	if err != nil {
+		return rawTestResult(backend, &result, err.Error()), nil
+	}
+
+	return map[string]any{
+		"success":          result.ExitCode == 0 && report.Failed == 0,
+		"backend":          backend,
+		"command":          strings.Join(cmd, " "),
+		"exit_code":        result.ExitCode,
+		"passed":           report.Passed,
+		"failed":           report.Failed,
+		"skipped":          report.Skipped,
+		"duration_seconds": report.Duration,
+		"failures":         report.Failures,
+		"report":           report,
+		"output":           report.Summary(),
+	}, nil
+}
+
+// rawTestResult reports a run whose output could not be parsed, so the model still sees why.
+func rawTestResult(backend string, result *exec.Result, reason string) map[string]any {
+	output := strings.TrimSpace(result.Stdout + "\n" + result.Stderr)
+	return map[string]any{
+		"success":   false,
+		"backend":   backend,
+		"exit_code": result.ExitCode,
+		"output":    output,
+		"error":     fmt.Sprintf("could not parse structured test results: %s", reason),
+	}
+}
+
+// goPackageForFile returns the Go package pattern containing a test file.
+func goPackageForFile(file string) string {
+	dir := path.Dir(path.Clean(file))
+	if dir == "." {
+		return "."
+	}
+	if path.IsAbs(dir) || strings.HasPrefix(dir, "./") || strings.HasPrefix(dir, "../") {
+		return dir
+	}
+	return "./" + dir
+}
+
+// testTargets returns the command targets for the optional package and file arguments. Go
+// runs the package of a file rather than the file itself.
+func testTargets(backend, target, file string) []string {
+	if backend == "go" && file != "" {
+		return []string{goPackageForFile(file)}
+	}
+	var targets []string
+	if file != "" {
+		targets = append(targets, file)
+	}
+	if target != "" {
+		targets = append(targets, target)
+	}
+	return targets
+}
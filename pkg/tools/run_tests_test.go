@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"orchestrator/pkg/build"
+	"orchestrator/pkg/exec"
+)
+
+func TestRunTestsTool_GoProject(t *testing.T) {
+	if _, err := osexec.LookPath("go"); err != nil {
+		t.Skip("go not available")
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":       "module example.com/calc\n\ngo 1.21\n",
+		"calc.go":      "package calc\n\nfunc Add(a, b int) int { return a + b }\n",
+		"calc_test.go": "package calc\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {\n\tif Add(1, 1) != 2 {\n\t\tt.Fatal(\"bad\")\n\t}\n}\n\nfunc TestWrong(t *testing.T) {\n\tt.Errorf(\"want %d, got %d\", 3, Add(1, 1))\n}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	tool := NewRunTestsTool(exec.NewLocalExec(), build.NewBuildService(), dir)
+	ctx := context.Background()
+
+	result, err := tool.Exec(ctx, map[string]any{})
+	if err != nil {
+		t.Fatalf("run_tests failed: %v", err)
+	}
+	resultMap := result.(map[string]any)
+	if resultMap["success"] != false || resultMap["passed"] != 1 || resultMap["failed"] != 1 {
+		t.Errorf("Unexpected result: %+v", resultMap)
+	}
+	failures := resultMap["failures"].([]build.TestCase)
+	if len(failures) != 1 || failures[0].Name != "TestWrong" || !strings.Contains(failures[0].Output, "want 3, got 2") {
+		t.Errorf("Unexpected failures: %+v", failures)
+	}
+
+	result, err = tool.Exec(ctx, map[string]any{"file": "calc_test.go", "test": "TestAdd"})
+	if err != nil {
+		t.Fatalf("filtered run_tests failed: %v", err)
+	}
+	resultMap = result.(map[string]any)
+	if resultMap["success"] != true || resultMap["passed"] != 1 || resultMap["failed"] != 0 {
+		t.Errorf("Expected filtered run to pass, got %+v", resultMap)
+	}
+}
+
+func TestRunTestsTool_RejectsOptionTargets(t *testing.T) {
+	tool := NewRunTestsTool(exec.NewLocalExec(), build.NewBuildService(), t.TempDir())
+	if _, err := tool.Exec(context.Background(), map[string]any{"package": "-exec=rm"}); err == nil {
+		t.Error("Expected option-like package to be rejected")
+	}
+}
+
+func TestGoPackageForFile(t *testing.T) {
+	tests := map[string]string{
+		"calc_test.go":            ".",
+		"pkg/calc/calc_test.go":   "./pkg/calc",
+		"./pkg/calc/calc_test.go": "./pkg/calc",
+	}
+	for file, want := range tests {
+		if got := goPackageForFile(file); got != want {
+			t.Errorf("goPackageForFile(%q) = %q, want %q", file, got, want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Self-review verdicts.
+const (
+	SelfReviewClean = "clean"
+	SelfReviewFix   = "fix"
+)
+
+// SubmitSelfReviewTool records the coder's critique of its own diff before code review.
+type SubmitSelfReviewTool struct{}
+
+// NewSubmitSelfReviewTool creates a new submit self-review tool instance.
+func NewSubmitSelfReviewTool() *SubmitSelfReviewTool {
+	return &SubmitSelfReviewTool{}
+}
+
+// Definition returns the tool's definition in Claude API format.
+func (s *SubmitSelfReviewTool) Definition() ToolDefinition {
+	return ToolDefinition{
+		Name:        ToolSubmitSelfReview,
+		Description: "Submit the result of reviewing your own diff before it goes to the architect",
+		InputSchema: InputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"verdict": {
+					Type:        "string",
+					Description: "clean if the diff is ready for the architect, fix if you found issues to correct first",
+					Enum:        []string{SelfReviewClean, SelfReviewFix},
+				},
+				"issues": {
+					Type:        "array",
+					Description: "Issues to fix, each naming the file and what is wrong (required for fix)",
+					Items:       &Property{Type: "string"},
+				},
+				"note": {
+					Type:        "string",
+					Description: "Short note for the architect: what you checked and anything reviewers should know",
+				},
+			},
+			Required: []string{"verdict", "note"},
+		},
+	}
+}
+
+// Name returns the tool identifier.
+func (s *SubmitSelfReviewTool) Name() string {
+	return ToolSubmitSelfReview
+}
+
+// PromptDocumentation returns markdown documentation for LLM prompts.
+func (s *SubmitSelfReviewTool) PromptDocumentation() string {
+	return `- **submit_self_review** - Submit your review of your own diff
+  - Parameters: verdict (clean or fix), issues (required for fix), note (required)
+  - fix returns you to CODING with the issues listed; clean sends the diff to the architect with your note`
+}
+
+// Exec validates the self-review.
+func (s *SubmitSelfReviewTool) Exec(_ context.Context, args map[string]any) (any, error) {
+	verdict, _ := args["verdict"].(string)
+	verdict = strings.TrimSpace(verdict)
+	if verdict != SelfReviewClean && verdict != SelfReviewFix {
+		return nil, fmt.Errorf("verdict must be %s or %s, got %q", SelfReviewClean, SelfReviewFix, verdict)
+	}
+
+	note, _ := args["note"].(string)
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil, fmt.Errorf("note parameter is required")
+	}
+
+	var issues []string
+	if rawIssues, ok := args["issues"].([]any); ok {
+		for _, raw := range rawIssues {
+			if issue, ok := raw.(string); ok && strings.TrimSpace(issue) != "" {
+				issues = append(issues, strings.TrimSpace(issue))
+			}
+		}
+	}
+	if verdict == SelfReviewFix && len(issues) == 0 {
+		return nil, fmt.Errorf("issues are required when the verdict is %s", SelfReviewFix)
+	}
+
+	return map[string]any{
+		"success": true,
+		"verdict": verdict,
+		"issues":  issues,
+		"note":    note,
+	}, nil
+}
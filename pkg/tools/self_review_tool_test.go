@@ -0,0 +1,38 @@
+package tools
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSubmitSelfReviewTool_Exec(t *testing.T) {
+	tool := NewSubmitSelfReviewTool()
+
+	result, err := tool.Exec(context.Background(), map[string]any{
+		"verdict": SelfReviewFix,
+		"issues":  []any{"main.go: remove debug print", "  ", "handler.go: missing test for error path"},
+		"note":    "Found leftover debugging",
+	})
+	if err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	issues := result.(map[string]any)["issues"].([]string)
+	if len(issues) != 2 {
+		t.Errorf("Expected blank issues to be dropped, got %v", issues)
+	}
+
+	invalid := []map[string]any{
+		{"verdict": "maybe", "note": "n"},
+		{"verdict": SelfReviewClean},
+		{"verdict": SelfReviewFix, "note": "n"},
+	}
+	for _, args := range invalid {
+		if _, err := tool.Exec(context.Background(), args); err == nil {
+			t.Errorf("Expected %v to be rejected", args)
+		}
+	}
+
+	if _, err := tool.Exec(context.Background(), map[string]any{"verdict": SelfReviewClean, "note": "Checked debug code and tests"}); err != nil {
+		t.Errorf("Expected a clean review without issues to be accepted: %v", err)
+	}
+}
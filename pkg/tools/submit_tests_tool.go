@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SubmitTestsTool submits the failing tests of a test-first story for architect approval.
+type SubmitTestsTool struct{}
+
+// NewSubmitTestsTool creates a new submit tests tool instance.
+func NewSubmitTestsTool() *SubmitTestsTool {
+	return &SubmitTestsTool{}
+}
+
+// Definition returns the tool's definition in Claude API format.
+func (s *SubmitTestsTool) Definition() ToolDefinition {
+	return ToolDefinition{
+		Name:        ToolSubmitTests,
+		Description: "Submit the failing tests written for a test-first story for architect approval",
+		InputSchema: InputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"summary": {
+					Type:        "string",
+					Description: "Which acceptance criteria the tests cover and how",
+				},
+				"tests": {
+					Type:        "array",
+					Description: "Names of the new tests, as reported by run_tests",
+					Items:       &Property{Type: "string"},
+				},
+				"files": {
+					Type:        "array",
+					Description: "Paths of the test files written or changed, relative to the workspace",
+					Items:       &Property{Type: "string"},
+				},
+			},
+			Required: []string{"summary", "tests", "files"},
+		},
+	}
+}
+
+// Name returns the tool identifier.
+func (s *SubmitTestsTool) Name() string {
+	return ToolSubmitTests
+}
+
+// PromptDocumentation returns markdown documentation for LLM prompts.
+func (s *SubmitTestsTool) PromptDocumentation() string {
+	return `- **submit_tests** - Submit the failing tests for this test-first story for architect approval
+  - Parameters: summary (required), tests (required, test names), files (required, test file paths)
+  - Every test is re-run in isolation and must fail before the tests are sent for review
+  - Once approved the test files are frozen and CODING begins`
+}
+
+// Exec validates the submitted tests.
+func (s *SubmitTestsTool) Exec(_ context.Context, args map[string]any) (any, error) {
+	summary, _ := args["summary"].(string)
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return nil, fmt.Errorf("summary parameter is required")
+	}
+
+	tests := stringList(args["tests"])
+	if len(tests) == 0 {
+		return nil, fmt.Errorf("tests must list at least one test name")
+	}
+	files := stringList(args["files"])
+	if len(files) == 0 {
+		return nil, fmt.Errorf("files must list at least one test file")
+	}
+	for _, file := range files {
+		if strings.HasPrefix(file, "/") || strings.Contains(file, "..") {
+			return nil, fmt.Errorf("test file %q must be a path relative to the workspace", file)
+		}
+	}
+
+	return map[string]any{
+		"success": true,
+		"summary": summary,
+		"tests":   tests,
+		"files":   files,
+	}, nil
+}
+
+// stringList collects the non-empty, trimmed strings of a JSON array argument.
+func stringList(raw any) []string {
+	rawItems, ok := raw.([]any)
+	if !ok {
+		return nil
+	}
+	var items []string
+	for _, rawItem := range rawItems {
+		if item, ok := rawItem.(string); ok && strings.TrimSpace(item) != "" {
+			items = append(items, strings.TrimSpace(item))
+		}
+	}
+	return items
+}
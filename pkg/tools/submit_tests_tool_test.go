@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSubmitTestsTool_Exec(t *testing.T) {
+	tool := NewSubmitTestsTool()
+
+	result, err := tool.Exec(context.Background(), map[string]any{
+		"summary": "Covers parsing of valid and malformed input",
+		"tests":   []any{"TestParseValid", " TestParseMalformed ", ""},
+		"files":   []any{"pkg/parser/parser_test.go"},
+	})
+	if err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+
+	resultMap := result.(map[string]any)
+	tests := resultMap["tests"].([]string)
+	if len(tests) != 2 || tests[1] != "TestParseMalformed" {
+		t.Errorf("Expected trimmed non-empty test names, got %v", tests)
+	}
+	if files := resultMap["files"].([]string); len(files) != 1 {
+		t.Errorf("Expected 1 file, got %v", files)
+	}
+}
+
+func TestSubmitTestsTool_ExecInvalid(t *testing.T) {
+	tool := NewSubmitTestsTool()
+	files := []any{"parser_test.go"}
+	tests := []any{"TestParse"}
+
+	invalid := map[string]map[string]any{
+		"missing summary": {"tests": tests, "files": files},
+		"no tests":        {"summary": "s", "tests": []any{}, "files": files},
+		"no files":        {"summary": "s", "tests": tests},
+		"absolute path":   {"summary": "s", "tests": tests, "files": []any{"/etc/passwd"}},
+		"escaping path":   {"summary": "s", "tests": tests, "files": []any{"../other/x_test.go"}},
+	}
+	for name, args := range invalid {
+		if _, err := tool.Exec(context.Background(), args); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Command mcpserver is a minimal stdio MCP server used by the MCP client tests.
+// It exposes an "echo" tool and a "fail" tool that always reports an error.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+type request struct {
+	ID     *int64         `json:"id"`
+	Method string         `json:"method"`
+	Params map[string]any `json:"params"`
+}
+
+func main() {
+	scanner := bufio.NewScanner(os.Stdin)
+	encoder := json.NewEncoder(os.Stdout)
+
+	for scanner.Scan() {
+		var req request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			continue
+		}
+		if req.ID == nil {
+			continue // notification
+		}
+
+		response := map[string]any{"jsonrpc": "2.0", "id": *req.ID}
+		switch req.Method {
+		case "initialize":
+			response["result"] = map[string]any{
+				"protocolVersion": "2024-11-05",
+				"capabilities":    map[string]any{"tools": map[string]any{}},
+				"serverInfo":      map[string]any{"name": "fixture", "version": "0.0.1"},
+			}
+		case "tools/list":
+			response["result"] = map[string]any{"tools": []any{
+				map[string]any{
+					"name":        "echo",
+					"description": "Echo the given text",
+					"inputSchema": map[string]any{
+						"type":       "object",
+						"properties": map[string]any{"text": map[string]any{"type": "string"}},
+						"required":   []string{"text"},
+					},
+				},
+				map[string]any{
+					"name":        "fail",
+					"description": "Always fails",
+					"inputSchema": map[string]any{"type": "object"},
+				},
+			}}
+		case "tools/call":
+			name, _ := req.Params["name"].(string)
+			args, _ := req.Params["arguments"].(map[string]any)
+			switch name {
+			case "echo":
+				response["result"] = map[string]any{
+					"content": []any{map[string]any{"type": "text", "text": fmt.Sprintf("echo: %v", args["text"])}},
+				}
+			case "fail":
+				response["result"] = map[string]any{
+					"content": []any{map[string]any{"type": "text", "text": "boom"}},
+					"isError": true,
+				}
+			default:
+				response["error"] = map[string]any{"code": -32602, "message": "unknown tool " + name}
+			}
+		default:
+			response["error"] = map[string]any{"code": -32601, "message": "method not found"}
+		}
+
+		_ = encoder.Encode(response)
+	}
+}
@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"orchestrator/pkg/proto"
+)
+
+// TodoTracker records progress on the approved plan's todos for the current story.
+// UpdateTodo returns the full todo list after the update.
+type TodoTracker interface {
+	UpdateTodo(ctx context.Context, id string, status proto.TodoStatus, note string) ([]proto.StoryTodo, error)
+}
+
+// UpdateTodoTool lets the coder mark approved-plan todos in progress, done or blocked.
+type UpdateTodoTool struct {
+	tracker TodoTracker
+}
+
+// NewUpdateTodoTool creates an update_todo tool backed by the given tracker.
+func NewUpdateTodoTool(tracker TodoTracker) *UpdateTodoTool {
+	return &UpdateTodoTool{tracker: tracker}
+}
+
+// Definition returns the tool's definition in Claude API format.
+func (u *UpdateTodoTool) Definition() ToolDefinition {
+	return ToolDefinition{
+		Name:        ToolUpdateTodo,
+		Description: "Update the status of a todo from your approved plan (in_progress, done, blocked or pending)",
+		InputSchema: InputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"id": {
+					Type:        "string",
+					Description: "Todo ID from the approved plan (e.g. todo_001)",
+				},
+				"status": {
+					Type:        "string",
+					Description: "New status for the todo",
+					Enum: []string{
+						string(proto.TodoInProgress), string(proto.TodoDone),
+						string(proto.TodoBlocked), string(proto.TodoPending),
+					},
+				},
+				"note": {
+					Type:        "string",
+					Description: "Required when blocked: why the todo cannot be done. Optional otherwise",
+				},
+			},
+			Required: []string{"id", "status"},
+		},
+	}
+}
+
+// Name returns the tool identifier.
+func (u *UpdateTodoTool) Name() string {
+	return ToolUpdateTodo
+}
+
+// PromptDocumentation returns markdown documentation for LLM prompts.
+func (u *UpdateTodoTool) PromptDocumentation() string {
+	return `- **update_todo** - Track progress on your approved plan
+  - Parameters: id (e.g. todo_001), status (in_progress, done, blocked, pending), note (required when blocked)
+  - Mark a todo in_progress when you start it and done when it is finished
+  - The architect sees your progress and checks at code review that every todo is done or justified`
+}
+
+// Exec updates a todo's status.
+func (u *UpdateTodoTool) Exec(ctx context.Context, args map[string]any) (any, error) {
+	if u.tracker == nil {
+		return nil, fmt.Errorf("update_todo tool requires a todo tracker")
+	}
+
+	id, _ := args["id"].(string)
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, fmt.Errorf("id parameter is required")
+	}
+
+	statusStr, _ := args["status"].(string)
+	status := proto.TodoStatus(strings.TrimSpace(statusStr))
+	if !proto.ValidTodoStatus(status) {
+		return nil, fmt.Errorf("invalid status %q (expected in_progress, done, blocked or pending)", statusStr)
+	}
+
+	note, _ := args["note"].(string)
+	note = strings.TrimSpace(note)
+	if status == proto.TodoBlocked && note == "" {
+		return nil, fmt.Errorf("note is required when marking a todo blocked")
+	}
+
+	todos, err := u.tracker.UpdateTodo(ctx, id, status, note)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update todo: %w", err)
+	}
+
+	return map[string]any{
+		"success": true,
+		"todos":   todos,
+		"output":  FormatTodoProgress(todos),
+	}, nil
+}
+
+// FormatTodoProgress renders a todo list as a markdown checklist with a progress count.
+func FormatTodoProgress(todos []proto.StoryTodo) string {
+	if len(todos) == 0 {
+		return "No plan todos recorded."
+	}
+
+	done := 0
+	var sb strings.Builder
+	for i := range todos {
+		todo := &todos[i]
+		mark := " "
+		switch todo.Status {
+		case proto.TodoDone:
+			mark = "x"
+			done++
+		case proto.TodoInProgress:
+			mark = "~"
+		case proto.TodoBlocked:
+			mark = "!"
+		}
+		sb.WriteString(fmt.Sprintf("- [%s] %s: %s", mark, todo.ID, todo.Description))
+		if todo.Status == proto.TodoInProgress || todo.Status == proto.TodoBlocked {
+			sb.WriteString(fmt.Sprintf(" (%s)", todo.Status))
+		}
+		if todo.Note != "" {
+			sb.WriteString(" - " + todo.Note)
+		}
+		sb.WriteString("\n")
+	}
+	return fmt.Sprintf("Plan progress: %d/%d done\n%s", done, len(todos), sb.String())
+}
@@ -0,0 +1,38 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"orchestrator/pkg/proto"
+)
+
+func TestFormatTodoProgress(t *testing.T) {
+	todos := []proto.StoryTodo{
+		{ID: "todo_001", Description: "Add handler", Status: proto.TodoDone},
+		{ID: "todo_002", Description: "Write tests", Status: proto.TodoInProgress},
+		{ID: "todo_003", Description: "Update docs", Status: proto.TodoBlocked, Note: "No docs directory"},
+		{ID: "todo_004", Description: "Add metrics", Status: proto.TodoPending},
+	}
+	output := FormatTodoProgress(todos)
+
+	for _, want := range []string{
+		"Plan progress: 1/4 done",
+		"- [x] todo_001: Add handler\n",
+		"- [~] todo_002: Write tests (in_progress)",
+		"- [!] todo_003: Update docs (blocked) - No docs directory",
+		"- [ ] todo_004: Add metrics\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	}
+}
+
+func TestUpdateTodoToolValidation(t *testing.T) {
+	tool := NewUpdateTodoTool(nil)
+	if _, err := tool.Exec(context.Background(), map[string]any{"id": "todo_001", "status": "done"}); err == nil {
+		t.Error("Expected error without a tracker")
+	}
+}
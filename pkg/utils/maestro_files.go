@@ -17,6 +17,8 @@ const (
 	CoderInstructionsFile = "CODER.md"
 	// ArchitectInstructionsFile is the filename for architect-specific user instructions.
 	ArchitectInstructionsFile = "ARCHITECT.md"
+	// ReviewChecklistFile is the filename for the project's code review checklist.
+	ReviewChecklistFile = "REVIEW_CHECKLIST.md"
 
 	// UserInstructionsTokenLimit is the token limit for user instruction files (2000 tokens ~ 8000 chars).
 	UserInstructionsTokenLimit = 2000
@@ -202,3 +204,20 @@ func joinStrings(strs []string, sep string) string {
 	}
 	return result
 }
+
+// LoadReviewChecklist loads the project's review checklist from the .maestro directory.
+// Returns an empty string when the file does not exist.
+func LoadReviewChecklist(workDir string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(workDir, MaestroDir, ReviewChecklistFile))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w (please check file permissions)", ReviewChecklistFile, err)
+	}
+	if len(content) > UserInstructionsCharLimit {
+		return "", fmt.Errorf("%s exceeds character limit of %d (current: %d)",
+			ReviewChecklistFile, UserInstructionsCharLimit, len(content))
+	}
+	return string(content), nil
+}
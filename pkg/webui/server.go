@@ -5,6 +5,7 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
@@ -29,6 +30,48 @@ type StoryProvider interface {
 	GetStoryList() []*architect.QueuedStory
 }
 
+// GraphProvider interface for agents that can provide the story dependency graph.
+type GraphProvider interface {
+	GetStoryGraph() *architect.StoryGraph
+}
+
+// RequestLatencyProvider interface for agents that report how long they take to answer requests.
+type RequestLatencyProvider interface {
+	GetRequestLatencies() architect.RequestLatencyReport
+}
+
+// ClarificationProvider interface for agents that ask a human about a spec before generating stories.
+type ClarificationProvider interface {
+	PendingClarification() *architect.SpecClarification
+	AnswerClarification(id string, answers map[string]string) error
+}
+
+// ClarificationAnswer is the body of POST /api/clarifications.
+type ClarificationAnswer struct {
+	Answers                map[string]string `json:"answers"`
+	ID                     string            `json:"id"`
+	ProceedWithAssumptions bool              `json:"proceed_with_assumptions"`
+}
+
+// SpecRevisionProvider interface for agents that revise an existing spec through an approved change set.
+type SpecRevisionProvider interface {
+	SubmitSpecRevision(specID, content string) error
+	SpecRevisionStatus() architect.SpecRevisionStatus
+	DecideChangeSet(id string, approve bool) error
+}
+
+// SpecRevisionRequest is the body of POST /api/spec-revisions.
+type SpecRevisionRequest struct {
+	SpecID  string `json:"spec_id"`
+	Content string `json:"content"`
+}
+
+// ChangeSetDecision is the body of POST /api/spec-revisions/decision.
+type ChangeSetDecision struct {
+	ID      string `json:"id"`
+	Approve bool   `json:"approve"`
+}
+
 // Server represents the web UI HTTP server.
 type Server struct {
 	dispatcher *dispatch.Dispatcher
@@ -85,8 +128,13 @@ func (s *Server) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/agent/", s.handleAgent)
 	mux.HandleFunc("/api/queues", s.handleQueues)
 	mux.HandleFunc("/api/stories", s.handleStories)
+	mux.HandleFunc("/api/graph", s.handleGraph)
+	mux.HandleFunc("/api/request-latency", s.handleRequestLatency)
 	mux.HandleFunc("/api/upload", s.handleUpload)
 	mux.HandleFunc("/api/answer", s.handleAnswer)
+	mux.HandleFunc("/api/clarifications", s.handleClarifications)
+	mux.HandleFunc("/api/spec-revisions", s.handleSpecRevisions)
+	mux.HandleFunc("/api/spec-revisions/decision", s.handleChangeSetDecision)
 	mux.HandleFunc("/api/shutdown", s.handleShutdown)
 	mux.HandleFunc("/api/logs", s.handleLogs)
 	mux.HandleFunc("/api/healthz", s.handleHealth)
@@ -312,6 +360,96 @@ func (s *Server) handleStories(w http.ResponseWriter, r *http.Request) {
 	s.logger.Debug("Served story information: %d stories", len(stories))
 }
 
+// handleGraph implements GET /api/graph. The format query parameter selects json (default),
+// dot or mermaid output.
+func (s *Server) handleGraph(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = architect.GraphFormatJSON
+	}
+
+	provider := s.findGraphProvider()
+	if provider == nil {
+		http.Error(w, "Architect not available", http.StatusServiceUnavailable)
+		return
+	}
+	graph := provider.GetStoryGraph()
+
+	if format == architect.GraphFormatJSON {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(graph); err != nil {
+			s.logger.Error("Failed to encode graph response: %v", err)
+		}
+		return
+	}
+
+	rendered, err := graph.Render(format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if _, err := io.WriteString(w, rendered); err != nil {
+		s.logger.Error("Failed to write graph response: %v", err)
+	}
+}
+
+// findGraphProvider returns the registered architect if it can provide the story graph.
+func (s *Server) findGraphProvider() GraphProvider {
+	if s.dispatcher == nil {
+		return nil
+	}
+	registeredAgents := s.dispatcher.GetRegisteredAgents()
+	for i := range registeredAgents {
+		if registeredAgents[i].Type == agent.TypeArchitect {
+			if provider, ok := registeredAgents[i].Driver.(GraphProvider); ok {
+				return provider
+			}
+		}
+	}
+	return nil
+}
+
+// handleRequestLatency implements GET /api/request-latency.
+func (s *Server) handleRequestLatency(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	provider := s.findRequestLatencyProvider()
+	if provider == nil {
+		http.Error(w, "Architect not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(provider.GetRequestLatencies()); err != nil {
+		s.logger.Error("Failed to encode request latency response: %v", err)
+	}
+}
+
+// findRequestLatencyProvider returns the registered architect if it reports request latencies.
+func (s *Server) findRequestLatencyProvider() RequestLatencyProvider {
+	if s.dispatcher == nil {
+		return nil
+	}
+	registeredAgents := s.dispatcher.GetRegisteredAgents()
+	for i := range registeredAgents {
+		if registeredAgents[i].Type == agent.TypeArchitect {
+			if provider, ok := registeredAgents[i].Driver.(RequestLatencyProvider); ok {
+				return provider
+			}
+		}
+	}
+	return nil
+}
+
 // handleUpload implements POST /api/upload.
 // validateUploadRequest validates the basic upload request.
 func (s *Server) validateUploadRequest(r *http.Request) error {
@@ -396,6 +534,13 @@ func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A spec ID turns the upload into a revision of that spec, which the
+	// architect accepts while it is busy with other work.
+	if specID := strings.TrimSpace(r.FormValue("spec_id")); specID != "" {
+		s.uploadSpecRevision(w, file, specID)
+		return
+	}
+
 	// Check architect availability
 	if availErr := s.checkArchitectAvailability(); availErr != nil {
 		s.logger.Warn("Architect availability check failed: %v", availErr)
@@ -465,6 +610,47 @@ func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
 	s.logger.Info("Successfully uploaded file: %s (%d bytes)", header.Filename, header.Size)
 }
 
+// uploadSpecRevision submits an uploaded file as a revision of an existing spec.
+func (s *Server) uploadSpecRevision(w http.ResponseWriter, file multipart.File, specID string) {
+	provider := s.findSpecRevisionProvider()
+	if provider == nil {
+		http.Error(w, "Architect not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		s.logger.Error("Failed to read uploaded file: %v", err)
+		http.Error(w, "Failed to read file", http.StatusInternalServerError)
+		return
+	}
+
+	s.submitSpecRevision(w, provider, specID, string(content))
+}
+
+// submitSpecRevision hands a revised spec to the architect and reports the outcome.
+func (s *Server) submitSpecRevision(w http.ResponseWriter, provider SpecRevisionProvider, specID, content string) {
+	if err := provider.SubmitSpecRevision(specID, content); err != nil {
+		if errors.Is(err, architect.ErrRevisionPending) {
+			http.Error(w, "A spec revision is already in progress", http.StatusConflict)
+		} else {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+
+	s.logger.Info("Submitted revision of spec %s (%d bytes)", specID, len(content))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	response := map[string]any{
+		"message": "Spec revision submitted - review the proposed change set before it is applied",
+		"spec_id": specID,
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.logger.Error("Failed to encode spec revision response: %v", err)
+	}
+}
+
 // handleDashboard serves the main dashboard page.
 func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -493,6 +679,144 @@ func (s *Server) handleAnswer(w http.ResponseWriter, _ *http.Request) {
 	http.Error(w, "Not implemented yet", http.StatusNotImplemented)
 }
 
+// handleClarifications implements GET and POST /api/clarifications. GET returns the architect's
+// pending questions about a spec, or 204 when there are none; POST answers them.
+func (s *Server) handleClarifications(w http.ResponseWriter, r *http.Request) {
+	provider := s.findClarificationProvider()
+	if provider == nil {
+		http.Error(w, "Architect not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		clarification := provider.PendingClarification()
+		if clarification == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(clarification); err != nil {
+			s.logger.Error("Failed to encode clarification response: %v", err)
+		}
+
+	case http.MethodPost:
+		var answer ClarificationAnswer
+		if err := json.NewDecoder(r.Body).Decode(&answer); err != nil {
+			http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		if answer.ProceedWithAssumptions {
+			answer.Answers = nil
+		}
+		if err := provider.AnswerClarification(answer.ID, answer.Answers); err != nil {
+			if errors.Is(err, architect.ErrNoPendingClarification) {
+				http.Error(w, "No pending clarification with this ID", http.StatusConflict)
+			} else {
+				http.Error(w, "Failed to answer clarification", http.StatusInternalServerError)
+			}
+			return
+		}
+		s.logger.Info("Answered spec clarification %s (%d answers)", answer.ID, len(answer.Answers))
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// findClarificationProvider returns the registered architect if it can take answers to spec questions.
+func (s *Server) findClarificationProvider() ClarificationProvider {
+	if s.dispatcher == nil {
+		return nil
+	}
+	registeredAgents := s.dispatcher.GetRegisteredAgents()
+	for i := range registeredAgents {
+		if registeredAgents[i].Type == agent.TypeArchitect {
+			if provider, ok := registeredAgents[i].Driver.(ClarificationProvider); ok {
+				return provider
+			}
+		}
+	}
+	return nil
+}
+
+// handleSpecRevisions implements GET and POST /api/spec-revisions. GET returns the state of the
+// current spec revision, including a change set awaiting approval; POST submits a revised spec.
+func (s *Server) handleSpecRevisions(w http.ResponseWriter, r *http.Request) {
+	provider := s.findSpecRevisionProvider()
+	if provider == nil {
+		http.Error(w, "Architect not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(provider.SpecRevisionStatus()); err != nil {
+			s.logger.Error("Failed to encode spec revision status: %v", err)
+		}
+
+	case http.MethodPost:
+		var revision SpecRevisionRequest
+		if err := json.NewDecoder(r.Body).Decode(&revision); err != nil {
+			http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		s.submitSpecRevision(w, provider, revision.SpecID, revision.Content)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleChangeSetDecision implements POST /api/spec-revisions/decision.
+func (s *Server) handleChangeSetDecision(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	provider := s.findSpecRevisionProvider()
+	if provider == nil {
+		http.Error(w, "Architect not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var decision ChangeSetDecision
+	if err := json.NewDecoder(r.Body).Decode(&decision); err != nil {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if err := provider.DecideChangeSet(decision.ID, decision.Approve); err != nil {
+		if errors.Is(err, architect.ErrNoPendingChangeSet) {
+			http.Error(w, "No pending change set with this ID", http.StatusConflict)
+		} else {
+			http.Error(w, "Failed to decide change set", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	s.logger.Info("Change set %s decided (approved: %v)", decision.ID, decision.Approve)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// findSpecRevisionProvider returns the registered architect if it can revise existing specs.
+func (s *Server) findSpecRevisionProvider() SpecRevisionProvider {
+	if s.dispatcher == nil {
+		return nil
+	}
+	registeredAgents := s.dispatcher.GetRegisteredAgents()
+	for i := range registeredAgents {
+		if registeredAgents[i].Type == agent.TypeArchitect {
+			if provider, ok := registeredAgents[i].Driver.(SpecRevisionProvider); ok {
+				return provider
+			}
+		}
+	}
+	return nil
+}
+
 // handleShutdown implements POST /api/shutdown.
 func (s *Server) handleShutdown(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
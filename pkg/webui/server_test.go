@@ -1152,6 +1152,24 @@ func (m *MockArchitectDriver) GetStoryList() []*architect.QueuedStory {
 	return m.stories
 }
 
+// GetStoryGraph implements GraphProvider from the mock's stories.
+func (m *MockArchitectDriver) GetStoryGraph() *architect.StoryGraph {
+	stories := make([]*persistence.Story, 0, len(m.stories))
+	for _, story := range m.stories {
+		stories = append(stories, story.ToPersistenceStory())
+	}
+	return architect.NewStoryGraph(stories)
+}
+
+// GetRequestLatencies implements RequestLatencyProvider with a fixed report.
+func (m *MockArchitectDriver) GetRequestLatencies() architect.RequestLatencyReport {
+	return architect.RequestLatencyReport{
+		Kinds:       []architect.RequestLatencyStats{{Kind: "code", Count: 2, AvgTotalMS: 1500}},
+		Pending:     1,
+		Connections: 3,
+	}
+}
+
 func TestHandleStories(t *testing.T) {
 	// Create temporary directory and stores.
 	tempDir := t.TempDir()
@@ -1223,6 +1241,11 @@ func TestHandleStories(t *testing.T) {
 				Title:           "Create REST API endpoints",
 				Status:          string(architect.StatusDone),
 				EstimatedPoints: 3,
+				EstimatedTokens: 200000,
+				Risk:            persistence.RiskHigh,
+				TokensUsed:      260000,
+				CostUSD:         0.78,
+				Iterations:      17,
 				DependsOn:       []string{"story-002"},
 				AssignedAgent:   "coder-002",
 				StartedAt:       &[]time.Time{time.Now().Add(-2 * time.Hour)}[0],
@@ -1293,6 +1316,9 @@ func TestHandleStories(t *testing.T) {
 		if stories[2].GetStatus() != architect.StatusDone {
 			t.Errorf("Expected third story status to be completed, got %s", stories[2].Status)
 		}
+		if stories[2].EstimatedTokens != 200000 || stories[2].Risk != persistence.RiskHigh || stories[2].Iterations != 17 {
+			t.Errorf("Expected estimates and actuals of the third story, got %+v", stories[2].Story)
+		}
 	})
 
 	t.Run("ArchitectWithNoStories", func(t *testing.T) {
@@ -1338,3 +1364,324 @@ func TestHandleStories(t *testing.T) {
 		}
 	})
 }
+
+// MockClarifyingArchitect is a mock architect with spec questions awaiting answers.
+type MockClarifyingArchitect struct {
+	*MockArchitectDriver
+	pending  *architect.SpecClarification
+	answered map[string]string
+}
+
+// PendingClarification implements ClarificationProvider.
+func (m *MockClarifyingArchitect) PendingClarification() *architect.SpecClarification {
+	return m.pending
+}
+
+// AnswerClarification implements ClarificationProvider.
+func (m *MockClarifyingArchitect) AnswerClarification(id string, answers map[string]string) error {
+	if m.pending == nil || m.pending.ID != id {
+		return architect.ErrNoPendingClarification
+	}
+	m.answered = answers
+	m.pending = nil
+	return nil
+}
+
+func TestHandleClarifications(t *testing.T) {
+	cfg := &config.Config{
+		Agents: &config.AgentConfig{MaxCoders: 1, CoderModel: "test_model", ArchitectModel: "test_model"},
+		Orchestrator: &config.OrchestratorConfig{
+			Models: []config.Model{{Name: "test_model", MaxTPM: 1000, DailyBudget: 10.0, MaxConnections: 2, CPM: 3.0}},
+		},
+	}
+	dispatcher, err := dispatch.NewDispatcher(cfg, limiter.NewLimiter(cfg))
+	if err != nil {
+		t.Fatalf("Failed to create dispatcher: %v", err)
+	}
+	server := NewServer(dispatcher, nil, t.TempDir())
+
+	w := httptest.NewRecorder()
+	server.handleClarifications(w, httptest.NewRequest(http.MethodGet, "/api/clarifications", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 without an architect, got %d", w.Code)
+	}
+
+	mockArchitect := &MockClarifyingArchitect{
+		MockArchitectDriver: NewMockArchitectDriver("architect-001", proto.StateWaiting, nil),
+		pending: &architect.SpecClarification{
+			ID:        "clarify-1",
+			Questions: []architect.ClarificationQuestion{{ID: "q1", Question: "Which database?", Assumption: "SQLite"}},
+		},
+	}
+	dispatcher.Attach(mockArchitect)
+
+	w = httptest.NewRecorder()
+	server.handleClarifications(w, httptest.NewRequest(http.MethodGet, "/api/clarifications", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var pending architect.SpecClarification
+	if err := json.NewDecoder(w.Body).Decode(&pending); err != nil {
+		t.Fatalf("Failed to decode clarification: %v", err)
+	}
+	if pending.ID != "clarify-1" || len(pending.Questions) != 1 || pending.Questions[0].Assumption != "SQLite" {
+		t.Errorf("Unexpected clarification: %+v", pending)
+	}
+
+	body := `{"id": "clarify-1", "answers": {"q1": "PostgreSQL"}}`
+	w = httptest.NewRecorder()
+	server.handleClarifications(w, httptest.NewRequest(http.MethodPost, "/api/clarifications", strings.NewReader(body)))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d", w.Code)
+	}
+	if mockArchitect.answered["q1"] != "PostgreSQL" {
+		t.Errorf("Expected answer to be delivered, got %v", mockArchitect.answered)
+	}
+
+	// The clarification is no longer pending
+	w = httptest.NewRecorder()
+	server.handleClarifications(w, httptest.NewRequest(http.MethodGet, "/api/clarifications", nil))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204 once answered, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	server.handleClarifications(w, httptest.NewRequest(http.MethodPost, "/api/clarifications", strings.NewReader(`{"id": "clarify-1", "proceed_with_assumptions": true}`)))
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for an answered clarification, got %d", w.Code)
+	}
+}
+
+// MockRevisingArchitect is a mock architect that revises existing specs.
+type MockRevisingArchitect struct {
+	*MockArchitectDriver
+	status    architect.SpecRevisionStatus
+	submitted map[string]string
+	decided   map[string]bool
+}
+
+// SubmitSpecRevision implements SpecRevisionProvider.
+func (m *MockRevisingArchitect) SubmitSpecRevision(specID, content string) error {
+	if specID == "" || content == "" {
+		return fmt.Errorf("spec ID and revised content are required")
+	}
+	if m.status.State != architect.RevisionIdle {
+		return architect.ErrRevisionPending
+	}
+	m.submitted[specID] = content
+	m.status.State = architect.RevisionProposing
+	return nil
+}
+
+// SpecRevisionStatus implements SpecRevisionProvider.
+func (m *MockRevisingArchitect) SpecRevisionStatus() architect.SpecRevisionStatus {
+	return m.status
+}
+
+// DecideChangeSet implements SpecRevisionProvider.
+func (m *MockRevisingArchitect) DecideChangeSet(id string, approve bool) error {
+	if m.status.ChangeSet == nil || m.status.ChangeSet.ID != id {
+		return architect.ErrNoPendingChangeSet
+	}
+	m.decided[id] = approve
+	m.status = architect.SpecRevisionStatus{State: architect.RevisionIdle}
+	return nil
+}
+
+// newOfflineTestDispatcher creates a dispatcher without validating external tools.
+func newOfflineTestDispatcher(t *testing.T) *dispatch.Dispatcher {
+	cfg := &config.Config{
+		Agents: &config.AgentConfig{MaxCoders: 1, CoderModel: "test_model", ArchitectModel: "test_model"},
+		Orchestrator: &config.OrchestratorConfig{
+			Models: []config.Model{{Name: "test_model", MaxTPM: 1000, DailyBudget: 10.0, MaxConnections: 2, CPM: 3.0}},
+		},
+	}
+	dispatcher, err := dispatch.NewDispatcher(cfg, limiter.NewLimiter(cfg))
+	if err != nil {
+		t.Fatalf("Failed to create dispatcher: %v", err)
+	}
+	return dispatcher
+}
+
+func TestHandleSpecRevisions(t *testing.T) {
+	dispatcher := newOfflineTestDispatcher(t)
+	server := NewServer(dispatcher, nil, t.TempDir())
+
+	w := httptest.NewRecorder()
+	server.handleSpecRevisions(w, httptest.NewRequest(http.MethodGet, "/api/spec-revisions", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 without an architect, got %d", w.Code)
+	}
+
+	mockArchitect := &MockRevisingArchitect{
+		MockArchitectDriver: NewMockArchitectDriver("architect-001", architect.StateMonitoring, nil),
+		status:              architect.SpecRevisionStatus{State: architect.RevisionIdle},
+		submitted:           make(map[string]string),
+		decided:             make(map[string]bool),
+	}
+	dispatcher.Attach(mockArchitect)
+
+	body := `{"spec_id": "spec-1", "content": "# Revised spec"}`
+	w = httptest.NewRecorder()
+	server.handleSpecRevisions(w, httptest.NewRequest(http.MethodPost, "/api/spec-revisions", strings.NewReader(body)))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d", w.Code)
+	}
+	if mockArchitect.submitted["spec-1"] != "# Revised spec" {
+		t.Errorf("Expected revision to be submitted, got %v", mockArchitect.submitted)
+	}
+
+	w = httptest.NewRecorder()
+	server.handleSpecRevisions(w, httptest.NewRequest(http.MethodPost, "/api/spec-revisions", strings.NewReader(body)))
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 while a revision is pending, got %d", w.Code)
+	}
+
+	mockArchitect.status = architect.SpecRevisionStatus{
+		State:     architect.RevisionPending,
+		ChangeSet: &architect.SpecChangeSet{ID: "cs-1", SpecID: "spec-1", Summary: "Add CSV export"},
+	}
+	w = httptest.NewRecorder()
+	server.handleSpecRevisions(w, httptest.NewRequest(http.MethodGet, "/api/spec-revisions", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var status architect.SpecRevisionStatus
+	if err := json.NewDecoder(w.Body).Decode(&status); err != nil {
+		t.Fatalf("Failed to decode spec revision status: %v", err)
+	}
+	if status.State != architect.RevisionPending || status.ChangeSet == nil || status.ChangeSet.ID != "cs-1" {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	w = httptest.NewRecorder()
+	server.handleChangeSetDecision(w, httptest.NewRequest(http.MethodPost, "/api/spec-revisions/decision", strings.NewReader(`{"id": "cs-1", "approve": true}`)))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d", w.Code)
+	}
+	if approved, ok := mockArchitect.decided["cs-1"]; !ok || !approved {
+		t.Errorf("Expected change set to be approved, got %v", mockArchitect.decided)
+	}
+
+	w = httptest.NewRecorder()
+	server.handleChangeSetDecision(w, httptest.NewRequest(http.MethodPost, "/api/spec-revisions/decision", strings.NewReader(`{"id": "cs-1", "approve": false}`)))
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for a decided change set, got %d", w.Code)
+	}
+}
+
+func TestHandleUploadSpecRevision(t *testing.T) {
+	dispatcher := newOfflineTestDispatcher(t)
+	server := NewServer(dispatcher, nil, t.TempDir())
+
+	mockArchitect := &MockRevisingArchitect{
+		MockArchitectDriver: NewMockArchitectDriver("architect-001", architect.StateMonitoring, nil),
+		status:              architect.SpecRevisionStatus{State: architect.RevisionIdle},
+		submitted:           make(map[string]string),
+		decided:             make(map[string]bool),
+	}
+	dispatcher.Attach(mockArchitect)
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, err := writer.CreateFormFile("file", "spec.md")
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	if _, err := part.Write([]byte("# Revised spec")); err != nil {
+		t.Fatalf("Failed to write content: %v", err)
+	}
+	if err := writer.WriteField("spec_id", "spec-1"); err != nil {
+		t.Fatalf("Failed to write spec ID: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close writer: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/upload", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	// The architect is busy monitoring, which does not block revisions.
+	w := httptest.NewRecorder()
+	server.handleUpload(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d: %s", w.Code, w.Body.String())
+	}
+	if mockArchitect.submitted["spec-1"] != "# Revised spec" {
+		t.Errorf("Expected revision to be submitted, got %v", mockArchitect.submitted)
+	}
+}
+
+func TestHandleGraph(t *testing.T) {
+	dispatcher := newOfflineTestDispatcher(t)
+	server := NewServer(dispatcher, nil, t.TempDir())
+
+	w := httptest.NewRecorder()
+	server.handleGraph(w, httptest.NewRequest(http.MethodGet, "/api/graph", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 without an architect, got %d", w.Code)
+	}
+
+	stories := []*architect.QueuedStory{
+		architect.NewQueuedStory(&persistence.Story{ID: "001", Title: "Set up project", Status: string(architect.StatusDone)}),
+		architect.NewQueuedStory(&persistence.Story{ID: "002", Title: "Add login", Status: string(architect.StatusCoding), AssignedAgent: "coder-001", DependsOn: []string{"001"}}),
+	}
+	dispatcher.Attach(NewMockArchitectDriver("architect-001", architect.StateMonitoring, stories))
+
+	w = httptest.NewRecorder()
+	server.handleGraph(w, httptest.NewRequest(http.MethodGet, "/api/graph", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var graph architect.StoryGraph
+	if err := json.NewDecoder(w.Body).Decode(&graph); err != nil {
+		t.Fatalf("Failed to decode graph: %v", err)
+	}
+	if len(graph.Nodes) != 2 || len(graph.Edges) != 1 || graph.Nodes[1].AssignedAgent != "coder-001" {
+		t.Errorf("Unexpected graph: %+v", graph)
+	}
+
+	for format, want := range map[string]string{"dot": `"001" -> "002";`, "mermaid": "s_001 --> s_002"} {
+		w = httptest.NewRecorder()
+		server.handleGraph(w, httptest.NewRequest(http.MethodGet, "/api/graph?format="+format, nil))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), want) {
+			t.Errorf("Expected %s graph containing %q, got %d: %s", format, want, w.Code, w.Body.String())
+		}
+	}
+
+	w = httptest.NewRecorder()
+	server.handleGraph(w, httptest.NewRequest(http.MethodGet, "/api/graph?format=svg", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown format, got %d", w.Code)
+	}
+}
+
+func TestHandleRequestLatency(t *testing.T) {
+	dispatcher := newOfflineTestDispatcher(t)
+	server := NewServer(dispatcher, nil, t.TempDir())
+
+	w := httptest.NewRecorder()
+	server.handleRequestLatency(w, httptest.NewRequest(http.MethodGet, "/api/request-latency", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 without an architect, got %d", w.Code)
+	}
+
+	dispatcher.Attach(NewMockArchitectDriver("architect-001", architect.StateMonitoring, nil))
+
+	w = httptest.NewRecorder()
+	server.handleRequestLatency(w, httptest.NewRequest(http.MethodGet, "/api/request-latency", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var report architect.RequestLatencyReport
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if report.Pending != 1 || report.Connections != 3 || len(report.Kinds) != 1 || report.Kinds[0].AvgTotalMS != 1500 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	w = httptest.NewRecorder()
+	server.handleRequestLatency(w, httptest.NewRequest(http.MethodPost, "/api/request-latency", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", w.Code)
+	}
+}
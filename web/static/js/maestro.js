@@ -7,6 +7,10 @@ class MaestroUI {
         this.isConnected = true;
         this.autoscroll = true;
         this.queuePollingIntervals = {};
+        this.clarificationId = null;
+        this.changeSetId = null;
+        this.revisionError = null;
+        this.graphSnapshot = null;
         
         this.init();
     }
@@ -32,6 +36,10 @@ class MaestroUI {
         document.getElementById('refresh-data').addEventListener('click', this.refreshData.bind(this));
         document.getElementById('show-escalations').addEventListener('click', this.showEscalations.bind(this));
         document.getElementById('close-modal').addEventListener('click', this.closeModal.bind(this));
+        document.getElementById('clarification-submit').addEventListener('click', () => this.answerClarification(false));
+        document.getElementById('clarification-proceed').addEventListener('click', () => this.answerClarification(true));
+        document.getElementById('revision-approve').addEventListener('click', () => this.decideChangeSet(true));
+        document.getElementById('revision-reject').addEventListener('click', () => this.decideChangeSet(false));
         
         // Log controls
         document.getElementById('log-domain').addEventListener('change', this.onLogDomainChange.bind(this));
@@ -43,8 +51,14 @@ class MaestroUI {
         this.pollAgents();
         this.pollStories();
         this.pollLogs();
+        this.pollClarifications();
+        this.pollSpecRevisions();
+        this.pollGraph();
         setInterval(() => this.pollAgents(), this.pollingInterval);
+        setInterval(() => this.pollClarifications(), this.pollingInterval);
+        setInterval(() => this.pollSpecRevisions(), this.pollingInterval);
         setInterval(() => this.pollStories(), this.pollingInterval);
+        setInterval(() => this.pollGraph(), this.pollingInterval);
         setInterval(() => this.pollLogs(), this.pollingInterval);
         setInterval(() => this.updateLastUpdated(), 1000);
     }
@@ -96,6 +110,183 @@ class MaestroUI {
         }
     }
 
+    async pollClarifications() {
+        try {
+            const response = await fetch('/api/clarifications');
+            if (response.status === 204 || response.status === 503) {
+                this.hideClarification();
+                return;
+            }
+            if (!response.ok) throw new Error('Failed to fetch clarifications');
+
+            const clarification = await response.json();
+            this.showClarification(clarification);
+
+        } catch (error) {
+            console.error('Error polling clarifications:', error);
+        }
+    }
+
+    showClarification(clarification) {
+        const panel = document.getElementById('clarification-panel');
+        const deadline = new Date(clarification.deadline);
+        document.getElementById('clarification-deadline').textContent = `Proceeding with assumptions at ${deadline.toLocaleTimeString()}`;
+
+        // Keep answers being typed: only render a clarification once
+        if (this.clarificationId === clarification.id) return;
+        this.clarificationId = clarification.id;
+
+        document.getElementById('clarification-questions').innerHTML = clarification.questions.map(q => `
+            <div>
+                <label for="clarification-${this.escapeHtml(q.id)}" class="text-sm font-medium text-gray-900">${this.escapeHtml(q.question)}</label>
+                ${q.why ? `<p class="text-xs text-gray-500 mt-1">${this.escapeHtml(q.why)}</p>` : ''}
+                <textarea id="clarification-${this.escapeHtml(q.id)}" data-question-id="${this.escapeHtml(q.id)}" rows="2"
+                    class="mt-1 w-full border border-gray-300 rounded-md px-3 py-2 text-sm"
+                    placeholder="Assumption if unanswered: ${this.escapeHtml(q.assumption)}"></textarea>
+            </div>
+        `).join('');
+        panel.classList.remove('hidden');
+    }
+
+    hideClarification() {
+        this.clarificationId = null;
+        document.getElementById('clarification-panel').classList.add('hidden');
+    }
+
+    async answerClarification(proceedWithAssumptions) {
+        if (!this.clarificationId) return;
+
+        const answers = {};
+        document.querySelectorAll('#clarification-questions textarea').forEach(input => {
+            if (input.value.trim()) {
+                answers[input.dataset.questionId] = input.value.trim();
+            }
+        });
+
+        try {
+            const response = await fetch('/api/clarifications', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({
+                    id: this.clarificationId,
+                    answers: answers,
+                    proceed_with_assumptions: proceedWithAssumptions
+                })
+            });
+
+            if (response.ok) {
+                this.showToast(proceedWithAssumptions ? 'Proceeding with assumptions' : 'Answers sent to architect', 'success');
+                this.hideClarification();
+            } else if (response.status === 409) {
+                this.showToast('These questions were already answered or timed out', 'warning');
+                this.hideClarification();
+            } else {
+                this.showToast('Failed to send answers', 'error');
+            }
+        } catch (error) {
+            console.error('Clarification error:', error);
+            this.showToast('Failed to send answers', 'error');
+        }
+    }
+
+    async pollSpecRevisions() {
+        try {
+            const response = await fetch('/api/spec-revisions');
+            if (response.status === 503) {
+                this.hideRevision();
+                return;
+            }
+            if (!response.ok) throw new Error('Failed to fetch spec revisions');
+
+            const status = await response.json();
+            if (status.state === 'failed' && status.error !== this.revisionError) {
+                this.showToast(`Spec revision failed: ${status.error}`, 'error');
+            }
+            this.revisionError = status.state === 'failed' ? status.error : null;
+
+            if (status.state === 'pending' && status.change_set) {
+                this.showRevision(status.change_set);
+            } else if (status.state === 'proposing') {
+                this.showRevisionProgress();
+            } else {
+                this.hideRevision();
+            }
+
+        } catch (error) {
+            console.error('Error polling spec revisions:', error);
+        }
+    }
+
+    showRevisionProgress() {
+        this.changeSetId = null;
+        document.getElementById('revision-spec').textContent = '';
+        document.getElementById('revision-summary').textContent = 'The architect is comparing the revised spec with the existing stories...';
+        document.getElementById('revision-changes').innerHTML = '';
+        document.getElementById('revision-actions').classList.add('hidden');
+        document.getElementById('revision-panel').classList.remove('hidden');
+    }
+
+    showRevision(changeSet) {
+        // Only render a change set once
+        if (this.changeSetId === changeSet.id) return;
+        this.changeSetId = changeSet.id;
+
+        const section = (title, items, render) => (items && items.length) ? `
+            <div>
+                <h3 class="font-medium text-gray-900">${title}</h3>
+                <ul class="mt-1 space-y-1 text-gray-700">${items.map(item => `<li>${render(item)}</li>`).join('')}</ul>
+            </div>
+        ` : '';
+        const reason = (text) => text ? ` <span class="text-gray-500">- ${this.escapeHtml(text)}</span>` : '';
+
+        document.getElementById('revision-spec').textContent = `Spec ${changeSet.spec_id}`;
+        document.getElementById('revision-summary').textContent = changeSet.summary || 'The architect proposes the following story changes.';
+        document.getElementById('revision-changes').innerHTML = [
+            section('New stories', changeSet.new_stories, s =>
+                `${this.escapeHtml(s.title)} (${this.escapeHtml(s.story_type)}, ${s.estimated_points} pts)${reason(s.reason)}`),
+            section('Modified stories', changeSet.modified_stories, s =>
+                `${this.escapeHtml(s.id)}: ${this.escapeHtml(s.title)}${reason(s.reason)}`),
+            section('Cancelled stories', changeSet.cancelled_stories, s =>
+                `${this.escapeHtml(s.id)}: ${this.escapeHtml(s.title || '')}${reason(s.reason)}`),
+            section('Dependency changes', changeSet.dependency_changes, d =>
+                `${this.escapeHtml(d.story_id)}: ${(d.add || []).map(id => '+' + this.escapeHtml(id)).concat((d.remove || []).map(id => '-' + this.escapeHtml(id))).join(', ')}${reason(d.reason)}`),
+            section('Not applied', changeSet.skipped, note => `<span class="text-gray-500">${this.escapeHtml(note)}</span>`)
+        ].join('') || '<p class="text-gray-500">No story changes are needed.</p>';
+        document.getElementById('revision-actions').classList.remove('hidden');
+        document.getElementById('revision-panel').classList.remove('hidden');
+    }
+
+    hideRevision() {
+        this.changeSetId = null;
+        document.getElementById('revision-panel').classList.add('hidden');
+    }
+
+    async decideChangeSet(approve) {
+        if (!this.changeSetId) return;
+
+        try {
+            const response = await fetch('/api/spec-revisions/decision', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ id: this.changeSetId, approve: approve })
+            });
+
+            if (response.ok) {
+                this.showToast(approve ? 'Change set applied' : 'Change set rejected', 'success');
+                this.hideRevision();
+                this.refreshData();
+            } else if (response.status === 409) {
+                this.showToast('This change set was already decided', 'warning');
+                this.hideRevision();
+            } else {
+                this.showToast('Failed to send decision', 'error');
+            }
+        } catch (error) {
+            console.error('Change set decision error:', error);
+            this.showToast('Failed to send decision', 'error');
+        }
+    }
+
     updateAgentGrid(agents) {
         const grid = document.getElementById('agent-grid');
         grid.innerHTML = '';
@@ -168,6 +359,109 @@ class MaestroUI {
         list.innerHTML = stories.map(story => this.createStoryCard(story)).join('');
     }
 
+    async pollGraph() {
+        try {
+            const response = await fetch('/api/graph');
+            if (response.status === 503) return;
+            if (!response.ok) throw new Error('Failed to fetch story graph');
+
+            // Only re-render when a story changed
+            const snapshot = await response.text();
+            if (snapshot === this.graphSnapshot) return;
+            this.graphSnapshot = snapshot;
+            this.renderGraph(JSON.parse(snapshot));
+
+        } catch (error) {
+            console.error('Error polling story graph:', error);
+        }
+    }
+
+    renderGraph(graph) {
+        const container = document.getElementById('story-graph');
+        const cycles = document.getElementById('graph-cycles');
+
+        if (graph.cycles && graph.cycles.length > 0) {
+            cycles.textContent = `Dependency cycles: ${graph.cycles.map(cycle => cycle.join(' → ')).join('; ')}`;
+            cycles.classList.remove('hidden');
+        } else {
+            cycles.classList.add('hidden');
+        }
+
+        if (!graph.nodes || graph.nodes.length === 0) {
+            container.innerHTML = '<p class="text-center py-8 text-gray-500">No stories available</p>';
+            return;
+        }
+
+        // Lay stories out in columns by dependency depth
+        const dependsOn = {};
+        graph.nodes.forEach(node => { dependsOn[node.id] = []; });
+        graph.edges.forEach(edge => dependsOn[edge.to].push(edge.from));
+
+        const depth = {};
+        const visiting = new Set();
+        const depthOf = (id) => {
+            if (depth[id] !== undefined) return depth[id];
+            if (visiting.has(id)) return 0; // Cycle
+            visiting.add(id);
+            depth[id] = dependsOn[id].reduce((max, dep) => Math.max(max, depthOf(dep) + 1), 0);
+            visiting.delete(id);
+            return depth[id];
+        };
+
+        const columns = [];
+        graph.nodes.forEach(node => {
+            const column = depthOf(node.id);
+            (columns[column] = columns[column] || []).push(node);
+        });
+
+        const nodeWidth = 200, nodeHeight = 48, gapX = 60, gapY = 16;
+        const position = {};
+        columns.forEach((nodes, column) => nodes.forEach((node, row) => {
+            position[node.id] = { x: column * (nodeWidth + gapX), y: row * (nodeHeight + gapY) };
+        }));
+        const width = columns.length * (nodeWidth + gapX) - gapX;
+        const height = Math.max(...columns.map(nodes => nodes.length)) * (nodeHeight + gapY) - gapY;
+
+        const colors = {
+            'new': '#e5e7eb', 'pending': '#fef3c7', 'assigned': '#dbeafe', 'planning': '#e0e7ff',
+            'coding': '#ede9fe', 'done': '#d1fae5', 'cancelled': '#f3f4f6', 'missing': '#fee2e2'
+        };
+        const truncate = (text, max) => text.length > max ? text.slice(0, max - 1) + '…' : text;
+
+        const edges = graph.edges.map(edge => {
+            const from = position[edge.from], to = position[edge.to];
+            const x1 = from.x + nodeWidth, y1 = from.y + nodeHeight / 2;
+            const x2 = to.x, y2 = to.y + nodeHeight / 2;
+            const color = edge.in_cycle ? '#dc2626' : '#9ca3af';
+            return `<path d="M${x1},${y1} C${x1 + gapX / 2},${y1} ${x2 - gapX / 2},${y2} ${x2},${y2}" fill="none" stroke="${color}" stroke-width="${edge.in_cycle ? 2 : 1.5}" marker-end="url(#graph-arrow)"/>`;
+        }).join('');
+
+        const nodes = graph.nodes.map(node => {
+            const { x, y } = position[node.id];
+            const details = [node.status, node.assigned_agent, node.cost_usd > 0 ? `$${node.cost_usd.toFixed(2)}` : ''].filter(Boolean).join(' · ');
+            return `
+                <g>
+                    <title>${this.escapeHtml(`${node.id}: ${node.title}`)}</title>
+                    <rect x="${x}" y="${y}" width="${nodeWidth}" height="${nodeHeight}" rx="6" fill="${colors[node.status] || '#ffffff'}" stroke="#6b7280"${node.status === 'missing' ? ' stroke-dasharray="4"' : ''}/>
+                    <text x="${x + 8}" y="${y + 19}" font-size="12" font-weight="600" fill="#111827">${this.escapeHtml(truncate(`${node.id}: ${node.title}`, 30))}</text>
+                    <text x="${x + 8}" y="${y + 37}" font-size="11" fill="#4b5563">${this.escapeHtml(truncate(details, 34))}</text>
+                </g>
+            `;
+        }).join('');
+
+        container.innerHTML = `
+            <svg width="${width + 2}" height="${height + 2}" viewBox="-1 -1 ${width + 2} ${height + 2}" xmlns="http://www.w3.org/2000/svg">
+                <defs>
+                    <marker id="graph-arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6" markerHeight="6" orient="auto">
+                        <path d="M0,0 L10,5 L0,10 z" fill="#9ca3af"/>
+                    </marker>
+                </defs>
+                ${edges}
+                ${nodes}
+            </svg>
+        `;
+    }
+
     createStoryCard(story) {
         const statusClass = this.getStoryStatusClass(story.status);
         const statusIcon = this.getStoryStatusIcon(story.status);
@@ -190,8 +484,10 @@ class MaestroUI {
                 <div class="text-sm text-gray-500 flex items-center justify-between">
                     <div class="flex items-center space-x-4">
                         ${story.estimated_points ? `<span>📊 ${story.estimated_points} pts</span>` : ''}
+                        ${this.getEstimateInfo(story)}
                         ${story.assigned_agent ? `<span>👤 ${story.assigned_agent}</span>` : ''}
                         ${story.depends_on && story.depends_on.length > 0 ? `<span>🔗 Depends on: ${story.depends_on.join(', ')}</span>` : ''}
+                        ${this.getTodoProgress(story)}
                     </div>
                     <div class="text-xs text-gray-400">
                         ${timeInfo}
@@ -201,6 +497,40 @@ class MaestroUI {
         `;
     }
 
+    getEstimateInfo(story) {
+        if (!story.estimated_tokens && !story.tokens_used) {
+            return '';
+        }
+        const tokens = (n) => n >= 1000000 ? `${(n / 1000000).toFixed(1)}M` : n >= 1000 ? `${Math.round(n / 1000)}k` : `${n}`;
+        const risk = story.risk ? `<span>⚠️ ${this.escapeHtml(story.risk)} risk</span>` : '';
+        const estimate = `${tokens(story.estimated_tokens || 0)} tokens ($${(story.estimated_cost_usd || 0).toFixed(2)})`;
+        if (!story.tokens_used) {
+            return `${risk}<span title="Estimated spend">🎯 ~${estimate}</span>`;
+        }
+        const ratio = story.estimated_tokens ? ` · ${(story.tokens_used / story.estimated_tokens).toFixed(1)}x` : '';
+        const actual = `${tokens(story.tokens_used)} tokens ($${(story.cost_usd || 0).toFixed(2)}), ${story.iterations || 0} LLM round trips`;
+        return `${risk}<span title="Actual vs estimated spend: ${this.escapeHtml(actual)} vs ${this.escapeHtml(estimate)}">🎯 ${actual}${ratio}</span>`;
+    }
+
+    getTodoProgress(story) {
+        if (!story.todos || story.todos.length === 0) {
+            return '';
+        }
+        const done = story.todos.filter(todo => todo.status === 'done').length;
+        const blocked = story.todos.filter(todo => todo.status === 'blocked').length;
+        const current = story.todos.find(todo => todo.status === 'in_progress');
+        const title = story.todos.map(todo => `${todo.id} [${todo.status}] ${todo.description}`).join('\n');
+        return `<span title="${this.escapeHtml(title)}">✅ ${done}/${story.todos.length} todos${blocked ? ` (${blocked} blocked)` : ''}${current ? ` · ${this.escapeHtml(current.description)}` : ''}</span>`;
+    }
+
+    escapeHtml(text) {
+        return String(text)
+            .replace(/&/g, '&amp;')
+            .replace(/</g, '&lt;')
+            .replace(/>/g, '&gt;')
+            .replace(/"/g, '&quot;');
+    }
+
     getStoryStatusClass(status) {
         const statusMap = {
             'pending': 'bg-gray-100 text-gray-800',
@@ -418,6 +748,10 @@ class MaestroUI {
 
         const formData = new FormData();
         formData.append('file', file);
+        const specId = document.getElementById('revise-spec-id').value.trim();
+        if (specId) {
+            formData.append('spec_id', specId);
+        }
 
         try {
             const response = await fetch('/api/upload', {
@@ -425,11 +759,14 @@ class MaestroUI {
                 body: formData
             });
 
-            if (response.ok) {
+            if (response.status === 202) {
+                this.showToast('Spec revision submitted for review', 'success');
+                document.getElementById('revise-spec-id').value = '';
+            } else if (response.ok) {
                 this.showToast('File uploaded successfully', 'success');
                 this.refreshData();
             } else if (response.status === 409) {
-                this.showToast('Architect is busy', 'error');
+                this.showToast(specId ? 'A spec revision is already in progress' : 'Architect is busy', 'error');
             } else {
                 this.showToast('Upload failed', 'error');
             }